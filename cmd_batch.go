@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"prompt-cli/internal/logger"
+	"prompt-cli/internal/runner"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// batchPrompt is one entry of a batch file. YAML files may give each prompt
+// a name, which is used for its output file.
+type batchPrompt struct {
+	Name   string `yaml:"name"`
+	Prompt string `yaml:"prompt"`
+}
+
+// UnmarshalYAML accepts either a plain string or a {name, prompt} mapping.
+func (p *batchPrompt) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&p.Prompt)
+	}
+	type plain batchPrompt
+	return node.Decode((*plain)(p))
+}
+
+// unsafeNameChars matches characters that are replaced in output file names.
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// runBatch implements the "batch" subcommand. It answers every prompt in a
+// file with the same model and tools as the TUI and writes one Markdown file
+// per answer.
+func runBatch(args []string) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	outDir := flags.String("o", "batch-output", "Directory the answers are written to")
+	jobs := flags.Int("j", 1, "Number of prompts processed concurrently")
+	model := flags.String("model", "", "Model to use (default: default_llm)")
+	chatOnly := flags.Bool("chatonly", false, "Answer without the tool-using agent persona")
+	allowWrites := flags.Bool("yolo", false, "Allow the tools that need permission, like file writes, git changes and network tools, without asking")
+	maxSteps := flags.Int("max-steps", 10, "Maximum tool calls per prompt")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *jobs < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: prompt-cli batch [-o dir] [-j N] [-model name] [-chatonly] [-yolo] [-max-steps N] <prompts.txt|prompts.yaml>")
+		return 2
+	}
+
+	configs, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *model == "" {
+		*model = configs.DefaultLLM
+	}
+	if *model == "" {
+		fmt.Fprintln(os.Stderr, "No model: pass -model or set default_llm.")
+		return 2
+	}
+
+	prompts, err := loadBatchPrompts(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading prompts: %v\n", err)
+		return 1
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", *outDir, err)
+		return 1
+	}
+
+	appLogger := logger.NewLogger()
+	if configs.LogEnabled {
+		appLogger.Toggle()
+	}
+	appAgent := newAgent(configs, appLogger)
+	r := &runner.Runner{
+		Client:       newClient(configs, ollamaBaseURL(configs), appLogger),
+		Agent:        appAgent,
+		Model:        *model,
+		SystemPrompt: systemPromptFor(configs, *chatOnly, appAgent),
+		AllowWrites:  *allowWrites,
+		MaxSteps:     *maxSteps,
+	}
+
+	var (
+		mu     sync.Mutex
+		failed int
+		wg     sync.WaitGroup
+	)
+	slots := make(chan struct{}, *jobs)
+	for i, p := range prompts {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, p batchPrompt) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, err := r.Run(context.Background(), p.Prompt)
+			path := filepath.Join(*outDir, batchFileName(i, p))
+			content := result.Answer
+			if err != nil {
+				content = fmt.Sprintf("Error: %v\n\n%s", err, result.Answer)
+			}
+			writeErr := os.WriteFile(path, []byte(content+"\n"), 0644)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failed++
+				fmt.Printf("FAIL %s: %v\n", path, err)
+			case writeErr != nil:
+				failed++
+				fmt.Printf("FAIL %s: %v\n", path, writeErr)
+			default:
+				fmt.Printf("ok   %s (%d tool calls)\n", path, result.Steps)
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	fmt.Printf("\n%d of %d prompts answered, written to %s\n", len(prompts)-failed, len(prompts), *outDir)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// loadBatchPrompts reads a YAML list of prompts, or a text file with one
+// prompt per line where blank lines and lines starting with # are skipped.
+func loadBatchPrompts(path string) ([]batchPrompt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompts []batchPrompt
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &prompts); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	default:
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			prompts = append(prompts, batchPrompt{Prompt: line})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, p := range prompts {
+		if strings.TrimSpace(p.Prompt) == "" {
+			return nil, fmt.Errorf("prompt %d is empty", i+1)
+		}
+	}
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("%s contains no prompts", path)
+	}
+	return prompts, nil
+}
+
+// batchFileName numbers output files in input order so they sort the same
+// way regardless of which prompt finished first.
+func batchFileName(i int, p batchPrompt) string {
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(p.Name, "-"), "-")
+	if name == "" {
+		return fmt.Sprintf("%03d.md", i+1)
+	}
+	return fmt.Sprintf("%03d-%s.md", i+1, name)
+}
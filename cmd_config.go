@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"prompt-cli/internal/secrets"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// runConfig implements the "config" subcommand, which stores the tokens
+// referenced from config.json in the OS keyring.
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: prompt-cli config set-secret <name> [value] | delete-secret <name>")
+		return 2
+	}
+
+	switch args[0] {
+	case "set-secret":
+		if len(args) < 2 || len(args) > 3 {
+			fmt.Fprintln(os.Stderr, "Usage: prompt-cli config set-secret <name> [value]")
+			return 2
+		}
+		name := args[1]
+		var value string
+		if len(args) == 3 {
+			value = args[2]
+		} else {
+			var err error
+			if value, err = readSecret(name); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading the secret: %v\n", err)
+				return 1
+			}
+		}
+		if value == "" {
+			fmt.Fprintln(os.Stderr, "Error: the secret is empty.")
+			return 1
+		}
+		if err := secrets.Set(name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error storing the secret: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Stored %q in the keyring. Use it in config.json as:\n  \"token\": \"%s%s\"\n", name, secrets.Prefix, name)
+		return 0
+
+	case "delete-secret":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: prompt-cli config delete-secret <name>")
+			return 2
+		}
+		if err := secrets.Delete(args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error deleting the secret: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Deleted %q from the keyring.\n", args[1])
+		return 0
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown config command: %s\n", args[0])
+		return 2
+	}
+}
+
+// readSecret asks for the value of a secret without echoing it, or reads the
+// first line of stdin when it is not a terminal, e.g. "gh auth token |
+// prompt-cli config set-secret github".
+func readSecret(name string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(value)), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"prompt-cli/internal/bridge"
+	"prompt-cli/internal/daemon"
+	"prompt-cli/internal/logger"
+	"prompt-cli/internal/ollama"
+	"prompt-cli/internal/rpc"
+	"prompt-cli/internal/types"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// defaultDaemonSocket is where the daemon listens unless -socket is given.
+func defaultDaemonSocket() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("promptcli-daemon-%d.sock", os.Getuid()))
+}
+
+// runDaemon implements the "daemon" subcommand. It hosts sessions in the
+// background until it is stopped, so terminals can attach to them, detach
+// and attach again later.
+func runDaemon(args []string) int {
+	flags := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	socketPath := flags.String("socket", defaultDaemonSocket(), "Unix socket to listen on")
+	chatOnly := flags.Bool("chatonly", false, "Run sessions without the tool-using agent persona")
+	background := flags.Bool("background", false, "Keep running when the starting terminal is closed or interrupted")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	configs, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	appLogger := logger.NewLogger()
+	if configs.LogEnabled {
+		appLogger.Toggle()
+	}
+	appLogger.Setup()
+	baseURL := ollamaBaseURL(configs)
+	models, err := ollama.GetModels(baseURL, appLogger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting models: %v\n", err)
+		return 1
+	}
+	if len(models) == 0 {
+		fmt.Fprintln(os.Stderr, "No models found on the Ollama server.")
+		return 1
+	}
+
+	listener, err := rpc.Listen(*socketPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listening on %s: %v\n", *socketPath, err)
+		return 1
+	}
+	defer listener.Close()
+	signal.Ignore(syscall.SIGHUP)
+	if *background {
+		signal.Ignore(syscall.SIGINT)
+	}
+
+	client := newClient(configs, baseURL, appLogger)
+	d := &daemon.Daemon{NewBridge: func(model string) *bridge.Bridge {
+		if model == "" {
+			model = configs.DefaultLLM
+		}
+		if model == "" {
+			model = models[0].Name
+		}
+		appAgent := newAgent(configs, appLogger)
+		return &bridge.Bridge{
+			Client:       client,
+			Agent:        appAgent,
+			Logger:       appLogger,
+			Model:        model,
+			SystemPrompt: systemPromptFor(configs, *chatOnly, appAgent),
+			Options:      types.Options{NumCtx: configs.ContextLength},
+		}
+	}}
+	handler := func(method string, params json.RawMessage) (interface{}, error) {
+		if method == "shutdown" {
+			listener.Close()
+			return "ok", nil
+		}
+		return d.Handle(method, params)
+	}
+	appLogger.Log(fmt.Sprintf("Daemon listening on %s", *socketPath))
+	if err := rpc.Serve(listener, handler); err != nil {
+		fmt.Fprintf(os.Stderr, "Error serving %s: %v\n", *socketPath, err)
+		return 1
+	}
+	return 0
+}
+
+// runAttach implements the "attach" subcommand: a line-based terminal for a
+// session hosted by the daemon, which is started if it is not running.
+// Without a session ID a new session is started.
+func runAttach(args []string) int {
+	flags := flag.NewFlagSet("attach", flag.ContinueOnError)
+	socketPath := flags.String("socket", defaultDaemonSocket(), "Unix socket of the daemon")
+	model := flags.String("model", "", "Model of a new session (default: default_llm)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: prompt-cli attach [-socket path] [-model name] [session-id]")
+		return 2
+	}
+
+	if err := ensureDaemon(*socketPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting the daemon: %v\n", err)
+		return 1
+	}
+	call := func(method string, params, result interface{}) error {
+		c, err := rpc.Dial(*socketPath)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		return c.Call(method, params, result)
+	}
+
+	var info daemon.Info
+	var err error
+	if id := flags.Arg(0); id != "" {
+		err = call("resume", map[string]string{"session": id}, &info)
+	} else {
+		err = call("new", map[string]string{"model": *model}, &info)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening the session: %v\n", err)
+		return 1
+	}
+	session := map[string]string{"session": info.ID}
+	call("attach", session, nil)
+	defer call("detach", session, nil)
+	fmt.Printf("Attached to %s. /approve, /always or /deny a tool call, /sessions lists the sessions, /detach or Ctrl+D leaves it running.\n\n", info)
+
+	go watchSession(*socketPath, info.ID)
+
+	send := func(method string, params map[string]interface{}) {
+		params["session"] = info.ID
+		go func() {
+			if err := call(method, params, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}()
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+		case "/detach":
+			return 0
+		case "/approve":
+			send("approve", map[string]interface{}{"allow": true})
+		case "/always":
+			send("approve", map[string]interface{}{"allow": true, "always": true})
+		case "/deny":
+			send("approve", map[string]interface{}{"allow": false})
+		case "/sessions":
+			var infos []daemon.Info
+			if err := call("sessions", nil, &infos); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			for _, i := range infos {
+				fmt.Printf("%s, %d attached\n", i, i.Clients)
+			}
+		default:
+			send("chat", map[string]interface{}{"prompt": line})
+		}
+	}
+	return 0
+}
+
+// ensureDaemon starts the daemon in the background unless one answers on
+// socketPath, and waits for it to listen.
+func ensureDaemon(socketPath string) error {
+	if c, err := rpc.Dial(socketPath); err == nil {
+		return c.Close()
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, "daemon", "-background", "-socket", socketPath)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		if c, err := rpc.Dial(socketPath); err == nil {
+			return c.Close()
+		}
+	}
+	return fmt.Errorf("no daemon listening on %s", socketPath)
+}
+
+// watchSession prints the session's messages as they are added, from any
+// terminal, and the tool calls waiting for approval.
+func watchSession(socketPath, id string) {
+	c, err := rpc.Dial(socketPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error following the session: %v\n", err)
+		return
+	}
+	defer c.Close()
+	since, version := 0, -1
+	var shownPending *bridge.Pending
+	for {
+		var snapshot daemon.Snapshot
+		params := map[string]interface{}{"session": id, "since": since, "version": version, "timeout_ms": 30000}
+		if err := c.Call("wait", params, &snapshot); err != nil {
+			fmt.Fprintf(os.Stderr, "Lost the daemon: %v\n", err)
+			os.Exit(1)
+		}
+		for _, msg := range snapshot.Messages {
+			printMessage(msg)
+		}
+		since, version = snapshot.From+len(snapshot.Messages), snapshot.Version
+		if snapshot.Pending == nil {
+			shownPending = nil
+		} else if shownPending == nil {
+			shownPending = snapshot.Pending
+			input, _ := json.Marshal(snapshot.Pending.Input)
+			fmt.Printf("? %s %s\n", snapshot.Pending.Tool, input)
+			if snapshot.Pending.Conflict != "" {
+				fmt.Printf("  %s\n", snapshot.Pending.Conflict)
+			}
+			fmt.Println("  /approve, /always or /deny")
+		}
+	}
+}
+
+// printMessage prints a message of the session for attach.
+func printMessage(msg types.Message) {
+	switch msg.Role {
+	case "system":
+	case "user":
+		fmt.Printf("> %s\n\n", msg.Content)
+	case "tool":
+		switch {
+		case msg.Result == nil:
+			fmt.Printf("  [tool] %s\n", firstLine(msg.Content))
+		case msg.Result.Failed():
+			fmt.Printf("  [%s] %s: %s\n", msg.Result.Tool, msg.Result.ErrorClass, msg.Result.Error)
+		default:
+			fmt.Printf("  [%s] ok, %d ms\n", msg.Result.Tool, msg.Result.DurationMs)
+		}
+	default:
+		if msg.Content != "" {
+			fmt.Printf("%s\n\n", msg.Content)
+		}
+		for _, call := range msg.ToolCalls {
+			args, _ := json.Marshal(call.Function.Arguments)
+			fmt.Printf("  → %s %s\n", call.Function.Name, args)
+		}
+	}
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"prompt-cli/internal/eval"
+	"prompt-cli/internal/logger"
+	"strings"
+)
+
+// runEval implements the "eval" subcommand, which runs a YAML suite of
+// prompts against one or more models and reports pass rates and latency.
+func runEval(args []string) int {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	modelList := flags.String("models", "", "Comma-separated models to evaluate (default: the suite's models, then default_llm)")
+	verbose := flags.Bool("v", false, "Print the answer of every failed case")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: prompt-cli eval [-models a,b] [-v] <suite.yaml>")
+		return 2
+	}
+
+	configs, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	suite, err := eval.LoadSuite(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading suite: %v\n", err)
+		return 1
+	}
+
+	var models []string
+	switch {
+	case *modelList != "":
+		for _, name := range strings.Split(*modelList, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				models = append(models, name)
+			}
+		}
+	case len(suite.Models) > 0:
+		models = suite.Models
+	case configs.DefaultLLM != "":
+		models = []string{configs.DefaultLLM}
+	default:
+		fmt.Fprintln(os.Stderr, "No models to evaluate: pass -models, list them in the suite or set default_llm.")
+		return 2
+	}
+
+	appLogger := logger.NewLogger()
+	if configs.LogEnabled {
+		appLogger.Toggle()
+	}
+	client := newClient(configs, ollamaBaseURL(configs), appLogger)
+
+	results := eval.Run(context.Background(), client, suite, models, func(r eval.Result) {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+		}
+		fmt.Printf("%s  %-20s %-30s %6.2fs", status, r.Model, r.Case, r.Latency.Seconds())
+		if len(r.Failures) > 0 {
+			fmt.Printf("  %s", strings.Join(r.Failures, "; "))
+		}
+		fmt.Println()
+		if *verbose && !r.Passed && r.Answer != "" {
+			fmt.Printf("      %s\n", strings.ReplaceAll(strings.TrimSpace(r.Answer), "\n", "\n      "))
+		}
+	})
+
+	fmt.Println()
+	failed := false
+	for _, s := range eval.Summarize(results) {
+		fmt.Printf("%-20s %d/%d passed (%.0f%%), mean latency %.2fs\n", s.Model, s.Passed, s.Total, 100*float64(s.Passed)/float64(s.Total), s.MeanLatency.Seconds())
+		if s.Passed < s.Total {
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
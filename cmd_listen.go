@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"prompt-cli/internal/bridge"
+	"prompt-cli/internal/config"
+	"prompt-cli/internal/logger"
+	"prompt-cli/internal/rpc"
+	"prompt-cli/internal/types"
+)
+
+// runListen serves the editor bridge on stdin and stdout instead of
+// starting the TUI. Editor plugins start prompt-cli with -listen and talk
+// JSON-RPC to it, one message per line.
+func runListen(configs *config.Config, appLogger *logger.Logger, baseURL string, models []types.Model, chatOnly bool) int {
+	model := configs.DefaultLLM
+	if model == "" {
+		model = models[0].Name
+	}
+	appAgent := newAgent(configs, appLogger)
+	b := &bridge.Bridge{
+		Client:       newClient(configs, baseURL, appLogger),
+		Agent:        appAgent,
+		Logger:       appLogger,
+		Model:        model,
+		SystemPrompt: systemPromptFor(configs, chatOnly, appAgent),
+		Options:      types.Options{NumCtx: configs.ContextLength},
+	}
+	b.Start()
+	err := rpc.ServeConn(os.Stdin, os.Stdout, b.Handle)
+	appAgent.RemoveScratchDir()
+	if configs.PurgeTrashOnExit {
+		appAgent.PurgeTrash()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error serving the editor bridge: %v\n", err)
+		return 1
+	}
+	return 0
+}
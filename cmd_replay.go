@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"prompt-cli/internal/session"
+	"prompt-cli/internal/tui"
+)
+
+// runReplay implements the "replay" subcommand, which steps through a saved
+// session message by message.
+func runReplay(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: prompt-cli replay <session-id>")
+		return 2
+	}
+	s, err := session.Load(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading session %s: %v\n", args[0], err)
+		return 1
+	}
+	if err := tui.Replay(s); err != nil {
+		fmt.Fprintf(os.Stderr, "Error replaying session: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"prompt-cli/internal/session"
+	"strings"
+)
+
+// runSessions implements the "sessions" subcommand for working with saved
+// conversations without starting the TUI.
+func runSessions(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: prompt-cli sessions list | search <query> | export [-o file] [-all] <session-id>...")
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		sessions, err := session.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing sessions: %v\n", err)
+			return 1
+		}
+		for _, s := range sessions {
+			fmt.Printf("%s  %s  %-20s %d messages\n", s.ID, s.Updated.Format("2006-01-02 15:04"), s.Model, len(s.Messages)+s.Archived)
+		}
+		return 0
+
+	case "search":
+		query := strings.Join(args[1:], " ")
+		matches, err := session.Search(query)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error searching sessions: %v\n", err)
+			return 1
+		}
+		if len(matches) == 0 {
+			fmt.Printf("No saved messages match %q.\n", query)
+			return 0
+		}
+		for _, match := range matches {
+			fmt.Printf("%s #%d (%s): %s\n", match.SessionID, match.Index, match.Role, match.Snippet)
+		}
+		fmt.Println("\nResume a session with: prompt-cli -resume <session-id>")
+		return 0
+
+	case "export":
+		return exportSessions(args[1:])
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown sessions command: %s\n", args[0])
+		return 2
+	}
+}
+
+// exportSessions implements "sessions export", writing the selected sessions
+// as chat fine-tuning JSONL.
+func exportSessions(args []string) int {
+	flags := flag.NewFlagSet("sessions export", flag.ContinueOnError)
+	outPath := flags.String("o", "", "Write to this file instead of stdout")
+	all := flags.Bool("all", false, "Export every saved session")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !*all && flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: prompt-cli sessions export [-o file] [-all] <session-id>...")
+		return 2
+	}
+
+	var selected []*session.Session
+	if *all {
+		sessions, err := session.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing sessions: %v\n", err)
+			return 1
+		}
+		selected = sessions
+	} else {
+		for _, id := range flags.Args() {
+			s, err := session.Load(id)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading session %s: %v\n", id, err)
+				return 1
+			}
+			selected = append(selected, s)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", *outPath, err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	written, err := session.ExportFineTune(out, selected)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting sessions: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d of %d sessions.\n", written, len(selected))
+	return 0
+}
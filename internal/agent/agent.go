@@ -4,25 +4,47 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"net/http"
-	"os"
+	"io"
 	"os/exec"
-	"path/filepath"
 	"prompt-cli/internal/logger"
 	"strings"
 	"time"
-
-	"github.com/bmatcuk/doublestar/v4"
 )
 
 // Agent is responsible for executing commands received from the LLM.
 type Agent struct {
-	logger *logger.Logger
+	logger         *logger.Logger
+	roots          map[string]string        // Named workspace roots, see SetRoots.
+	defaultTimeout time.Duration            // Timeout for tools without their own entry.
+	timeouts       map[string]time.Duration // Per-tool timeouts, see SetTimeouts.
+	cache          *toolCache               // Results of deterministic tools for this session.
+	cacheDisabled  bool                     // Set in low-memory mode, see SetCacheEnabled.
+	versions       *versionTracker          // Files read or written this session, see conflict.go.
+	ssh            *sshFS                   // Remote workspace, see SetSSHWorkspace.
+	docker         *DockerConfig            // Container for shell commands, see SetDocker.
+	projects       []Project                // Projects in the workspace, see DetectProjects.
+	enabled        map[string]bool          // Opt-in tools, see EnableTools.
+	forges         map[string]ForgeConfig   // Repositories for the github and gitlab tools.
+	jira           *JiraConfig              // Tracker for the ticket tool.
+	linear         *LinearConfig            // Tracker for the ticket tool.
+	search         SearchConfig             // Defaults for web_search.
+	fetcher        *Fetcher                 // HTTP client for the web tools, see SetFetch.
+	guardConfig    GuardConfig              // Prompt injection guard, see SetGuard.
+	scratchDir     string                   // Per-session scratch space, see UseScratchDir.
+	readAllLimit   int                      // Default byte cap of read_all_files, see SetReadAllLimit.
+	downloadLimit  int                      // Default byte cap of download_file, see SetDownloadLimit.
+	trash          trashCan                 // Files deleted this session, see UseTrash.
+	tasks          taskList                 // Checklist of the task_list tool, see tasks.go.
+	validators     map[string]string        // Checks run after writes by extension, see SetValidators.
+	formatters     map[string]string        // Formatters run after writes by extension, see SetFormatters.
+	hooks          []HookConfig             // Scripts run around tool calls, see SetHooks.
+	changesets     changesetLog             // Applied changesets for /undo, see changeset.go.
+	chunks         chunkedWrites            // write_file calls sent in chunks, see chunks.go.
 }
 
 // NewAgent creates a new Agent.
 func NewAgent(logger *logger.Logger) *Agent {
-	return &Agent{logger: logger}
+	return &Agent{logger: logger, cache: newToolCache(), versions: newVersionTracker(), fetcher: NewFetcher(FetchConfig{}, logger)}
 }
 
 // ExecuteCommand processes the LLM response and executes the specified command.
@@ -30,25 +52,67 @@ func (a *Agent) ExecuteCommand(toolName string, input map[string]interface{}) st
 	if toolName == "" {
 		return "" // Do nothing if the tool name is empty
 	}
+
+	key, cached, ok := a.cachedResult(toolName, input)
+	if ok {
+		a.logger.Log(fmt.Sprintf("Serving %s from cache", toolName))
+		return CachedMarker + cached
+	}
+
+	timeout := a.toolTimeout(toolName, input)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result := a.runWithTimeout(ctx, toolName, timeout, func(ctx context.Context) string {
+		if blocked := a.runBeforeHooks(ctx, toolName, input); blocked != "" {
+			return blocked
+		}
+		return a.runAfterHooks(ctx, toolName, input, a.dispatch(ctx, toolName, input))
+	})
+	result = a.guard(toolName, input, result)
+	a.storeResult(toolName, key, result, input)
+	return result
+}
+
+// dispatch routes a tool call to its handler.
+func (a *Agent) dispatch(ctx context.Context, toolName string, input map[string]interface{}) string {
 	switch toolName {
 	case "write_file":
-		return a.HandleWriteFile(input)
+		return a.writeFile(ctx, input)
 	case "read_file":
-		return a.HandleReadFile(input)
+		return a.HandleReadFile(ctx, input)
 	case "read_all_files":
-		return a.HandleReadAllFiles(input)
+		return a.HandleReadAllFiles(ctx, input)
 	case "list_files":
 		return a.HandleListFiles(input)
 	case "delete_file":
 		return a.HandleDeleteFile(input)
+	case "restore_file":
+		return a.HandleRestoreFile(input)
+	case "task_list":
+		return a.HandleTaskList(input)
+	case "apply_changeset":
+		return a.HandleApplyChangeset(input)
 	case "append_file":
-		return a.HandleAppendFile(input)
+		return a.verifyWrite(ctx, toolName, input, a.HandleAppendFile(input))
 	case "git":
-		return a.HandleGit(input)
+		return a.HandleGit(ctx, input)
 	case "web_search":
-		return a.HandleWebSearch(input)
+		return a.HandleWebSearch(ctx, input)
 	case "visit_url":
-		return a.HandleVisitURL(input)
+		return a.HandleVisitURL(ctx, input)
+	case "read_feed":
+		return a.HandleReadFeed(ctx, input)
+	case "environment":
+		return a.HandleEnvironment(ctx)
+	case "download_file":
+		return a.HandleDownloadFile(ctx, input)
+	case "summarize_data":
+		return a.HandleSummarizeData(input)
+	case "list_archive":
+		return a.HandleListArchive(input)
+	case "extract_archive":
+		return a.HandleExtractArchive(input)
 	case "respond":
 		// This is handled by the UI, but we can log it here.
 		if msg, ok := input["message"].(string); ok {
@@ -56,11 +120,14 @@ func (a *Agent) ExecuteCommand(toolName string, input map[string]interface{}) st
 		}
 		return "" // No further action needed from the handler
 	default:
+		if result, ok := a.runOptional(ctx, toolName, input); ok {
+			return result
+		}
 		return fmt.Sprintf("Unknown command: %s", toolName)
 	}
 }
 
-func (a *Agent) HandleVisitURL(input map[string]interface{}) string {
+func (a *Agent) HandleVisitURL(ctx context.Context, input map[string]interface{}) string {
 	url, ok := input["url"].(string)
 	if !ok {
 		return "Error: 'url' not specified or not a string for visit_url."
@@ -69,14 +136,7 @@ func (a *Agent) HandleVisitURL(input map[string]interface{}) string {
 
 	maxBytes, _ := input["max_bytes"].(float64)
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return fmt.Sprintf("Error creating request for url %s: %v", url, err)
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/109.0.0.0 Safari/537.36")
-
-	client := &http.Client{}
-	res, err := client.Do(req)
+	res, err := a.fetcher.Get(ctx, url, true)
 	if err != nil {
 		return fmt.Sprintf("Error fetching url %s: %v", url, err)
 	}
@@ -86,7 +146,17 @@ func (a *Agent) HandleVisitURL(input map[string]interface{}) string {
 		return fmt.Sprintf("Request to %s failed with status code: %d", url, res.StatusCode)
 	}
 
-	text, err := ExtractTextFromHTML(res.Body)
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxDocumentBytes))
+	if err != nil {
+		return fmt.Sprintf("Error reading %s: %v", url, err)
+	}
+
+	var text string
+	if kind := documentKind(body, res.Header.Get("Content-Type"), res.Request.URL.Path); kind != "" {
+		text, err = extractDocumentText(ctx, body, kind)
+	} else {
+		text, err = ExtractTextFromHTML(bytes.NewReader(body))
+	}
 	if err != nil {
 		return fmt.Sprintf("Error extracting text from %s: %v", url, err)
 	}
@@ -98,14 +168,25 @@ func (a *Agent) HandleVisitURL(input map[string]interface{}) string {
 	return text
 }
 
-func (a *Agent) HandleWebSearch(input map[string]interface{}) string {
+func (a *Agent) HandleWebSearch(ctx context.Context, input map[string]interface{}) string {
 	query, ok := input["q"].(string)
 	if !ok {
 		return "Error: 'q' not specified or not a string for web_search."
 	}
 	a.logger.Log(fmt.Sprintf("HandleWebSearch query: %s", query))
 
-	results, err := PerformWebSearch(query, a.logger)
+	opts := SearchOptions{MaxResults: a.search.MaxResults, PerDomain: a.search.PerDomain}
+	if n, ok := input["max_results"].(float64); ok && n > 0 {
+		opts.MaxResults = int(n)
+	}
+	if site, ok := input["site"].(string); ok {
+		opts.Site = site
+	}
+	if days, ok := input["recency_days"].(float64); ok {
+		opts.RecencyDays = int(days)
+	}
+
+	results, err := PerformWebSearch(ctx, a.fetcher, query, opts, a.logger)
 	if err != nil {
 		return fmt.Sprintf("Error performing web search: %v", err)
 	}
@@ -113,69 +194,27 @@ func (a *Agent) HandleWebSearch(input map[string]interface{}) string {
 	return results
 }
 
-func (a *Agent) HandleReadFile(input map[string]interface{}) string {
+func (a *Agent) HandleReadFile(ctx context.Context, input map[string]interface{}) string {
 	path, ok := input["path"].(string)
 	if !ok {
 		return "Error: 'path' not specified or not a string for read_file."
 	}
-
-	content, err := os.ReadFile(path)
+	fullPath, err := a.ResolvePath(path)
 	if err != nil {
-		return fmt.Sprintf("Error reading file '%s': %v", path, err)
-	}
-
-	return string(content)
-}
-
-func (a *Agent) HandleReadAllFiles(input map[string]interface{}) string {
-	glob, ok := input["glob"].(string)
-	if !ok || glob == "" {
-		return "Error: 'glob' pattern not specified or not a string for read_all_files."
+		return fmt.Sprintf("Error: %v", err)
 	}
 
-	path, _ := input["path"].(string)
-	if path == "" {
-		path = "."
-	}
-
-	fsys := os.DirFS(path)
-	filePaths, err := doublestar.Glob(fsys, glob)
+	content, err := a.files().ReadFile(fullPath)
 	if err != nil {
-		return fmt.Sprintf("Error matching glob pattern '%s': %v", glob, err)
-	}
-
-	if len(filePaths) == 0 {
-		return fmt.Sprintf("No files found matching glob pattern '%s' in directory '%s'", glob, path)
-	}
-
-	var builder strings.Builder
-
-	for _, filePath := range filePaths {
-		// doublestar.Glob returns paths relative to the fsys root, so we need to join them with the base path
-		// to read the actual file from the OS.
-		fullPath := filepath.Join(path, filePath)
-
-		content, err := os.ReadFile(fullPath)
-		if err != nil {
-			// Log the error but continue with other files
-			a.logger.Log(fmt.Sprintf("Error reading file '%s', skipping: %v", fullPath, err))
-			continue
-		}
-
-		header := fmt.Sprintf("---\nFile: %s\n---\n", filePath) // Use relative path in header for clarity
-		builder.WriteString(header)
-		builder.Write(content)
-		builder.WriteString("\n\n")
+		return fmt.Sprintf("Error reading file '%s': %v", path, err)
 	}
+	a.recordVersion(fullPath, content)
 
-	// Handle max_bytes
-	maxBytes, _ := input["max_bytes"].(float64)
-	output := builder.String()
-	if maxBytes > 0 && len(output) > int(maxBytes) {
-		output = output[:int(maxBytes)]
+	text, err := readableText(ctx, content, path)
+	if err != nil {
+		return fmt.Sprintf("Error extracting text from '%s': %v", path, err)
 	}
-
-	return output
+	return text
 }
 
 func (a *Agent) HandleWriteFile(input map[string]interface{}) string {
@@ -188,26 +227,35 @@ func (a *Agent) HandleWriteFile(input map[string]interface{}) string {
 		return "Error: 'content' not specified or not a string for write_file."
 	}
 	mode, _ := input["mode"].(string) // Default is effectively "overwrite" if not specified
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if conflict := a.conflictError(path, fullPath); conflict != "" {
+		return conflict
+	}
 
 	var responseToLLM string
 
 	if mode == "create_only" {
-		_, err := os.Stat(path)
+		_, err := a.files().Stat(fullPath)
 		if err == nil {
 			responseToLLM = fmt.Sprintf("File '%s' already exists.", path)
 		} else {
-			err := os.WriteFile(path, []byte(content), 0644)
+			err := a.files().WriteFile(fullPath, []byte(content))
 			if err != nil {
 				responseToLLM = fmt.Sprintf("Error creating file '%s': %v", path, err)
 			} else {
+				a.recordVersion(fullPath, []byte(content))
 				responseToLLM = fmt.Sprintf("File '%s' created successfully.", path)
 			}
 		}
 	} else { // "overwrite" is the default
-		err := os.WriteFile(path, []byte(content), 0644)
+		err := a.files().WriteFile(fullPath, []byte(content))
 		if err != nil {
 			responseToLLM = fmt.Sprintf("Error writing to file '%s': %v", path, err)
 		} else {
+			a.recordVersion(fullPath, []byte(content))
 			responseToLLM = fmt.Sprintf("File '%s' overwritten successfully.", path)
 		}
 	}
@@ -224,16 +272,18 @@ func (a *Agent) HandleAppendFile(input map[string]interface{}) string {
 	if !ok {
 		return "Error: 'content' not specified or not a string for append_file."
 	}
-
-	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	fullPath, err := a.ResolvePath(path)
 	if err != nil {
-		return fmt.Sprintf("Error opening file '%s': %v", path, err)
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if conflict := a.conflictError(path, fullPath); conflict != "" {
+		return conflict
 	}
-	defer f.Close()
 
-	if _, err := f.WriteString(content); err != nil {
+	if err := a.files().AppendFile(fullPath, []byte(content)); err != nil {
 		return fmt.Sprintf("Error appending to file '%s': %v", path, err)
 	}
+	a.refreshVersion(fullPath)
 
 	return fmt.Sprintf("Content appended to file '%s' successfully.", path)
 }
@@ -243,41 +293,54 @@ func (a *Agent) HandleDeleteFile(input map[string]interface{}) string {
 	if !ok {
 		return "Error: 'path' not specified or not a string for delete_file."
 	}
-
-	err := os.Remove(path)
+	fullPath, err := a.ResolvePath(path)
 	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if conflict := a.conflictError(path, fullPath); conflict != "" {
+		return conflict
+	}
+
+	if err := a.moveToTrash(path, fullPath); err != nil {
 		return fmt.Sprintf("Error deleting file '%s': %v", path, err)
 	}
+	a.versions.forget(fullPath)
 
-	return fmt.Sprintf("File '%s' deleted successfully.", path)
+	return fmt.Sprintf("File '%s' moved to the session trash. restore_file brings it back.", path)
 }
 
 func (a *Agent) HandleListFiles(input map[string]interface{}) string {
 	a.logger.Log(fmt.Sprintf("handleListFiles input: %v", input))
 	path, _ := input["path"].(string)
 	if path == "" {
+		if len(a.roots) > 0 {
+			// With roots configured there is no single "current" directory,
+			// so show the model which roots it can address.
+			return fmt.Sprintf("Workspace roots:\n%s", strings.Join(a.RootNames(), "\n"))
+		}
 		path = "."
 	}
+	dirPath, err := a.ResolvePath(path)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
 
 	glob, _ := input["glob"].(string)
 
 	var fileNames []string
 	if glob != "" {
-		fsys := os.DirFS(path)
 		var err error
-		fileNames, err = doublestar.Glob(fsys, glob)
+		fileNames, err = a.files().Glob(dirPath, glob)
 		if err != nil {
 			return fmt.Sprintf("Error matching glob pattern '%s': %v", glob, err)
 		}
 	} else {
-        // Original non-recursive logic if no glob is provided.
-		files, err := os.ReadDir(path)
+		// Original non-recursive logic if no glob is provided.
+		var err error
+		fileNames, err = a.files().ReadDir(dirPath)
 		if err != nil {
 			return fmt.Sprintf("Error reading directory '%s': %v", path, err)
 		}
-		for _, file := range files {
-			fileNames = append(fileNames, file.Name())
-		}
 	}
 
 	result := fmt.Sprintf("Files in '%s':\n%s", path, strings.Join(fileNames, "\n"))
@@ -285,36 +348,42 @@ func (a *Agent) HandleListFiles(input map[string]interface{}) string {
 	return result
 }
 
-func (a *Agent) HandleGit(input map[string]interface{}) string {
+func (a *Agent) HandleGit(ctx context.Context, input map[string]interface{}) string {
 	cmd, ok := input["cmd"].(string)
 	if !ok {
 		return "Error: 'cmd' not specified or not a string for git."
 	}
 
-	var args []string
-	if argsVal, ok := input["args"].([]interface{}); ok {
-		for _, arg := range argsVal {
-			if argStr, ok := arg.(string); ok {
-				args = append(args, argStr)
-			}
-		}
-	} else if argsVal, ok := input["args"].(string); ok {
-		args = strings.Fields(argsVal)
-	} else if argsVal, ok := input["args"].(string); ok {
-		args = strings.Fields(argsVal)
-	}
+	args := toolArgs(input)
 
 	cwd, _ := input["cwd"].(string)
-	timeout_ms, _ := input["timeout_ms"].(float64)
+	if cwd != "" || len(a.roots) > 0 || a.ssh != nil {
+		if cwd == "" {
+			cwd = "."
+		}
+		resolved, err := a.ResolvePath(cwd)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		cwd = resolved
+	}
 	max_bytes, _ := input["max_bytes"].(float64)
 
-	if timeout_ms == 0 {
-		timeout_ms = 5000 // default timeout of 5 seconds
+	if a.ssh != nil {
+		if cwd == "" {
+			cwd = a.ssh.config.Dir
+		}
+		out, err := a.ssh.gitCommand(ctx, cwd, append([]string{cmd}, args...))
+		if err != nil {
+			return fmt.Sprintf("Error executing git command: %v", err)
+		}
+		output := string(out)
+		if max_bytes > 0 && len(output) > int(max_bytes) {
+			output = output[:int(max_bytes)]
+		}
+		return output
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout_ms)*time.Millisecond)
-	defer cancel()
-
 	command := exec.CommandContext(ctx, "git", append([]string{cmd}, args...)...)
 	command.Dir = cwd
 
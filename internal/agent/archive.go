@@ -0,0 +1,363 @@
+package agent
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const (
+	// maxArchiveBytes caps the size of an archive the tools open; it is
+	// read into memory.
+	maxArchiveBytes = 200 << 20
+	// defaultExtractBytes caps the uncompressed bytes one extract_archive
+	// call writes, which also stops zip bombs.
+	defaultExtractBytes = 100 << 20
+	// maxExtractFiles caps the number of files one extract_archive call
+	// writes.
+	maxExtractFiles = 1000
+	// defaultArchiveEntries is how many entries list_archive shows by
+	// default.
+	defaultArchiveEntries = 500
+)
+
+// archiveEntry is a file, directory or link in an archive.
+type archiveEntry struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+	Dir     bool
+	Link    bool // A symbolic or hard link, which is never extracted.
+}
+
+// walkArchive calls fn for each entry of a zip or (compressed) tar archive;
+// open reads the entry's content. The format is detected from the content,
+// not the file name.
+func walkArchive(data []byte, fn func(entry archiveEntry, open func() (io.Reader, error)) error) (format string, err error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")) {
+		return "zip", walkZip(data, fn)
+	}
+	var r io.Reader = bytes.NewReader(data)
+	format = "tar"
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return "", err
+		}
+		defer gz.Close()
+		r, format = gz, "tar.gz"
+	case bytes.HasPrefix(data, []byte("BZh")):
+		r, format = bzip2.NewReader(r), "tar.bz2"
+	case len(data) > 262 && string(data[257:262]) == "ustar":
+	default:
+		return "", errors.New("not a zip, tar, tar.gz or tar.bz2 archive")
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return format, nil
+		}
+		if err != nil {
+			return format, err
+		}
+		entry := archiveEntry{Name: header.Name, Size: header.Size, ModTime: header.ModTime}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			entry.Dir = true
+		case tar.TypeSymlink, tar.TypeLink:
+			entry.Link = true
+		case tar.TypeReg, tar.TypeRegA:
+		default:
+			continue // Devices, FIFOs and PAX headers.
+		}
+		if err := fn(entry, func() (io.Reader, error) { return tr, nil }); err != nil {
+			return format, err
+		}
+	}
+}
+
+func walkZip(data []byte, fn func(entry archiveEntry, open func() (io.Reader, error)) error) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, file := range zr.File {
+		mode := file.Mode()
+		entry := archiveEntry{
+			Name:    file.Name,
+			Size:    int64(file.UncompressedSize64),
+			ModTime: file.Modified,
+			Dir:     mode.IsDir(),
+			Link:    mode&fs.ModeSymlink != 0,
+		}
+		var rc io.ReadCloser
+		err := fn(entry, func() (io.Reader, error) {
+			rc, err = file.Open()
+			return rc, err
+		})
+		if rc != nil {
+			rc.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// safeEntryName cleans an entry name for extraction, or returns ok false
+// for names that would land outside the destination: absolute paths, drive
+// letters and ".." components.
+func safeEntryName(name string) (string, bool) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return "", false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", false
+		}
+	}
+	name = path.Clean(name)
+	return name, name != "."
+}
+
+// readArchive reads an archive file of the workspace, refusing files over
+// maxArchiveBytes.
+func (a *Agent) readArchive(archive string) ([]byte, error) {
+	fullPath, err := a.ResolvePath(archive)
+	if err != nil {
+		return nil, err
+	}
+	info, err := a.files().Stat(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size > maxArchiveBytes {
+		return nil, fmt.Errorf("'%s' is %d bytes, over the archive limit of %d bytes", archive, info.Size, maxArchiveBytes)
+	}
+	return a.files().ReadFile(fullPath)
+}
+
+// HandleListArchive lists the entries of a zip or tar archive with their
+// sizes and modification times.
+func (a *Agent) HandleListArchive(input map[string]interface{}) string {
+	archive, _ := input["path"].(string)
+	if archive == "" {
+		return "Error: 'path' not specified or not a string for list_archive."
+	}
+	maxEntries := defaultArchiveEntries
+	if n, ok := input["max_entries"].(float64); ok && n > 0 {
+		maxEntries = int(n)
+	}
+	a.logger.Log(fmt.Sprintf("HandleListArchive path: %s", archive))
+	data, err := a.readArchive(archive)
+	if err != nil {
+		return fmt.Sprintf("Error reading archive '%s': %v", archive, err)
+	}
+
+	var lines []string
+	entries, files := 0, 0
+	var total int64
+	format, err := walkArchive(data, func(entry archiveEntry, _ func() (io.Reader, error)) error {
+		entries++
+		if !entry.Dir && !entry.Link {
+			files++
+			total += entry.Size
+		}
+		if len(lines) >= maxEntries {
+			return nil
+		}
+		name := entry.Name
+		switch {
+		case entry.Dir:
+			name = strings.TrimSuffix(name, "/") + "/"
+		case entry.Link:
+			name += " (link, not extracted)"
+		}
+		lines = append(lines, fmt.Sprintf("%10d  %s  %s", entry.Size, entry.ModTime.Format("2006-01-02 15:04"), name))
+		return nil
+	})
+	if err != nil {
+		return fmt.Sprintf("Error reading archive '%s': %v", archive, err)
+	}
+	result := fmt.Sprintf("%s (%s, %d entries, %d files, %d bytes uncompressed):\n%s", archive, format, entries, files, total, strings.Join(lines, "\n"))
+	if entries > len(lines) {
+		result += fmt.Sprintf("\n... %d more entries; raise max_entries to see them.", entries-len(lines))
+	}
+	return result
+}
+
+// HandleExtractArchive writes the files of an archive, or those matching
+// the given globs, into a workspace directory. Entries that would escape
+// the directory and links are skipped. If the files exceed the byte or file
+// limit, or a file to overwrite changed since the agent read it, nothing is
+// written.
+func (a *Agent) HandleExtractArchive(input map[string]interface{}) string {
+	archive, _ := input["path"].(string)
+	if archive == "" {
+		return "Error: 'path' not specified or not a string for extract_archive."
+	}
+	dest, _ := input["dest"].(string)
+	if dest == "" {
+		dest = archiveStem(archive)
+	}
+	var patterns []string
+	if list, ok := input["files"].([]interface{}); ok {
+		for _, item := range list {
+			pattern, ok := item.(string)
+			if !ok || !doublestar.ValidatePattern(pattern) {
+				return fmt.Sprintf("Error: invalid files pattern %v for extract_archive.", item)
+			}
+			patterns = append(patterns, strings.TrimPrefix(pattern, "./"))
+		}
+	}
+	maxBytes := int64(defaultExtractBytes)
+	if n, ok := input["max_bytes"].(float64); ok && n > 0 {
+		maxBytes = int64(n)
+	}
+	overwrite, _ := input["overwrite"].(bool)
+
+	destPath, err := a.ResolvePath(dest)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	a.logger.Log(fmt.Sprintf("HandleExtractArchive path: %s dest: %s files: %v", archive, dest, patterns))
+	data, err := a.readArchive(archive)
+	if err != nil {
+		return fmt.Sprintf("Error reading archive '%s': %v", archive, err)
+	}
+
+	// Everything is read before anything is written, so a limit or a
+	// corrupt entry leaves the workspace untouched.
+	type extracted struct {
+		name, fullPath string
+		data           []byte
+	}
+	var files []extracted
+	var skipped []string
+	var total int64
+	// A file changed since the agent read it stops the extraction.
+	var conflict string
+	errConflict := errors.New("conflict")
+	_, err = walkArchive(data, func(entry archiveEntry, open func() (io.Reader, error)) error {
+		if entry.Dir {
+			return nil
+		}
+		name, ok := safeEntryName(entry.Name)
+		if !ok {
+			skipped = append(skipped, entry.Name+" (outside the destination)")
+			return nil
+		}
+		if len(patterns) > 0 && !matchesAny(patterns, name) {
+			return nil
+		}
+		if entry.Link {
+			skipped = append(skipped, name+" (link)")
+			return nil
+		}
+		fullPath := a.joinPath(destPath, name)
+		if !isWithin(destPath, fullPath) {
+			skipped = append(skipped, entry.Name+" (outside the destination)")
+			return nil
+		}
+		if _, err := a.files().Stat(fullPath); err == nil && !overwrite {
+			skipped = append(skipped, name+" (already exists)")
+			return nil
+		} else if err == nil {
+			if conflict = a.conflictError(path.Join(dest, name), fullPath); conflict != "" {
+				return errConflict
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if len(files) >= maxExtractFiles {
+			return fmt.Errorf("more than %d files match; extract fewer with files", maxExtractFiles)
+		}
+		r, err := open()
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(r, maxBytes-total+1))
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		total += int64(len(content))
+		if total > maxBytes {
+			return fmt.Errorf("the files are over the extract limit of %d bytes; extract fewer with files or raise max_bytes", maxBytes)
+		}
+		files = append(files, extracted{name: name, fullPath: fullPath, data: content})
+		return nil
+	})
+	if conflict != "" {
+		return conflict + " Nothing was written."
+	}
+	if err != nil {
+		return fmt.Sprintf("Error extracting '%s': %v. Nothing was written.", archive, err)
+	}
+	if len(files) == 0 {
+		result := fmt.Sprintf("Error: no files extracted from '%s'", archive)
+		if len(patterns) > 0 && len(skipped) == 0 {
+			result += fmt.Sprintf("; nothing matches %s. Use list_archive to see the entries", strings.Join(patterns, ", "))
+		}
+		if len(skipped) > 0 {
+			result += ". Skipped:\n" + strings.Join(skipped, "\n")
+		}
+		return result
+	}
+
+	var builder strings.Builder
+	for i, file := range files {
+		if err := a.files().MkdirAll(a.joinPath(file.fullPath, "..")); err != nil {
+			return fmt.Sprintf("Error creating the directory of '%s': %v. %d of %d files were written.", file.name, err, i, len(files))
+		}
+		if err := a.files().WriteFile(file.fullPath, file.data); err != nil {
+			return fmt.Sprintf("Error writing file '%s': %v. %d of %d files were written.", file.name, err, i, len(files))
+		}
+		a.recordVersion(file.fullPath, file.data)
+		builder.WriteString(fmt.Sprintf("%s (%d bytes)\n", path.Join(dest, file.name), len(file.data)))
+	}
+	result := fmt.Sprintf("Extracted %d files (%d bytes) from '%s' to '%s':\n%s", len(files), total, archive, dest, builder.String())
+	if len(skipped) > 0 {
+		result += "Skipped:\n" + strings.Join(skipped, "\n")
+	}
+	return strings.TrimSuffix(result, "\n")
+}
+
+// archiveStem is the default destination of extract_archive: the archive
+// path without its archive extensions, e.g. "dist/app" for
+// "dist/app.tar.gz".
+func archiveStem(archive string) string {
+	stem := archive
+	for _, ext := range []string{".gz", ".tgz", ".bz2", ".tbz2", ".tar", ".zip", ".jar", ".whl"} {
+		if strings.HasSuffix(strings.ToLower(stem), ext) {
+			stem = stem[:len(stem)-len(ext)]
+		}
+	}
+	if stem == "" || stem == archive {
+		stem = archive + ".d"
+	}
+	return stem
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"prompt-cli/internal/logger"
+)
+
+func TestSafeEntryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"file.txt", "file.txt", true},
+		{"dir/file.txt", "dir/file.txt", true},
+		{"./dir//file.txt", "dir/file.txt", true},
+		{`dir\file.txt`, "dir/file.txt", true},
+		{"/etc/passwd", "", false},
+		{`\Windows\win.ini`, "", false},
+		{"C:/Windows/win.ini", "", false},
+		{`C:\Windows\win.ini`, "", false},
+		{"c:file.txt", "", false},
+		{"../file.txt", "", false},
+		{"dir/../../file.txt", "", false},
+		{`dir\..\..\file.txt`, "", false},
+		{"dir/..", "", false},
+		{".", ".", false},
+		{"./", ".", false},
+	}
+	for _, tt := range tests {
+		got, ok := safeEntryName(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("safeEntryName(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExtractArchiveWritesNothing(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+		input   map[string]interface{}
+		result  string
+	}{
+		{
+			name:    "traversal",
+			entries: map[string]string{"../evil.txt": "evil", "/abs.txt": "evil", `..\win.txt`: "evil"},
+			input:   map[string]interface{}{},
+			result:  "outside the destination",
+		},
+		{
+			name:    "over the byte limit",
+			entries: map[string]string{"a.txt": strings.Repeat("a", 60), "b.txt": strings.Repeat("b", 60)},
+			input:   map[string]interface{}{"max_bytes": float64(100)},
+			result:  "over the extract limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			work := filepath.Join(base, "work")
+			if err := os.Mkdir(work, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			t.Chdir(work)
+			writeZip(t, "test.zip", tt.entries)
+
+			input := map[string]interface{}{"path": "test.zip", "dest": "out"}
+			for k, v := range tt.input {
+				input[k] = v
+			}
+			result := NewAgent(logger.NewLogger()).HandleExtractArchive(input)
+			if !strings.Contains(result, tt.result) {
+				t.Errorf("result %q does not mention %q", result, tt.result)
+			}
+
+			var written []string
+			filepath.WalkDir(base, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() && d.Name() != "test.zip" {
+					written = append(written, path)
+				}
+				return nil
+			})
+			if len(written) > 0 {
+				t.Errorf("extract_archive wrote %v", written)
+			}
+		})
+	}
+}
+
+// writeZip writes a zip archive with the given entry names and contents.
+func writeZip(t *testing.T, name string, entries map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for entry, content := range entries {
+		w, err := zw.Create(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CachedMarker prefixes results served from the session cache so the model
+// knows it is seeing a repeat rather than a fresh read.
+const CachedMarker = "[cached] "
+
+// toolCache holds results of deterministic tool calls for the lifetime of a
+// session.
+type toolCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func newToolCache() *toolCache {
+	return &toolCache{entries: make(map[string]string)}
+}
+
+func (c *toolCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.entries[key]
+	return result, ok
+}
+
+func (c *toolCache) put(key, result string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = result
+}
+
+// invalidatePrefix drops every entry whose key starts with prefix.
+func (c *toolCache) invalidatePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *toolCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]string)
+}
+
+// SetCacheEnabled turns the session tool cache on or off. The low-memory
+// mode turns it off.
+func (a *Agent) SetCacheEnabled(on bool) {
+	a.cacheDisabled = !on
+	if !on {
+		a.cache.clear()
+	}
+}
+
+// ClearCache forgets all cached tool results and the versions of the files
+// read, e.g. when a new chat starts.
+func (a *Agent) ClearCache() {
+	a.cache.clear()
+	a.versions.clear()
+}
+
+// cacheKey returns the cache key for a tool call, or "" if the call is not
+// cacheable. read_file keys include the file's modification time and size so
+// edits made outside the agent are picked up.
+func (a *Agent) cacheKey(toolName string, input map[string]interface{}) string {
+	switch toolName {
+	case "read_file":
+		path, _ := input["path"].(string)
+		fullPath, err := a.ResolvePath(path)
+		if err != nil {
+			return ""
+		}
+		info, err := a.files().Stat(fullPath)
+		if err != nil {
+			return ""
+		}
+		maxBytes, _ := input["max_bytes"].(float64)
+		return fmt.Sprintf("read_file|%s|%d|%d|%v", fullPath, info.ModTime.UnixNano(), info.Size, maxBytes)
+	case "list_files":
+		// Keyed by the resolved directory, so "dir", "./dir" and "root:dir"
+		// share an entry. Without a path and with roots, the roots are
+		// listed.
+		path, _ := input["path"].(string)
+		if path != "" || len(a.roots) == 0 {
+			if path == "" {
+				path = "."
+			}
+			fullPath, err := a.ResolvePath(path)
+			if err != nil {
+				return ""
+			}
+			path = fullPath
+		}
+		glob, _ := input["glob"].(string)
+		return fmt.Sprintf("list_files|%s|%s", path, glob)
+	case "visit_url":
+		url, _ := input["url"].(string)
+		maxBytes, _ := input["max_bytes"].(float64)
+		return fmt.Sprintf("visit_url|%s|%v", url, maxBytes)
+	}
+	return ""
+}
+
+// cachedResult looks up a previous result for the call.
+func (a *Agent) cachedResult(toolName string, input map[string]interface{}) (string, string, bool) {
+	key := a.cacheKey(toolName, input)
+	if key == "" {
+		return "", "", false
+	}
+	result, ok := a.cache.get(key)
+	return key, result, ok
+}
+
+// storeResult caches a successful result. Tools that can change the files
+// invalidate the directory listings, which may no longer be accurate: the
+// file tools (with the formatters they run), the shell and git commands
+// that change the working tree.
+func (a *Agent) storeResult(toolName, key, result string, input map[string]interface{}) {
+	if fileWritingTools[toolName] || toolName == "shell" || toolName == "git" && gitMutates(input) {
+		a.cache.invalidatePrefix("list_files|")
+		return
+	}
+	if key == "" || a.cacheDisabled || strings.HasPrefix(result, "Error") {
+		return
+	}
+	a.cache.put(key, result)
+}
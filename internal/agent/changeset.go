@@ -0,0 +1,261 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// FileChange is one file of a changeset: its new content, or its deletion.
+type FileChange struct {
+	Path    string
+	Content string
+	Delete  bool
+}
+
+// ChangePreview describes a file of a changeset before it is applied, for
+// the review in the UI.
+type ChangePreview struct {
+	FileChange
+	Original []byte
+	Existed  bool
+	Conflict string // Set if the file changed since the agent read it.
+}
+
+// changedFile is a file of an applied changeset with what it takes to
+// revert it.
+type changedFile struct {
+	path      string
+	fullPath  string
+	original  []byte
+	existed   bool
+	trashPath string // Where a deleted file went.
+}
+
+// appliedChangeset is a changeset /undo can revert as one unit.
+type appliedChangeset struct {
+	seq   int64
+	files []changedFile
+}
+
+// changesetLog keeps the applied changesets of the session. Its counter
+// also orders the trashed files, so /undo reverts whichever came last.
+type changesetLog struct {
+	mu      sync.Mutex
+	seq     atomic.Int64
+	applied []appliedChangeset
+}
+
+// Undone describes what UndoLast reverted.
+type Undone struct {
+	Changeset bool
+	Paths     []string
+}
+
+// ParseChangeset reads the files of an apply_changeset call.
+func ParseChangeset(input map[string]interface{}) ([]FileChange, error) {
+	raw, ok := input["changes"].([]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, errors.New("'changes' must be a non-empty array of {\"path\", \"content\"} or {\"path\", \"delete\": true} objects")
+	}
+	changes := make([]FileChange, 0, len(raw))
+	for i, item := range raw {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("change %d is not an object", i+1)
+		}
+		change := FileChange{}
+		change.Path, _ = entry["path"].(string)
+		change.Delete, _ = entry["delete"].(bool)
+		content, hasContent := entry["content"].(string)
+		change.Content = content
+		switch {
+		case change.Path == "":
+			return nil, fmt.Errorf("change %d has no 'path'", i+1)
+		case !change.Delete && !hasContent:
+			return nil, fmt.Errorf("change %d ('%s') needs 'content' or \"delete\": true", i+1, change.Path)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// changesetPaths lists the paths of an apply_changeset call, skipping
+// malformed entries.
+func changesetPaths(input map[string]interface{}) []string {
+	raw, _ := input["changes"].([]interface{})
+	var paths []string
+	for _, item := range raw {
+		if entry, ok := item.(map[string]interface{}); ok {
+			if path, _ := entry["path"].(string); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// PreviewChangeset reads the current state of every file of a changeset
+// for review. It fails like the call itself would on invalid input.
+func (a *Agent) PreviewChangeset(input map[string]interface{}) ([]ChangePreview, error) {
+	changes, err := ParseChangeset(input)
+	if err != nil {
+		return nil, err
+	}
+	previews := make([]ChangePreview, 0, len(changes))
+	seen := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		fullPath, err := a.ResolvePath(change.Path)
+		if err != nil {
+			return nil, err
+		}
+		if seen[fullPath] {
+			return nil, fmt.Errorf("'%s' appears more than once", change.Path)
+		}
+		seen[fullPath] = true
+		original, err := a.files().ReadFile(fullPath)
+		existed := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading '%s': %w", change.Path, err)
+		}
+		if change.Delete && !existed {
+			return nil, fmt.Errorf("cannot delete '%s': it does not exist", change.Path)
+		}
+		previews = append(previews, ChangePreview{
+			FileChange: change,
+			Original:   original,
+			Existed:    existed,
+			Conflict:   a.changedSinceRead(fullPath),
+		})
+	}
+	return previews, nil
+}
+
+// HandleApplyChangeset writes and deletes the files of a changeset as one
+// unit: every entry is checked first, and if a write fails the files
+// already changed are put back, so the workspace is never left half done.
+func (a *Agent) HandleApplyChangeset(input map[string]interface{}) string {
+	previews, err := a.PreviewChangeset(input)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	for _, preview := range previews {
+		if preview.Conflict != "" {
+			fullPath, _ := a.ResolvePath(preview.Path)
+			return a.conflictError(preview.Path, fullPath)
+		}
+	}
+
+	var applied []changedFile
+	for _, preview := range previews {
+		fullPath, _ := a.ResolvePath(preview.Path)
+		file := changedFile{path: preview.Path, fullPath: fullPath, original: preview.Original, existed: preview.Existed}
+		if preview.Delete {
+			file.trashPath, err = a.trashFile(preview.Path, fullPath, false)
+		} else {
+			err = a.files().WriteFile(fullPath, []byte(preview.Content))
+		}
+		if err != nil {
+			a.revertFiles(applied)
+			return fmt.Sprintf("Error applying the changeset at '%s': %v. No files were changed.", preview.Path, err)
+		}
+		applied = append(applied, file)
+	}
+
+	var created, changed, deleted []string
+	for i, preview := range previews {
+		switch {
+		case preview.Delete:
+			a.versions.forget(applied[i].fullPath)
+			deleted = append(deleted, preview.Path)
+		case preview.Existed:
+			a.recordVersion(applied[i].fullPath, []byte(preview.Content))
+			changed = append(changed, preview.Path)
+		default:
+			a.recordVersion(applied[i].fullPath, []byte(preview.Content))
+			created = append(created, preview.Path)
+		}
+	}
+	a.changesets.mu.Lock()
+	a.changesets.applied = append(a.changesets.applied, appliedChangeset{seq: a.changesets.seq.Add(1), files: applied})
+	a.changesets.mu.Unlock()
+
+	var parts []string
+	for _, group := range []struct {
+		verb  string
+		paths []string
+	}{{"created", created}, {"changed", changed}, {"deleted", deleted}} {
+		if len(group.paths) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", group.verb, strings.Join(group.paths, ", ")))
+		}
+	}
+	return fmt.Sprintf("Changeset applied to %d files: %s. The user can revert it as one unit with /undo.", len(applied), strings.Join(parts, "; "))
+}
+
+// revertFiles puts the files of a changeset back as they were, last first.
+func (a *Agent) revertFiles(files []changedFile) error {
+	var errs []error
+	for i := len(files) - 1; i >= 0; i-- {
+		file := files[i]
+		var err error
+		switch {
+		case file.trashPath != "":
+			err = a.restoreTrashed(file.trashPath)
+			if err == nil {
+				a.recordVersion(file.fullPath, file.original)
+			}
+		case file.existed:
+			err = a.files().WriteFile(file.fullPath, file.original)
+			if err == nil {
+				a.recordVersion(file.fullPath, file.original)
+			}
+		default:
+			_, err = a.trashFile(file.path, file.fullPath, false)
+			a.versions.forget(file.fullPath)
+		}
+		if err != nil {
+			a.logger.Log(fmt.Sprintf("Error reverting '%s': %v", file.path, err))
+			errs = append(errs, fmt.Errorf("'%s': %w", file.path, err))
+		}
+	}
+	a.cache.invalidatePrefix("list_files|")
+	return errors.Join(errs...)
+}
+
+// UndoLast reverts the most recent change /undo covers: an applied
+// changeset, all of its files at once, or a file deleted with delete_file.
+// ok is false if there is nothing to undo.
+func (a *Agent) UndoLast() (undone Undone, ok bool, err error) {
+	a.changesets.mu.Lock()
+	defer a.changesets.mu.Unlock()
+
+	a.trash.mu.Lock()
+	trashed := -1
+	for i := len(a.trash.files) - 1; i >= 0; i-- {
+		if a.trash.files[i].seq > 0 {
+			trashed = i
+			break
+		}
+	}
+	n := len(a.changesets.applied)
+	if trashed >= 0 && (n == 0 || a.trash.files[trashed].seq > a.changesets.applied[n-1].seq) {
+		path, err := a.restore(trashed)
+		a.trash.mu.Unlock()
+		return Undone{Paths: []string{path}}, true, err
+	}
+	a.trash.mu.Unlock()
+	if n == 0 {
+		return Undone{}, false, nil
+	}
+
+	last := a.changesets.applied[n-1]
+	a.changesets.applied = a.changesets.applied[:n-1]
+	undone = Undone{Changeset: true}
+	for _, file := range last.files {
+		undone.Paths = append(undone.Paths, file.path)
+	}
+	return undone, true, a.revertFiles(last.files)
+}
@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// maxChunkedBytes caps the content staged for one chunked write_file.
+const maxChunkedBytes = 50 << 20
+
+// chunkedWrites holds the write_file calls that send a file in numbered
+// chunks, by resolved path, until the final chunk writes it. Models with a
+// small output limit can produce files larger than one response this way
+// without leaving a half-written file behind.
+type chunkedWrites struct {
+	mu    sync.Mutex
+	files map[string][]string // The chunks received so far.
+}
+
+// writeFile runs write_file. A call with a chunk number is staged until
+// the one marked final, which writes all chunks as one file. Content sent
+// with "encoding": "base64" is decoded first, for binary files.
+func (a *Agent) writeFile(ctx context.Context, input map[string]interface{}) string {
+	content, _ := input["content"].(string)
+	chunks := 0
+	if _, ok := input["chunk"]; ok {
+		var result string
+		content, chunks, result = a.writeChunk(input)
+		if chunks == 0 {
+			return result
+		}
+	}
+	encoding, _ := input["encoding"].(string)
+	switch strings.ToLower(encoding) {
+	case "", "utf-8":
+	case "base64":
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), ""))
+		if err != nil {
+			return fmt.Sprintf("Error: content is not valid base64: %v", err)
+		}
+		content = string(decoded)
+	default:
+		return fmt.Sprintf("Error: unknown encoding %q for write_file; use base64 or leave it out.", encoding)
+	}
+	if chunks > 0 || encoding != "" {
+		input = withContent(input, content)
+	}
+	output := a.verifyWrite(ctx, "write_file", input, a.HandleWriteFile(input))
+	if chunks > 0 && !failed(output) {
+		path, _ := input["path"].(string)
+		a.dropChunks(path)
+		output += fmt.Sprintf("\nAssembled from %d chunks.", chunks)
+	}
+	return output
+}
+
+// writeChunk stages a chunk. For the final chunk it returns the content of
+// all chunks and their count; otherwise a count of 0 and the result for
+// the model. Chunk 1 starts over, and the last chunk can be sent again to
+// replace it, e.g. after an error.
+func (a *Agent) writeChunk(input map[string]interface{}) (content string, chunks int, result string) {
+	path, _ := input["path"].(string)
+	content, ok := input["content"].(string)
+	if path == "" || !ok {
+		return "", 0, "Error: 'path' and 'content' must be strings for write_file."
+	}
+	n, ok := input["chunk"].(float64)
+	if !ok || n < 1 || n != math.Trunc(n) {
+		return "", 0, "Error: 'chunk' must be a whole number starting at 1 for write_file."
+	}
+	chunk := int(n)
+	final, _ := input["final"].(bool)
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return "", 0, fmt.Sprintf("Error: %v", err)
+	}
+	if mode, _ := input["mode"].(string); chunk == 1 && mode == "create_only" {
+		if _, err := a.files().Stat(fullPath); err == nil {
+			return "", 0, fmt.Sprintf("File '%s' already exists.", path)
+		}
+	}
+
+	a.chunks.mu.Lock()
+	defer a.chunks.mu.Unlock()
+	if a.chunks.files == nil {
+		a.chunks.files = map[string][]string{}
+	}
+	parts := a.chunks.files[fullPath]
+	switch {
+	case chunk == 1:
+		parts = []string{content}
+	case chunk == len(parts):
+		parts[chunk-1] = content
+	case chunk == len(parts)+1:
+		parts = append(parts, content)
+	case len(parts) == 0:
+		return "", 0, fmt.Sprintf("Error: no chunks of '%s' were received yet; start with chunk 1.", path)
+	default:
+		return "", 0, fmt.Sprintf("Error: chunk %d of '%s' is out of order; %d chunks were received, send chunk %d next.", chunk, path, len(parts), len(parts)+1)
+	}
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+	if size > maxChunkedBytes {
+		delete(a.chunks.files, fullPath)
+		return "", 0, fmt.Sprintf("Error: the chunks of '%s' are over the limit of %d bytes; the staged chunks were dropped.", path, maxChunkedBytes)
+	}
+	a.chunks.files[fullPath] = parts
+	if !final {
+		return "", 0, fmt.Sprintf("Received chunk %d of '%s' (%d bytes staged, nothing written yet). Send chunk %d next, with final set to true on the last one.", chunk, path, size, chunk+1)
+	}
+	return strings.Join(parts, ""), len(parts), ""
+}
+
+// dropChunks forgets the staged chunks of a file once it is written.
+func (a *Agent) dropChunks(path string) {
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return
+	}
+	a.chunks.mu.Lock()
+	defer a.chunks.mu.Unlock()
+	delete(a.chunks.files, fullPath)
+}
+
+// withContent copies a write_file input with other content and without the
+// chunk and encoding fields.
+func withContent(input map[string]interface{}, content string) map[string]interface{} {
+	copied := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		switch key {
+		case "chunk", "final", "encoding":
+			continue
+		}
+		copied[key] = value
+	}
+	copied["content"] = content
+	return copied
+}
+
+// isStagedChunk reports whether a write_file call only stages a chunk and
+// does not write yet.
+func isStagedChunk(input map[string]interface{}) bool {
+	_, chunked := input["chunk"]
+	final, _ := input["final"].(bool)
+	return chunked && !final
+}
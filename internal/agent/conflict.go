@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileVersion is what the agent last saw of a file.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// versionTracker remembers the version of every file the agent read or
+// wrote in this session, so writes over changes made since then, e.g. in
+// the user's editor, are caught.
+type versionTracker struct {
+	mu       sync.Mutex
+	versions map[string]fileVersion // By resolved path.
+}
+
+func newVersionTracker() *versionTracker {
+	return &versionTracker{versions: make(map[string]fileVersion)}
+}
+
+func (t *versionTracker) get(path string) (fileVersion, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	version, ok := t.versions[path]
+	return version, ok
+}
+
+func (t *versionTracker) put(path string, version fileVersion) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.versions[path] = version
+}
+
+func (t *versionTracker) forget(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.versions, path)
+}
+
+func (t *versionTracker) clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.versions = make(map[string]fileVersion)
+}
+
+// recordVersion notes the content of a file the agent just read or wrote.
+func (a *Agent) recordVersion(fullPath string, content []byte) {
+	info, err := a.files().Stat(fullPath)
+	if err != nil {
+		return
+	}
+	a.versions.put(fullPath, fileVersion{modTime: info.ModTime, size: info.Size, hash: sha256.Sum256(content)})
+}
+
+// refreshVersion re-reads a file after a change whose result the agent did
+// not hold in memory, such as an append.
+func (a *Agent) refreshVersion(fullPath string) {
+	if content, err := a.files().ReadFile(fullPath); err == nil {
+		a.recordVersion(fullPath, content)
+	}
+}
+
+// changedSinceRead describes how a file the agent has seen changed on disk
+// since then, or returns "" if it did not or was never seen. A new
+// modification time alone is not a change as long as the content is the
+// same.
+func (a *Agent) changedSinceRead(fullPath string) string {
+	known, ok := a.versions.get(fullPath)
+	if !ok {
+		return ""
+	}
+	info, err := a.files().Stat(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "was deleted"
+	}
+	if err != nil || info.ModTime.Equal(known.modTime) && info.Size == known.size {
+		return ""
+	}
+	content, err := a.files().ReadFile(fullPath)
+	if err != nil {
+		return ""
+	}
+	if sha256.Sum256(content) == known.hash {
+		a.recordVersion(fullPath, content)
+		return ""
+	}
+	return fmt.Sprintf("was modified at %s", info.ModTime.Format("15:04:05"))
+}
+
+// conflictError is the tool result for a write that would overwrite changes
+// made since the agent's last read, or "" if there is no conflict.
+func (a *Agent) conflictError(path, fullPath string) string {
+	change := a.changedSinceRead(fullPath)
+	if change == "" {
+		return ""
+	}
+	return fmt.Sprintf("Error: '%s' %s, after you last read it, probably by the user. "+
+		"Read it again and apply your change to the current version instead of overwriting it.", path, change)
+}
+
+// WriteConflict reports whether a file tool call would overwrite changes
+// made since the agent read the file, for the permission prompt. It returns
+// a description of the change or "".
+func (a *Agent) WriteConflict(toolName string, input map[string]interface{}) string {
+	switch toolName {
+	case "write_file", "append_file", "delete_file":
+	case "apply_changeset":
+		var conflicts []string
+		for _, path := range changesetPaths(input) {
+			if conflict := a.WriteConflict("write_file", map[string]interface{}{"path": path}); conflict != "" {
+				conflicts = append(conflicts, fmt.Sprintf("'%s' %s", path, conflict))
+			}
+		}
+		return strings.Join(conflicts, "; ")
+	default:
+		return ""
+	}
+	path, _ := input["path"].(string)
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return ""
+	}
+	return a.changedSinceRead(fullPath)
+}
+
+// AcceptChanges takes the current version of the file a tool call targets
+// as read, so a write the user approved despite a conflict goes ahead.
+func (a *Agent) AcceptChanges(toolName string, input map[string]interface{}) {
+	if toolName == "apply_changeset" {
+		for _, path := range changesetPaths(input) {
+			a.AcceptChanges("write_file", map[string]interface{}{"path": path})
+		}
+		return
+	}
+	if a.WriteConflict(toolName, input) == "" {
+		return
+	}
+	path, _ := input["path"].(string)
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return
+	}
+	if _, err := a.files().Stat(fullPath); errors.Is(err, fs.ErrNotExist) {
+		a.versions.forget(fullPath)
+		return
+	}
+	a.refreshVersion(fullPath)
+}
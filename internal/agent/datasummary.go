@@ -0,0 +1,553 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxDataBytes caps the size of a file summarize_data loads.
+	maxDataBytes = 200 << 20
+	// maxDistinctValues caps the values counted per column; columns with
+	// more only report that they have more.
+	maxDistinctValues = 10000
+	// maxDataColumns caps the columns described.
+	maxDataColumns = 100
+	// defaultSampleRows is how many rows summarize_data shows by default.
+	defaultSampleRows = 5
+	// maxSampleCell caps the characters of a value in the sample rows.
+	maxSampleCell = 80
+)
+
+// dateLayouts are the formats a string column is recognized as a date in.
+var dateLayouts = []string{time.RFC3339, "2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05", "01/02/2006"}
+
+// columnStats accumulates what summarize_data reports about a column.
+type columnStats struct {
+	name     string
+	values   int // Non-empty values.
+	empty    int // Empty strings, nulls and missing fields.
+	types    map[string]int
+	min, max float64
+	sum      float64
+	numbers  int
+	minLen   int
+	maxLen   int
+	dates    []time.Time // The earliest and latest date.
+	distinct map[string]int
+	overflow bool // More than maxDistinctValues distinct values.
+}
+
+func newColumnStats(name string) *columnStats {
+	return &columnStats{name: name, types: map[string]int{}, distinct: map[string]int{}, minLen: -1}
+}
+
+// add records a value. JSON values keep their type, CSV values are strings
+// whose type is inferred.
+func (c *columnStats) add(value interface{}) {
+	text := ""
+	switch value := value.(type) {
+	case nil:
+		c.empty++
+		return
+	case string:
+		if strings.TrimSpace(value) == "" {
+			c.empty++
+			return
+		}
+		text = value
+		c.addText(value)
+	case json.Number:
+		text = value.String()
+		if _, err := value.Int64(); err == nil {
+			c.types["integer"]++
+		} else {
+			c.types["number"]++
+		}
+		f, _ := value.Float64()
+		c.addNumber(f)
+	case bool:
+		text = strconv.FormatBool(value)
+		c.types["boolean"]++
+	case []interface{}:
+		encoded, _ := json.Marshal(value)
+		text = string(encoded)
+		c.types["array"]++
+		c.addLength(len(value))
+	default:
+		encoded, _ := json.Marshal(value)
+		text = string(encoded)
+		c.types["object"]++
+	}
+	c.values++
+	if c.overflow {
+		return
+	}
+	if _, ok := c.distinct[text]; !ok && len(c.distinct) >= maxDistinctValues {
+		c.overflow = true
+		return
+	}
+	c.distinct[text]++
+}
+
+// addText infers the type of a string value.
+func (c *columnStats) addText(value string) {
+	trimmed := strings.TrimSpace(value)
+	if _, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		f, _ := strconv.ParseFloat(trimmed, 64)
+		c.types["integer"]++
+		c.addNumber(f)
+		return
+	}
+	if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		c.types["number"]++
+		c.addNumber(f)
+		return
+	}
+	if strings.EqualFold(trimmed, "true") || strings.EqualFold(trimmed, "false") {
+		c.types["boolean"]++
+		return
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, trimmed); err == nil {
+			c.types["date"]++
+			switch {
+			case c.dates == nil:
+				c.dates = []time.Time{t, t}
+			case t.Before(c.dates[0]):
+				c.dates[0] = t
+			case t.After(c.dates[1]):
+				c.dates[1] = t
+			}
+			return
+		}
+	}
+	c.types["string"]++
+	c.addLength(len([]rune(value)))
+}
+
+func (c *columnStats) addNumber(f float64) {
+	if c.numbers == 0 || f < c.min {
+		c.min = f
+	}
+	if c.numbers == 0 || f > c.max {
+		c.max = f
+	}
+	c.sum += f
+	c.numbers++
+}
+
+func (c *columnStats) addLength(n int) {
+	if c.minLen < 0 || n < c.minLen {
+		c.minLen = n
+	}
+	c.maxLen = max(c.maxLen, n)
+}
+
+// typeName is the type all values of the column share: "number" for
+// integers mixed with decimals, "mixed (...)" for anything else.
+func (c *columnStats) typeName() string {
+	types := make([]string, 0, len(c.types))
+	for name := range c.types {
+		types = append(types, name)
+	}
+	slices.Sort(types)
+	switch {
+	case len(types) == 0:
+		return "empty"
+	case len(types) == 1:
+		return types[0]
+	case len(types) == 2 && types[0] == "integer" && types[1] == "number":
+		return "number"
+	}
+	slices.SortStableFunc(types, func(a, b string) int { return c.types[b] - c.types[a] })
+	parts := make([]string, len(types))
+	for i, name := range types {
+		parts[i] = fmt.Sprintf("%s %d", name, c.types[name])
+	}
+	return "mixed (" + strings.Join(parts, ", ") + ")"
+}
+
+// String describes the column in one line.
+func (c *columnStats) String() string {
+	parts := []string{c.typeName(), fmt.Sprintf("%d values", c.values)}
+	if c.empty > 0 {
+		parts = append(parts, fmt.Sprintf("%d empty", c.empty))
+	}
+	if c.overflow {
+		parts = append(parts, fmt.Sprintf("over %d distinct", maxDistinctValues))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d distinct", len(c.distinct)))
+	}
+	if c.numbers > 0 {
+		parts = append(parts, fmt.Sprintf("min %s, max %s, mean %.6g", formatNumber(c.min), formatNumber(c.max), c.sum/float64(c.numbers)))
+	}
+	if c.dates != nil {
+		parts = append(parts, fmt.Sprintf("from %s to %s", c.dates[0].Format(time.DateOnly), c.dates[1].Format(time.DateOnly)))
+	}
+	if c.minLen >= 0 && c.types["string"]+c.types["array"] > 0 {
+		parts = append(parts, fmt.Sprintf("length %d-%d", c.minLen, c.maxLen))
+	}
+	line := strings.Join(parts, ", ")
+	// Categories are worth listing; unique values such as IDs are not.
+	if !c.overflow && len(c.distinct) <= 10 && len(c.distinct) < c.values {
+		line += ": " + topValues(c.distinct, 5)
+	}
+	return line
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// topValues lists the n most frequent values with their counts.
+func topValues(counts map[string]int, n int) string {
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	slices.SortFunc(values, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+	parts := make([]string, 0, n)
+	for _, value := range values[:min(n, len(values))] {
+		parts = append(parts, fmt.Sprintf("%s (%d)", truncateCell(value), counts[value]))
+	}
+	if len(values) > n {
+		parts = append(parts, "...")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dataset is a loaded CSV or JSON file: the statistics of its columns in
+// order of appearance and its first rows as they appear in the file.
+type dataset struct {
+	format     string
+	columns    []*columnStats
+	index      map[string]*columnStats
+	rows       int
+	ragged     int // CSV rows with a different number of fields than the header.
+	note       string
+	sampleRows int
+	samples    []string // For CSV, the header comes first.
+}
+
+func (d *dataset) column(name string) *columnStats {
+	if column, ok := d.index[name]; ok {
+		return column
+	}
+	column := newColumnStats(name)
+	column.empty = d.rows // Missing from the rows before.
+	d.index[name] = column
+	d.columns = append(d.columns, column)
+	return column
+}
+
+// addRecord adds a JSON row; nested objects become dotted columns.
+func (d *dataset) addRecord(record interface{}) {
+	seen := map[string]bool{}
+	if object, ok := record.(map[string]interface{}); ok {
+		d.addObject("", object, seen)
+	} else {
+		d.column("value").add(record)
+		seen["value"] = true
+	}
+	for _, column := range d.columns {
+		if !seen[column.name] {
+			column.empty++
+		}
+	}
+	d.rows++
+}
+
+func (d *dataset) addObject(prefix string, object map[string]interface{}, seen map[string]bool) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if nested, ok := object[key].(map[string]interface{}); ok && len(nested) > 0 {
+			d.addObject(prefix+key+".", nested, seen)
+			continue
+		}
+		d.column(prefix + key).add(object[key])
+		seen[prefix+key] = true
+	}
+}
+
+// HandleSummarizeData describes a CSV, TSV, JSON or JSON Lines file
+// instead of returning its content: the row count, each column's type,
+// empty and distinct values, the range of numbers and dates, the most
+// common values of categories and a few sample rows.
+func (a *Agent) HandleSummarizeData(input map[string]interface{}) string {
+	path, _ := input["path"].(string)
+	if path == "" {
+		return "Error: 'path' not specified or not a string for summarize_data."
+	}
+	format, _ := input["format"].(string)
+	sampleRows := defaultSampleRows
+	if n, ok := input["sample_rows"].(float64); ok && n >= 0 {
+		sampleRows = int(n)
+	}
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if info, err := a.files().Stat(fullPath); err == nil && info.Size > maxDataBytes {
+		return fmt.Sprintf("Error: '%s' is %d bytes, over the limit of %d bytes for summarize_data.", path, info.Size, maxDataBytes)
+	}
+	a.logger.Log(fmt.Sprintf("HandleSummarizeData path: %s", path))
+	content, err := a.files().ReadFile(fullPath)
+	if err != nil {
+		return fmt.Sprintf("Error reading file '%s': %v", path, err)
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	if format == "" {
+		format = dataFormat(path, content)
+	}
+	data := &dataset{format: format, index: map[string]*columnStats{}, sampleRows: sampleRows}
+	switch format {
+	case "csv", "tsv":
+		err = data.loadCSV(content)
+	case "json":
+		err = data.loadJSON(content)
+	case "jsonl":
+		err = data.loadJSONLines(content)
+	default:
+		return fmt.Sprintf("Error: unknown format %q for summarize_data; use csv, tsv, json or jsonl.", format)
+	}
+	if err != nil {
+		return fmt.Sprintf("Error parsing '%s' as %s: %v", path, strings.ToUpper(format), err)
+	}
+	return data.summary(path)
+}
+
+// dataFormat guesses the format from the extension, then from the content.
+func dataFormat(path string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return "csv"
+	case ".tsv", ".tab":
+		return "tsv"
+	case ".json":
+		return "json"
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	}
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		return "json"
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		if json.Valid(trimmed) {
+			return "json"
+		}
+		return "jsonl"
+	}
+	header, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	if bytes.Count(header, []byte("\t")) > bytes.Count(header, []byte(",")) {
+		return "tsv"
+	}
+	return "csv"
+}
+
+func (d *dataset) loadCSV(content []byte) error {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+	header, _, _ := bytes.Cut(content, []byte("\n"))
+	switch {
+	case d.format == "tsv":
+		reader.Comma = '\t'
+	case bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")):
+		reader.Comma = ';'
+		d.note = "semicolon-separated"
+	}
+	names, err := reader.Read()
+	if err == io.EOF {
+		return errors.New("the file is empty")
+	}
+	if err != nil {
+		return err
+	}
+	names = slices.Clone(names)
+	for i, name := range names {
+		if strings.TrimSpace(name) == "" {
+			names[i] = fmt.Sprintf("column %d", i+1)
+		} else if _, ok := d.index[name]; ok {
+			names[i] = fmt.Sprintf("%s (column %d)", name, i+1)
+		}
+		d.column(names[i])
+	}
+	d.samples = append(d.samples, joinCSV(names, reader.Comma))
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) != len(names) {
+			d.ragged++
+		}
+		for i, column := range d.columns {
+			if i < len(record) {
+				column.add(record[i])
+			} else {
+				column.empty++
+			}
+		}
+		d.rows++
+		if d.rows <= d.sampleRows {
+			d.samples = append(d.samples, joinCSV(record, reader.Comma))
+		}
+	}
+}
+
+// joinCSV formats a sample row, shortening long values.
+func joinCSV(record []string, comma rune) string {
+	var builder strings.Builder
+	writer := csv.NewWriter(&builder)
+	writer.Comma = comma
+	cells := make([]string, len(record))
+	for i, cell := range record {
+		cells[i] = truncateCell(cell)
+	}
+	writer.Write(cells)
+	writer.Flush()
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// loadJSON reads an array of records, or an object whose largest array
+// holds the records, e.g. {"data": [...]}. Any other value is one record.
+func (d *dataset) loadJSON(content []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("more than one JSON value; use the jsonl format for JSON Lines")
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		key, records := "", []interface{}(nil)
+		for k, v := range object {
+			if list, ok := v.([]interface{}); ok && (len(list) > len(records) || len(list) == len(records) && k < key) {
+				key, records = k, list
+			}
+		}
+		if key != "" {
+			d.note = fmt.Sprintf("records in %q", key)
+			value = records
+		}
+	}
+	records, ok := value.([]interface{})
+	if !ok {
+		records = []interface{}{value}
+	}
+	for _, record := range records {
+		d.addRecord(record)
+		if d.rows <= d.sampleRows {
+			d.samples = append(d.samples, sampleJSON(record))
+		}
+	}
+	return nil
+}
+
+func (d *dataset) loadJSONLines(content []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	for {
+		var record interface{}
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("record %d: %v", d.rows+1, err)
+		}
+		d.addRecord(record)
+		if d.rows <= d.sampleRows {
+			d.samples = append(d.samples, sampleJSON(record))
+		}
+	}
+}
+
+// sampleJSON formats a sample record on one line, shortening long strings.
+func sampleJSON(record interface{}) string {
+	var shorten func(value interface{}) interface{}
+	shorten = func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case string:
+			return truncateCell(value)
+		case map[string]interface{}:
+			short := make(map[string]interface{}, len(value))
+			for k, v := range value {
+				short[k] = shorten(v)
+			}
+			return short
+		case []interface{}:
+			short := make([]interface{}, len(value))
+			for i, v := range value {
+				short[i] = shorten(v)
+			}
+			return short
+		}
+		return value
+	}
+	encoded, _ := json.Marshal(shorten(record))
+	return string(encoded)
+}
+
+func truncateCell(value string) string {
+	runes := []rune(value)
+	if len(runes) <= maxSampleCell {
+		return value
+	}
+	return string(runes[:maxSampleCell-3]) + "..."
+}
+
+// summary renders the report.
+func (d *dataset) summary(path string) string {
+	var builder strings.Builder
+	header := fmt.Sprintf("%s: %s, %d rows, %d columns", path, strings.ToUpper(d.format), d.rows, len(d.columns))
+	if d.note != "" {
+		header += " (" + d.note + ")"
+	}
+	builder.WriteString(header + "\n")
+	if d.ragged > 0 {
+		builder.WriteString(fmt.Sprintf("Rows with a different number of fields than the header: %d\n", d.ragged))
+	}
+	if len(d.columns) > 0 {
+		builder.WriteString("\nColumns:\n")
+	}
+	for i, column := range d.columns {
+		if i == maxDataColumns {
+			builder.WriteString(fmt.Sprintf("... %d more columns\n", len(d.columns)-i))
+			break
+		}
+		builder.WriteString(fmt.Sprintf("- %s: %s\n", column.name, column))
+	}
+	if d.rows > 0 && d.sampleRows > 0 {
+		builder.WriteString(fmt.Sprintf("\nSample rows (first %d):\n", min(d.rows, d.sampleRows)))
+		builder.WriteString(strings.Join(d.samples, "\n") + "\n")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
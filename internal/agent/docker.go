@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// containerWorkdir is where the workspace is mounted inside the container.
+const containerWorkdir = "/workspace"
+
+// DockerConfig runs shell commands inside a throwaway container instead of
+// on the host. The workspace (or each workspace root) is mounted into it.
+type DockerConfig struct {
+	Image   string   `json:"image"`
+	Mount   string   `json:"mount,omitempty"`   // "rw" (default), "ro" or "none".
+	Network string   `json:"network,omitempty"` // Passed to --network, e.g. "none".
+	Args    []string `json:"args,omitempty"`    // Extra "docker run" arguments.
+}
+
+// SetDocker makes RunShell execute commands in a container. A nil config
+// runs them on the host again.
+func (a *Agent) SetDocker(cfg *DockerConfig) {
+	if cfg == nil || cfg.Image == "" {
+		a.docker = nil
+		return
+	}
+	c := *cfg
+	if c.Mount == "" {
+		c.Mount = "rw"
+	}
+	a.docker = &c
+}
+
+// RunShell runs a command line like the package level RunShell, inside the
+// configured container when there is one. dir is a workspace path; inside
+// the container it maps to the mounted workspace.
+func (a *Agent) RunShell(ctx context.Context, command, dir string) (string, int, error) {
+	if a.docker == nil {
+		if dir != "" {
+			resolved, err := a.ResolvePath(dir)
+			if err != nil {
+				return "", -1, err
+			}
+			dir = resolved
+		}
+		return RunShell(ctx, command, dir)
+	}
+
+	name := fmt.Sprintf("promptcli-%d", time.Now().UnixNano())
+	args, err := a.dockerRunArgs(name, command, dir)
+	if err != nil {
+		return "", -1, err
+	}
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	output, exitCode, err := runCaptured(ctx, cmd)
+	if ctx.Err() != nil {
+		// Killing the docker client leaves the container running.
+		exec.Command("docker", "kill", name).Run()
+	}
+	return output, exitCode, err
+}
+
+// dockerRunArgs builds the "docker run" command line. Without workspace
+// roots the current directory is mounted at /workspace; with roots each one
+// is mounted at /workspace/<name>.
+func (a *Agent) dockerRunArgs(name, command, dir string) ([]string, error) {
+	cfg := a.docker
+	args := []string{"run", "--rm", "-i", "--init", "--name", name}
+	if cfg.Network != "" {
+		args = append(args, "--network", cfg.Network)
+	}
+
+	workdir := containerWorkdir
+	if cfg.Mount != "none" {
+		suffix := ""
+		if cfg.Mount == "ro" {
+			suffix = ":ro"
+		}
+		mounts := map[string]string{}
+		if len(a.roots) == 0 {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return nil, err
+			}
+			mounts[cwd] = containerWorkdir
+		} else {
+			for _, rootName := range a.RootNames() {
+				mounts[a.roots[rootName]] = containerWorkdir + "/" + rootName
+			}
+		}
+		for host, target := range mounts {
+			args = append(args, "-v", host+":"+target+suffix)
+		}
+
+		if dir != "" {
+			resolved, err := a.ResolvePath(dir)
+			if err != nil {
+				return nil, err
+			}
+			abs, err := filepath.Abs(resolved)
+			if err != nil {
+				return nil, err
+			}
+			for host, target := range mounts {
+				if isWithin(host, abs) {
+					rel, _ := filepath.Rel(host, abs)
+					workdir = target + "/" + filepath.ToSlash(rel)
+				}
+			}
+		}
+	}
+	args = append(args, "-w", workdir)
+	args = append(args, cfg.Args...)
+	return append(args, cfg.Image, "sh", "-c", command), nil
+}
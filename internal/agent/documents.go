@@ -0,0 +1,416 @@
+package agent
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxDocumentBytes caps how much of a fetched page or document is read.
+const maxDocumentBytes = 32 << 20
+
+// documentKind reports whether data is a document the tools convert to text
+// ("pdf" or "docx") based on the content type, the file name and the leading
+// bytes. Anything else returns "".
+func documentKind(data []byte, contentType, name string) string {
+	contentType = strings.ToLower(contentType)
+	ext := strings.ToLower(filepath.Ext(name))
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")),
+		strings.HasPrefix(contentType, "application/pdf"),
+		ext == ".pdf":
+		return "pdf"
+	case strings.HasPrefix(contentType, "application/vnd.openxmlformats-officedocument.wordprocessingml"),
+		ext == ".docx",
+		bytes.HasPrefix(data, []byte("PK\x03\x04")) && bytes.Contains(data, []byte("word/document.xml")):
+		return "docx"
+	}
+	return ""
+}
+
+// extractDocumentText converts a PDF or docx document into plain text.
+func extractDocumentText(ctx context.Context, data []byte, kind string) (string, error) {
+	switch kind {
+	case "pdf":
+		return extractPDFText(ctx, data)
+	case "docx":
+		return extractDocxText(data)
+	}
+	return "", fmt.Errorf("unsupported document type %q", kind)
+}
+
+// readableText returns content as text, converting PDF and docx files
+// first. The name is used to detect the document type.
+func readableText(ctx context.Context, content []byte, name string) (string, error) {
+	kind := documentKind(content, "", name)
+	if kind == "" {
+		return string(content), nil
+	}
+	return extractDocumentText(ctx, content, kind)
+}
+
+// extractPDFText prefers poppler's pdftotext when it is installed and falls
+// back to a built-in extractor that handles simple, unencrypted PDFs.
+func extractPDFText(ctx context.Context, data []byte) (string, error) {
+	if _, err := exec.LookPath("pdftotext"); err == nil {
+		cmd := exec.CommandContext(ctx, "pdftotext", "-layout", "-enc", "UTF-8", "-", "-")
+		cmd.Stdin = bytes.NewReader(data)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		if err := cmd.Run(); err == nil {
+			return out.String(), nil
+		}
+	}
+
+	text := strings.TrimSpace(pdfContentText(data))
+	if text == "" || !mostlyPrintable(text) {
+		return "", fmt.Errorf("no extractable text in PDF (install pdftotext for scanned, encrypted or CID-font documents)")
+	}
+	return text, nil
+}
+
+// pdfContentText decodes the PDF's content streams and collects the strings
+// shown by the text operators.
+func pdfContentText(data []byte) string {
+	var builder strings.Builder
+	pos := 0
+	for {
+		idx := bytes.Index(data[pos:], []byte("stream"))
+		if idx < 0 {
+			break
+		}
+		start := pos + idx
+		pos = start + len("stream")
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			continue
+		}
+		// The stream data starts after the end-of-line following the keyword.
+		body := pos
+		if body < len(data) && data[body] == '\r' {
+			body++
+		}
+		if body < len(data) && data[body] == '\n' {
+			body++
+		}
+		end := bytes.Index(data[body:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		stream := data[body : body+end]
+		pos = body + end + len("endstream")
+
+		dict := data[max(0, start-1024):start]
+		if objStart := bytes.LastIndex(dict, []byte(" obj")); objStart >= 0 {
+			dict = dict[objStart:]
+		}
+		if skipPDFStream(dict) {
+			continue
+		}
+		if bytes.Contains(dict, []byte("/Filter")) {
+			if !bytes.Contains(dict, []byte("/FlateDecode")) {
+				continue
+			}
+			r, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			// Truncated streams still yield the text decoded so far.
+			stream, _ = io.ReadAll(r)
+		}
+		builder.WriteString(pdfShownText(stream))
+	}
+	return builder.String()
+}
+
+// skipPDFStream reports whether a stream dictionary belongs to something other
+// than page content, such as fonts, images and cross-reference data.
+func skipPDFStream(dict []byte) bool {
+	for _, marker := range []string{"/FontFile", "/Length1", "/Image", "/ObjStm", "/XRef", "/Metadata", "/ICCBased", "/N 3", "/N 4"} {
+		if bytes.Contains(dict, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pdfShownText interprets a content stream just far enough to extract the
+// operands of Tj, TJ, ' and ", inserting line breaks for text positioning.
+func pdfShownText(stream []byte) string {
+	var builder strings.Builder
+	var operands []interface{} // string, float64 or []interface{}
+	var array []interface{}
+	inArray := false
+
+	push := func(v interface{}) {
+		if inArray {
+			array = append(array, v)
+		} else {
+			operands = append(operands, v)
+		}
+	}
+	newline := func() {
+		if builder.Len() > 0 && !strings.HasSuffix(builder.String(), "\n") {
+			builder.WriteByte('\n')
+		}
+	}
+
+	for i := 0; i < len(stream); {
+		c := stream[i]
+		switch {
+		case c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0:
+			i++
+		case c == '%':
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, next := pdfLiteralString(stream, i)
+			push(s)
+			i = next
+		case c == '<' && i+1 < len(stream) && stream[i+1] == '<', c == '>' && i+1 < len(stream) && stream[i+1] == '>':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(stream[i:], '>')
+			if end < 0 {
+				return builder.String()
+			}
+			digits := strings.Map(func(r rune) rune {
+				if unicode.IsSpace(r) {
+					return -1
+				}
+				return r
+			}, string(stream[i+1:i+end]))
+			if len(digits)%2 == 1 {
+				digits += "0"
+			}
+			decoded, _ := hex.DecodeString(digits)
+			push(string(decoded))
+			i += end + 1
+		case c == '[':
+			inArray, array = true, nil
+			i++
+		case c == ']':
+			inArray = false
+			operands = append(operands, array)
+			i++
+		case c == '/':
+			j := i + 1
+			for j < len(stream) && !isPDFDelimiter(stream[j]) {
+				j++
+			}
+			push(nil)
+			i = j
+		default:
+			j := i
+			for j < len(stream) && !isPDFDelimiter(stream[j]) {
+				j++
+			}
+			if j == i {
+				i++
+				continue
+			}
+			token := string(stream[i:j])
+			i = j
+			if n, err := strconv.ParseFloat(token, 64); err == nil {
+				push(n)
+				continue
+			}
+			switch token {
+			case "Tj":
+				writePDFOperand(&builder, operands)
+			case "'", "\"":
+				newline()
+				writePDFOperand(&builder, operands)
+			case "TJ":
+				if len(operands) > 0 {
+					if items, ok := operands[len(operands)-1].([]interface{}); ok {
+						for _, item := range items {
+							switch v := item.(type) {
+							case string:
+								builder.WriteString(pdfTextString(v))
+							case float64:
+								// Large negative kerning is how many PDFs
+								// encode word spacing.
+								if v < -200 {
+									builder.WriteByte(' ')
+								}
+							}
+						}
+					}
+				}
+			case "Td", "TD":
+				if len(operands) >= 2 {
+					if ty, ok := operands[len(operands)-1].(float64); ok && ty != 0 {
+						newline()
+					} else {
+						builder.WriteByte(' ')
+					}
+				}
+			case "T*", "ET":
+				newline()
+			}
+			operands = operands[:0]
+		}
+	}
+	return builder.String()
+}
+
+// writePDFOperand writes the last string operand, if any.
+func writePDFOperand(builder *strings.Builder, operands []interface{}) {
+	if len(operands) == 0 {
+		return
+	}
+	if s, ok := operands[len(operands)-1].(string); ok {
+		builder.WriteString(pdfTextString(s))
+	}
+}
+
+// pdfTextString maps the bytes of a PDF string to text, treating them as
+// Latin-1 and dropping control characters.
+func pdfTextString(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 32 || s[i] == '\t' {
+			builder.WriteRune(rune(s[i]))
+		}
+	}
+	return builder.String()
+}
+
+// pdfLiteralString parses a parenthesised PDF string starting at stream[i]
+// and returns it with the index after the closing parenthesis.
+func pdfLiteralString(stream []byte, i int) (string, int) {
+	var out []byte
+	depth := 0
+	for i++; i < len(stream); i++ {
+		c := stream[i]
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth == 0 {
+				return string(out), i + 1
+			}
+			depth--
+			out = append(out, c)
+		case '\\':
+			i++
+			if i >= len(stream) {
+				return string(out), i
+			}
+			switch e := stream[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation.
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					for k := 0; k < 3 && i < len(stream) && stream[i] >= '0' && stream[i] <= '7'; k++ {
+						n = n*8 + int(stream[i]-'0')
+						i++
+					}
+					i--
+					out = append(out, byte(n))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return string(out), i
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
+
+// mostlyPrintable reports whether text looks like readable text rather than
+// glyph IDs from an embedded font encoding.
+func mostlyPrintable(text string) bool {
+	total, printable := 0, 0
+	for _, r := range text {
+		total++
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || unicode.IsPunct(r) {
+			printable++
+		}
+	}
+	return total > 0 && printable*10 >= total*8
+}
+
+// extractDocxText reads the paragraphs of a Word document.
+func extractDocxText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("reading docx: %w", err)
+	}
+	var document *zip.File
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			document = file
+			break
+		}
+	}
+	if document == nil {
+		return "", fmt.Errorf("reading docx: word/document.xml not found")
+	}
+	r, err := document.Open()
+	if err != nil {
+		return "", fmt.Errorf("reading docx: %w", err)
+	}
+	defer r.Close()
+
+	var builder strings.Builder
+	decoder := xml.NewDecoder(r)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading docx: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				builder.WriteByte('\t')
+			case "br", "cr":
+				builder.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				builder.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				builder.Write(t)
+			}
+		}
+	}
+	return strings.TrimSpace(builder.String()), nil
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// defaultDownloadBytes caps download_file when neither the config nor the
+// call sets a limit.
+const defaultDownloadBytes = 50 << 20
+
+// SetDownloadLimit sets the default byte cap of download_file. Zero keeps
+// the built-in default.
+func (a *Agent) SetDownloadLimit(maxBytes int) {
+	a.downloadLimit = maxBytes
+}
+
+// HandleDownloadFile saves a URL to a workspace file. The download is
+// refused if it exceeds the size limit, has an unexpected content type or
+// does not match the given sha256; nothing is written then.
+func (a *Agent) HandleDownloadFile(ctx context.Context, input map[string]interface{}) string {
+	url, _ := input["url"].(string)
+	if url == "" {
+		return "Error: 'url' not specified or not a string for download_file."
+	}
+	path, _ := input["path"].(string)
+	if path == "" {
+		return "Error: 'path' not specified or not a string for download_file."
+	}
+	want, _ := input["sha256"].(string)
+	want = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(want, "sha256:")))
+	if want != "" {
+		if decoded, err := hex.DecodeString(want); err != nil || len(decoded) != sha256.Size {
+			return fmt.Sprintf("Error: sha256 %q is not a hex-encoded SHA-256 digest.", want)
+		}
+	}
+	maxBytes := a.downloadLimit
+	if maxBytes <= 0 {
+		maxBytes = defaultDownloadBytes
+	}
+	if n, ok := input["max_bytes"].(float64); ok && n > 0 {
+		maxBytes = int(n)
+	}
+
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	overwrite, _ := input["overwrite"].(bool)
+	if _, err := a.files().Stat(fullPath); err == nil && !overwrite {
+		return fmt.Sprintf("Error: '%s' already exists. Set overwrite to replace it.", path)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	a.logger.Log(fmt.Sprintf("HandleDownloadFile url: %s path: %s", url, path))
+	res, err := a.fetcher.Get(ctx, url, false)
+	if err != nil {
+		return fmt.Sprintf("Error downloading %s: %v", url, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return fmt.Sprintf("Request to %s failed with status code: %d", url, res.StatusCode)
+	}
+	if res.ContentLength > int64(maxBytes) {
+		return fmt.Sprintf("Error: %s is %d bytes, over the download limit of %d bytes.", url, res.ContentLength, maxBytes)
+	}
+	contentType := res.Header.Get("Content-Type")
+	expected, _ := input["content_type"].(string)
+	if err := checkContentType(contentType, expected, path); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, int64(maxBytes)+1))
+	if err != nil {
+		return fmt.Sprintf("Error downloading %s: %v", url, err)
+	}
+	if len(data) > maxBytes {
+		return fmt.Sprintf("Error: %s is over the download limit of %d bytes.", url, maxBytes)
+	}
+	sum := sha256.Sum256(data)
+	got := hex.EncodeToString(sum[:])
+	if want != "" && got != want {
+		return fmt.Sprintf("Error: sha256 mismatch for %s: expected %s, got %s. The file was not saved.", url, want, got)
+	}
+
+	if err := a.files().WriteFile(fullPath, data); err != nil {
+		return fmt.Sprintf("Error writing file '%s': %v", path, err)
+	}
+	a.recordVersion(fullPath, data)
+	verified := ""
+	if want != "" {
+		verified = ", checksum verified"
+	}
+	return fmt.Sprintf("Downloaded %s to '%s' (%d bytes, %s, sha256 %s%s).", url, path, len(data), orUnknown(contentType), got, verified)
+}
+
+// checkContentType refuses a download whose Content-Type differs from the
+// expected one, or, without an expectation, an HTML page saved under a
+// name that is not HTML, which is usually a login or error page instead of
+// the file.
+func checkContentType(contentType, expected, path string) error {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if expected != "" {
+		want, _, _ := mime.ParseMediaType(expected)
+		if want == "" {
+			want = strings.ToLower(strings.TrimSpace(expected))
+		}
+		if !strings.EqualFold(mediaType, want) && !(strings.HasSuffix(want, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(want, "*"))) {
+			return fmt.Errorf("expected content type %s, got %s", expected, orUnknown(contentType))
+		}
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if mediaType == "text/html" && ext != ".html" && ext != ".htm" {
+		return fmt.Errorf("got an HTML page, not a %s file; check the URL or set content_type to text/html", orUnknown(ext))
+	}
+	return nil
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
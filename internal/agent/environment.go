@@ -0,0 +1,245 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeTimeout bounds each command the environment probe runs.
+const probeTimeout = 2 * time.Second
+
+// knownRuntime is a program the environment probe looks for, with the
+// arguments that print its version.
+type knownRuntime struct {
+	name string
+	args []string
+}
+
+// knownRuntimes are the runtimes and build tools the model is told about.
+var knownRuntimes = []knownRuntime{
+	{"go", []string{"version"}},
+	{"node", []string{"--version"}},
+	{"npm", []string{"--version"}},
+	{"python3", []string{"--version"}},
+	{"python", []string{"--version"}},
+	{"cargo", []string{"--version"}},
+	{"rustc", []string{"--version"}},
+	{"java", []string{"-version"}},
+	{"dotnet", []string{"--version"}},
+	{"make", []string{"--version"}},
+	{"docker", []string{"--version"}},
+	{"git", []string{"--version"}},
+}
+
+// versionPattern finds the version number in the output of a version flag,
+// e.g. "1.25.1" in "go version go1.25.1 linux/amd64".
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// installedRuntime is a runtime found on the PATH. Version is empty if it
+// could not be read.
+type installedRuntime struct {
+	Name    string
+	Version string
+}
+
+func (r installedRuntime) String() string {
+	if r.Version == "" {
+		return r.Name
+	}
+	return r.Name + " " + r.Version
+}
+
+// environment is what the model is told about the machine the workspace is
+// on.
+type environment struct {
+	OS, Arch  string
+	Host      string   // SSH host of a remote workspace, else "".
+	Dirs      []string // Workspace directory, or "name: dir" per root.
+	Branches  []string // Git branch per workspace directory, "" if none.
+	Runtimes  []installedRuntime
+	Container string // Image shell commands run in, see SetDocker.
+}
+
+// probeEnvironment collects the environment of the workspace, on the SSH
+// host for a remote one. Reading the versions of local runtimes takes a
+// process each, so the summary at startup only looks them up on the PATH.
+func (a *Agent) probeEnvironment(ctx context.Context, versions bool) environment {
+	env := environment{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	if a.docker != nil {
+		env.Container = a.docker.Image
+	}
+	if a.ssh != nil {
+		return a.probeRemote(ctx, env)
+	}
+
+	dirs := map[string]string{}
+	if len(a.roots) == 0 {
+		cwd, _ := os.Getwd()
+		env.Dirs = []string{cwd}
+		dirs[cwd] = cwd
+	} else {
+		for _, name := range a.RootNames() {
+			env.Dirs = append(env.Dirs, name+": "+a.roots[name])
+			dirs[name+": "+a.roots[name]] = a.roots[name]
+		}
+	}
+
+	var wg sync.WaitGroup
+	env.Branches = make([]string, len(env.Dirs))
+	for i, label := range env.Dirs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			env.Branches[i] = probeLine(ctx, dirs[label], "git", "rev-parse", "--abbrev-ref", "HEAD")
+		}()
+	}
+	found := make([]*installedRuntime, len(knownRuntimes))
+	for i, known := range knownRuntimes {
+		if _, err := exec.LookPath(known.name); err != nil {
+			continue
+		}
+		found[i] = &installedRuntime{Name: known.name}
+		if !versions {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found[i].Version = versionPattern.FindString(probeLine(ctx, "", known.name, known.args...))
+		}()
+	}
+	wg.Wait()
+	for _, r := range found {
+		if r != nil {
+			env.Runtimes = append(env.Runtimes, *r)
+		}
+	}
+	return env
+}
+
+// probeRemote collects the environment of a remote workspace with one SSH
+// command that prints a line per finding.
+func (a *Agent) probeRemote(ctx context.Context, env environment) environment {
+	env.Host = a.ssh.config.Host
+	env.Dirs = []string{a.ssh.config.Dir}
+	script := []string{
+		"uname -s", "uname -m",
+		"(cd " + shellQuote(a.ssh.config.Dir) + " && git rev-parse --abbrev-ref HEAD 2>/dev/null) || echo",
+	}
+	for _, known := range knownRuntimes {
+		script = append(script, fmt.Sprintf("command -v %s >/dev/null 2>&1 && echo \"%s: $(%s %s 2>&1 | head -n 1)\"",
+			known.name, known.name, known.name, strings.Join(known.args, " ")))
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*probeTimeout)
+	defer cancel()
+	out, err := a.ssh.run(ctx, strings.Join(script, "; ")+"; true", nil)
+	if err != nil {
+		a.logger.Log(fmt.Sprintf("Could not probe the remote environment: %v", err))
+		env.OS, env.Arch = "unknown", "unknown"
+		return env
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	for len(lines) < 3 {
+		lines = append(lines, "")
+	}
+	env.OS, env.Arch = strings.ToLower(strings.TrimSpace(lines[0])), strings.TrimSpace(lines[1])
+	env.Branches = []string{strings.TrimSpace(lines[2])}
+	for _, line := range lines[3:] {
+		if name, version, ok := strings.Cut(line, ": "); ok {
+			env.Runtimes = append(env.Runtimes, installedRuntime{Name: name, Version: versionPattern.FindString(version)})
+		}
+	}
+	return env
+}
+
+// probeLine runs a command and returns the first line of its output, or ""
+// if it failed.
+func probeLine(ctx context.Context, dir, name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line)
+}
+
+// EnvironmentSummary describes the workspace's machine for the system
+// prompt: OS, shell, directories, git branch and runtimes, so the model
+// does not have to guess them.
+func (a *Agent) EnvironmentSummary() string {
+	env := a.probeEnvironment(context.Background(), false)
+	var builder strings.Builder
+	builder.WriteString("## Environment\n")
+	machine := env.OS + "/" + env.Arch
+	if env.Host != "" {
+		machine += " on " + env.Host
+	}
+	builder.WriteString(fmt.Sprintf("- OS: %s\n", machine))
+	shell := a.Shell()
+	where := ""
+	if env.Container != "" {
+		where = fmt.Sprintf(" inside a %s container", env.Container)
+	}
+	builder.WriteString(fmt.Sprintf("- Shell: %s%s. Write commands in %s syntax.\n", shell.Name, where, shell.Syntax()))
+	for i, dir := range env.Dirs {
+		line := fmt.Sprintf("- Workspace: %s", dir)
+		if branch := env.Branches[i]; branch != "" {
+			line += fmt.Sprintf(" (git branch %s)", branch)
+		}
+		builder.WriteString(line + "\n")
+	}
+	if len(env.Runtimes) > 0 {
+		names := make([]string, len(env.Runtimes))
+		for i, r := range env.Runtimes {
+			names[i] = r.String()
+		}
+		builder.WriteString("- Installed: " + strings.Join(names, ", ") + "\n")
+	}
+	builder.WriteString("Call the environment tool for more details.\n")
+	return builder.String()
+}
+
+// HandleEnvironment describes the environment in detail: the summary plus
+// the local time, paths and CPU count.
+func (a *Agent) HandleEnvironment(ctx context.Context) string {
+	env := a.probeEnvironment(ctx, true)
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("os: %s\narch: %s\n", env.OS, env.Arch))
+	if env.Host != "" {
+		builder.WriteString(fmt.Sprintf("remote host: %s\n", env.Host))
+	}
+	shell := a.Shell()
+	builder.WriteString(fmt.Sprintf("shell: %s (%s syntax)\n", shell.Name, shell.Syntax()))
+	if env.Container != "" {
+		builder.WriteString(fmt.Sprintf("shell commands run in container: %s\n", env.Container))
+	}
+	for i, dir := range env.Dirs {
+		builder.WriteString(fmt.Sprintf("workspace: %s\n", dir))
+		if branch := env.Branches[i]; branch != "" {
+			builder.WriteString(fmt.Sprintf("git branch: %s\n", branch))
+		}
+	}
+	if env.Host == "" {
+		home, _ := os.UserHomeDir()
+		builder.WriteString(fmt.Sprintf("home: %s\ntemp: %s\npath separator: %c\ncpus: %d\n", home, os.TempDir(), os.PathSeparator, runtime.NumCPU()))
+	}
+	builder.WriteString(fmt.Sprintf("local time: %s\n", time.Now().Format("2006-01-02 15:04:05 -0700 MST")))
+	if len(env.Runtimes) == 0 {
+		builder.WriteString("runtimes: none found\n")
+	}
+	for _, r := range env.Runtimes {
+		builder.WriteString(fmt.Sprintf("runtime: %s\n", r))
+	}
+	return builder.String()
+}
@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// defaultFeedItems is how many entries read_feed returns unless asked.
+const defaultFeedItems = 20
+
+// feedSummaryLength caps each entry's summary so long feeds stay readable.
+const feedSummaryLength = 400
+
+// feedItem is an RSS or Atom entry in format-neutral form.
+type feedItem struct {
+	Title     string
+	Link      string
+	Published time.Time
+	Summary   string
+}
+
+// rssFeed covers RSS 2.0 and RSS 1.0 (RDF), which keep items under the
+// channel and at the top level respectively.
+type rssFeed struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+}
+
+type atomFeed struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+	} `xml:"entry"`
+}
+
+// HandleReadFeed fetches an RSS or Atom feed and lists its entries.
+func (a *Agent) HandleReadFeed(ctx context.Context, input map[string]interface{}) string {
+	url, ok := input["url"].(string)
+	if !ok || url == "" {
+		return "Error: 'url' not specified or not a string for read_feed."
+	}
+	a.logger.Log(fmt.Sprintf("HandleReadFeed url: %s", url))
+
+	limit := defaultFeedItems
+	if n, ok := input["max_items"].(float64); ok && n > 0 {
+		limit = int(n)
+	}
+	var since time.Time
+	if days, ok := input["since_days"].(float64); ok && days > 0 {
+		since = time.Now().Add(-time.Duration(days*24) * time.Hour)
+	}
+
+	res, err := a.fetcher.Get(ctx, url, true)
+	if err != nil {
+		return fmt.Sprintf("Error fetching feed %s: %v", url, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return fmt.Sprintf("Request to %s failed with status code: %d", url, res.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxDocumentBytes))
+	if err != nil {
+		return fmt.Sprintf("Error reading feed %s: %v", url, err)
+	}
+
+	title, items, err := parseFeed(data)
+	if err != nil {
+		return fmt.Sprintf("Error parsing feed %s: %v", url, err)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# %s\n", title))
+	count := 0
+	for _, item := range items {
+		if !since.IsZero() && !item.Published.IsZero() && item.Published.Before(since) {
+			continue
+		}
+		if count == limit {
+			break
+		}
+		count++
+		builder.WriteString(fmt.Sprintf("\n%d. %s - %s", count, item.Title, item.Link))
+		if !item.Published.IsZero() {
+			builder.WriteString(fmt.Sprintf(" (%s)", item.Published.Format("2006-01-02")))
+		}
+		builder.WriteString("\n")
+		if item.Summary != "" {
+			builder.WriteString("   " + item.Summary + "\n")
+		}
+	}
+	if count == 0 {
+		builder.WriteString("\nNo entries found.\n")
+	}
+	return builder.String()
+}
+
+// parseFeed detects the feed format from its root element and returns the
+// feed title and entries.
+func parseFeed(data []byte) (string, []feedItem, error) {
+	decoder := newFeedDecoder(data)
+	var root string
+	for root == "" {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", nil, fmt.Errorf("not an RSS or Atom feed: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root = start.Name.Local
+		}
+	}
+
+	decoder = newFeedDecoder(data)
+	switch root {
+	case "feed":
+		var feed atomFeed
+		if err := decoder.Decode(&feed); err != nil {
+			return "", nil, err
+		}
+		var items []feedItem
+		for _, entry := range feed.Entries {
+			item := feedItem{Title: strings.TrimSpace(entry.Title), Summary: feedSummary(entry.Summary)}
+			if item.Summary == "" {
+				item.Summary = feedSummary(entry.Content)
+			}
+			for _, link := range entry.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					item.Link = link.Href
+					break
+				}
+			}
+			item.Published = parseFeedTime(entry.Published)
+			if item.Published.IsZero() {
+				item.Published = parseFeedTime(entry.Updated)
+			}
+			items = append(items, item)
+		}
+		return strings.TrimSpace(feed.Title), items, nil
+	case "rss", "RDF":
+		var feed rssFeed
+		if err := decoder.Decode(&feed); err != nil {
+			return "", nil, err
+		}
+		var items []feedItem
+		for _, entry := range append(feed.Channel.Items, feed.Items...) {
+			published := parseFeedTime(entry.PubDate)
+			if published.IsZero() {
+				published = parseFeedTime(entry.Date)
+			}
+			items = append(items, feedItem{
+				Title:     strings.TrimSpace(entry.Title),
+				Link:      strings.TrimSpace(entry.Link),
+				Published: published,
+				Summary:   feedSummary(entry.Description),
+			})
+		}
+		return strings.TrimSpace(feed.Channel.Title), items, nil
+	}
+	return "", nil, fmt.Errorf("not an RSS or Atom feed (root element <%s>)", root)
+}
+
+// newFeedDecoder returns a lenient XML decoder, since many feeds in the wild
+// are not quite well-formed.
+func newFeedDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// Most feeds are UTF-8; others are read as-is rather than failing.
+		return input, nil
+	}
+	return decoder
+}
+
+// feedTimeLayouts are the date formats seen in RSS and Atom feeds.
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02",
+}
+
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// feedSummary strips the HTML that feeds embed in descriptions and shortens
+// the result to a single line.
+func feedSummary(description string) string {
+	text, err := ExtractTextFromHTML(strings.NewReader(description))
+	if err != nil {
+		text = description
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > feedSummaryLength {
+		cut := strings.LastIndex(text[:feedSummaryLength], " ")
+		if cut <= 0 {
+			cut = feedSummaryLength
+		}
+		text = text[:cut] + "..."
+	}
+	return text
+}
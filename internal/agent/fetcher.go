@@ -0,0 +1,330 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"prompt-cli/internal/logger"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FetchConfig controls how the web tools behave towards the sites they
+// fetch from.
+type FetchConfig struct {
+	UserAgent        string `json:"user_agent,omitempty"`         // Sent with every request. Default identifies PromptCLI.
+	DomainIntervalMs int    `json:"domain_interval_ms,omitempty"` // Minimum gap between requests to one host. Default 1000.
+	MaxConcurrent    int    `json:"max_concurrent,omitempty"`     // Requests in flight across all hosts. Default 4.
+	IgnoreRobots     bool   `json:"ignore_robots,omitempty"`      // Skip robots.txt checks for visit_url.
+}
+
+const (
+	defaultUserAgent      = "PromptCLI/1.0 (+https://github.com/3583Bytes/PromptCLI)"
+	defaultDomainInterval = time.Second
+	defaultMaxConcurrent  = 4
+	robotsTTL             = time.Hour
+	robotsTimeout         = 10 * time.Second
+)
+
+// Fetcher is a polite HTTP client for the web tools. It identifies itself
+// with a configurable user agent, honours robots.txt, spaces out requests to
+// the same host and caps the number of requests in flight.
+type Fetcher struct {
+	config   FetchConfig
+	interval time.Duration
+	client   *http.Client
+	logger   *logger.Logger
+	slots    chan struct{}
+
+	mu     sync.Mutex
+	next   map[string]time.Time    // Earliest time the next request to a host may start.
+	robots map[string]*robotsRules // Parsed robots.txt per scheme://host.
+}
+
+// NewFetcher creates a Fetcher, filling in defaults for unset values.
+func NewFetcher(cfg FetchConfig, logger *logger.Logger) *Fetcher {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+	interval := defaultDomainInterval
+	if cfg.DomainIntervalMs > 0 {
+		interval = time.Duration(cfg.DomainIntervalMs) * time.Millisecond
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = defaultMaxConcurrent
+	}
+	return &Fetcher{
+		config:   cfg,
+		interval: interval,
+		client:   &http.Client{},
+		logger:   logger,
+		slots:    make(chan struct{}, cfg.MaxConcurrent),
+		next:     make(map[string]time.Time),
+		robots:   make(map[string]*robotsRules),
+	}
+}
+
+// SetFetch configures the polite fetcher used by web_search and visit_url.
+// A nil config keeps the defaults.
+func (a *Agent) SetFetch(cfg *FetchConfig) {
+	if cfg != nil {
+		a.fetcher = NewFetcher(*cfg, a.logger)
+	}
+}
+
+// Get fetches rawURL. With checkRobots set the request is refused when the
+// site's robots.txt disallows the path for our user agent. The response body
+// must be closed to free the concurrency slot.
+func (f *Fetcher) Get(ctx context.Context, rawURL string, checkRobots bool) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+	if checkRobots && !f.config.IgnoreRobots {
+		rules := f.robotsFor(ctx, u)
+		if !rules.allowed(u.EscapedPath()) {
+			return nil, fmt.Errorf("disallowed by %s://%s/robots.txt", u.Scheme, u.Host)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return f.do(req)
+}
+
+// do sends req once a concurrency slot is free and the host's rate limit,
+// including any Crawl-delay, allows it.
+func (f *Fetcher) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	select {
+	case f.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-f.slots }
+
+	if err := f.wait(ctx, req.URL); err != nil {
+		release()
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", f.config.UserAgent)
+	res, err := f.client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	res.Body = &releasingBody{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+// wait blocks until a request to u's host may start and reserves the slot
+// after it.
+func (f *Fetcher) wait(ctx context.Context, u *url.URL) error {
+	host := strings.ToLower(u.Host)
+	interval := f.interval
+
+	f.mu.Lock()
+	if rules := f.robots[u.Scheme+"://"+host]; rules != nil && rules.crawlDelay > interval {
+		interval = rules.crawlDelay
+	}
+	now := time.Now()
+	start := f.next[host]
+	if start.Before(now) {
+		start = now
+	}
+	f.next[host] = start.Add(interval)
+	f.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	f.logger.Log(fmt.Sprintf("Rate limiting %s for %s", host, delay.Round(time.Millisecond)))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releasingBody frees the fetcher's concurrency slot when the body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// robotsRules holds the robots.txt rules that apply to our user agent.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	fetched    time.Time
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int // Length of the original pattern; the longest match wins.
+	pattern *regexp.Regexp
+}
+
+// allowed reports whether path may be fetched. The most specific matching
+// rule wins and Allow wins ties, as in RFC 9309.
+func (r *robotsRules) allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > best || (rule.length == best && rule.allow) {
+			best, allow = rule.length, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsFor returns the cached robots.txt rules for u's site, fetching them
+// when missing or stale. Sites whose robots.txt cannot be read are treated
+// as allowing everything.
+func (f *Fetcher) robotsFor(ctx context.Context, u *url.URL) *robotsRules {
+	site := u.Scheme + "://" + strings.ToLower(u.Host)
+	f.mu.Lock()
+	rules := f.robots[site]
+	f.mu.Unlock()
+	if rules != nil && time.Since(rules.fetched) < robotsTTL {
+		return rules
+	}
+
+	rules = &robotsRules{fetched: time.Now()}
+	robotsCtx, cancel := context.WithTimeout(ctx, robotsTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(robotsCtx, "GET", site+"/robots.txt", nil)
+	if err == nil {
+		var res *http.Response
+		res, err = f.do(req)
+		if err == nil {
+			if res.StatusCode == http.StatusOK {
+				*rules = parseRobots(io.LimitReader(res.Body, 512*1024), f.config.UserAgent)
+				rules.fetched = time.Now()
+			}
+			res.Body.Close()
+		}
+	}
+	if err != nil {
+		f.logger.Log(fmt.Sprintf("Could not read %s/robots.txt: %v", site, err))
+	}
+
+	f.mu.Lock()
+	f.robots[site] = rules
+	f.mu.Unlock()
+	return rules
+}
+
+// parseRobots extracts the rules for userAgent from a robots.txt file. The
+// group naming our product token is used if present, otherwise the "*"
+// group.
+func parseRobots(r io.Reader, userAgent string) robotsRules {
+	product := strings.ToLower(strings.SplitN(userAgent, "/", 2)[0])
+
+	type group struct {
+		agents []string
+		rules  []robotsRule
+		delay  time.Duration
+	}
+	var groups []*group
+	var current *group
+	inAgents := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				length:  len(value),
+				pattern: robotsPattern(value),
+			})
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.delay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+
+	var wildcard, specific *group
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" && wildcard == nil {
+				wildcard = g
+			} else if agent != "*" && product != "" && strings.Contains(product, agent) && specific == nil {
+				specific = g
+			}
+		}
+	}
+	chosen := specific
+	if chosen == nil {
+		chosen = wildcard
+	}
+	if chosen == nil {
+		return robotsRules{}
+	}
+	return robotsRules{rules: chosen.rules, crawlDelay: chosen.delay}
+}
+
+// robotsPattern compiles a robots.txt path pattern, where "*" matches any
+// sequence and a trailing "$" anchors the end.
+func robotsPattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// fileSystem is where the file tools read and write. It is the local disk
+// unless an SSH workspace is configured.
+type fileSystem interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte) error
+	AppendFile(path string, data []byte) error
+	Remove(path string) error
+	// Rename moves a file, creating the target's directory if needed.
+	Rename(oldPath, newPath string) error
+	// Stat reports the size and modification time of a file. Missing files
+	// give an error matching fs.ErrNotExist.
+	Stat(path string) (fileStat, error)
+	// ReadDir lists the names of the entries in a directory.
+	ReadDir(path string) ([]string, error)
+	// MkdirAll creates a directory and any missing parents.
+	MkdirAll(path string) error
+	// Glob returns the paths below dir, relative to it, matching a
+	// doublestar pattern.
+	Glob(dir, pattern string) ([]string, error)
+}
+
+// fileStat is the part of a file's metadata the tools use.
+type fileStat struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// localFS implements fileSystem on the local disk.
+type localFS struct{}
+
+func (localFS) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (localFS) WriteFile(path string, data []byte) error {
+	return os.WriteFile(path, data, 0644)
+}
+
+func (localFS) AppendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return err
+}
+
+func (localFS) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (localFS) Rename(oldPath, newPath string) error {
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return err
+	}
+	err := os.Rename(oldPath, newPath)
+	if err == nil {
+		return nil
+	}
+	// Renaming fails across file systems; copy and remove instead.
+	info, statErr := os.Stat(oldPath)
+	if statErr != nil || !info.Mode().IsRegular() {
+		return err
+	}
+	data, readErr := os.ReadFile(oldPath)
+	if readErr != nil {
+		return err
+	}
+	if err := os.WriteFile(newPath, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Remove(oldPath)
+}
+
+func (localFS) Stat(path string) (fileStat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}, err
+	}
+	return fileStat{Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (localFS) ReadDir(path string) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+func (localFS) MkdirAll(path string) error {
+	return os.MkdirAll(path, 0755)
+}
+
+// Glob matches with forward slashes, as io/fs requires. On Windows a
+// pattern written with backslashes, such as "src\**\*.go", is converted
+// first.
+func (localFS) Glob(dir, pattern string) ([]string, error) {
+	if runtime.GOOS == "windows" {
+		pattern = filepath.ToSlash(pattern)
+	}
+	return doublestar.Glob(os.DirFS(dir), pattern)
+}
+
+// ReadWorkspaceFile reads a file through the active workspace backend, e.g.
+// for @ mentions in the prompt.
+func (a *Agent) ReadWorkspaceFile(path string) ([]byte, error) {
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return a.files().ReadFile(fullPath)
+}
+
+// files returns the active backend.
+func (a *Agent) files() fileSystem {
+	if a.ssh != nil {
+		return a.ssh
+	}
+	return localFS{}
+}
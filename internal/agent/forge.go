@@ -0,0 +1,411 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ForgeConfig points the github or gitlab tool at a repository.
+type ForgeConfig struct {
+	Repo    string `json:"repo"`               // "owner/name", or the GitLab project path.
+	Token   string `json:"token,omitempty"`    // Falls back to $GITHUB_TOKEN or $GITLAB_TOKEN.
+	BaseURL string `json:"base_url,omitempty"` // API root for GitHub Enterprise or self-hosted GitLab.
+}
+
+// forgeItem is an issue or pull/merge request in provider-neutral form.
+type forgeItem struct {
+	Number int
+	Title  string
+	State  string
+	Author string
+	Body   string
+	URL    string
+	IsPR   bool
+}
+
+// forgeComment is a comment on an issue or pull request.
+type forgeComment struct {
+	Author  string
+	Body    string
+	Created time.Time
+}
+
+// forgeClient talks to one provider's REST API.
+type forgeClient interface {
+	list(ctx context.Context, prs bool, state string) ([]forgeItem, error)
+	get(ctx context.Context, prs bool, number int) (forgeItem, []forgeComment, error)
+	createIssue(ctx context.Context, title, body string) (forgeItem, error)
+	comment(ctx context.Context, prs bool, number int, body string) error
+}
+
+const forgeToolPrompt = `- %[1]s
+  - purpose: work with %[2]s issues and %[3]s of the configured repository
+  - input: {"action":"list_issues | read_issue | create_issue | list_prs | read_pr | comment","number":integer|null,"title":"string|null","body":"string|null","state":"open | closed | all | null","pr":boolean|null}
+  - notes: read_issue and read_pr include the comments. create_issue needs title and body; comment needs number and body and comments on a %[4]s when "pr" is true. Creating and commenting ask the user first.`
+
+// SetForge configures the repository used by the github or gitlab tool.
+func (a *Agent) SetForge(provider string, cfg *ForgeConfig) {
+	if a.forges == nil {
+		a.forges = make(map[string]ForgeConfig)
+	}
+	if cfg == nil {
+		delete(a.forges, provider)
+		return
+	}
+	a.forges[provider] = *cfg
+}
+
+// forgeMutates reports whether a github/gitlab call changes the repository.
+func forgeMutates(input map[string]interface{}) bool {
+	action, _ := input["action"].(string)
+	return action == "create_issue" || action == "comment"
+}
+
+// HandleGitHub runs the github tool.
+func (a *Agent) HandleGitHub(ctx context.Context, input map[string]interface{}) string {
+	return a.handleForge(ctx, "github", input)
+}
+
+// HandleGitLab runs the gitlab tool.
+func (a *Agent) HandleGitLab(ctx context.Context, input map[string]interface{}) string {
+	return a.handleForge(ctx, "gitlab", input)
+}
+
+func (a *Agent) handleForge(ctx context.Context, provider string, input map[string]interface{}) string {
+	cfg, ok := a.forges[provider]
+	if !ok || cfg.Repo == "" {
+		return fmt.Sprintf("Error: no repository configured for %s.", provider)
+	}
+	client := newForgeClient(provider, cfg)
+
+	action, _ := input["action"].(string)
+	number := 0
+	if n, ok := input["number"].(float64); ok {
+		number = int(n)
+	}
+	title, _ := input["title"].(string)
+	body, _ := input["body"].(string)
+	state, _ := input["state"].(string)
+	if state == "" {
+		state = "open"
+	}
+	pr, _ := input["pr"].(bool)
+
+	switch action {
+	case "list_issues", "list_prs":
+		items, err := client.list(ctx, action == "list_prs", state)
+		if err != nil {
+			return fmt.Sprintf("Error listing from %s: %v", provider, err)
+		}
+		if len(items) == 0 {
+			return fmt.Sprintf("No %s items in %s.", state, cfg.Repo)
+		}
+		var builder strings.Builder
+		for _, item := range items {
+			builder.WriteString(fmt.Sprintf("- #%d [%s] %s (by %s)\n", item.Number, item.State, item.Title, item.Author))
+		}
+		return truncateOutput(builder.String(), input)
+
+	case "read_issue", "read_pr":
+		if number <= 0 {
+			return fmt.Sprintf("Error: 'number' is required for %s.", action)
+		}
+		item, comments, err := client.get(ctx, action == "read_pr", number)
+		if err != nil {
+			return fmt.Sprintf("Error reading #%d from %s: %v", number, provider, err)
+		}
+		return truncateOutput(formatForgeItem(item, comments), input)
+
+	case "create_issue":
+		if title == "" {
+			return "Error: 'title' is required for create_issue."
+		}
+		item, err := client.createIssue(ctx, title, body)
+		if err != nil {
+			return fmt.Sprintf("Error creating issue on %s: %v", provider, err)
+		}
+		return fmt.Sprintf("Created issue #%d: %s", item.Number, item.URL)
+
+	case "comment":
+		if number <= 0 || body == "" {
+			return "Error: 'number' and 'body' are required for comment."
+		}
+		if err := client.comment(ctx, pr, number, body); err != nil {
+			return fmt.Sprintf("Error commenting on #%d: %v", number, err)
+		}
+		return fmt.Sprintf("Comment added to #%d.", number)
+
+	default:
+		return fmt.Sprintf("Error: unknown %s action %q.", provider, action)
+	}
+}
+
+// formatForgeItem renders an issue or pull request and its comments as
+// Markdown.
+func formatForgeItem(item forgeItem, comments []forgeComment) string {
+	var builder strings.Builder
+	kind := "Issue"
+	if item.IsPR {
+		kind = "Pull request"
+	}
+	builder.WriteString(fmt.Sprintf("# %s #%d: %s\n\nState: %s | Author: %s | %s\n\n", kind, item.Number, item.Title, item.State, item.Author, item.URL))
+	builder.WriteString(strings.TrimSpace(item.Body) + "\n")
+	for _, c := range comments {
+		builder.WriteString(fmt.Sprintf("\n## Comment by %s (%s)\n\n%s\n", c.Author, c.Created.Format("2006-01-02"), strings.TrimSpace(c.Body)))
+	}
+	return builder.String()
+}
+
+// truncateOutput applies max_bytes, or defaultOptionalOutput.
+func truncateOutput(output string, input map[string]interface{}) string {
+	maxBytes := defaultOptionalOutput
+	if mb, ok := input["max_bytes"].(float64); ok && mb > 0 {
+		maxBytes = int(mb)
+	}
+	if len(output) > maxBytes {
+		return output[:maxBytes] + fmt.Sprintf("\n... truncated, %d of %d bytes shown ...", maxBytes, len(output))
+	}
+	return output
+}
+
+func newForgeClient(provider string, cfg ForgeConfig) forgeClient {
+	if provider == "gitlab" {
+		if cfg.Token == "" {
+			cfg.Token = os.Getenv("GITLAB_TOKEN")
+		}
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = "https://gitlab.com/api/v4"
+		}
+		return &gitlabClient{cfg: cfg}
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("GITHUB_TOKEN")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.github.com"
+	}
+	return &githubClient{cfg: cfg}
+}
+
+// forgeRequest sends a JSON API request and decodes the response into out.
+func forgeRequest(ctx context.Context, method, endpoint string, headers map[string]string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// --- GitHub ---
+
+type githubClient struct {
+	cfg ForgeConfig
+}
+
+type githubIssue struct {
+	Number      int                    `json:"number"`
+	Title       string                 `json:"title"`
+	State       string                 `json:"state"`
+	Body        string                 `json:"body"`
+	HTMLURL     string                 `json:"html_url"`
+	User        struct{ Login string } `json:"user"`
+	PullRequest *struct{}              `json:"pull_request"`
+}
+
+type githubComment struct {
+	Body      string                 `json:"body"`
+	CreatedAt time.Time              `json:"created_at"`
+	User      struct{ Login string } `json:"user"`
+}
+
+func (g *githubClient) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if g.cfg.Token != "" {
+		headers["Authorization"] = "Bearer " + g.cfg.Token
+	}
+	return forgeRequest(ctx, method, strings.TrimRight(g.cfg.BaseURL, "/")+"/repos/"+g.cfg.Repo+path, headers, payload, out)
+}
+
+func (g *githubClient) item(issue githubIssue, isPR bool) forgeItem {
+	return forgeItem{Number: issue.Number, Title: issue.Title, State: issue.State, Author: issue.User.Login, Body: issue.Body, URL: issue.HTMLURL, IsPR: isPR || issue.PullRequest != nil}
+}
+
+func (g *githubClient) list(ctx context.Context, prs bool, state string) ([]forgeItem, error) {
+	path := "/issues"
+	if prs {
+		path = "/pulls"
+	}
+	var issues []githubIssue
+	if err := g.do(ctx, "GET", path+"?per_page=30&state="+url.QueryEscape(state), nil, &issues); err != nil {
+		return nil, err
+	}
+	var items []forgeItem
+	for _, issue := range issues {
+		// The issues endpoint also returns pull requests.
+		if !prs && issue.PullRequest != nil {
+			continue
+		}
+		items = append(items, g.item(issue, prs))
+	}
+	return items, nil
+}
+
+func (g *githubClient) get(ctx context.Context, prs bool, number int) (forgeItem, []forgeComment, error) {
+	path := fmt.Sprintf("/issues/%d", number)
+	if prs {
+		path = fmt.Sprintf("/pulls/%d", number)
+	}
+	var issue githubIssue
+	if err := g.do(ctx, "GET", path, nil, &issue); err != nil {
+		return forgeItem{}, nil, err
+	}
+	var raw []githubComment
+	if err := g.do(ctx, "GET", fmt.Sprintf("/issues/%d/comments?per_page=100", number), nil, &raw); err != nil {
+		return forgeItem{}, nil, err
+	}
+	comments := make([]forgeComment, 0, len(raw))
+	for _, c := range raw {
+		comments = append(comments, forgeComment{Author: c.User.Login, Body: c.Body, Created: c.CreatedAt})
+	}
+	return g.item(issue, prs), comments, nil
+}
+
+func (g *githubClient) createIssue(ctx context.Context, title, body string) (forgeItem, error) {
+	var issue githubIssue
+	err := g.do(ctx, "POST", "/issues", map[string]string{"title": title, "body": body}, &issue)
+	return g.item(issue, false), err
+}
+
+// comment uses the issue comments endpoint, which also covers pull requests.
+func (g *githubClient) comment(ctx context.Context, prs bool, number int, body string) error {
+	return g.do(ctx, "POST", fmt.Sprintf("/issues/%d/comments", number), map[string]string{"body": body}, nil)
+}
+
+// --- GitLab ---
+
+type gitlabClient struct {
+	cfg ForgeConfig
+}
+
+type gitlabIssue struct {
+	IID         int                       `json:"iid"`
+	Title       string                    `json:"title"`
+	State       string                    `json:"state"`
+	Description string                    `json:"description"`
+	WebURL      string                    `json:"web_url"`
+	Author      struct{ Username string } `json:"author"`
+}
+
+type gitlabNote struct {
+	Body      string                    `json:"body"`
+	CreatedAt time.Time                 `json:"created_at"`
+	System    bool                      `json:"system"`
+	Author    struct{ Username string } `json:"author"`
+}
+
+func (g *gitlabClient) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	headers := map[string]string{}
+	if g.cfg.Token != "" {
+		headers["PRIVATE-TOKEN"] = g.cfg.Token
+	}
+	endpoint := strings.TrimRight(g.cfg.BaseURL, "/") + "/projects/" + url.PathEscape(g.cfg.Repo) + path
+	return forgeRequest(ctx, method, endpoint, headers, payload, out)
+}
+
+// kind returns the API collection for issues or merge requests.
+func (g *gitlabClient) kind(prs bool) string {
+	if prs {
+		return "/merge_requests"
+	}
+	return "/issues"
+}
+
+func (g *gitlabClient) item(issue gitlabIssue, isPR bool) forgeItem {
+	return forgeItem{Number: issue.IID, Title: issue.Title, State: issue.State, Author: issue.Author.Username, Body: issue.Description, URL: issue.WebURL, IsPR: isPR}
+}
+
+func (g *gitlabClient) list(ctx context.Context, prs bool, state string) ([]forgeItem, error) {
+	switch state {
+	case "open":
+		state = "opened"
+	case "all":
+		state = ""
+	}
+	query := "?per_page=30"
+	if state != "" {
+		query += "&state=" + url.QueryEscape(state)
+	}
+	var issues []gitlabIssue
+	if err := g.do(ctx, "GET", g.kind(prs)+query, nil, &issues); err != nil {
+		return nil, err
+	}
+	items := make([]forgeItem, 0, len(issues))
+	for _, issue := range issues {
+		items = append(items, g.item(issue, prs))
+	}
+	return items, nil
+}
+
+func (g *gitlabClient) get(ctx context.Context, prs bool, number int) (forgeItem, []forgeComment, error) {
+	path := fmt.Sprintf("%s/%d", g.kind(prs), number)
+	var issue gitlabIssue
+	if err := g.do(ctx, "GET", path, nil, &issue); err != nil {
+		return forgeItem{}, nil, err
+	}
+	var notes []gitlabNote
+	if err := g.do(ctx, "GET", path+"/notes?sort=asc&per_page=100", nil, &notes); err != nil {
+		return forgeItem{}, nil, err
+	}
+	var comments []forgeComment
+	for _, n := range notes {
+		// System notes record events like label changes, not discussion.
+		if n.System {
+			continue
+		}
+		comments = append(comments, forgeComment{Author: n.Author.Username, Body: n.Body, Created: n.CreatedAt})
+	}
+	return g.item(issue, prs), comments, nil
+}
+
+func (g *gitlabClient) createIssue(ctx context.Context, title, body string) (forgeItem, error) {
+	var issue gitlabIssue
+	err := g.do(ctx, "POST", "/issues", map[string]string{"title": title, "description": body}, &issue)
+	return g.item(issue, false), err
+}
+
+func (g *gitlabClient) comment(ctx context.Context, prs bool, number int, body string) error {
+	return g.do(ctx, "POST", fmt.Sprintf("%s/%d/notes", g.kind(prs), number), map[string]string{"body": body}, nil)
+}
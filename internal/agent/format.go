@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"prompt-cli/internal/diff"
+)
+
+// maxFormatDiff caps the formatter diff quoted in a tool result.
+const maxFormatDiff = 4000
+
+// FormattedMarker starts the line of a write result that reports the
+// changes a formatter made.
+const FormattedMarker = "Formatted with "
+
+// SetFormatters sets the commands that format files after write_file and
+// append_file, by extension, e.g. {".go": "gofmt -w", ".py": "black -q",
+// ".ts": "prettier --write"}. The file's path is appended and the command
+// must rewrite the file in place.
+func (a *Agent) SetFormatters(formatters map[string]string) {
+	a.formatters = byExtension(formatters)
+}
+
+// formatWritten runs a formatter on a file the agent just wrote and
+// returns the formatted content with a note for the tool result. The note
+// holds the formatter's diff, so the model knows the file no longer matches
+// what it sent and does not fight the formatter on its next edit.
+func (a *Agent) formatWritten(ctx context.Context, formatter, p, fullPath string, before []byte) ([]byte, string) {
+	if out, err := a.runOnFile(ctx, formatter, fullPath); err != nil {
+		if out != "" {
+			return before, fmt.Sprintf("Formatter `%s` failed: %v\n%s", formatter, err, out)
+		}
+		return before, fmt.Sprintf("Formatter `%s` failed: %v", formatter, err)
+	}
+	after, err := a.files().ReadFile(fullPath)
+	if err != nil {
+		return before, fmt.Sprintf("Formatter `%s` ran, but the file could not be read back: %v", formatter, err)
+	}
+	changes := diff.Unified(p, string(before), string(after))
+	if changes == "" {
+		return after, fmt.Sprintf("`%s` made no changes.", formatter)
+	}
+	a.recordVersion(fullPath, after)
+	if len(changes) > maxFormatDiff {
+		changes = changes[:maxFormatDiff] + "\n... diff truncated ..."
+	}
+	return after, fmt.Sprintf("%s`%s`:\n```diff\n%s```", FormattedMarker, formatter, changes)
+}
@@ -0,0 +1,198 @@
+package agent
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignoreRule is one pattern line of a .gitignore file.
+type ignoreRule struct {
+	base     string // Slash-separated directory of the .gitignore, relative to the walk root; "" for the root.
+	pattern  string
+	negate   bool // "!pattern" re-includes a path.
+	dirOnly  bool // "pattern/" only matches directories.
+	anchored bool // The pattern contains a slash, so it matches from base rather than at any depth.
+}
+
+// ignoreRules are the .gitignore rules that apply below a directory, in the
+// order git evaluates them: parent files first, the last match wins.
+type ignoreRules []ignoreRule
+
+// parseIgnoreFile adds the rules of a .gitignore file located in base.
+func (r ignoreRules) parseIgnoreFile(base string, content []byte) ignoreRules {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimRight(line, " ")
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		r = append(r, rule)
+	}
+	return r
+}
+
+// ignored reports whether the slash-separated path, relative to the walk
+// root, is excluded by the rules. A path is also excluded when one of its
+// parent directories is, because git does not look inside ignored
+// directories. The returned string is the excluded path itself or the
+// directory that excludes it, so callers can report "node_modules/" once
+// instead of every file below it.
+func (r ignoreRules) ignored(file string) (string, bool) {
+	parts := strings.Split(file, "/")
+	for i := 1; i <= len(parts); i++ {
+		sub := strings.Join(parts[:i], "/")
+		isDir := i < len(parts)
+		if parts[i-1] == ".git" || r.matches(sub, isDir) {
+			if isDir {
+				sub += "/"
+			}
+			return sub, true
+		}
+	}
+	return "", false
+}
+
+// matches applies the rules to a single path; the last matching rule
+// decides.
+func (r ignoreRules) matches(name string, isDir bool) bool {
+	excluded := false
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel := name
+		if rule.base != "" {
+			var ok bool
+			if rel, ok = strings.CutPrefix(name, rule.base+"/"); !ok {
+				continue
+			}
+		}
+		target := rel
+		if !rule.anchored {
+			target = path.Base(rel)
+		}
+		if ok, _ := doublestar.Match(rule.pattern, target); ok {
+			excluded = !rule.negate
+		}
+	}
+	return excluded
+}
+
+// loadIgnoreRules reads the .gitignore files that apply to a walk of dir:
+// those of the parent directories up to the repository root, rebased onto
+// dir, then the one in dir and those below it. Parents come first so the
+// more specific rules override theirs.
+func (a *Agent) loadIgnoreRules(dir string) ignoreRules {
+	var ancestors, prefixes []string
+	current, rel, inRepo := dir, "", false
+	for {
+		if _, err := a.files().Stat(a.joinPath(current, ".git")); err == nil {
+			inRepo = true
+			break
+		}
+		parent := a.parentDir(current)
+		if parent == current {
+			break
+		}
+		rel = path.Join(a.baseName(current), rel)
+		ancestors = append(ancestors, parent)
+		prefixes = append(prefixes, rel)
+		current = parent
+	}
+
+	var rules ignoreRules
+	if inRepo {
+		for i := len(ancestors) - 1; i >= 0; i-- {
+			if content, err := a.files().ReadFile(a.joinPath(ancestors[i], ".gitignore")); err == nil {
+				rules = append(rules, rebaseRules(ignoreRules(nil).parseIgnoreFile("", content), prefixes[i])...)
+			}
+		}
+	}
+	if content, err := a.files().ReadFile(a.joinPath(dir, ".gitignore")); err == nil {
+		rules = rules.parseIgnoreFile("", content)
+	}
+
+	nested, _ := a.files().Glob(dir, "**/.gitignore")
+	sort.Slice(nested, func(i, j int) bool {
+		return strings.Count(nested[i], "/") < strings.Count(nested[j], "/")
+	})
+	for _, file := range nested {
+		if !strings.Contains(file, "/") {
+			continue
+		}
+		if _, excluded := rules.ignored(file); excluded {
+			continue
+		}
+		if content, err := a.files().ReadFile(a.joinPath(dir, file)); err == nil {
+			rules = rules.parseIgnoreFile(path.Dir(file), content)
+		}
+	}
+	return rules
+}
+
+// rebaseRules turns rules of a .gitignore in a parent directory into rules
+// relative to the subdirectory prefix below it.
+func rebaseRules(rules ignoreRules, prefix string) ignoreRules {
+	var rebased ignoreRules
+	for _, rule := range rules {
+		if !rule.anchored {
+			rebased = append(rebased, rule)
+			continue
+		}
+		// An anchored pattern applies below prefix only if it starts
+		// with it; "**/" patterns apply anywhere.
+		switch {
+		case strings.HasPrefix(rule.pattern, "**/"):
+			rebased = append(rebased, rule)
+		case strings.HasPrefix(rule.pattern, prefix+"/"):
+			rule.pattern = strings.TrimPrefix(rule.pattern, prefix+"/")
+			rebased = append(rebased, rule)
+		}
+	}
+	return rebased
+}
+
+// parentDir and baseName split paths of the active backend.
+func (a *Agent) parentDir(dir string) string {
+	if a.ssh != nil {
+		return path.Dir(dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	return filepath.Dir(abs)
+}
+
+func (a *Agent) baseName(dir string) string {
+	if a.ssh != nil {
+		return path.Base(dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Base(dir)
+	}
+	return filepath.Base(abs)
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// HookConfig is a user-defined script run before or after tool calls, a
+// policy point for teams: a "before" hook that exits with a non-zero status
+// blocks the call, and the output of an "after" hook, e.g. a linter, is
+// added to the tool result.
+type HookConfig struct {
+	When    string   `json:"when"`            // "before" or "after".
+	Tools   []string `json:"tools,omitempty"` // Tool names; empty matches every tool.
+	Paths   []string `json:"paths,omitempty"` // Globs of the "path" input, e.g. "**/*.pb.go"; empty matches any call.
+	Command string   `json:"command"`         // Run through the shell in the current directory.
+}
+
+// SetHooks sets the hooks run around tool calls.
+func (a *Agent) SetHooks(hooks []HookConfig) {
+	a.hooks = hooks
+}
+
+// matches reports whether the hook applies to a tool call.
+func (h HookConfig) matches(when, toolName string, input map[string]interface{}) bool {
+	if h.When != when || len(h.Tools) > 0 && !slices.Contains(h.Tools, toolName) {
+		return false
+	}
+	if len(h.Paths) == 0 {
+		return true
+	}
+	path, _ := input["path"].(string)
+	if path == "" {
+		return false
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	for _, pattern := range h.Paths {
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// runBeforeHooks runs the "before" hooks of a call and returns the error
+// result of the first one that blocks it, or "" to let the call run.
+func (a *Agent) runBeforeHooks(ctx context.Context, toolName string, input map[string]interface{}) string {
+	for _, hook := range a.hooks {
+		if !hook.matches("before", toolName, input) {
+			continue
+		}
+		out, code, err := a.runHook(ctx, hook, toolName, input, "")
+		switch {
+		case err != nil:
+			return fmt.Sprintf("Error: hook `%s` could not run: %v", hook.Command, err)
+		case code != 0:
+			a.logger.Log(fmt.Sprintf("Hook %q blocked %s", hook.Command, toolName))
+			if out == "" {
+				return fmt.Sprintf("Error: blocked by hook `%s` (exit status %d).", hook.Command, code)
+			}
+			return fmt.Sprintf("Error: blocked by hook `%s` (exit status %d).\n%s", hook.Command, code, out)
+		}
+	}
+	return ""
+}
+
+// runAfterHooks runs the "after" hooks of a call and adds their output to
+// the tool's output.
+func (a *Agent) runAfterHooks(ctx context.Context, toolName string, input map[string]interface{}, output string) string {
+	for _, hook := range a.hooks {
+		if !hook.matches("after", toolName, input) {
+			continue
+		}
+		out, code, err := a.runHook(ctx, hook, toolName, input, output)
+		switch {
+		case err != nil:
+			output += fmt.Sprintf("\nHook `%s` could not run: %v", hook.Command, err)
+		case code != 0:
+			output += fmt.Sprintf("\nHook `%s` failed (exit status %d):\n%s", hook.Command, code, out)
+		case out != "":
+			output += fmt.Sprintf("\nHook `%s`:\n%s", hook.Command, out)
+		}
+	}
+	return output
+}
+
+// runHook runs a hook with the call on stdin as JSON, {"tool", "input" and,
+// after the call, "output"}, and in the PROMPTCLI_TOOL and PROMPTCLI_PATH
+// environment variables.
+func (a *Agent) runHook(ctx context.Context, hook HookConfig, toolName string, input map[string]interface{}, output string) (string, int, error) {
+	payload := map[string]interface{}{"tool": toolName, "input": input}
+	if output != "" {
+		payload["output"] = output
+	}
+	stdin, err := json.Marshal(payload)
+	if err != nil {
+		return "", -1, err
+	}
+	path, _ := input["path"].(string)
+	shell, args := ShellCommand(hook.Command)
+	cmd := exec.CommandContext(ctx, shell, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Env = append(os.Environ(), "PROMPTCLI_TOOL="+toolName, "PROMPTCLI_PATH="+path)
+	out, code, err := runCaptured(ctx, cmd)
+	return strings.TrimSpace(out), code, err
+}
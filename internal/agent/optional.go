@@ -0,0 +1,330 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// defaultOptionalOutput caps the output of optional tools when the call sets
+// no max_bytes.
+const defaultOptionalOutput = 16 * 1024
+
+// optionalTool is a tool that is only offered to the model once the user
+// enables it in the config.
+type optionalTool struct {
+	// prompt documents the tool in the system prompt, in the format of the
+	// tool list in Prompt.MD. promptFunc is used instead when the text
+	// depends on the configuration.
+	prompt     string
+	promptFunc func(a *Agent) string
+	run        func(a *Agent, ctx context.Context, input map[string]interface{}) string
+	// mutates reports whether a call changes something outside the
+	// workspace or goes to the network and needs the user's permission.
+	// Nil means it does neither.
+	mutates func(input map[string]interface{}) bool
+	// scope is the YOLO scope that auto-approves the tool, see
+	// PermissionScope.
+	scope string
+}
+
+// optionalTools lists the opt-in tools by name.
+var optionalTools = map[string]optionalTool{
+	"kubectl": {
+		prompt: `- kubectl
+  - purpose: inspect a Kubernetes cluster (read-only)
+  - input: {"cmd":"get | describe | logs","args":["string",...],"namespace":"string|null","context":"string|null","max_bytes":integer|null}
+  - notes: Only get, describe and logs are allowed; watching and following are not. logs returns the last 200 lines unless args set --tail.`,
+		run:   (*Agent).HandleKubectl,
+		scope: "shell",
+	},
+	"docker": {
+		prompt: `- docker
+  - purpose: inspect local Docker containers (read-only)
+  - input: {"cmd":"ps | logs | inspect","args":["string",...],"max_bytes":integer|null}
+  - notes: Only ps, logs and inspect are allowed; following logs is not. logs returns the last 200 lines unless args set --tail.`,
+		run:   (*Agent).HandleDocker,
+		scope: "shell",
+	},
+	"github": {
+		prompt:  fmt.Sprintf(forgeToolPrompt, "github", "GitHub", "pull requests", "pull request"),
+		run:     (*Agent).HandleGitHub,
+		mutates: forgeMutates,
+		scope:   "git",
+	},
+	"gitlab": {
+		prompt:  fmt.Sprintf(forgeToolPrompt, "gitlab", "GitLab", "merge requests (pr)", "merge request"),
+		run:     (*Agent).HandleGitLab,
+		mutates: forgeMutates,
+		scope:   "git",
+	},
+	"shell": {
+		promptFunc: (*Agent).shellPrompt,
+		run:        (*Agent).HandleShell,
+		mutates:    func(map[string]interface{}) bool { return true },
+		scope:      "shell",
+	},
+	"ticket": {
+		promptFunc: (*Agent).ticketPrompt,
+		run:        (*Agent).HandleTicket,
+		mutates:    func(map[string]interface{}) bool { return true },
+		scope:      "web",
+	},
+}
+
+// OptionalToolNames returns the names of all opt-in tools.
+func OptionalToolNames() []string {
+	names := make([]string, 0, len(optionalTools))
+	for name := range optionalTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnableTools turns on the named opt-in tools.
+func (a *Agent) EnableTools(names []string) error {
+	a.enabled = make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := optionalTools[name]; !ok {
+			return fmt.Errorf("unknown optional tool %q (available: %s)", name, strings.Join(OptionalToolNames(), ", "))
+		}
+		a.enabled[name] = true
+	}
+	return nil
+}
+
+// ToolsSummary documents the enabled opt-in tools for the system prompt.
+func (a *Agent) ToolsSummary() string {
+	if len(a.enabled) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("## Additional tools\nThese tools are also available and are called like the ones above.\n\n")
+	for _, name := range OptionalToolNames() {
+		if !a.enabled[name] {
+			continue
+		}
+		tool := optionalTools[name]
+		prompt := tool.prompt
+		if tool.promptFunc != nil {
+			prompt = tool.promptFunc(a)
+		}
+		builder.WriteString(prompt + "\n")
+	}
+	return builder.String()
+}
+
+// NeedsPermission reports whether a tool call changes files or external
+// state or goes to the network, so the user has to approve it unless YOLO
+// mode is on. File writes inside the scratch directory are exempt.
+func (a *Agent) NeedsPermission(toolName string, input map[string]interface{}) bool {
+	switch toolName {
+	case "write_file", "append_file", "delete_file", "restore_file", "download_file":
+		path, _ := input["path"].(string)
+		return !a.InScratch(path) && !(toolName == "write_file" && isStagedChunk(input))
+	case "extract_archive":
+		dest, _ := input["dest"].(string)
+		if dest == "" {
+			path, _ := input["path"].(string)
+			dest = archiveStem(path)
+		}
+		return !a.InScratch(dest)
+	case "apply_changeset":
+		for _, path := range changesetPaths(input) {
+			if !a.InScratch(path) {
+				return true
+			}
+		}
+		return false
+	case "git":
+		return gitMutates(input)
+	case "web_search", "visit_url", "read_feed":
+		return true
+	}
+	if tool, ok := optionalTools[toolName]; ok && tool.mutates != nil {
+		return tool.mutates(input)
+	}
+	return false
+}
+
+// gitReadOnly lists the git commands that only read the repository.
+var gitReadOnly = map[string]bool{
+	"status": true, "log": true, "diff": true, "show": true, "blame": true, "grep": true,
+	"ls-files": true, "ls-tree": true, "rev-parse": true, "rev-list": true, "describe": true,
+	"shortlog": true, "cat-file": true, "merge-base": true, "name-rev": true, "whatchanged": true,
+}
+
+// gitMutates reports whether a git call can change the repository, the
+// working tree or a remote. Commands with both uses, like branch and stash,
+// only read when they list.
+func gitMutates(input map[string]interface{}) bool {
+	cmd, _ := input["cmd"].(string)
+	args := toolArgs(input)
+	for _, arg := range args {
+		// Read-only commands can still write their output to a file or
+		// run a program.
+		if strings.HasPrefix(arg, "--output") || strings.HasPrefix(arg, "-O") || strings.HasPrefix(arg, "--open-files-in-pager") {
+			return true
+		}
+	}
+	if gitReadOnly[cmd] {
+		return false
+	}
+	listing := func(flags ...string) bool {
+		for _, arg := range args {
+			if !slices.Contains(flags, arg) {
+				return false
+			}
+		}
+		return true
+	}
+	switch cmd {
+	case "branch":
+		return !listing("-a", "--all", "-r", "--remotes", "-v", "-vv", "--verbose", "-l", "--list", "--show-current", "--merged", "--no-merged")
+	case "tag":
+		return !listing("-l", "--list", "-n")
+	case "remote":
+		return !listing("-v", "--verbose") && !(len(args) > 0 && (args[0] == "show" || args[0] == "get-url"))
+	case "stash":
+		return len(args) == 0 || (args[0] != "list" && args[0] != "show")
+	case "reflog":
+		return len(args) > 0 && args[0] != "show"
+	case "config":
+		return !(len(args) > 0 && slices.Contains([]string{"--get", "--get-all", "--get-regexp", "-l", "--list"}, args[0]))
+	}
+	return true
+}
+
+// PermissionScopes are the groups of tools YOLO mode can auto-approve
+// separately.
+var PermissionScopes = []string{"files", "git", "web", "shell"}
+
+// PermissionScope returns the scope a tool belongs to: "files" for the file
+// tools, "git" for git and the forge tools, "web" for network tools and
+// "shell" for command-line tools.
+func PermissionScope(toolName string) string {
+	switch toolName {
+	case "list_files", "read_file", "read_all_files", "write_file", "append_file", "delete_file", "restore_file", "apply_changeset", "list_archive", "extract_archive", "summarize_data":
+		return "files"
+	case "git":
+		return "git"
+	case "web_search", "visit_url", "read_feed", "download_file":
+		return "web"
+	}
+	if tool, ok := optionalTools[toolName]; ok {
+		return tool.scope
+	}
+	return ""
+}
+
+// runOptional dispatches a call to an enabled opt-in tool.
+func (a *Agent) runOptional(ctx context.Context, toolName string, input map[string]interface{}) (string, bool) {
+	tool, ok := optionalTools[toolName]
+	if !ok || !a.enabled[toolName] {
+		return "", false
+	}
+	return tool.run(a, ctx, input), true
+}
+
+// HandleKubectl runs a read-only kubectl query.
+func (a *Agent) HandleKubectl(ctx context.Context, input map[string]interface{}) string {
+	cmd, _ := input["cmd"].(string)
+	switch cmd {
+	case "get", "describe", "logs":
+	default:
+		return fmt.Sprintf("Error: kubectl %q is not allowed. Use get, describe or logs.", cmd)
+	}
+	args := toolArgs(input)
+	flag := forbiddenFlag(args, "-w", "--watch", "--watch-only")
+	if cmd == "logs" && flag == "" {
+		flag = forbiddenFlag(args, "-f", "--follow")
+	}
+	if flag != "" {
+		return fmt.Sprintf("Error: kubectl %s is not allowed because it never returns.", flag)
+	}
+
+	full := []string{cmd}
+	if namespace, _ := input["namespace"].(string); namespace != "" {
+		full = append(full, "--namespace", namespace)
+	}
+	if kubeContext, _ := input["context"].(string); kubeContext != "" {
+		full = append(full, "--context", kubeContext)
+	}
+	if cmd == "logs" && !hasFlag(args, "--tail") {
+		full = append(full, "--tail=200")
+	}
+	return runInspection(ctx, "kubectl", append(full, args...), input)
+}
+
+// HandleDocker runs a read-only docker query.
+func (a *Agent) HandleDocker(ctx context.Context, input map[string]interface{}) string {
+	cmd, _ := input["cmd"].(string)
+	switch cmd {
+	case "ps", "logs", "inspect":
+	default:
+		return fmt.Sprintf("Error: docker %q is not allowed. Use ps, logs or inspect.", cmd)
+	}
+	args := toolArgs(input)
+	if flag := forbiddenFlag(args, "-f", "--follow"); cmd == "logs" && flag != "" {
+		return fmt.Sprintf("Error: docker logs %s is not allowed because it never returns.", flag)
+	}
+
+	full := []string{cmd}
+	if cmd == "logs" && !hasFlag(args, "--tail", "-n") {
+		full = append(full, "--tail", "200")
+	}
+	return runInspection(ctx, "docker", append(full, args...), input)
+}
+
+// runInspection runs a CLI and returns its combined output, truncated to
+// max_bytes or defaultOptionalOutput.
+func runInspection(ctx context.Context, name string, args []string, input map[string]interface{}) string {
+	output, exitCode, err := runCaptured(ctx, exec.CommandContext(ctx, name, args...))
+	if err != nil {
+		return fmt.Sprintf("Error running %s: %v\n%s", name, err, output)
+	}
+	output = truncateOutput(output, input)
+	if exitCode != 0 {
+		return fmt.Sprintf("Error: %s exited with code %d\n%s", name, exitCode, output)
+	}
+	return output
+}
+
+// toolArgs reads the "args" input, given either as an array of strings or as
+// a single space-separated string.
+func toolArgs(input map[string]interface{}) []string {
+	var args []string
+	if argsVal, ok := input["args"].([]interface{}); ok {
+		for _, arg := range argsVal {
+			if argStr, ok := arg.(string); ok {
+				args = append(args, argStr)
+			}
+		}
+	} else if argsVal, ok := input["args"].(string); ok {
+		args = strings.Fields(argsVal)
+	}
+	return args
+}
+
+// forbiddenFlag returns the first of flags present in args, also matching
+// the "--flag=value" form.
+func forbiddenFlag(args []string, flags ...string) string {
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		for _, flag := range flags {
+			if name == flag {
+				return flag
+			}
+		}
+	}
+	return ""
+}
+
+// hasFlag reports whether any of flags is present in args.
+func hasFlag(args []string, flags ...string) bool {
+	return forbiddenFlag(args, flags...) != ""
+}
@@ -0,0 +1,211 @@
+package agent
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Block is a function, method or type in a source file, with its 1-based,
+// inclusive line range. Doc comments directly above it are included.
+type Block struct {
+	Kind  string // e.g. "function", "method", "class" or "type".
+	Name  string
+	Start int
+	End   int
+}
+
+var (
+	// definitionRegex matches the first line of a named definition in the
+	// brace and indentation languages.
+	definitionRegex = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:static\s+)?(function\*?|fn|fun|func|def|class|interface|struct|enum|impl|trait|module)\s+([A-Za-z_$][\w$:.]*)`)
+	// methodRegex matches the signature of a method in Java-like languages
+	// and of a method in a JavaScript class body.
+	methodRegex = regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|override|virtual|abstract|async|synchronized)\s+)*(?:[\w<>\[\],.?]+\s+)?([A-Za-z_$][\w$]*)\s*\([^;]*\)\s*(?:[:\w<>\[\],.?\s]*)?(?:throws [\w.,\s]+)?\{?\s*$`)
+	// notMethods are keywords methodRegex would take for a method name.
+	notMethods = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true, "else": true, "do": true, "try": true, "new": true, "throw": true}
+)
+
+// indentedLanguages delimit blocks by indentation instead of braces.
+var indentedLanguages = map[string]bool{".py": true, ".pyw": true}
+
+// EnclosingBlock finds the innermost function or type around line in a
+// source file: with the Go parser for Go, by matching definitions and
+// braces or indentation for other languages.
+func EnclosingBlock(path string, content []byte, line int) (Block, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".go" {
+		return enclosingGoBlock(content, line)
+	}
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return Block{}, false
+	}
+	indented := indentedLanguages[ext]
+	for start := line - 1; start >= 0; start-- {
+		kind, name := definitionAt(lines[start], !indented)
+		if name == "" {
+			continue
+		}
+		end, ok := start, true
+		if indented {
+			end = indentedEnd(lines, start)
+		} else {
+			end, ok = braceEnd(lines, start)
+		}
+		if ok && end+1 >= line {
+			return Block{Kind: kind, Name: name, Start: docStart(lines, start) + 1, End: end + 1}, true
+		}
+	}
+	return Block{}, false
+}
+
+// enclosingGoBlock finds the top-level declaration around line.
+func enclosingGoBlock(content []byte, line int) (Block, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.ParseComments)
+	if file == nil {
+		return Block{}, false
+	}
+	_ = err // A partial AST is good enough to find the declaration.
+	for _, decl := range file.Decls {
+		start, end := fset.Position(decl.Pos()).Line, fset.Position(decl.End()).Line
+		if line < start || line > end {
+			continue
+		}
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			block := Block{Kind: "function", Name: decl.Name.Name, Start: start, End: end}
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				block.Kind = "method"
+				block.Name = receiverName(decl.Recv.List[0].Type) + "." + decl.Name.Name
+			}
+			if decl.Doc != nil {
+				block.Start = fset.Position(decl.Doc.Pos()).Line
+			}
+			return block, true
+		case *ast.GenDecl:
+			block := Block{Kind: decl.Tok.String(), Start: start, End: end}
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					block.Name = spec.Name.Name
+				case *ast.ValueSpec:
+					block.Name = spec.Names[0].Name
+				}
+				if block.Name != "" {
+					break
+				}
+			}
+			if decl.Tok == token.IMPORT {
+				block.Name = "imports"
+			}
+			if decl.Doc != nil {
+				block.Start = fset.Position(decl.Doc.Pos()).Line
+			}
+			return block, true
+		}
+	}
+	return Block{}, false
+}
+
+// receiverName returns the type name of a method receiver such as "*T" or
+// "T[K]".
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+// definitionAt returns the kind and name of the definition starting on
+// text, or an empty name if there is none. Method signatures are only
+// looked for in brace languages.
+func definitionAt(text string, methods bool) (kind, name string) {
+	if match := definitionRegex.FindStringSubmatch(text); match != nil {
+		kind = strings.TrimSuffix(match[1], "*")
+		switch kind {
+		case "function", "fn", "fun", "func", "def":
+			kind = "function"
+		}
+		return kind, match[2]
+	}
+	if !methods {
+		return "", ""
+	}
+	if match := methodRegex.FindStringSubmatch(text); match != nil && !notMethods[match[1]] {
+		return "method", match[1]
+	}
+	return "", ""
+}
+
+// braceEnd returns the index of the line that closes the first brace
+// opened at or after start, or ok false if no brace opens within the next
+// few lines, e.g. for a call mistaken for a signature. Braces in strings
+// and comments are counted too, which is good enough for finding a block.
+func braceEnd(lines []string, start int) (end int, ok bool) {
+	depth, opened := 0, false
+	for i := start; i < len(lines); i++ {
+		for _, r := range lines[i] {
+			switch r {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return i, true
+		}
+		if !opened && i-start >= 3 {
+			return start, false
+		}
+	}
+	return len(lines) - 1, opened
+}
+
+// indentedEnd returns the index of the last line of the indented block
+// that starts at start.
+func indentedEnd(lines []string, start int) int {
+	indent := indentOf(lines[start])
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if indentOf(lines[i]) <= indent && !strings.HasPrefix(strings.TrimSpace(lines[i]), ")") {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+func indentOf(text string) int {
+	return len(text) - len(strings.TrimLeft(text, " \t"))
+}
+
+// docStart moves start up over the comment and decorator lines directly
+// above a definition.
+func docStart(lines []string, start int) int {
+	for start > 0 {
+		prev := strings.TrimSpace(lines[start-1])
+		if prev == "" || !(strings.HasPrefix(prev, "//") || strings.HasPrefix(prev, "#") || strings.HasPrefix(prev, "@") ||
+			strings.HasPrefix(prev, "/*") || strings.HasPrefix(prev, "*") || strings.HasPrefix(prev, "///")) {
+			break
+		}
+		start--
+	}
+	return start
+}
@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// projectKind is a type of project recognized by its manifest file.
+type projectKind struct {
+	Name     string
+	Manifest string
+	Glob     string // Source files, the default glob of read_all_files.
+	// commands returns the build, test and run commands for the project,
+	// "" where there is none. exists reports whether a file exists next to
+	// the manifest, e.g. a lock file.
+	commands func(manifest []byte, exists func(name string) bool) (build, test, run string)
+	// name reads the project name from the manifest.
+	name func(manifest []byte) string
+}
+
+// projectKinds are the project types DetectProjects looks for.
+var projectKinds = []projectKind{
+	{
+		Name:     "Go",
+		Manifest: "go.mod",
+		Glob:     "**/*.go",
+		commands: func([]byte, func(string) bool) (string, string, string) {
+			return "go build ./...", "go test ./...", "go run ."
+		},
+		name: manifestName(`(?m)^module\s+(\S+)`),
+	},
+	{
+		Name:     "Node.js",
+		Manifest: "package.json",
+		Glob:     "**/*.{js,jsx,ts,tsx,mjs,cjs}",
+		commands: nodeCommands,
+		name: func(manifest []byte) string {
+			var pkg struct {
+				Name string `json:"name"`
+			}
+			json.Unmarshal(manifest, &pkg)
+			return pkg.Name
+		},
+	},
+	{
+		Name:     "Python",
+		Manifest: "pyproject.toml",
+		Glob:     "**/*.py",
+		commands: pythonCommands,
+		name:     manifestName(`(?m)^name\s*=\s*"([^"]+)"`),
+	},
+	{
+		Name:     "Rust",
+		Manifest: "Cargo.toml",
+		Glob:     "**/*.rs",
+		commands: func([]byte, func(string) bool) (string, string, string) {
+			return "cargo build", "cargo test", "cargo run"
+		},
+		name: manifestName(`(?m)^name\s*=\s*"([^"]+)"`),
+	},
+}
+
+// manifestName reads the project name with the first group of a pattern.
+func manifestName(pattern string) func([]byte) string {
+	re := regexp.MustCompile(pattern)
+	return func(manifest []byte) string {
+		if match := re.FindSubmatch(manifest); match != nil {
+			return string(match[1])
+		}
+		return ""
+	}
+}
+
+// nodeCommands uses the package manager of the lock file and only suggests
+// the scripts package.json defines.
+func nodeCommands(manifest []byte, exists func(string) bool) (build, test, run string) {
+	manager := "npm"
+	for _, lock := range []struct{ file, manager string }{
+		{"pnpm-lock.yaml", "pnpm"}, {"yarn.lock", "yarn"}, {"bun.lockb", "bun"}, {"bun.lock", "bun"},
+	} {
+		if exists(lock.file) {
+			manager = lock.manager
+			break
+		}
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	json.Unmarshal(manifest, &pkg)
+	script := func(names ...string) string {
+		for _, name := range names {
+			if _, ok := pkg.Scripts[name]; ok {
+				return manager + " run " + name
+			}
+		}
+		return ""
+	}
+	return script("build"), script("test"), script("dev", "start")
+}
+
+// pythonCommands runs pytest through the tool that manages the project.
+func pythonCommands(manifest []byte, exists func(string) bool) (build, test, run string) {
+	switch {
+	case exists("uv.lock"):
+		return "uv build", "uv run pytest", ""
+	case exists("poetry.lock"):
+		return "poetry build", "poetry run pytest", ""
+	}
+	python := "python3"
+	if runtime.GOOS == "windows" {
+		python = "python"
+	}
+	return "", python + " -m pytest", ""
+}
+
+// Project is a project found in the workspace.
+type Project struct {
+	Kind             string // e.g. "Go".
+	Name             string // From the manifest, "" if it has none.
+	Dir              string // Workspace path of the project, "" for the current directory.
+	Glob             string
+	Build, Test, Run string
+}
+
+// DetectProjects looks for the manifests of the known project types in the
+// workspace directory, or in each root, and remembers what it finds for
+// the project summary, the read_all_files default and /run.
+func (a *Agent) DetectProjects() {
+	a.projects = nil
+	dirs := []string{""}
+	if len(a.roots) > 0 && a.ssh == nil {
+		dirs = dirs[:0]
+		for _, name := range a.RootNames() {
+			dirs = append(dirs, name+":")
+		}
+	}
+	for _, dir := range dirs {
+		exists := func(name string) bool {
+			path, err := a.ResolvePath(dir + name)
+			if err != nil {
+				return false
+			}
+			_, err = a.files().Stat(path)
+			return err == nil
+		}
+		for _, kind := range projectKinds {
+			path, err := a.ResolvePath(dir + kind.Manifest)
+			if err != nil {
+				continue
+			}
+			manifest, err := a.files().ReadFile(path)
+			if err != nil {
+				continue
+			}
+			project := Project{Kind: kind.Name, Name: kind.name(manifest), Dir: dir, Glob: kind.Glob}
+			project.Build, project.Test, project.Run = kind.commands(manifest, exists)
+			a.projects = append(a.projects, project)
+		}
+	}
+}
+
+// Projects returns the projects DetectProjects found.
+func (a *Agent) Projects() []Project {
+	return a.projects
+}
+
+// defaultGlob is the glob read_all_files uses when the call has none: the
+// source files of the detected projects, or "" if none was found.
+func (a *Agent) defaultGlob() string {
+	var globs []string
+	for _, project := range a.projects {
+		if !slices.Contains(globs, project.Glob) {
+			globs = append(globs, project.Glob)
+		}
+	}
+	switch len(globs) {
+	case 0:
+		return ""
+	case 1:
+		return globs[0]
+	}
+	return "{" + strings.Join(globs, ",") + "}"
+}
+
+// ProjectSummary describes the detected projects for the system prompt,
+// with the commands that build, test and run them.
+func (a *Agent) ProjectSummary() string {
+	if len(a.projects) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("## Project\n")
+	for _, project := range a.projects {
+		line := "- " + project.Kind + " project"
+		if project.Name != "" {
+			line += " " + project.Name
+		}
+		if project.Dir != "" {
+			line += " in " + project.Dir
+		}
+		var commands []string
+		for _, command := range []struct{ label, command string }{
+			{"build", project.Build}, {"test", project.Test}, {"run", project.Run},
+		} {
+			if command.command != "" {
+				commands = append(commands, fmt.Sprintf("%s with `%s`", command.label, command.command))
+			}
+		}
+		if len(commands) > 0 {
+			line += ": " + strings.Join(commands, ", ")
+		}
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString(fmt.Sprintf("read_all_files reads %q when the call has no glob.\n", a.defaultGlob()))
+	return builder.String()
+}
@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// defaultReadAllBytes caps the output of read_all_files when neither the
+// configuration nor the call sets a limit.
+const defaultReadAllBytes = 256 << 10
+
+// maxSkippedListed is how many skipped paths of each kind are named in the
+// report; the rest are only counted.
+const maxSkippedListed = 10
+
+// SetReadAllLimit sets the default byte cap of read_all_files. Zero keeps
+// the built-in default.
+func (a *Agent) SetReadAllLimit(maxBytes int) {
+	a.readAllLimit = maxBytes
+}
+
+// skippedFiles collects what read_all_files left out, by reason.
+type skippedFiles struct {
+	reasons []string // Reasons in the order first seen.
+	paths   map[string][]string
+}
+
+func (s *skippedFiles) add(reason, path string) {
+	if s.paths == nil {
+		s.paths = make(map[string][]string)
+	}
+	if _, ok := s.paths[reason]; !ok {
+		s.reasons = append(s.reasons, reason)
+	}
+	s.paths[reason] = append(s.paths[reason], path)
+}
+
+// report describes the skipped files, or returns "" if there were none.
+func (s *skippedFiles) report() string {
+	if len(s.reasons) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("---\nSkipped:\n")
+	for _, reason := range s.reasons {
+		paths := s.paths[reason]
+		listed := paths[:min(len(paths), maxSkippedListed)]
+		builder.WriteString(fmt.Sprintf("- %d %s: %s", len(paths), reason, strings.Join(listed, ", ")))
+		if len(paths) > len(listed) {
+			builder.WriteString(fmt.Sprintf(", … (%d more)", len(paths)-len(listed)))
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// HandleReadAllFiles concatenates the files matching a glob. Gitignored and
+// binary files are skipped unless "include_ignored" is set, and once the
+// byte cap is reached the remaining files are only listed with their size
+// so the model can read the ones it needs with read_file.
+func (a *Agent) HandleReadAllFiles(ctx context.Context, input map[string]interface{}) string {
+	glob, _ := input["glob"].(string)
+	if glob == "" {
+		glob = a.defaultGlob()
+	}
+	if glob == "" {
+		return "Error: 'glob' pattern not specified or not a string for read_all_files."
+	}
+
+	dir, _ := input["path"].(string)
+	if dir == "" {
+		dir = "."
+	}
+	basePath, err := a.ResolvePath(dir)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	filePaths, err := a.files().Glob(basePath, glob)
+	if err != nil {
+		return fmt.Sprintf("Error matching glob pattern '%s': %v", glob, err)
+	}
+	if len(filePaths) == 0 {
+		return fmt.Sprintf("No files found matching glob pattern '%s' in directory '%s'", glob, dir)
+	}
+
+	maxBytes := a.readAllLimit
+	if maxBytes <= 0 {
+		maxBytes = defaultReadAllBytes
+	}
+	if n, ok := input["max_bytes"].(float64); ok && n > 0 {
+		maxBytes = int(n)
+	}
+	includeIgnored, _ := input["include_ignored"].(bool)
+	var rules ignoreRules
+	if !includeIgnored {
+		rules = a.loadIgnoreRules(basePath)
+	}
+
+	// Glob also returns directories; they are the parents of other matches.
+	dirs := make(map[string]bool)
+	for _, filePath := range filePaths {
+		for parent := path.Dir(filePath); parent != "."; parent = path.Dir(parent) {
+			dirs[parent] = true
+		}
+	}
+
+	var builder strings.Builder
+	var skipped skippedFiles
+	seenIgnored := make(map[string]bool)
+	var summaries []string
+	for _, filePath := range filePaths {
+		if dirs[filePath] {
+			continue
+		}
+		if !includeIgnored {
+			if excluded, ok := rules.ignored(filePath); ok {
+				if !seenIgnored[excluded] {
+					seenIgnored[excluded] = true
+					skipped.add("gitignored", excluded)
+				}
+				continue
+			}
+		}
+		if ctx.Err() != nil {
+			skipped.add("not read before the timeout", filePath)
+			continue
+		}
+
+		// doublestar.Glob returns paths relative to the fsys root, so we need to join them with the base path
+		// to read the actual file from the OS.
+		fullPath := a.joinPath(basePath, filePath)
+		content, err := a.files().ReadFile(fullPath)
+		if err != nil {
+			a.logger.Log(fmt.Sprintf("Error reading file '%s', skipping: %v", fullPath, err))
+			skipped.add("unreadable", filePath)
+			continue
+		}
+		if !includeIgnored && documentKind(content, "", filePath) == "" && isBinary(content) {
+			skipped.add("binary", filePath)
+			continue
+		}
+
+		text, err := readableText(ctx, content, filePath)
+		if err != nil {
+			a.logger.Log(fmt.Sprintf("Error extracting text from '%s', skipping: %v", fullPath, err))
+			skipped.add("unreadable", filePath)
+			continue
+		}
+
+		entry := fmt.Sprintf("---\nFile: %s\n---\n%s\n\n", filePath, text) // Use relative path in header for clarity
+		if len(summaries) > 0 || builder.Len()+len(entry) > maxBytes {
+			summaries = append(summaries, fmt.Sprintf("- %s (%d bytes, %d lines)", filePath, len(text), strings.Count(text, "\n")+1))
+			continue
+		}
+		a.recordVersion(fullPath, content)
+		builder.WriteString(entry)
+	}
+
+	if len(summaries) > 0 {
+		builder.WriteString(fmt.Sprintf("---\nByte limit of %d reached; these %d files were not included, read them with read_file:\n", maxBytes, len(summaries)))
+		builder.WriteString(strings.Join(summaries, "\n") + "\n")
+	}
+	builder.WriteString(skipped.report())
+	if builder.Len() == 0 {
+		return fmt.Sprintf("No readable files found matching glob pattern '%s' in directory '%s'", glob, dir)
+	}
+	return builder.String()
+}
+
+// isBinary reports whether content looks like binary data: a NUL byte in
+// the first few kilobytes, as git and most tools check.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
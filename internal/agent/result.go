@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"prompt-cli/internal/types"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// errorClasses map tool error messages to a types.Error* class. They are
+// tried in order; messages matching none are ErrorFailed.
+var errorClasses = []struct {
+	class   string
+	pattern *regexp.Regexp
+}{
+	{types.ErrorTimeout, regexp.MustCompile(`(?i)timed out|deadline exceeded`)},
+	{types.ErrorPermission, regexp.MustCompile(`(?i)outside the workspace|escapes (the )?(workspace|scratch)|permission denied|non-interactive run|denied|not allowed for the '`)},
+	{types.ErrorConflict, regexp.MustCompile(`(?i)after you last read it|already exists|exists again`)},
+	{types.ErrorInvalidInput, regexp.MustCompile(`(?i)not specified|is required|are required|not a string|is not allowed|not allowed because|^unknown command|unknown \S+ (action|source)|pattern`)},
+	{types.ErrorNotFound, regexp.MustCompile(`(?i)no such file|not found|does not exist|status code: 404|nothing to restore|cannot find`)},
+}
+
+// truncatedRegex matches the notes the tools append when they cut output.
+var truncatedRegex = regexp.MustCompile(`(?i)output truncated|\.\.\. truncated|byte limit of \d+ reached`)
+
+// ExecuteTool runs a tool call like ExecuteCommand and returns the outcome
+// as a structured result.
+func (a *Agent) ExecuteTool(toolName string, input map[string]interface{}) types.ToolResult {
+	start := time.Now()
+	output := a.ExecuteCommand(toolName, input)
+	result := ClassifyResult(toolName, input, output)
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
+
+// ClassifyResult turns the text a tool handler returned into a ToolResult.
+// Failures are recognised by the "Error" prefix the handlers use and a few
+// other known messages. The first line of an error is the message; what
+// follows is the command's output, with a "Stderr:" section split off.
+func ClassifyResult(toolName string, input map[string]interface{}, output string) types.ToolResult {
+	result := types.ToolResult{Tool: toolName, Status: types.ToolOK}
+	if cached, ok := strings.CutPrefix(output, CachedMarker); ok {
+		result.Cached = true
+		output = cached
+	}
+	if maxBytes, _ := input["max_bytes"].(float64); maxBytes > 0 && len(output) >= int(maxBytes) || truncatedRegex.MatchString(output) {
+		result.Truncated = true
+	}
+	if !failed(output) {
+		result.Output = output
+		result.Sources = webSources(toolName, input, output)
+		return result
+	}
+
+	result.Status = types.ToolError
+	message, rest, _ := strings.Cut(output, "\n")
+	if before, stderr, ok := strings.Cut(rest, "Stderr: "); ok {
+		rest, result.Stderr = before, stderr
+	}
+	result.Error = message
+	result.Output = strings.TrimRight(rest, "\n")
+	result.ErrorClass = types.ErrorFailed
+	for _, class := range errorClasses {
+		if class.pattern.MatchString(message) {
+			result.ErrorClass = class.class
+			break
+		}
+	}
+	return result
+}
+
+// failed reports whether a tool's output describes a failure.
+func failed(output string) bool {
+	switch {
+	case strings.HasPrefix(output, "Error"),
+		strings.HasPrefix(output, "Unknown command:"),
+		strings.HasPrefix(output, "Request to ") && strings.Contains(output, "failed with status code"),
+		strings.HasPrefix(output, "File '") && strings.HasSuffix(output, "' already exists."):
+		return true
+	}
+	return false
+}
+
+// ErrorResult builds the result of a call that was refused before it ran.
+func ErrorResult(toolName, class, message string) types.ToolResult {
+	return types.ToolResult{Tool: toolName, Status: types.ToolError, ErrorClass: class, Error: message}
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// FileState returns the content of a file before a tool changes it, or
+// exists false if there is no such file yet.
+func (a *Agent) FileState(path string) (content []byte, exists bool, err error) {
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	content, err = a.files().ReadFile(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	return content, err == nil, err
+}
+
+// RevertFile undoes the agent's changes to a file: it writes back the
+// content the file had before, or, if the agent created it, moves it to
+// the session trash so /undo can bring it back.
+func (a *Agent) RevertFile(path string, original []byte, existed bool) error {
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return err
+	}
+	defer a.cache.invalidatePrefix("list_files|")
+	if !existed {
+		if _, err := a.files().Stat(fullPath); errors.Is(err, fs.ErrNotExist) {
+			return nil // Already gone.
+		}
+		a.versions.forget(fullPath)
+		return a.moveToTrash(path, fullPath)
+	}
+	if err := a.files().WriteFile(fullPath, original); err != nil {
+		return err
+	}
+	a.recordVersion(fullPath, original)
+	return nil
+}
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scratchRoot is the name scratch paths are addressed by, as in
+// "scratch:script.py".
+const scratchRoot = "scratch"
+
+// UseScratchDir provisions the scratch directory of a session below the
+// system temp directory and makes it the agent's scratch space. The
+// previous session's directory is removed if it was left empty. Scratch
+// space is local, so it is not offered with an SSH workspace.
+func (a *Agent) UseScratchDir(sessionID string) error {
+	if a.ssh != nil {
+		return nil
+	}
+	dir := filepath.Join(os.TempDir(), "promptcli", sessionID)
+	if dir == a.scratchDir {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating scratch directory: %w", err)
+	}
+	a.RemoveScratchDir()
+	a.scratchDir = dir
+	return nil
+}
+
+// ScratchDir returns the current scratch directory, or "" if there is none.
+func (a *Agent) ScratchDir() string {
+	return a.scratchDir
+}
+
+// RemoveScratchDir deletes the scratch directory if nothing was written to
+// it. Directories with files are kept so their artifacts can be inspected.
+func (a *Agent) RemoveScratchDir() {
+	if a.scratchDir != "" {
+		os.Remove(a.scratchDir)
+	}
+}
+
+// resolveScratchPath resolves "scratch:rel" and paths that already lie in
+// the scratch directory. ok is false for any other path.
+func (a *Agent) resolveScratchPath(path string) (resolved string, ok bool, err error) {
+	if a.scratchDir == "" {
+		return "", false, nil
+	}
+	if rel, found := strings.CutPrefix(path, scratchRoot+":"); found {
+		resolved = filepath.Join(a.scratchDir, filepath.FromSlash(rel))
+		if !isWithin(a.scratchDir, resolved) {
+			return "", true, fmt.Errorf("path '%s' escapes the scratch directory", path)
+		}
+		return resolved, true, nil
+	}
+	if abs, err := filepath.Abs(path); err == nil && isWithin(a.scratchDir, abs) {
+		return abs, true, nil
+	}
+	return "", false, nil
+}
+
+// InScratch reports whether a tool path points into the scratch directory.
+// Writes there do not need the user's approval.
+func (a *Agent) InScratch(path string) bool {
+	_, ok, err := a.resolveScratchPath(path)
+	return ok && err == nil
+}
+
+// ScratchSummary tells the model about its scratch directory for the system
+// prompt.
+func (a *Agent) ScratchSummary() string {
+	if a.scratchDir == "" {
+		return ""
+	}
+	return fmt.Sprintf("## Scratch directory\n"+
+		"Put intermediate files such as generated scripts, downloads and test data in %s instead of the workspace; "+
+		"address them as \"%s:relative/path\". Writes there need no approval. The directory belongs to this session only.\n",
+		a.scratchDir, scratchRoot)
+}
@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// maxShellOutput caps the captured output of a shell command.
+const maxShellOutput = 64 * 1024
+
+// Shell is a command interpreter that runs command lines: a POSIX shell,
+// PowerShell or cmd.
+type Shell struct {
+	Name  string // e.g. "bash", "pwsh" or "cmd".
+	flags []string
+}
+
+// shellFlags are the arguments that make each known shell run one command
+// line and exit.
+var shellFlags = map[string][]string{
+	"sh":         {"-c"},
+	"bash":       {"-c"},
+	"zsh":        {"-c"},
+	"dash":       {"-c"},
+	"ksh":        {"-c"},
+	"pwsh":       {"-NoProfile", "-NonInteractive", "-Command"},
+	"powershell": {"-NoProfile", "-NonInteractive", "-Command"},
+	"cmd":        {"/C"},
+}
+
+// ShellNames returns the shells that can be configured.
+func ShellNames() []string {
+	names := make([]string, 0, len(shellFlags))
+	for name := range shellFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// currentShell runs the command lines of /run, the hooks, the speech
+// commands and the shell tool. It is process wide like ShellCommand.
+var currentShell = DetectShell()
+
+// SetShell picks the shell by name; "" or "auto" detects it.
+func SetShell(name string) error {
+	if name == "" || name == "auto" {
+		currentShell = DetectShell()
+		return nil
+	}
+	flags, ok := shellFlags[name]
+	if !ok {
+		return fmt.Errorf("unknown shell %q (available: auto, %s)", name, strings.Join(ShellNames(), ", "))
+	}
+	currentShell = Shell{Name: name, flags: flags}
+	return nil
+}
+
+// CurrentShell returns the shell command lines run with.
+func CurrentShell() Shell {
+	return currentShell
+}
+
+// DetectShell picks the shell for this platform: PowerShell 7, then Windows
+// PowerShell, then cmd on Windows; elsewhere the user's $SHELL if it is a
+// POSIX shell, then bash, then sh.
+func DetectShell() Shell {
+	candidates := []string{"pwsh", "powershell", "cmd"}
+	if runtime.GOOS != "windows" {
+		candidates = []string{"bash", "sh"}
+		if login := filepath.Base(os.Getenv("SHELL")); login != "." && shellFlags[login] != nil && login != "pwsh" {
+			candidates = append([]string{login}, candidates...)
+		}
+	}
+	for _, name := range candidates {
+		if _, err := exec.LookPath(name); err == nil {
+			return Shell{Name: name, flags: shellFlags[name]}
+		}
+	}
+	last := candidates[len(candidates)-1]
+	return Shell{Name: last, flags: shellFlags[last]}
+}
+
+// Command returns the program and arguments that run a command line.
+func (s Shell) Command(command string) (string, []string) {
+	return s.Name, append(append([]string(nil), s.flags...), command)
+}
+
+// PowerShell reports whether s is Windows PowerShell or PowerShell 7.
+func (s Shell) PowerShell() bool {
+	return s.Name == "pwsh" || s.Name == "powershell"
+}
+
+// Quote quotes a single argument, e.g. a file path, for a command line of
+// the shell.
+func (s Shell) Quote(arg string) string {
+	switch {
+	case s.PowerShell():
+		return "'" + strings.ReplaceAll(arg, "'", "''") + "'"
+	case s.Name == "cmd":
+		// cmd has no escape for quotes inside quotes; paths cannot
+		// contain them anyway.
+		return `"` + arg + `"`
+	}
+	return shellQuote(arg)
+}
+
+// Syntax names the command language of the shell for the model.
+func (s Shell) Syntax() string {
+	switch {
+	case s.PowerShell():
+		return "PowerShell"
+	case s.Name == "cmd":
+		return "cmd.exe batch"
+	}
+	return "POSIX shell"
+}
+
+// ShellCommand returns the shell and arguments used to run a command line
+// on this platform.
+func ShellCommand(command string) (string, []string) {
+	return currentShell.Command(command)
+}
+
+// RunShell runs a command line through the platform shell in dir and returns
+// its combined output and exit code. A non-zero exit is not an error; err is
+// only set when the command could not be run at all or timed out.
+func RunShell(ctx context.Context, command, dir string) (string, int, error) {
+	shell, args := ShellCommand(command)
+	cmd := exec.CommandContext(ctx, shell, args...)
+	cmd.Dir = dir
+	return runCaptured(ctx, cmd)
+}
+
+// runCaptured runs cmd and returns its capped combined output and exit code.
+func runCaptured(ctx context.Context, cmd *exec.Cmd) (string, int, error) {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	output := out.String()
+	if len(output) > maxShellOutput {
+		output = output[:maxShellOutput] + "\n... output truncated ..."
+	}
+
+	if ctx.Err() != nil {
+		return output, -1, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return output, exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return output, -1, err
+	}
+	return output, 0, nil
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Shell returns the shell the shell tool and /run use: sh inside the
+// container or on the SSH host, the configured shell otherwise.
+func (a *Agent) Shell() Shell {
+	if a.docker != nil || a.ssh != nil {
+		return Shell{Name: "sh", flags: shellFlags["sh"]}
+	}
+	return currentShell
+}
+
+// shellPrompt documents the shell tool with the shell it runs in.
+func (a *Agent) shellPrompt() string {
+	shell := a.Shell()
+	return fmt.Sprintf(`- shell
+  - purpose: run a command line with %s and return its output
+  - input: {"command":"string","dir":"string|null","max_bytes":integer|null}
+  - notes: Write the command in %s syntax. dir is a workspace path and defaults to the workspace. Every call needs the user's permission. Commands must not wait for input or run forever.`, shell.Name, shell.Syntax())
+}
+
+// HandleShell runs a command line for the model: in the container if one
+// is configured, on the SSH host for a remote workspace, else locally.
+func (a *Agent) HandleShell(ctx context.Context, input map[string]interface{}) string {
+	command, _ := input["command"].(string)
+	if strings.TrimSpace(command) == "" {
+		return "Error: command is required."
+	}
+	dir, _ := input["dir"].(string)
+
+	if a.ssh != nil {
+		cwd := a.ssh.config.Dir
+		if dir != "" {
+			resolved, err := a.ResolvePath(dir)
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err)
+			}
+			cwd = resolved
+		}
+		output, err := a.ssh.run(ctx, "cd "+shellQuote(cwd)+" && { "+command+"\n} 2>&1", nil)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		return truncateOutput(string(output), input)
+	}
+
+	output, exitCode, err := a.RunShell(ctx, command, dir)
+	if err != nil {
+		return fmt.Sprintf("Error running %s: %v\n%s", a.Shell().Name, err, truncateOutput(output, input))
+	}
+	output = truncateOutput(output, input)
+	if exitCode != 0 {
+		return fmt.Sprintf("Error: exit code %d\n%s", exitCode, output)
+	}
+	return output
+}
@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"prompt-cli/internal/types"
+	"regexp"
+)
+
+// listedLinkRegex matches an entry of the web_search and read_feed output,
+// "1. Title - https://example.com/page".
+var listedLinkRegex = regexp.MustCompile(`(?m)^\d+\. (.*) - (https?://\S+)`)
+
+// webSources returns the web pages the output of a web tool came from: the
+// listed results of web_search and read_feed, and the page of visit_url.
+func webSources(toolName string, input map[string]interface{}, output string) []types.Source {
+	switch toolName {
+	case "web_search", "read_feed":
+		var sources []types.Source
+		for _, match := range listedLinkRegex.FindAllStringSubmatch(output, -1) {
+			sources = append(sources, types.Source{Title: match[1], URL: match[2]})
+		}
+		return sources
+	case "visit_url":
+		if url, _ := input["url"].(string); url != "" {
+			return []types.Source{{URL: url}}
+		}
+	}
+	return nil
+}
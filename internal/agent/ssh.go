@@ -0,0 +1,210 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// SSHConfig describes a remote workspace. The file tools and git then run on
+// the remote host through the system ssh client, so keys, agents and
+// ~/.ssh/config work as usual.
+type SSHConfig struct {
+	Host         string `json:"host"`                    // user@host or an alias from ~/.ssh/config
+	Port         int    `json:"port,omitempty"`          // Defaults to the ssh default.
+	IdentityFile string `json:"identity_file,omitempty"` // Passed to ssh -i.
+	Dir          string `json:"dir"`                     // Absolute workspace directory on the host.
+}
+
+// sshFS implements fileSystem on a remote host.
+type sshFS struct {
+	config SSHConfig
+}
+
+// SetSSHWorkspace makes the file tools and git operate on a remote host.
+// Relative paths are resolved against cfg.Dir and cannot leave it.
+func (a *Agent) SetSSHWorkspace(cfg *SSHConfig) {
+	if cfg == nil || cfg.Host == "" {
+		a.ssh = nil
+		return
+	}
+	a.ssh = &sshFS{config: *cfg}
+}
+
+// Remote reports whether the workspace is on an SSH host.
+func (a *Agent) Remote() bool {
+	return a.ssh != nil
+}
+
+// resolveRemotePath maps a tool path into the remote workspace directory.
+func (s *sshFS) resolveRemotePath(p string) (string, error) {
+	if runtime.GOOS == "windows" {
+		// Paths typed on Windows, e.g. in @ mentions, use backslashes; the
+		// host expects slashes.
+		p = strings.ReplaceAll(p, "\\", "/")
+	}
+	dir := path.Clean(s.config.Dir)
+	resolved := p
+	if !path.IsAbs(p) {
+		resolved = path.Join(dir, p)
+	}
+	resolved = path.Clean(resolved)
+	if resolved != dir && !strings.HasPrefix(resolved, dir+"/") {
+		return "", fmt.Errorf("path '%s' is outside the remote workspace %s", p, dir)
+	}
+	return resolved, nil
+}
+
+// run executes a shell command on the host and returns its stdout.
+func (s *sshFS) run(ctx context.Context, command string, stdin []byte) ([]byte, error) {
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+	if s.config.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(s.config.Port))
+	}
+	if s.config.IdentityFile != "" {
+		args = append(args, "-i", s.config.IdentityFile)
+	}
+	args = append(args, s.config.Host, command)
+
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "No such file or directory") {
+			return nil, fmt.Errorf("%s: %w", msg, fs.ErrNotExist)
+		}
+		if msg == "" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%v: %s", err, msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// runDefault runs a command for the file operations, which take no context.
+// The tool timeout still applies through runWithTimeout; this limit only
+// reaps an ssh process whose connection hung.
+func (s *sshFS) runDefault(command string, stdin []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	return s.run(ctx, command, stdin)
+}
+
+func (s *sshFS) ReadFile(p string) ([]byte, error) {
+	return s.runDefault("cat -- "+shellQuote(p), nil)
+}
+
+func (s *sshFS) WriteFile(p string, data []byte) error {
+	_, err := s.runDefault("cat > "+shellQuote(p), data)
+	return err
+}
+
+func (s *sshFS) AppendFile(p string, data []byte) error {
+	_, err := s.runDefault("cat >> "+shellQuote(p), data)
+	return err
+}
+
+func (s *sshFS) Remove(p string) error {
+	_, err := s.runDefault("rm -- "+shellQuote(p), nil)
+	return err
+}
+
+func (s *sshFS) Rename(oldPath, newPath string) error {
+	_, err := s.runDefault("mkdir -p -- "+shellQuote(path.Dir(newPath))+" && mv -- "+shellQuote(oldPath)+" "+shellQuote(newPath), nil)
+	return err
+}
+
+func (s *sshFS) Stat(p string) (fileStat, error) {
+	quoted := shellQuote(p)
+	// GNU stat first, then the BSD flavour used by macOS hosts.
+	out, err := s.runDefault("stat -c '%s %Y' -- "+quoted+" 2>/dev/null || stat -f '%z %m' -- "+quoted, nil)
+	if err != nil {
+		return fileStat{}, err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return fileStat{}, fmt.Errorf("unexpected stat output %q", out)
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return fileStat{}, err
+	}
+	mtime, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return fileStat{}, err
+	}
+	return fileStat{Size: size, ModTime: time.Unix(mtime, 0)}, nil
+}
+
+func (s *sshFS) ReadDir(p string) ([]string, error) {
+	out, err := s.runDefault("ls -1A -- "+shellQuote(p), nil)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(out)), nil
+}
+
+func (s *sshFS) MkdirAll(p string) error {
+	_, err := s.runDefault("mkdir -p -- "+shellQuote(p), nil)
+	return err
+}
+
+// Glob lists the tree on the host once and matches the pattern locally.
+func (s *sshFS) Glob(dir, pattern string) ([]string, error) {
+	out, err := s.runDefault("cd "+shellQuote(dir)+" && find . -mindepth 1", nil)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, line := range splitLines(string(out)) {
+		rel := strings.TrimPrefix(line, "./")
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			matches = append(matches, rel)
+		}
+	}
+	return matches, nil
+}
+
+// gitCommand runs git in dir on the host.
+func (s *sshFS) gitCommand(ctx context.Context, dir string, args []string) ([]byte, error) {
+	quoted := make([]string, 0, len(args)+1)
+	quoted = append(quoted, "git")
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return s.run(ctx, "cd "+shellQuote(dir)+" && "+strings.Join(quoted, " "), nil)
+}
+
+// summary describes the remote workspace for the system prompt.
+func (s *sshFS) summary() string {
+	return fmt.Sprintf("## Remote workspace\nFile tools and git run on %s in %s. Use paths relative to that directory.\n", s.config.Host, s.config.Dir)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
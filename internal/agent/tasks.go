@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"fmt"
+	"prompt-cli/internal/types"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// taskList is the checklist of the task_list tool for the current session.
+type taskList struct {
+	mu     sync.Mutex
+	items  []types.Task
+	nextID int
+}
+
+// Tasks returns a copy of the session's task list.
+func (a *Agent) Tasks() []types.Task {
+	a.tasks.mu.Lock()
+	defer a.tasks.mu.Unlock()
+	return slices.Clone(a.tasks.items)
+}
+
+// SetTasks replaces the task list, e.g. with the one of a resumed session.
+func (a *Agent) SetTasks(tasks []types.Task) {
+	a.tasks.mu.Lock()
+	defer a.tasks.mu.Unlock()
+	a.tasks.items = slices.Clone(tasks)
+	a.tasks.nextID = 1
+	for _, task := range tasks {
+		a.tasks.nextID = max(a.tasks.nextID, task.ID+1)
+	}
+}
+
+// HandleTaskList adds, updates, completes, removes or lists the items of
+// the session's checklist and returns the list after the change.
+func (a *Agent) HandleTaskList(input map[string]interface{}) string {
+	action, _ := input["action"].(string)
+	if action == "" {
+		action = "list"
+	}
+	id, hasID := input["id"].(float64)
+	text, _ := input["text"].(string)
+	status, _ := input["status"].(string)
+
+	a.tasks.mu.Lock()
+	defer a.tasks.mu.Unlock()
+	t := &a.tasks
+	if t.nextID == 0 {
+		t.nextID = 1
+	}
+	find := func() (int, string) {
+		if !hasID {
+			return -1, fmt.Sprintf("Error: 'id' is required for the %s action of task_list.", action)
+		}
+		for i, task := range t.items {
+			if task.ID == int(id) {
+				return i, ""
+			}
+		}
+		return -1, fmt.Sprintf("Error: task %d does not exist.", int(id))
+	}
+
+	switch action {
+	case "list":
+	case "add":
+		var texts []string
+		if items, ok := input["items"].([]interface{}); ok {
+			for _, item := range items {
+				if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+					texts = append(texts, strings.TrimSpace(s))
+				}
+			}
+		}
+		if strings.TrimSpace(text) != "" {
+			texts = append(texts, strings.TrimSpace(text))
+		}
+		if len(texts) == 0 {
+			return "Error: 'items' or 'text' is required for the add action of task_list."
+		}
+		for _, text := range texts {
+			t.items = append(t.items, types.Task{ID: t.nextID, Text: text, Status: types.TaskPending})
+			t.nextID++
+		}
+	case "update", "complete":
+		i, errMsg := find()
+		if errMsg != "" {
+			return errMsg
+		}
+		if action == "complete" {
+			status = types.TaskDone
+		}
+		switch status {
+		case "":
+		case types.TaskPending, types.TaskInProgress, types.TaskDone:
+			t.items[i].Status = status
+		default:
+			return fmt.Sprintf("Error: unknown task_list status '%s'; use pending, in_progress or done.", status)
+		}
+		if strings.TrimSpace(text) != "" {
+			t.items[i].Text = strings.TrimSpace(text)
+		}
+	case "remove":
+		i, errMsg := find()
+		if errMsg != "" {
+			return errMsg
+		}
+		t.items = slices.Delete(t.items, i, i+1)
+	case "clear":
+		t.items = nil
+	default:
+		return fmt.Sprintf("Error: unknown task_list action '%s'; use add, update, complete, remove, clear or list.", action)
+	}
+	return formatTasks(t.items)
+}
+
+// formatTasks renders the checklist for the model.
+func formatTasks(tasks []types.Task) string {
+	if len(tasks) == 0 {
+		return "The task list is empty."
+	}
+	done := 0
+	var builder strings.Builder
+	for _, task := range tasks {
+		marker := " "
+		switch task.Status {
+		case types.TaskDone:
+			marker = "x"
+			done++
+		case types.TaskInProgress:
+			marker = ">"
+		}
+		fmt.Fprintf(&builder, "[%s] %d. %s\n", marker, task.ID, task.Text)
+	}
+	return fmt.Sprintf("Task list (%d/%d done):\n%s", done, len(tasks), builder.String())
+}
@@ -0,0 +1,249 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// JiraConfig points the ticket tool at a Jira site.
+type JiraConfig struct {
+	BaseURL string `json:"base_url"`        // e.g. "https://example.atlassian.net"
+	Email   string `json:"email,omitempty"` // Jira Cloud account; without it the token is sent as a bearer token (Server/Data Center).
+	Token   string `json:"token,omitempty"` // Falls back to $JIRA_API_TOKEN.
+}
+
+// LinearConfig holds the Linear API key for the ticket tool.
+type LinearConfig struct {
+	Token string `json:"token,omitempty"` // Falls back to $LINEAR_API_KEY.
+}
+
+// ticket is a Jira or Linear issue in provider-neutral form.
+type ticket struct {
+	Key         string
+	Title       string
+	Status      string
+	Assignee    string
+	URL         string
+	Description string
+	Comments    []forgeComment
+}
+
+const ticketToolPrompt = `- ticket
+  - purpose: read a ticket from the issue tracker (%s) with its description and comments
+  - input: {"key":"string","source":"jira | linear | null","max_bytes":integer|null}
+  - notes: key is the ticket identifier, e.g. "PROJ-123". Use it to start work from the actual requirements.`
+
+// SetTicketSources configures the trackers used by the ticket tool. Either
+// may be nil.
+func (a *Agent) SetTicketSources(jira *JiraConfig, linear *LinearConfig) {
+	a.jira = jira
+	a.linear = linear
+}
+
+// ticketPrompt documents the ticket tool with the configured sources.
+func (a *Agent) ticketPrompt() string {
+	var sources []string
+	if a.jira != nil {
+		sources = append(sources, "Jira")
+	}
+	if a.linear != nil {
+		sources = append(sources, "Linear")
+	}
+	if len(sources) == 0 {
+		sources = append(sources, "not configured")
+	}
+	return fmt.Sprintf(ticketToolPrompt, strings.Join(sources, ", "))
+}
+
+// HandleTicket fetches a ticket by key and returns it as Markdown.
+func (a *Agent) HandleTicket(ctx context.Context, input map[string]interface{}) string {
+	key, _ := input["key"].(string)
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "Error: 'key' not specified or not a string for ticket."
+	}
+
+	source, _ := input["source"].(string)
+	if source == "" {
+		// Jira wins when both are configured; the model can ask for Linear.
+		if a.jira != nil {
+			source = "jira"
+		} else if a.linear != nil {
+			source = "linear"
+		}
+	}
+
+	var (
+		t   ticket
+		err error
+	)
+	switch source {
+	case "jira":
+		if a.jira == nil {
+			return "Error: Jira is not configured."
+		}
+		t, err = fetchJiraTicket(ctx, *a.jira, key)
+	case "linear":
+		if a.linear == nil {
+			return "Error: Linear is not configured."
+		}
+		t, err = fetchLinearTicket(ctx, *a.linear, key)
+	case "":
+		return "Error: no issue tracker is configured."
+	default:
+		return fmt.Sprintf("Error: unknown ticket source %q.", source)
+	}
+	if err != nil {
+		return fmt.Sprintf("Error fetching %s from %s: %v", key, source, err)
+	}
+	return truncateOutput(formatTicket(t), input)
+}
+
+// formatTicket renders a ticket as Markdown.
+func formatTicket(t ticket) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# %s: %s\n\nStatus: %s", t.Key, t.Title, t.Status))
+	if t.Assignee != "" {
+		builder.WriteString(" | Assignee: " + t.Assignee)
+	}
+	if t.URL != "" {
+		builder.WriteString(" | " + t.URL)
+	}
+	builder.WriteString("\n\n" + strings.TrimSpace(t.Description) + "\n")
+	for _, c := range t.Comments {
+		builder.WriteString(fmt.Sprintf("\n## Comment by %s (%s)\n\n%s\n", c.Author, c.Created.Format("2006-01-02"), strings.TrimSpace(c.Body)))
+	}
+	return builder.String()
+}
+
+// jiraTime parses Jira's timestamp format, e.g. 2024-01-02T15:04:05.000+0000.
+func jiraTime(s string) time.Time {
+	t, _ := time.Parse("2006-01-02T15:04:05.000-0700", s)
+	return t
+}
+
+func fetchJiraTicket(ctx context.Context, cfg JiraConfig, key string) (ticket, error) {
+	token := cfg.Token
+	if token == "" {
+		token = os.Getenv("JIRA_API_TOKEN")
+	}
+	headers := map[string]string{"Accept": "application/json"}
+	if cfg.Email != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(cfg.Email+":"+token))
+	} else if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	var issue struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string                `json:"summary"`
+			Description string                `json:"description"`
+			Status      struct{ Name string } `json:"status"`
+			Assignee    *struct {
+				DisplayName string `json:"displayName"`
+			} `json:"assignee"`
+			Comment struct {
+				Comments []struct {
+					Body    string `json:"body"`
+					Created string `json:"created"`
+					Author  struct {
+						DisplayName string `json:"displayName"`
+					} `json:"author"`
+				} `json:"comments"`
+			} `json:"comment"`
+		} `json:"fields"`
+	}
+	base := strings.TrimRight(cfg.BaseURL, "/")
+	// API v2 returns descriptions as plain wiki text rather than v3's
+	// document format.
+	endpoint := base + "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,description,status,assignee,comment"
+	if err := forgeRequest(ctx, "GET", endpoint, headers, nil, &issue); err != nil {
+		return ticket{}, err
+	}
+
+	t := ticket{
+		Key:         issue.Key,
+		Title:       issue.Fields.Summary,
+		Status:      issue.Fields.Status.Name,
+		URL:         base + "/browse/" + issue.Key,
+		Description: issue.Fields.Description,
+	}
+	if issue.Fields.Assignee != nil {
+		t.Assignee = issue.Fields.Assignee.DisplayName
+	}
+	for _, c := range issue.Fields.Comment.Comments {
+		t.Comments = append(t.Comments, forgeComment{Author: c.Author.DisplayName, Body: c.Body, Created: jiraTime(c.Created)})
+	}
+	return t, nil
+}
+
+// linearIssueQuery fetches an issue by its identifier, e.g. "ENG-123".
+const linearIssueQuery = `query($id: String!) {
+  issue(id: $id) {
+    identifier title description url
+    state { name }
+    assignee { name }
+    comments { nodes { body createdAt user { name } } }
+  }
+}`
+
+func fetchLinearTicket(ctx context.Context, cfg LinearConfig, key string) (ticket, error) {
+	token := cfg.Token
+	if token == "" {
+		token = os.Getenv("LINEAR_API_KEY")
+	}
+	headers := map[string]string{"Authorization": token}
+	payload := map[string]interface{}{"query": linearIssueQuery, "variables": map[string]string{"id": key}}
+
+	var resp struct {
+		Data struct {
+			Issue *struct {
+				Identifier  string                 `json:"identifier"`
+				Title       string                 `json:"title"`
+				Description string                 `json:"description"`
+				URL         string                 `json:"url"`
+				State       struct{ Name string }  `json:"state"`
+				Assignee    *struct{ Name string } `json:"assignee"`
+				Comments    struct {
+					Nodes []struct {
+						Body      string                 `json:"body"`
+						CreatedAt time.Time              `json:"createdAt"`
+						User      *struct{ Name string } `json:"user"`
+					} `json:"nodes"`
+				} `json:"comments"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := forgeRequest(ctx, "POST", "https://api.linear.app/graphql", headers, payload, &resp); err != nil {
+		return ticket{}, err
+	}
+	if len(resp.Errors) > 0 {
+		return ticket{}, fmt.Errorf("%s", resp.Errors[0].Message)
+	}
+	issue := resp.Data.Issue
+	if issue == nil {
+		return ticket{}, fmt.Errorf("issue not found")
+	}
+
+	t := ticket{Key: issue.Identifier, Title: issue.Title, Status: issue.State.Name, URL: issue.URL, Description: issue.Description}
+	if issue.Assignee != nil {
+		t.Assignee = issue.Assignee.Name
+	}
+	for _, c := range issue.Comments.Nodes {
+		author := "unknown"
+		if c.User != nil {
+			author = c.User.Name
+		}
+		t.Comments = append(t.Comments, forgeComment{Author: author, Body: c.Body, Created: c.CreatedAt})
+	}
+	return t, nil
+}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultToolTimeout applies when no timeout has been configured.
+const defaultToolTimeout = 30 * time.Second
+
+// SetTimeouts configures how long each tool may run. defaultMs applies to
+// every tool without an entry in perToolMs. Zero or negative values are
+// ignored.
+func (a *Agent) SetTimeouts(defaultMs int, perToolMs map[string]int) {
+	if defaultMs > 0 {
+		a.defaultTimeout = time.Duration(defaultMs) * time.Millisecond
+	}
+	a.timeouts = make(map[string]time.Duration, len(perToolMs))
+	for tool, ms := range perToolMs {
+		if ms > 0 {
+			a.timeouts[tool] = time.Duration(ms) * time.Millisecond
+		}
+	}
+}
+
+// toolTimeout returns the timeout for a tool call: the configured one for
+// the tool, or the global default. A "timeout_ms" in the tool input can
+// shorten it but not lengthen it, so the model cannot turn off the
+// protection against stalled tools.
+func (a *Agent) toolTimeout(toolName string, input map[string]interface{}) time.Duration {
+	timeout := defaultToolTimeout
+	if configured, ok := a.timeouts[toolName]; ok {
+		timeout = configured
+	} else if a.defaultTimeout > 0 {
+		timeout = a.defaultTimeout
+	}
+	if ms, ok := input["timeout_ms"].(float64); ok && ms > 0 {
+		timeout = min(timeout, time.Duration(ms)*time.Millisecond)
+	}
+	return timeout
+}
+
+// fileWritingTools change files in the workspace. Their handlers are never
+// abandoned on a timeout, so a write cannot land after the model was told
+// the call timed out.
+var fileWritingTools = map[string]bool{
+	"write_file": true, "append_file": true, "delete_file": true, "restore_file": true,
+	"apply_changeset": true, "extract_archive": true, "download_file": true,
+}
+
+// runWithTimeout runs fn and gives up once ctx expires. Handlers that take
+// the context stop their work; the others are abandoned and their result is
+// discarded, except for the fileWritingTools, which run to the end.
+func (a *Agent) runWithTimeout(ctx context.Context, toolName string, timeout time.Duration, fn func(context.Context) string) string {
+	if fileWritingTools[toolName] {
+		return fn(ctx)
+	}
+	result := make(chan string, 1)
+	go func() {
+		result <- fn(ctx)
+	}()
+
+	select {
+	case output := <-result:
+		return output
+	case <-ctx.Done():
+		a.logger.Log(fmt.Sprintf("Tool %s timed out after %s", toolName, timeout))
+		return fmt.Sprintf("Error: %s timed out after %s.", toolName, timeout)
+	}
+}
+
+// Timeout returns the configured timeout for a tool or user command name.
+func (a *Agent) Timeout(name string) time.Duration {
+	return a.toolTimeout(name, nil)
+}
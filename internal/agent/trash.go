@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"prompt-cli/internal/workspace"
+	"sync"
+	"time"
+)
+
+// trashedFile is a file delete_file moved to the session trash.
+type trashedFile struct {
+	path      string // As the model addressed it.
+	fullPath  string // Original location.
+	trashPath string
+	seq       int64 // Position in the /undo order, or 0 if a changeset restores it.
+}
+
+// trashCan keeps the files deleted in the current session so they can be
+// restored with restore_file or /undo.
+type trashCan struct {
+	mu      sync.Mutex
+	session string
+	files   []trashedFile
+	moved   int // Files moved so far, for unique names in the trash folder.
+}
+
+// UseTrash starts a new trash for the session. Files deleted in earlier
+// sessions stay in their trash folder on disk but can no longer be
+// restored through the agent.
+func (a *Agent) UseTrash(sessionID string) {
+	a.trash.mu.Lock()
+	defer a.trash.mu.Unlock()
+	if a.trash.session != sessionID {
+		a.trash.session = sessionID
+		a.trash.files = nil
+	}
+}
+
+// trashDir is the folder of the session's trash: .promptcli/trash/<session>
+// in the workspace, or below the remote directory with an SSH workspace.
+func (a *Agent) trashDir() (string, error) {
+	if a.ssh != nil {
+		return path.Join(a.ssh.config.Dir, ".promptcli", "trash", a.trash.session), nil
+	}
+	dir, err := workspace.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(filepath.Join(dir, "trash", a.trash.session))
+}
+
+// moveToTrash moves a file into the session trash instead of deleting it.
+func (a *Agent) moveToTrash(path, fullPath string) error {
+	_, err := a.trashFile(path, fullPath, true)
+	return err
+}
+
+// trashFile moves a file into the session trash and returns where it went.
+// Files of a changeset are not undoable on their own: /undo restores them
+// with the rest of the changeset.
+func (a *Agent) trashFile(path, fullPath string, undoable bool) (string, error) {
+	a.trash.mu.Lock()
+	defer a.trash.mu.Unlock()
+	if _, err := a.files().Stat(fullPath); err != nil {
+		return "", err
+	}
+	dir, err := a.trashDir()
+	if err != nil {
+		return "", err
+	}
+	a.trash.moved++
+	name := fmt.Sprintf("%s-%d-%s", time.Now().Format("150405"), a.trash.moved, filepath.Base(fullPath))
+	trashPath := a.joinPath(dir, name)
+	if err := a.files().Rename(fullPath, trashPath); err != nil {
+		return "", err
+	}
+	file := trashedFile{path: path, fullPath: fullPath, trashPath: trashPath}
+	if undoable {
+		file.seq = a.changesets.seq.Add(1)
+	}
+	a.trash.files = append(a.trash.files, file)
+	return trashPath, nil
+}
+
+// restoreTrashed moves the file at trashPath back to where it was.
+func (a *Agent) restoreTrashed(trashPath string) error {
+	a.trash.mu.Lock()
+	defer a.trash.mu.Unlock()
+	for i, file := range a.trash.files {
+		if file.trashPath == trashPath {
+			_, err := a.restore(i)
+			return err
+		}
+	}
+	return fmt.Errorf("'%s' is no longer in the trash", trashPath)
+}
+
+// restore moves the trashed file at index i back. It refuses if a new file
+// has been created in its place.
+func (a *Agent) restore(i int) (string, error) {
+	file := a.trash.files[i]
+	if _, err := a.files().Stat(file.fullPath); err == nil {
+		return "", fmt.Errorf("'%s' exists again; move or delete it first", file.path)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if err := a.files().Rename(file.trashPath, file.fullPath); err != nil {
+		return "", err
+	}
+	a.trash.files = append(a.trash.files[:i], a.trash.files[i+1:]...)
+	a.cache.invalidatePrefix("list_files|")
+	return file.path, nil
+}
+
+// HandleRestoreFile brings back the most recent deleted version of a file.
+func (a *Agent) HandleRestoreFile(input map[string]interface{}) string {
+	path, ok := input["path"].(string)
+	if !ok {
+		return "Error: 'path' not specified or not a string for restore_file."
+	}
+	fullPath, err := a.ResolvePath(path)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	a.trash.mu.Lock()
+	defer a.trash.mu.Unlock()
+	for i := len(a.trash.files) - 1; i >= 0; i-- {
+		if a.trash.files[i].fullPath != fullPath {
+			continue
+		}
+		if _, err := a.restore(i); err != nil {
+			return fmt.Sprintf("Error restoring file '%s': %v", path, err)
+		}
+		return fmt.Sprintf("File '%s' restored from the trash.", path)
+	}
+	return fmt.Sprintf("Error: '%s' was not deleted in this session, so there is nothing to restore.", path)
+}
+
+// PurgeTrash permanently deletes the files in the session trash.
+func (a *Agent) PurgeTrash() {
+	a.trash.mu.Lock()
+	defer a.trash.mu.Unlock()
+	if len(a.trash.files) == 0 {
+		return
+	}
+	for _, file := range a.trash.files {
+		if err := a.files().Remove(file.trashPath); err != nil {
+			a.logger.Log(fmt.Sprintf("Error purging '%s' from the trash: %v", file.trashPath, err))
+		}
+	}
+	a.trash.files = nil
+	if dir, err := a.trashDir(); err == nil && a.ssh == nil {
+		os.Remove(dir)
+	}
+}
@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxValidatorOutput caps the validator output quoted in a tool result.
+const maxValidatorOutput = 2000
+
+// SetValidators sets the commands that check files after write_file and
+// append_file, by extension, e.g. {".go": "gofmt -l", ".json": "jq empty"}.
+// The file's path is appended to the command, which fails the check by
+// exiting with a non-zero status. Extensions without a command get the
+// built-in syntax check for Go, JSON and YAML.
+func (a *Agent) SetValidators(validators map[string]string) {
+	a.validators = byExtension(validators)
+}
+
+// byExtension normalizes the keys of a command table to lower-case
+// extensions with a leading dot, so "go", ".go" and ".GO" all work.
+func byExtension(commands map[string]string) map[string]string {
+	normalized := make(map[string]string, len(commands))
+	for ext, command := range commands {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		normalized[strings.ToLower(ext)] = command
+	}
+	return normalized
+}
+
+// verifyWrite reads a written file back and checks its syntax, adding the
+// outcome to the tool's output so the model notices a truncated or broken
+// write right away instead of a few steps later.
+func (a *Agent) verifyWrite(ctx context.Context, toolName string, input map[string]interface{}, output string) string {
+	if failed(output) {
+		return output
+	}
+	p, _ := input["path"].(string)
+	content, _ := input["content"].(string)
+	fullPath, err := a.ResolvePath(p)
+	if err != nil {
+		return output
+	}
+	written, err := a.files().ReadFile(fullPath)
+	if err != nil {
+		return output + fmt.Sprintf("\nVerification failed: could not read the file back: %v", err)
+	}
+	switch {
+	case toolName == "write_file" && !bytes.Equal(written, []byte(content)):
+		return output + fmt.Sprintf("\nVerification failed: the file has %d bytes instead of the %d written. Write it again.", len(written), len(content))
+	case toolName == "append_file" && !bytes.HasSuffix(written, []byte(content)):
+		return output + "\nVerification failed: the file does not end with the appended content. Check it with read_file."
+	}
+
+	ext := strings.ToLower(path.Ext(p))
+	if formatter, ok := a.formatters[ext]; ok {
+		var note string
+		written, note = a.formatWritten(ctx, formatter, p, fullPath, written)
+		output += "\n" + note
+	}
+	if command, ok := a.validators[ext]; ok {
+		return output + "\n" + a.runValidator(ctx, command, fullPath)
+	}
+	if problem := checkSyntax(ext, written); problem != "" {
+		return output + "\nVerification failed: " + problem + ". Fix the file."
+	}
+	return output + fmt.Sprintf("\nVerified: read back %d bytes.", len(written))
+}
+
+// runValidator runs a configured validator on a file.
+func (a *Agent) runValidator(ctx context.Context, command, fullPath string) string {
+	out, err := a.runOnFile(ctx, command, fullPath)
+	if err == nil {
+		return fmt.Sprintf("Verified: `%s` passed.", command)
+	}
+	if out == "" {
+		return fmt.Sprintf("Verification failed: `%s`: %v. Fix the file.", command, err)
+	}
+	return fmt.Sprintf("Verification failed: `%s`: %v. Fix the file.\n%s", command, err, out)
+}
+
+// runOnFile runs a configured command with a file's path appended, on the
+// SSH host for a remote workspace. A non-zero exit is an error; the
+// returned output is trimmed and capped.
+func (a *Agent) runOnFile(ctx context.Context, command, fullPath string) (string, error) {
+	var out string
+	var failure error
+	if a.ssh != nil {
+		stdout, err := a.ssh.run(ctx, "cd "+shellQuote(a.ssh.config.Dir)+" && "+command+" "+shellQuote(fullPath), nil)
+		out, failure = string(stdout), err
+	} else {
+		output, code, err := RunShell(ctx, command+" "+currentShell.Quote(fullPath), "")
+		out, failure = output, err
+		if err == nil && code != 0 {
+			failure = fmt.Errorf("exit status %d", code)
+		}
+	}
+	out = strings.TrimSpace(out)
+	if len(out) > maxValidatorOutput {
+		out = out[:maxValidatorOutput] + "\n... output truncated ..."
+	}
+	return out, failure
+}
+
+// checkSyntax parses Go, JSON and YAML files and describes the first
+// syntax error, or returns "" if there is none or the type is unknown.
+func checkSyntax(ext string, content []byte) string {
+	switch ext {
+	case ".go":
+		if _, err := parser.ParseFile(token.NewFileSet(), "", content, parser.AllErrors); err != nil {
+			return "invalid Go: " + err.Error()
+		}
+	case ".json":
+		var v interface{}
+		if err := json.Unmarshal(content, &v); err != nil {
+			if syntaxErr, ok := err.(*json.SyntaxError); ok {
+				line := bytes.Count(content[:syntaxErr.Offset], []byte("\n")) + 1
+				return fmt.Sprintf("invalid JSON at line %d: %v", line, err)
+			}
+			return "invalid JSON: " + err.Error()
+		}
+	case ".yaml", ".yml":
+		var node yaml.Node
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			return "invalid YAML: " + err.Error()
+		}
+	}
+	return ""
+}
@@ -1,36 +1,61 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"prompt-cli/internal/logger"
+	"regexp"
+	"sort"
 	"strings"
 
 	"golang.org/x/net/html"
 )
 
 type searchResult struct {
-	title, link, snippet string
+	title, link, snippet, date string
 }
 
-func PerformWebSearch(query string, logger *logger.Logger) (string, error) {
+// SearchConfig sets the defaults for web_search.
+type SearchConfig struct {
+	MaxResults int `json:"max_results,omitempty"` // Results returned to the model. Default 5.
+	PerDomain  int `json:"per_domain,omitempty"`  // Results kept from any one domain. Default 2.
+}
+
+// SearchOptions controls a single web search.
+type SearchOptions struct {
+	MaxResults  int
+	PerDomain   int
+	Site        string // Restricts results to this domain.
+	RecencyDays int    // Only results from the last N days; rounded up to a day, week, month or year.
+}
+
+const (
+	defaultSearchResults   = 5
+	defaultSearchPerDomain = 2
+)
+
+// dateRegex finds the publication date DuckDuckGo adds next to some results.
+var dateRegex = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+func PerformWebSearch(ctx context.Context, fetcher *Fetcher, query string, opts SearchOptions, logger *logger.Logger) (string, error) {
 	logger.Log(fmt.Sprintf("performWebSearch query: %s", query))
 
 	// 1. Construct the search URL
-	encodedQuery := url.QueryEscape(query)
-	searchURL := fmt.Sprintf("https://html.duckduckgo.com/html/?q=%s", encodedQuery)
-
-	// 2. Make the HTTP request
-	req, err := http.NewRequest("GET", searchURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create search request: %w", err)
+	q := query
+	if opts.Site != "" {
+		q += " site:" + opts.Site
+	}
+	params := url.Values{"q": {q}}
+	if df := recencyFilter(opts.RecencyDays); df != "" {
+		params.Set("df", df)
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/109.0.0.0 Safari/537.36")
+	searchURL := "https://html.duckduckgo.com/html/?" + params.Encode()
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+	// 2. Make the HTTP request. The search page is fetched on the user's
+	// behalf rather than crawled, so robots.txt does not apply.
+	res, err := fetcher.Get(ctx, searchURL, false)
 	if err != nil {
 		return "", fmt.Errorf("failed to perform search request: %w", err)
 	}
@@ -46,8 +71,8 @@ func PerformWebSearch(query string, logger *logger.Logger) (string, error) {
 		return "", fmt.Errorf("failed to parse HTML response: %w", err)
 	}
 
-	// 4. Find and extract search results
-	results := findResults(doc)
+	// 4. Find, rank and deduplicate the search results
+	results := selectResults(rankResults(findResults(doc), query), opts)
 
 	// 5. Format the results
 	if len(results) == 0 {
@@ -57,10 +82,11 @@ func PerformWebSearch(query string, logger *logger.Logger) (string, error) {
 	var summary strings.Builder
 	summary.WriteString("Search results:\n")
 	for i, result := range results {
-		if i > 4 { // Limit to 5 results
-			break
+		summary.WriteString(fmt.Sprintf("%d. %s - %s", i+1, result.title, result.link))
+		if result.date != "" {
+			summary.WriteString(fmt.Sprintf(" (published %s)", result.date))
 		}
-		summary.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, result.title, result.link))
+		summary.WriteString("\n")
 		if result.snippet != "" {
 			summary.WriteString(fmt.Sprintf("   %s\n", result.snippet))
 		}
@@ -69,6 +95,108 @@ func PerformWebSearch(query string, logger *logger.Logger) (string, error) {
 	return summary.String(), nil
 }
 
+// recencyFilter maps a number of days to DuckDuckGo's df parameter, which
+// only knows day, week, month and year.
+func recencyFilter(days int) string {
+	switch {
+	case days <= 0:
+		return ""
+	case days <= 1:
+		return "d"
+	case days <= 7:
+		return "w"
+	case days <= 31:
+		return "m"
+	case days <= 366:
+		return "y"
+	}
+	return ""
+}
+
+// rankResults orders results by how many query terms their title and snippet
+// contain, falling back to the search engine's order.
+func rankResults(results []searchResult, query string) []searchResult {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return results
+	}
+	scores := make([]float64, len(results))
+	order := make([]int, len(results))
+	for i, result := range results {
+		text := strings.ToLower(result.title + " " + result.snippet)
+		hits := 0
+		for _, term := range terms {
+			if strings.Contains(text, term) {
+				hits++
+			}
+		}
+		// Term coverage dominates; the original position breaks ties and
+		// keeps the engine's own ranking relevant.
+		scores[i] = float64(hits)/float64(len(terms)) + 0.5/float64(i+1)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(x, y int) bool {
+		return scores[order[x]] > scores[order[y]]
+	})
+	ranked := make([]searchResult, len(results))
+	for i, idx := range order {
+		ranked[i] = results[idx]
+	}
+	return ranked
+}
+
+// selectResults drops duplicate URLs, caps the results taken from one domain
+// and returns at most opts.MaxResults entries.
+func selectResults(results []searchResult, opts SearchOptions) []searchResult {
+	limit := opts.MaxResults
+	if limit <= 0 {
+		limit = defaultSearchResults
+	}
+	perDomain := opts.PerDomain
+	if perDomain <= 0 {
+		perDomain = defaultSearchPerDomain
+	}
+
+	seen := make(map[string]bool)
+	domains := make(map[string]int)
+	var selected []searchResult
+	for _, result := range results {
+		key, domain := normalizeResultURL(result.link)
+		if seen[key] || domains[domain] >= perDomain {
+			continue
+		}
+		seen[key] = true
+		domains[domain]++
+		selected = append(selected, result)
+		if len(selected) == limit {
+			break
+		}
+	}
+	return selected
+}
+
+// normalizeResultURL returns a key identifying the page behind link, ignoring
+// the scheme, "www.", fragments, tracking parameters and trailing slashes, and
+// the domain it belongs to.
+func normalizeResultURL(link string) (string, string) {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link, link
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	query := u.Query()
+	for param := range query {
+		if strings.HasPrefix(param, "utm_") {
+			query.Del(param)
+		}
+	}
+	key := host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key, host
+}
+
 // findResults traverses the HTML node tree and extracts search results.
 func findResults(n *html.Node) []searchResult {
 	var results []searchResult
@@ -77,6 +205,7 @@ func findResults(n *html.Node) []searchResult {
 			var result searchResult
 			result.title, result.link = extractTitleAndLink(n)
 			result.snippet = extractSnippet(n)
+			result.date = extractDate(n)
 			if result.title != "" && result.link != "" {
 				results = append(results, result)
 			}
@@ -127,6 +256,25 @@ func extractSnippet(n *html.Node) string {
 	return snippet
 }
 
+// extractDate returns the publication date shown in a result's URL line,
+// if there is one.
+func extractDate(n *html.Node) string {
+	var date string
+	var crawler func(*html.Node)
+	crawler = func(node *html.Node) {
+		if node.Type == html.ElementNode && (hasClass(node, "result__extras__url") || hasClass(node, "result__timestamp")) {
+			if match := dateRegex.FindString(getText(node)); match != "" {
+				date = match
+			}
+		}
+		for c := node.FirstChild; c != nil && date == ""; c = c.NextSibling {
+			crawler(c)
+		}
+	}
+	crawler(n)
+	return date
+}
+
 // hasClass checks if a node has a specific CSS class.
 func hasClass(n *html.Node, className string) bool {
 	for _, a := range n.Attr {
@@ -195,3 +343,11 @@ func ExtractTextFromHTML(r io.Reader) (string, error) {
 
 	return strings.Join(cleanedLines, "\n"), nil
 }
+
+// SetSearch configures the defaults for web_search. A nil config keeps the
+// built-in defaults.
+func (a *Agent) SetSearch(cfg *SearchConfig) {
+	if cfg != nil {
+		a.search = *cfg
+	}
+}
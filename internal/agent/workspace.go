@@ -0,0 +1,193 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// SetRoots configures the named workspace roots the file tools are allowed
+// to touch. Each key is the name used in "name:path" addresses and each value
+// is the directory on disk. An empty map restores the default behaviour where
+// paths are used as given.
+func (a *Agent) SetRoots(roots map[string]string) {
+	a.roots = make(map[string]string, len(roots))
+	for name, dir := range roots {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			a.logger.Log(fmt.Sprintf("Ignoring workspace root '%s': %v", name, err))
+			continue
+		}
+		a.roots[name] = abs
+	}
+}
+
+// RootNames returns the configured workspace root names in sorted order.
+func (a *Agent) RootNames() []string {
+	names := make([]string, 0, len(a.roots))
+	for name := range a.roots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RootDir returns the directory configured for the named root.
+func (a *Agent) RootDir(name string) (string, bool) {
+	dir, ok := a.roots[name]
+	return dir, ok
+}
+
+// ResolvePath turns a tool path into a path on disk. Paths of the form
+// "name:rel" are resolved against the named root. When roots are configured,
+// any other path must also fall inside one of them; this keeps the agent from
+// reaching outside the workspace. "scratch:rel" and paths inside the scratch
+// directory are always allowed. With an SSH workspace the result is a path
+// on the remote host.
+func (a *Agent) ResolvePath(path string) (string, error) {
+	if a.ssh != nil {
+		return a.ssh.resolveRemotePath(path)
+	}
+	if resolved, ok, err := a.resolveScratchPath(path); ok {
+		return resolved, err
+	}
+	if len(a.roots) == 0 {
+		return localPath(path), nil
+	}
+
+	// "C:\dir" is a Windows drive, not a root named "C".
+	if name, rel, found := strings.Cut(path, ":"); found && filepath.VolumeName(path) == "" {
+		if dir, ok := a.roots[name]; ok {
+			resolved := filepath.Join(dir, filepath.FromSlash(rel))
+			if !isWithin(dir, resolved) {
+				return "", fmt.Errorf("path '%s' escapes workspace root '%s'", path, name)
+			}
+			return resolved, nil
+		}
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range a.roots {
+		if isWithin(dir, abs) {
+			return abs, nil
+		}
+	}
+	return "", fmt.Errorf("path '%s' is outside the workspace roots (%s)", path, strings.Join(a.RootNames(), ", "))
+}
+
+// localPath normalizes a path used without roots. On Windows, where the
+// model and @ mentions mix / and \, it is cleaned so both spellings of a
+// file share the conflict check, the trash and the cache.
+func localPath(path string) string {
+	if runtime.GOOS != "windows" || path == "" {
+		return path
+	}
+	return filepath.Clean(path)
+}
+
+// DisplayPath converts a path on disk back into its "name:rel" form when it
+// lies inside a configured root.
+func (a *Agent) DisplayPath(path string) string {
+	if a.ssh != nil {
+		if rel := strings.TrimPrefix(path, a.ssh.config.Dir+"/"); rel != path {
+			return rel
+		}
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	for _, name := range a.RootNames() {
+		dir := a.roots[name]
+		if isWithin(dir, abs) {
+			rel, err := filepath.Rel(dir, abs)
+			if err != nil {
+				continue
+			}
+			return name + ":" + filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+// WorkspaceFiles lists the entries used for @ mention completion. Without
+// roots this is the current directory; with roots it is the top level of each
+// root, addressed as "name:entry". An SSH workspace lists its remote
+// directory.
+func (a *Agent) WorkspaceFiles() []string {
+	if a.ssh != nil {
+		names, err := a.ssh.ReadDir(a.ssh.config.Dir)
+		if err != nil {
+			a.logger.Log(fmt.Sprintf("could not list remote workspace: %v", err))
+		}
+		return names
+	}
+	if len(a.roots) == 0 {
+		files, err := os.ReadDir(".")
+		if err != nil {
+			a.logger.Log(fmt.Sprintf("could not list files: %v", err))
+			return nil
+		}
+		var fileNames []string
+		for _, file := range files {
+			fileNames = append(fileNames, file.Name())
+		}
+		return fileNames
+	}
+
+	var fileNames []string
+	for _, name := range a.RootNames() {
+		files, err := os.ReadDir(a.roots[name])
+		if err != nil {
+			a.logger.Log(fmt.Sprintf("could not list root '%s': %v", name, err))
+			continue
+		}
+		for _, file := range files {
+			fileNames = append(fileNames, name+":"+file.Name())
+		}
+	}
+	return fileNames
+}
+
+// RootsSummary describes the configured roots, or the remote workspace, for
+// the system prompt.
+func (a *Agent) RootsSummary() string {
+	if a.ssh != nil {
+		return a.ssh.summary()
+	}
+	if len(a.roots) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("## Workspace roots\n")
+	builder.WriteString("File tools only work inside these roots. Address files as \"root:relative/path\".\n")
+	for _, name := range a.RootNames() {
+		builder.WriteString(fmt.Sprintf("- %s: %s\n", name, a.roots[name]))
+	}
+	return builder.String()
+}
+
+// isWithin reports whether target is dir itself or lies below it.
+func isWithin(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// joinPath joins a directory and a relative path for the active backend.
+func (a *Agent) joinPath(dir, rel string) string {
+	if a.ssh != nil {
+		return path.Join(dir, rel)
+	}
+	return filepath.Join(dir, rel)
+}
@@ -0,0 +1,38 @@
+// Package appdir locates the directory holding config.json, the logs and
+// the saved sessions.
+package appdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Dir returns the application directory. It is the executable's directory,
+// which keeps an unpacked release self-contained. On Windows, where programs
+// usually live under Program Files and cannot write next to themselves,
+// %APPDATA%\PromptCLI is used instead unless config.json is next to the
+// executable.
+func Dir() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("error getting executable path: %w", err)
+	}
+	exeDir := filepath.Dir(exePath)
+	if runtime.GOOS != "windows" {
+		return exeDir, nil
+	}
+	if _, err := os.Stat(filepath.Join(exeDir, "config.json")); err == nil {
+		return exeDir, nil
+	}
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return exeDir, nil
+	}
+	dir := filepath.Join(appData, "PromptCLI")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating %s: %w", dir, err)
+	}
+	return dir, nil
+}
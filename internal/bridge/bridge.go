@@ -0,0 +1,419 @@
+// Package bridge lets editor plugins use prompt-cli as their local LLM
+// backend. It answers JSON-RPC requests (see Bridge.Handle) with the same
+// agent, tools and permission checks as the TUI: tool calls that need
+// permission are handed back to the editor to approve.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/diff"
+	"prompt-cli/internal/logger"
+	"prompt-cli/internal/ollama"
+	"prompt-cli/internal/rpc"
+	"prompt-cli/internal/runner"
+	"prompt-cli/internal/session"
+	"prompt-cli/internal/types"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// defaultMaxSteps bounds the tool calls run for one chat request.
+const defaultMaxSteps = 10
+
+// editPrompt is the system prompt of edit requests.
+const editPrompt = `You edit code in the user's editor. Apply the requested change and reply with only the resulting code in a single fenced code block, without explanations. Keep everything else exactly as it is.`
+
+// fencedBlock matches a fenced code block of a reply.
+var fencedBlock = regexp.MustCompile("(?s)```[^\\n]*\\n(.*?)\\n?```")
+
+// Bridge holds the state of one editor connection: the open buffers, the
+// conversation, a tool call waiting for approval and the proposed edits.
+type Bridge struct {
+	Client       *ollama.OllamaClient
+	Agent        *agent.Agent
+	Logger       *logger.Logger
+	Model        string
+	SystemPrompt string
+	Options      types.Options
+	MaxSteps     int
+	// OnChange, if set, is called with a copy of the conversation whenever
+	// a message is added, e.g. to show progress in other terminals.
+	OnChange func(messages []types.Message)
+
+	session     *session.Session
+	buffers     map[string]string // Unsaved editor content by path.
+	messages    []types.Message
+	pending     *Pending
+	steps       int // Tool calls run for the current chat request.
+	alwaysAllow map[string]bool
+	edits       map[int]Edit
+	nextEdit    int
+}
+
+// Pending is a tool call that waits for the editor's approval.
+type Pending struct {
+	Tool     string                 `json:"tool"`
+	Input    map[string]interface{} `json:"input"`
+	Conflict string                 `json:"conflict,omitempty"` // How the file changed since the agent read it.
+}
+
+// Turn is the result of "chat" and "approve": the answer, or the tool call
+// to approve before the agent can go on.
+type Turn struct {
+	Answer  string   `json:"answer,omitempty"`
+	Steps   int      `json:"steps"`
+	Pending *Pending `json:"pending,omitempty"`
+}
+
+// Edit is a proposed change to a file, applied with "apply".
+type Edit struct {
+	ID      int    `json:"edit_id"`
+	Path    string `json:"path"`
+	Diff    string `json:"diff"`
+	Content string `json:"content"` // The whole file after the edit.
+}
+
+// Start begins a new session for the connection.
+func (b *Bridge) Start() {
+	b.use(session.New(b.Model), nil)
+}
+
+// Resume continues a saved session instead of starting a new one.
+func (b *Bridge) Resume(s *session.Session) {
+	b.use(s, s.Messages)
+}
+
+func (b *Bridge) use(s *session.Session, messages []types.Message) {
+	b.session = s
+	b.buffers = make(map[string]string)
+	b.alwaysAllow = make(map[string]bool)
+	b.edits = make(map[int]Edit)
+	if err := b.Agent.UseScratchDir(b.session.ID); err != nil {
+		b.Logger.Log(fmt.Sprintf("Error: %v", err))
+	}
+	b.Agent.UseTrash(b.session.ID)
+	b.Agent.SetTasks(s.Tasks)
+	b.messages = messages
+	if len(b.messages) == 0 && b.SystemPrompt != "" {
+		b.messages = []types.Message{{Role: "system", Content: b.SystemPrompt}}
+	}
+}
+
+// SessionID returns the ID the conversation is saved under.
+func (b *Bridge) SessionID() string {
+	return b.session.ID
+}
+
+// Messages returns a copy of the conversation.
+func (b *Bridge) Messages() []types.Message {
+	return slices.Clone(b.messages)
+}
+
+// PendingCall returns the tool call waiting for approval, or nil.
+func (b *Bridge) PendingCall() *Pending {
+	return b.pending
+}
+
+// Handle answers one request:
+//
+//   - initialize: the model and session ID
+//   - open {"path", "content"}: attach an editor buffer to later prompts
+//   - close {"path"}: detach it again
+//   - chat {"prompt"}: ask the agent, returns a Turn
+//   - approve {"allow", "always"}: decide on the pending tool call, returns
+//     the next Turn
+//   - edit {"path", "instruction", "start_line", "end_line"}: propose a
+//     change to a buffer or file, or to a range of its lines, as an Edit
+//   - apply {"edit_id", "force"}: write a proposed edit to disk
+func (b *Bridge) Handle(method string, params json.RawMessage) (interface{}, error) {
+	ctx := context.Background()
+	switch method {
+	case "initialize":
+		return map[string]string{"model": b.Model, "session": b.session.ID}, nil
+
+	case "open":
+		var args struct {
+			Path    string  `json:"path"`
+			Content *string `json:"content"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Path == "" || args.Content == nil {
+			return nil, rpc.InvalidParams("path and content are required")
+		}
+		b.buffers[args.Path] = *args.Content
+		return map[string][]string{"buffers": b.bufferPaths()}, nil
+
+	case "close":
+		var args struct {
+			Path string `json:"path"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, err
+		}
+		delete(b.buffers, args.Path)
+		return map[string][]string{"buffers": b.bufferPaths()}, nil
+
+	case "chat":
+		var args struct {
+			Prompt string `json:"prompt"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Prompt == "" {
+			return nil, rpc.InvalidParams("prompt is required")
+		}
+		if b.pending != nil {
+			return nil, fmt.Errorf("the %s call is waiting for approval", b.pending.Tool)
+		}
+		b.add(types.Message{Role: "user", Content: args.Prompt + b.bufferContext()})
+		b.steps = 0
+		return b.run(ctx)
+
+	case "approve":
+		var args struct {
+			Allow  bool `json:"allow"`
+			Always bool `json:"always"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, err
+		}
+		return b.approve(ctx, args.Allow, args.Always)
+
+	case "edit":
+		var args struct {
+			Path        string `json:"path"`
+			Instruction string `json:"instruction"`
+			StartLine   int    `json:"start_line"`
+			EndLine     int    `json:"end_line"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Path == "" || args.Instruction == "" {
+			return nil, rpc.InvalidParams("path and instruction are required")
+		}
+		return b.edit(ctx, args.Path, args.Instruction, args.StartLine, args.EndLine)
+
+	case "apply":
+		var args struct {
+			EditID int  `json:"edit_id"`
+			Force  bool `json:"force"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, err
+		}
+		return b.apply(args.EditID, args.Force)
+	}
+	return nil, rpc.MethodNotFound(method)
+}
+
+// requestMessages returns the history sent to the model, without messages
+// that are only shown or were left out with /forget.
+func (b *Bridge) requestMessages() []types.Message {
+	return slices.DeleteFunc(slices.Clone(b.messages), func(msg types.Message) bool {
+		return msg.Local || msg.Excluded
+	})
+}
+
+// run continues the conversation until the model answers, a tool call
+// needs approval or the step limit is reached.
+func (b *Bridge) run(ctx context.Context) (Turn, error) {
+	maxSteps := b.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxSteps
+	}
+	defer b.save()
+	for {
+		resp, err := b.Client.Chat(ctx, b.Model, b.requestMessages(), b.Options)
+		if err != nil {
+			return Turn{Steps: b.steps}, err
+		}
+		reply := resp.Message
+		reply.Role = "assistant"
+
+		action := runner.ParseAction(reply)
+		if action == nil {
+			b.add(reply)
+			return Turn{Answer: reply.Content, Steps: b.steps}, nil
+		}
+		if action.Tool == "respond" {
+			answer := runner.RespondMessage(action.Input)
+			b.add(types.Message{Role: "assistant", Content: answer})
+			return Turn{Answer: answer, Steps: b.steps}, nil
+		}
+
+		reply.Content = ""
+		reply.ToolCalls = []types.ToolCall{{Function: types.FunctionCall{Name: action.Tool, Arguments: action.Input}}}
+		b.add(reply)
+		if b.steps >= maxSteps {
+			return Turn{Steps: b.steps}, fmt.Errorf("stopped after %d tool calls without a final answer", maxSteps)
+		}
+
+		// Like the TUI, calls that would overwrite changes made since the
+		// agent read the file always ask.
+		conflict := b.Agent.WriteConflict(action.Tool, action.Input)
+		if conflict != "" || b.Agent.NeedsPermission(action.Tool, action.Input) && !b.alwaysAllow[b.permissionKey(action)] {
+			b.pending = &Pending{Tool: action.Tool, Input: action.Input, Conflict: conflict}
+			return Turn{Steps: b.steps, Pending: b.pending}, nil
+		}
+		b.execute(action.Tool, action.Input)
+	}
+}
+
+// approve runs or denies the pending tool call and lets the model go on.
+func (b *Bridge) approve(ctx context.Context, allow, always bool) (Turn, error) {
+	call := b.pending
+	if call == nil {
+		return Turn{}, fmt.Errorf("no tool call is waiting for approval")
+	}
+	b.pending = nil
+	if !allow {
+		result := agent.ErrorResult(call.Tool, types.ErrorPermission, fmt.Sprintf("Error: %s was denied by the user.", call.Tool))
+		b.add(types.Message{Role: "tool", Content: result.String(), Result: &result})
+		return b.run(ctx)
+	}
+	if always {
+		if key := b.permissionKey(&types.Action{Tool: call.Tool, Input: call.Input}); key != "" {
+			b.alwaysAllow[key] = true
+		}
+	}
+	b.Agent.AcceptChanges(call.Tool, call.Input)
+	b.execute(call.Tool, call.Input)
+	return b.run(ctx)
+}
+
+// add appends a message to the conversation.
+func (b *Bridge) add(msg types.Message) {
+	b.messages = append(b.messages, msg)
+	if b.OnChange != nil {
+		b.OnChange(slices.Clone(b.messages))
+	}
+}
+
+// execute runs a tool call and adds its result to the conversation.
+func (b *Bridge) execute(tool string, input map[string]interface{}) {
+	b.steps++
+	result := b.Agent.ExecuteTool(tool, input)
+	b.add(types.Message{Role: "tool", Content: result.String(), Result: &result})
+}
+
+// permissionKey identifies a tool and file for "always allow", as in the
+// TUI.
+func (b *Bridge) permissionKey(action *types.Action) string {
+	path, ok := action.Input["path"].(string)
+	if !ok {
+		return ""
+	}
+	if resolved, err := b.Agent.ResolvePath(path); err == nil {
+		path = b.Agent.DisplayPath(resolved)
+	}
+	return fmt.Sprintf("%s:%s", action.Tool, path)
+}
+
+// edit asks the model for a change to a file, or to lines start to end of
+// it, and returns it as a diff to review.
+func (b *Bridge) edit(ctx context.Context, path, instruction string, start, end int) (Edit, error) {
+	content, ok := b.buffers[path]
+	if !ok {
+		data, err := b.Agent.ReadWorkspaceFile(path)
+		if err != nil {
+			return Edit{}, err
+		}
+		content = string(data)
+	}
+
+	lines := diff.Lines(content)
+	var before, selection, after []string
+	if start > 0 || end > 0 {
+		if start < 1 || end < start || end > len(lines) {
+			return Edit{}, rpc.InvalidParams("lines %d-%d are outside %s (%d lines)", start, end, path, len(lines))
+		}
+		before, selection, after = lines[:start-1], lines[start-1:end], lines[end:]
+	} else {
+		selection = lines
+	}
+
+	prompt := fmt.Sprintf("File: %s\n```\n%s\n```\n\n%s", path, content, instruction)
+	if len(before)+len(after) > 0 {
+		prompt = fmt.Sprintf("File: %s\n```\n%s\n```\n\nRewrite only lines %d-%d:\n```\n%s\n```\n\n%s\n\nReply with the new version of these lines only.",
+			path, content, start, end, strings.Join(selection, "\n"), instruction)
+	}
+	messages := []types.Message{{Role: "system", Content: editPrompt}, {Role: "user", Content: prompt}}
+	resp, err := b.Client.Chat(ctx, b.Model, messages, b.Options)
+	if err != nil {
+		return Edit{}, err
+	}
+	replacement := resp.Message.Content
+	if match := fencedBlock.FindStringSubmatch(replacement); match != nil {
+		replacement = match[1]
+	}
+
+	newLines := append(append(append([]string{}, before...), diff.Lines(replacement)...), after...)
+	newContent := strings.Join(newLines, "\n")
+	if strings.HasSuffix(content, "\n") || content == "" {
+		newContent += "\n"
+	}
+	b.nextEdit++
+	proposed := Edit{ID: b.nextEdit, Path: path, Diff: diff.Unified(path, content, newContent), Content: newContent}
+	b.edits[proposed.ID] = proposed
+	return proposed, nil
+}
+
+// apply writes a proposed edit with write_file. It refuses if the file
+// changed since the agent last read or wrote it, unless force is set.
+func (b *Bridge) apply(id int, force bool) (types.ToolResult, error) {
+	proposed, ok := b.edits[id]
+	if !ok {
+		return types.ToolResult{}, rpc.InvalidParams("unknown edit_id %d", id)
+	}
+	input := map[string]interface{}{"path": proposed.Path, "content": proposed.Content}
+	if conflict := b.Agent.WriteConflict("write_file", input); conflict != "" {
+		if !force {
+			return types.ToolResult{}, fmt.Errorf("%s; pass force to overwrite", conflict)
+		}
+		b.Agent.AcceptChanges("write_file", input)
+	}
+	result := b.Agent.ExecuteTool("write_file", input)
+	if !result.Failed() {
+		delete(b.edits, id)
+		b.buffers[proposed.Path] = proposed.Content
+	}
+	return result, nil
+}
+
+// bufferContext formats the open buffers for a prompt, like @ mentions in
+// the TUI.
+func (b *Bridge) bufferContext() string {
+	var builder strings.Builder
+	for _, path := range b.bufferPaths() {
+		builder.WriteString(fmt.Sprintf("\n\n---\nFile: %s\n```\n%s\n```\n", path, b.buffers[path]))
+	}
+	return builder.String()
+}
+
+func (b *Bridge) bufferPaths() []string {
+	paths := make([]string, 0, len(b.buffers))
+	for path := range b.buffers {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// save stores the conversation as a session, so it can be resumed in the
+// TUI or exported.
+func (b *Bridge) save() {
+	b.session.Messages = b.messages
+	b.session.Tasks = b.Agent.Tasks()
+	if err := b.session.Save(); err != nil {
+		b.Logger.Log(fmt.Sprintf("Error saving session: %v", err))
+	}
+}
@@ -4,6 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/persona"
+	"prompt-cli/internal/postprocess"
+	"prompt-cli/internal/types"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 // Config holds the application configuration
@@ -13,6 +22,125 @@ type Config struct {
 	DefaultLLM       string `json:"default_llm"`
 	LogEnabled       bool   `json:"log_enabled,omitempty"`
 	ContextLength    int64  `json:"context_length,omitempty"`
+	// WorkspaceRoots maps a root name to a directory. When set, file tools
+	// address paths as "name:path" and cannot reach outside these roots.
+	WorkspaceRoots map[string]string `json:"workspace_roots,omitempty"`
+	// Editor is the command used by Ctrl+E and /open. Defaults to $VISUAL or $EDITOR.
+	Editor string `json:"editor,omitempty"`
+	// ToolTimeoutMs is the default time limit for a single tool call and
+	// ToolTimeoutsMs overrides it per tool name (e.g. "git", "visit_url").
+	ToolTimeoutMs  int            `json:"tool_timeout_ms,omitempty"`
+	ToolTimeoutsMs map[string]int `json:"tool_timeouts_ms,omitempty"`
+	// StallTimeoutMs is how long a streamed reply may go without a chunk
+	// before the user is offered to retry or cancel it. Negative turns the
+	// check off.
+	StallTimeoutMs int `json:"stall_timeout_ms,omitempty"`
+	// Personas adds or overrides the presets selectable with /persona.
+	Personas map[string]persona.Persona `json:"personas,omitempty"`
+	// Presets adds or overrides the option sets selectable with /preset,
+	// e.g. {"precise": {"temperature": 0.1}}.
+	Presets map[string]types.Options `json:"presets,omitempty"`
+	// Locale selects the UI language, e.g. "de" or "es". Detected from the
+	// environment when empty.
+	Locale string `json:"locale,omitempty"`
+	// Accessible enables the screen-reader friendly output mode.
+	Accessible bool `json:"accessible,omitempty"`
+	// Inline renders without the alternate screen or mouse capture and
+	// prints finished messages to the terminal's scrollback.
+	Inline bool `json:"inline,omitempty"`
+	// TTSCommand receives finished responses on stdin when /speak is on,
+	// e.g. "say" or "piper --model en_US-amy-medium.onnx --output-raw | aplay -r 22050 -f S16_LE".
+	TTSCommand string `json:"tts_command,omitempty"`
+	// STTCommand records audio until interrupted and prints the transcript,
+	// e.g. a script wrapping arecord and whisper.cpp. Used by Ctrl+T.
+	STTCommand string `json:"stt_command,omitempty"`
+	// ChatPrompt is the system prompt used when agent mode is off
+	// (-chatonly or /agent off). Prompt.MD is only sent in agent mode.
+	ChatPrompt string `json:"chat_prompt,omitempty"`
+	// SSHWorkspace runs the file tools and git on a remote host instead of
+	// the local disk.
+	SSHWorkspace *agent.SSHConfig `json:"ssh_workspace,omitempty"`
+	// Docker runs shell commands (/run) in a container built from this image
+	// with the workspace mounted, for isolation in YOLO mode.
+	Docker *agent.DockerConfig `json:"docker,omitempty"`
+	// Shell runs /run, the hooks, the speech commands and the shell tool:
+	// "auto" (default) detects it, or e.g. "bash", "pwsh" or "cmd".
+	Shell string `json:"shell,omitempty"`
+	// OptionalTools enables opt-in tools such as "kubectl" and "docker".
+	OptionalTools []string `json:"optional_tools,omitempty"`
+	// GitHub and GitLab configure the repositories used by the github and
+	// gitlab optional tools.
+	GitHub *agent.ForgeConfig `json:"github,omitempty"`
+	GitLab *agent.ForgeConfig `json:"gitlab,omitempty"`
+	// Jira and Linear configure the trackers read by the ticket optional tool.
+	Jira   *agent.JiraConfig   `json:"jira,omitempty"`
+	Linear *agent.LinearConfig `json:"linear,omitempty"`
+	// Search sets the default result count and per-domain cap for web_search.
+	Search *agent.SearchConfig `json:"search,omitempty"`
+	// Fetch sets the user agent, robots.txt handling and rate limits of the
+	// web tools.
+	Fetch *agent.FetchConfig `json:"fetch,omitempty"`
+	// RespondLanguage asks the model to answer in this language, e.g.
+	// "German", and is the default target of /translate.
+	RespondLanguage string `json:"respond_language,omitempty"`
+	// PostProcessors overrides the reply clean-up steps per model family,
+	// e.g. {"qwen3": ["special_tokens", "think_tags"]}. The "default" entry
+	// applies to families without a built-in or configured pipeline.
+	PostProcessors map[string][]string `json:"post_processors,omitempty"`
+	// PurgeTrashOnExit permanently deletes the files the agent deleted when
+	// the session ends. By default they stay in .promptcli/trash.
+	PurgeTrashOnExit bool `json:"purge_trash_on_exit,omitempty"`
+	// ReadAllMaxBytes caps the output of read_all_files. Files past the
+	// cap are listed with their size instead. Zero uses 256 KiB.
+	ReadAllMaxBytes int `json:"read_all_max_bytes,omitempty"`
+	// DownloadMaxBytes caps the files download_file saves. Zero uses 50 MiB.
+	DownloadMaxBytes int `json:"download_max_bytes,omitempty"`
+	// Timestamps shows the time of each message in its header: "off"
+	// (default), "absolute" or "relative". /timestamps switches it.
+	Timestamps string `json:"timestamps,omitempty"`
+	// TranscriptLog appends every message with a timestamp to a JSONL file
+	// per session in the sessions folder, independent of the debug log.
+	TranscriptLog bool `json:"transcript_log,omitempty"`
+	// Validators check files after write_file and append_file, by
+	// extension, e.g. {".go": "gofmt -l", ".json": "jq empty"}. The file's
+	// path is appended and a non-zero exit fails the check. Go, JSON and
+	// YAML files are parsed without a configured validator.
+	Validators map[string]string `json:"validators,omitempty"`
+	// Formatters rewrite files after write_file and append_file, by
+	// extension, e.g. {".go": "gofmt -w", ".py": "black -q"}. The changes
+	// are shown to the model as a diff.
+	Formatters map[string]string `json:"formatters,omitempty"`
+	// Hooks run scripts before or after tool calls. A "before" hook that
+	// fails blocks the call; the output of an "after" hook is added to the
+	// result.
+	Hooks []agent.HookConfig `json:"hooks,omitempty"`
+	// HideThoughts leaves the "thoughts" of earlier agent replies out of
+	// requests, keeping only their actions and the tool results.
+	HideThoughts bool `json:"hide_thoughts,omitempty"`
+	// MaxTranscriptMessages caps the messages kept in memory and rendered.
+	// Older ones are moved to the session's archive file and can be brought
+	// back with /older. Zero keeps everything.
+	MaxTranscriptMessages int `json:"max_transcript_messages,omitempty"`
+	// LowMemory is "on", "off" or "auto" (default), which turns the
+	// lightweight mode on in Termux and on machines with less than 2 GiB of
+	// RAM. It renders plain text instead of Markdown, keeps fewer messages
+	// and caches no tool results.
+	LowMemory string `json:"low_memory,omitempty"`
+	// AutomationSocket is the path of a Unix socket on which the running
+	// TUI accepts JSON-RPC requests from scripts and editors. Empty
+	// disables it.
+	AutomationSocket string `json:"automation_socket,omitempty"`
+	// Critic has a model review every answer and proposed file change
+	// before it is final. Personas can set their own critic.
+	Critic *persona.Critic `json:"critic,omitempty"`
+	// PlannerModel writes the step list of /plan and ExecutorModel, usually
+	// a smaller and faster model, carries out each step with tools. Both
+	// default to the current model.
+	PlannerModel  string `json:"planner_model,omitempty"`
+	ExecutorModel string `json:"executor_model,omitempty"`
+	// InjectionGuard controls how web, tracker and untrusted file content is
+	// fenced off from the model's instructions.
+	InjectionGuard *agent.GuardConfig `json:"injection_guard,omitempty"`
 }
 
 // LoadConfig loads the configuration from the specified file path
@@ -49,6 +177,22 @@ func LoadConfig(path string) (*Config, error) {
 	if config.ContextLength == 0 {
 		config.ContextLength = 8192 // Default context length
 	}
+	if config.ToolTimeoutMs == 0 {
+		config.ToolTimeoutMs = 30000 // Default tool timeout of 30 seconds
+	}
+	if config.StallTimeoutMs == 0 {
+		config.StallTimeoutMs = 120000 // Loading a large model can take a while
+	}
+	if config.ToolTimeoutsMs == nil {
+		config.ToolTimeoutsMs = map[string]int{"git": 5000} // git used to have its own 5 second limit
+	}
+	if config.ChatPrompt == "" {
+		config.ChatPrompt = "You are a helpful assistant."
+	}
+	config.resolveLowMemory()
+	if err := config.resolveSecrets(); err != nil {
+		return nil, err
+	}
 
 	return config, nil
 }
@@ -64,5 +208,106 @@ func ValidateConfig(config *Config) error {
 	if config.ContextLength <= 0 {
 		return fmt.Errorf("context length must be greater than 0")
 	}
+	if config.ToolTimeoutMs < 0 {
+		return fmt.Errorf("tool timeout must not be negative")
+	}
+	for tool, ms := range config.ToolTimeoutsMs {
+		if ms <= 0 {
+			return fmt.Errorf("timeout for tool %q must be greater than 0", tool)
+		}
+	}
+	for name, dir := range config.WorkspaceRoots {
+		if name == "" || strings.ContainsAny(name, ":/\\") {
+			return fmt.Errorf("invalid workspace root name %q", name)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("workspace root %q is not a directory: %s", name, dir)
+		}
+	}
+	if ssh := config.SSHWorkspace; ssh != nil {
+		if ssh.Host == "" {
+			return fmt.Errorf("ssh workspace host cannot be empty")
+		}
+		if !path.IsAbs(ssh.Dir) {
+			return fmt.Errorf("ssh workspace dir must be an absolute path on the host: %q", ssh.Dir)
+		}
+		if len(config.WorkspaceRoots) > 0 {
+			return fmt.Errorf("ssh_workspace cannot be combined with workspace_roots")
+		}
+	}
+	for i, hook := range config.Hooks {
+		if hook.When != "before" && hook.When != "after" {
+			return fmt.Errorf("hook %d: \"when\" must be \"before\" or \"after\", not %q", i+1, hook.When)
+		}
+		if strings.TrimSpace(hook.Command) == "" {
+			return fmt.Errorf("hook %d: command cannot be empty", i+1)
+		}
+		for _, pattern := range hook.Paths {
+			if !doublestar.ValidatePattern(pattern) {
+				return fmt.Errorf("hook %d: invalid path pattern %q", i+1, pattern)
+			}
+		}
+	}
+	for _, name := range config.OptionalTools {
+		if !slices.Contains(agent.OptionalToolNames(), name) {
+			return fmt.Errorf("unknown optional tool %q (available: %s)", name, strings.Join(agent.OptionalToolNames(), ", "))
+		}
+	}
+	if search := config.Search; search != nil && (search.MaxResults < 0 || search.PerDomain < 0) {
+		return fmt.Errorf("search max_results and per_domain cannot be negative")
+	}
+	if fetch := config.Fetch; fetch != nil && (fetch.DomainIntervalMs < 0 || fetch.MaxConcurrent < 0) {
+		return fmt.Errorf("fetch domain_interval_ms and max_concurrent cannot be negative")
+	}
+	if guard := config.InjectionGuard; guard != nil {
+		for _, pattern := range guard.UntrustedPaths {
+			if !doublestar.ValidatePattern(pattern) {
+				return fmt.Errorf("invalid untrusted_paths pattern '%s'", pattern)
+			}
+		}
+	}
+	for family, steps := range config.PostProcessors {
+		if _, err := postprocess.Build(steps); err != nil {
+			return fmt.Errorf("post_processors.%s: %w", family, err)
+		}
+	}
+	if config.ReadAllMaxBytes < 0 {
+		return fmt.Errorf("read_all_max_bytes cannot be negative")
+	}
+	if config.DownloadMaxBytes < 0 {
+		return fmt.Errorf("download_max_bytes cannot be negative")
+	}
+	switch config.Timestamps {
+	case "", "off", "absolute", "relative":
+	default:
+		return fmt.Errorf("timestamps must be \"off\", \"absolute\" or \"relative\", not %q", config.Timestamps)
+	}
+	switch config.LowMemory {
+	case "", "auto", "on", "off":
+	default:
+		return fmt.Errorf("low_memory must be \"on\", \"off\" or \"auto\", not %q", config.LowMemory)
+	}
+	if config.MaxTranscriptMessages < 0 {
+		return fmt.Errorf("max_transcript_messages cannot be negative")
+	}
+	if config.Jira != nil && config.Jira.BaseURL == "" {
+		return fmt.Errorf("jira base_url cannot be empty")
+	}
+	if config.Shell != "" && config.Shell != "auto" && !slices.Contains(agent.ShellNames(), config.Shell) {
+		return fmt.Errorf("unknown shell %q (available: auto, %s)", config.Shell, strings.Join(agent.ShellNames(), ", "))
+	}
+	if docker := config.Docker; docker != nil {
+		if docker.Image == "" {
+			return fmt.Errorf("docker image cannot be empty")
+		}
+		switch docker.Mount {
+		case "", "rw", "ro", "none":
+		default:
+			return fmt.Errorf("docker mount must be \"rw\", \"ro\" or \"none\", not %q", docker.Mount)
+		}
+		if config.SSHWorkspace != nil {
+			return fmt.Errorf("docker cannot be combined with ssh_workspace")
+		}
+	}
 	return nil
 }
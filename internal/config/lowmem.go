@@ -0,0 +1,66 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// lowMemoryLimitKB is the total RAM below which "auto" turns the low-memory
+// mode on.
+const lowMemoryLimitKB = 2 << 20
+
+// Defaults lowered by the low-memory mode, unless set in config.json.
+const (
+	lowMemoryTranscriptMessages = 40
+	lowMemoryReadAllBytes       = 64 << 10
+)
+
+// LowMemoryMode reports whether the lightweight mode for phones and small
+// boards is on.
+func (c *Config) LowMemoryMode() bool {
+	return c.LowMemory == "on"
+}
+
+// resolveLowMemory turns "auto" (the default) into "on" or "off" and lowers
+// the memory-related defaults when the mode is on.
+func (c *Config) resolveLowMemory() {
+	if c.LowMemory == "" || c.LowMemory == "auto" {
+		c.LowMemory = "off"
+		if smallDevice() {
+			c.LowMemory = "on"
+		}
+	}
+	if !c.LowMemoryMode() {
+		return
+	}
+	if c.MaxTranscriptMessages == 0 {
+		c.MaxTranscriptMessages = lowMemoryTranscriptMessages
+	}
+	if c.ReadAllMaxBytes == 0 {
+		c.ReadAllMaxBytes = lowMemoryReadAllBytes
+	}
+}
+
+// smallDevice reports whether we run in Termux or on a machine with little
+// RAM, as far as /proc/meminfo tells.
+func smallDevice() bool {
+	if os.Getenv("TERMUX_VERSION") != "" || strings.Contains(os.Getenv("PREFIX"), "com.termux") {
+		return true
+	}
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.Atoi(fields[1])
+			return err == nil && kb < lowMemoryLimitKB
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"prompt-cli/internal/secrets"
+)
+
+// resolveSecrets replaces the tokens written as "keyring:<name>" with the
+// secrets stored by "prompt-cli config set-secret", so they do not have to
+// be kept in config.json as plain text.
+func (c *Config) resolveSecrets() error {
+	tokens := map[string]*string{}
+	if c.GitHub != nil {
+		tokens["github"] = &c.GitHub.Token
+	}
+	if c.GitLab != nil {
+		tokens["gitlab"] = &c.GitLab.Token
+	}
+	if c.Jira != nil {
+		tokens["jira"] = &c.Jira.Token
+	}
+	if c.Linear != nil {
+		tokens["linear"] = &c.Linear.Token
+	}
+	for section, token := range tokens {
+		value, err := secrets.Resolve(*token)
+		if err != nil {
+			return fmt.Errorf("%s token: %w", section, err)
+		}
+		*token = value
+	}
+	return nil
+}
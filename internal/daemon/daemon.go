@@ -0,0 +1,258 @@
+// Package daemon keeps sessions running in a background process that
+// terminals attach to, so a conversation can be followed and continued from
+// several windows, or picked up again after the terminal was closed.
+//
+// Each session is a bridge.Bridge. Requests to it are serialized, while
+// "wait" lets every attached terminal follow its progress.
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"prompt-cli/internal/bridge"
+	"prompt-cli/internal/rpc"
+	"prompt-cli/internal/session"
+	"prompt-cli/internal/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxWait caps how long a "wait" request blocks.
+const maxWait = 60 * time.Second
+
+// Daemon hosts the sessions. NewBridge creates the bridge of a new or
+// resumed session, with its own agent.
+type Daemon struct {
+	NewBridge func(model string) *bridge.Bridge
+
+	mu       sync.Mutex
+	sessions map[string]*hosted
+}
+
+// hosted is a session held by the daemon.
+type hosted struct {
+	turn   sync.Mutex // Held while the bridge handles a request.
+	bridge *bridge.Bridge
+
+	mu       sync.Mutex
+	version  int           // Incremented on every change.
+	changed  chan struct{} // Closed and replaced on every change.
+	messages []types.Message
+	pending  *bridge.Pending
+	busy     bool
+	clients  int // Attached terminals.
+}
+
+// Snapshot is the state of a session as returned by "wait".
+type Snapshot struct {
+	Session  string          `json:"session"`
+	Version  int             `json:"version"`
+	From     int             `json:"from"`
+	Messages []types.Message `json:"messages"`
+	Pending  *bridge.Pending `json:"pending,omitempty"`
+	Busy     bool            `json:"busy"`
+}
+
+// Info describes a hosted session for "sessions".
+type Info struct {
+	ID       string `json:"id"`
+	Model    string `json:"model"`
+	Messages int    `json:"messages"`
+	Busy     bool   `json:"busy"`
+	Pending  bool   `json:"pending"`
+	Clients  int    `json:"clients"`
+}
+
+// sessionParams selects the session of a request.
+type sessionParams struct {
+	Session string `json:"session"`
+}
+
+// Handle answers a request:
+//
+//   - sessions: the hosted sessions
+//   - new {"model"}: start a session; resume {"session"}: load a saved one
+//   - attach / detach {"session"}: count the terminals following a session
+//   - wait {"session", "since", "version", "timeout_ms"}: block until the
+//     session changes from version, then return the messages from index
+//     since on
+//   - chat, approve, open, close, edit, apply with a "session": passed to
+//     the session's bridge
+func (d *Daemon) Handle(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "sessions":
+		return d.list(), nil
+
+	case "new":
+		var args struct {
+			Model string `json:"model"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, err
+		}
+		b := d.NewBridge(args.Model)
+		b.Start()
+		return d.host(b), nil
+
+	case "resume":
+		var args sessionParams
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, err
+		}
+		if h, ok := d.get(args.Session); ok {
+			return h.info(), nil
+		}
+		saved, err := session.Load(args.Session)
+		if err != nil {
+			return nil, err
+		}
+		b := d.NewBridge(saved.Model)
+		b.Resume(saved)
+		return d.host(b), nil
+	}
+
+	var args sessionParams
+	if err := rpc.DecodeParams(params, &args); err != nil {
+		return nil, err
+	}
+	h, ok := d.get(args.Session)
+	if !ok {
+		return nil, rpc.InvalidParams("no session %q in the daemon", args.Session)
+	}
+	switch method {
+	case "attach", "detach":
+		h.mu.Lock()
+		if method == "attach" {
+			h.clients++
+		} else if h.clients > 0 {
+			h.clients--
+		}
+		h.mu.Unlock()
+		h.notify()
+		return h.info(), nil
+
+	case "wait":
+		var wait struct {
+			Since     int `json:"since"`
+			Version   int `json:"version"`
+			TimeoutMs int `json:"timeout_ms"`
+		}
+		if err := rpc.DecodeParams(params, &wait); err != nil {
+			return nil, err
+		}
+		timeout := min(time.Duration(wait.TimeoutMs)*time.Millisecond, maxWait)
+		if timeout <= 0 {
+			timeout = maxWait
+		}
+		return h.wait(wait.Since, wait.Version, timeout), nil
+	}
+
+	h.turn.Lock()
+	defer h.turn.Unlock()
+	h.setBusy(true)
+	result, err := h.bridge.Handle(method, params)
+	h.mu.Lock()
+	h.pending = h.bridge.PendingCall()
+	h.busy = false
+	h.mu.Unlock()
+	h.notify()
+	return result, err
+}
+
+// host adds a bridge to the hosted sessions.
+func (d *Daemon) host(b *bridge.Bridge) Info {
+	h := &hosted{bridge: b, changed: make(chan struct{}), messages: b.Messages()}
+	b.OnChange = func(messages []types.Message) {
+		h.mu.Lock()
+		h.messages = messages
+		h.mu.Unlock()
+		h.notify()
+	}
+	d.mu.Lock()
+	if d.sessions == nil {
+		d.sessions = make(map[string]*hosted)
+	}
+	d.sessions[b.SessionID()] = h
+	d.mu.Unlock()
+	return h.info()
+}
+
+func (d *Daemon) get(id string) (*hosted, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	h, ok := d.sessions[id]
+	return h, ok
+}
+
+// list returns the hosted sessions, oldest first.
+func (d *Daemon) list() []Info {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	infos := make([]Info, 0, len(d.sessions))
+	for _, h := range d.sessions {
+		infos = append(infos, h.info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+func (h *hosted) info() Info {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return Info{
+		ID:       h.bridge.SessionID(),
+		Model:    h.bridge.Model,
+		Messages: len(h.messages),
+		Busy:     h.busy,
+		Pending:  h.pending != nil,
+		Clients:  h.clients,
+	}
+}
+
+func (h *hosted) setBusy(busy bool) {
+	h.mu.Lock()
+	h.busy = busy
+	h.mu.Unlock()
+	h.notify()
+}
+
+// notify wakes the waiting terminals.
+func (h *hosted) notify() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.version++
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+// wait returns once the session is past version, or after timeout.
+func (h *hosted) wait(since, version int, timeout time.Duration) Snapshot {
+	h.mu.Lock()
+	changed := h.changed
+	current := h.version
+	h.mu.Unlock()
+	if current == version {
+		select {
+		case <-changed:
+		case <-time.After(timeout):
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	since = max(0, min(since, len(h.messages)))
+	return Snapshot{
+		Session:  h.bridge.SessionID(),
+		Version:  h.version,
+		From:     since,
+		Messages: h.messages[since:],
+		Pending:  h.pending,
+		Busy:     h.busy,
+	}
+}
+
+// String describes the session for log lines.
+func (i Info) String() string {
+	return fmt.Sprintf("%s (%s, %d messages)", i.ID, i.Model, i.Messages)
+}
@@ -0,0 +1,216 @@
+// Package eval runs suites of prompts against one or more models and checks
+// the answers, so prompt and model changes can be regression-tested.
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"prompt-cli/internal/ollama"
+	"prompt-cli/internal/types"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a YAML-defined set of test cases.
+type Suite struct {
+	Models     []string `yaml:"models"`      // Models to run when none are given on the command line.
+	System     string   `yaml:"system"`      // System prompt text.
+	SystemFile string   `yaml:"system_file"` // Or a file holding it, relative to the suite.
+	JudgeModel string   `yaml:"judge_model"` // Model used for judge checks; defaults to the model under test.
+	Cases      []Case   `yaml:"cases"`
+}
+
+// Case is a single prompt and the checks its answer must pass.
+type Case struct {
+	Name   string `yaml:"name"`
+	Prompt string `yaml:"prompt"`
+	Expect Expect `yaml:"expect"`
+}
+
+// Expect lists the checks for a case. All of them must pass.
+type Expect struct {
+	Contains    []string `yaml:"contains"`     // Substrings that must appear (case-insensitive).
+	NotContains []string `yaml:"not_contains"` // Substrings that must not appear (case-insensitive).
+	Regex       []string `yaml:"regex"`        // Patterns that must match.
+	Judge       string   `yaml:"judge"`        // Criterion an LLM judge must confirm.
+}
+
+// Result is the outcome of one case against one model.
+type Result struct {
+	Model    string
+	Case     string
+	Passed   bool
+	Failures []string
+	Latency  time.Duration
+	Answer   string
+	Err      error
+}
+
+// Summary aggregates the results for one model.
+type Summary struct {
+	Model       string
+	Passed      int
+	Total       int
+	MeanLatency time.Duration
+}
+
+// LoadSuite reads and validates a suite file.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var suite Suite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if suite.SystemFile != "" {
+		systemPath := suite.SystemFile
+		if !filepath.IsAbs(systemPath) {
+			systemPath = filepath.Join(filepath.Dir(path), systemPath)
+		}
+		content, err := os.ReadFile(systemPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading system_file: %w", err)
+		}
+		suite.System = string(content)
+	}
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("%s has no cases", path)
+	}
+	for i, c := range suite.Cases {
+		if c.Prompt == "" {
+			return nil, fmt.Errorf("case %d (%s) has no prompt", i+1, c.Name)
+		}
+		if c.Name == "" {
+			suite.Cases[i].Name = fmt.Sprintf("case-%d", i+1)
+		}
+		for _, pattern := range c.Expect.Regex {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("case %s: invalid regex %q: %w", c.Name, pattern, err)
+			}
+		}
+	}
+	return &suite, nil
+}
+
+// Run executes every case against every model, calling report after each
+// result so progress can be shown while the suite runs.
+func Run(ctx context.Context, client *ollama.OllamaClient, suite *Suite, models []string, report func(Result)) []Result {
+	var results []Result
+	for _, model := range models {
+		for _, c := range suite.Cases {
+			result := runCase(ctx, client, suite, model, c)
+			if report != nil {
+				report(result)
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// Summarize groups results per model, in the order the models were run.
+func Summarize(results []Result) []Summary {
+	var summaries []Summary
+	index := map[string]int{}
+	totals := map[string]time.Duration{}
+	for _, r := range results {
+		i, ok := index[r.Model]
+		if !ok {
+			i = len(summaries)
+			index[r.Model] = i
+			summaries = append(summaries, Summary{Model: r.Model})
+		}
+		summaries[i].Total++
+		if r.Passed {
+			summaries[i].Passed++
+		}
+		totals[r.Model] += r.Latency
+	}
+	for i := range summaries {
+		summaries[i].MeanLatency = totals[summaries[i].Model] / time.Duration(summaries[i].Total)
+	}
+	return summaries
+}
+
+func runCase(ctx context.Context, client *ollama.OllamaClient, suite *Suite, model string, c Case) Result {
+	result := Result{Model: model, Case: c.Name}
+
+	var messages []types.Message
+	if suite.System != "" {
+		messages = append(messages, types.Message{Role: "system", Content: suite.System})
+	}
+	messages = append(messages, types.Message{Role: "user", Content: c.Prompt})
+
+	start := time.Now()
+	resp, err := client.Chat(ctx, model, messages, types.Options{})
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = err
+		result.Failures = append(result.Failures, err.Error())
+		return result
+	}
+	result.Answer = resp.Message.Content
+
+	result.Failures = check(c.Expect, result.Answer)
+	if c.Expect.Judge != "" {
+		judgeModel := suite.JudgeModel
+		if judgeModel == "" {
+			judgeModel = model
+		}
+		if failure := judge(ctx, client, judgeModel, c, result.Answer); failure != "" {
+			result.Failures = append(result.Failures, failure)
+		}
+	}
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+// check applies the deterministic checks and returns a description of each
+// one that failed.
+func check(expect Expect, answer string) []string {
+	var failures []string
+	lower := strings.ToLower(answer)
+	for _, s := range expect.Contains {
+		if !strings.Contains(lower, strings.ToLower(s)) {
+			failures = append(failures, fmt.Sprintf("missing %q", s))
+		}
+	}
+	for _, s := range expect.NotContains {
+		if strings.Contains(lower, strings.ToLower(s)) {
+			failures = append(failures, fmt.Sprintf("unexpected %q", s))
+		}
+	}
+	for _, pattern := range expect.Regex {
+		if !regexp.MustCompile(pattern).MatchString(answer) {
+			failures = append(failures, fmt.Sprintf("no match for /%s/", pattern))
+		}
+	}
+	return failures
+}
+
+// judge asks a model whether the answer meets the case's criterion. It
+// returns an empty string when the judge says it does.
+func judge(ctx context.Context, client *ollama.OllamaClient, model string, c Case, answer string) string {
+	prompt := fmt.Sprintf("You are grading an answer to a prompt.\n\nPrompt:\n%s\n\nAnswer:\n%s\n\nCriterion:\n%s\n\nReply with PASS if the answer meets the criterion, otherwise FAIL, followed by a one-line reason.", c.Prompt, answer, c.Expect.Judge)
+	resp, err := client.Chat(ctx, model, []types.Message{{Role: "user", Content: prompt}}, types.Options{})
+	if err != nil {
+		return fmt.Sprintf("judge error: %v", err)
+	}
+	verdict := strings.TrimSpace(resp.Message.Content)
+	if strings.HasPrefix(strings.ToUpper(verdict), "PASS") {
+		return ""
+	}
+	return "judge: " + firstLine(verdict)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,404 @@
+package i18n
+
+var german = Catalog{
+	// Input and footer
+	"input.placeholder":   "Nachricht senden... (Strg+V zum Einfügen)",
+	"footer.waiting":      "Warte auf Antwort...",
+	"footer.quit_confirm": "Erneut Strg-C drücken, um die Anwendung zu beenden. Esc zum Abbrechen.",
+	"footer.file_search":  "Dateisuche: %s",
+	"footer.no_matches":   "Keine Treffer",
+	"footer.recording":    "● Aufnahme... (Strg+T zum Beenden)",
+	"footer.translating":  "Übersetze...",
+	"footer.planning":     "Plane...",
+	"footer.reviewing":    "Prüfe...",
+	"footer.plan":         "Plan: Schritt %d/%d mit %s",
+	"footer.multiline":    "Mehrzeilig: Alt+Enter sendet",
+	"footer.no_stats":     "Tokens/s: k. A.",
+	"footer.no_context":   "Kontext: k. A.",
+	"footer.chat":         "Chat",
+
+	// Help
+	"help.title":       "Befehle:",
+	"help.new":         "/new - Neue Chat-Sitzung starten",
+	"help.bye":         "/bye - Anwendung beenden",
+	"help.help":        "/help - Diese Hilfe anzeigen",
+	"help.stop":        "/stop - Aktuelle Antwort abbrechen",
+	"help.log":         "/log - Protokollierung in eine Datei umschalten",
+	"help.copy":        "/copy - Letzte Antwort in die Zwischenablage kopieren",
+	"help.explain":     "/explain <Pfad[:Zeile]> - Eine Datei oder die Funktion bzw. den Typ um die Zeile erklären lassen",
+	"help.open":        "/open [N|Pfad] - Datei oder den N-ten Codeblock der letzten Antwort im Editor öffnen",
+	"help.open_full":   "/open-full [N] - Den vollständigen Text der letzten langen Antwort oder von Nachricht N im Editor öffnen",
+	"help.paste_image": "/paste-image - Bild aus der Zwischenablage an die nächste Nachricht anhängen",
+	"help.history":     "/history [Suche] - Eingaben dieser Sitzung für !N anzeigen oder gespeicherte Sitzungen durchsuchen (/history open <N> zum Fortsetzen)",
+	"help.timestamps":  "/timestamps [off|absolute|relative] - Uhrzeit jeder Nachricht in ihrer Kopfzeile anzeigen; ohne Argument zum nächsten Modus wechseln",
+	"help.share":       "/share [Datei] - Das Gespräch als eigenständige HTML-Seite speichern",
+	"help.undo":        "/undo - Das letzte Änderungspaket zurücknehmen oder die zuletzt vom Agenten gelöschte Datei wiederherstellen",
+	"help.sources":     "/sources [N] - Die von Antworten zitierten Webseiten auflisten oder Quelle N im Browser öffnen",
+	"help.translate":   "/translate [Sprache] - Die letzte Antwort übersetzen (Standard: respond_language)",
+	"help.older":       "/older [N] - Die N neuesten archivierten Nachrichten zurückholen (Standard 20)",
+	"help.forget":      "/forget [N...] - Nachrichten auflisten oder Nachricht N aus künftigen Anfragen ausschließen (erneut zum Zurückholen)",
+	"help.model":       "/model [Name] - Ein anderes Modell auswählen oder direkt zum genannten wechseln",
+	"help.pull":        "/pull [Modell] - Ein Modell (Standard: das aktuelle) auf den Ollama-Server herunterladen",
+	"help.ctx":         "/ctx [N|Nk|reset] - Kontextgröße (num_ctx) für diese Sitzung anzeigen oder ändern",
+	"help.run":         "/run [-i] <Befehl> - Shell-Befehl ausführen (-i hängt die Ausgabe an die nächste Nachricht an)",
+	"help.bundle":      "/bundle [save <Name> <Dateien|Globs|URLs>...|load <Name>|delete <Name>] - Benannte Kontext-Bündel verwalten",
+	"help.persona":     "/persona [Name|off] - Personas anzeigen oder wechseln",
+	"help.preset":      "/preset [Name|off] - Options-Presets wie precise oder creative auflisten oder wechseln",
+	"help.json":        "/json <Schema> [Prompt] - Die nächste Antwort auf ein JSON-Schema (Datei oder inline) beschränken; /json off bricht ab",
+	"help.artifacts":   "/artifacts - Vom Agenten geschriebene Dateien mit Änderungen auflisten; öffnen, zurücksetzen oder Pfad kopieren",
+	"help.toolstats":   "/toolstats - Werkzeugaufrufe, Fehlerquoten und Dauer dieser Sitzung anzeigen",
+	"help.agent":       "/agent [on|off] - Zwischen Agent-Modus (Tools) und reinem Chat wechseln",
+	"help.yolo":        "/yolo [files|git|web|shell|all|off] - Tool-Aufrufe in diesen Bereichen automatisch erlauben",
+	"help.permissions": "/permissions [delete <n>] - Gespeicherte Immer-erlauben-Regeln anzeigen oder entfernen",
+	"help.speak":       "/speak - Vorlesen von Antworten umschalten",
+	"help.critic":      "/critic [on|off|auto] - Antworten und Dateiänderungen in einem zweiten Durchgang prüfen lassen",
+	"help.plan":        "/plan <Aufgabe>|next|stop - Das Planungsmodell zerlegt eine Aufgabe in Schritte für das ausführende Modell",
+	"help.send_to":     "/send-to <Sitzung> [Text|@Datei] - Text, eine Datei oder die letzte Antwort an eine andere Sitzung weiterleiten",
+	"help.ctrl_e":      "Strg+E - Eingabe im Editor verfassen",
+	"help.ctrl_t":      "Strg+T - Diktat starten/beenden (Sprache zu Text)",
+	"help.ctrl_l":      "Strg+L - Zuletzt vom Agenten geänderte Datei einfügen (oder @last tippen)",
+	"help.ctrl_o":      "Strg+O - Vorschau der letzten @-Erwähnung und Zeilenbereich zum Senden wählen",
+	"help.ctrl_r":      "Strg+R - Antwort unterbrechen und mit einem Hinweis erneut fragen",
+	"help.fold":        "Esc, dann Enter - Sichtbare Tool-Ausgabe auf-/zuklappen",
+	"help.jump":        "Esc, dann [ / ] / g / G - Zur vorherigen/nächsten Nachricht, zum Anfang oder Ende springen",
+
+	// Permissions
+	"permission.prompt":  "Das Modell möchte folgenden Befehl ausführen:\n\n%s\nFortfahren?",
+	"permission.options": "(A) Einmal erlauben   (Y) Immer erlauben   (N) Nein / Befehl anzeigen",
+	"yolo.enabled":       "YOLO-Modus aktiviert. Alle Befehle werden ohne Nachfrage ausgeführt.",
+	"yolo.disabled":      "YOLO-Modus deaktiviert. Verändernde Befehle erfordern eine Bestätigung.",
+	"yolo.status_off":    "YOLO-Modus ist aus. Jedes verändernde Tool fragt nach Erlaubnis.",
+	"yolo.status":        "YOLO-Bereiche: %s. /yolo <Bereich> schaltet um, /yolo off beendet.",
+	"yolo.scoped":        "YOLO an für: %s. Andere Tools fragen weiterhin nach Erlaubnis.",
+	"yolo.usage":         "Verwendung: /yolo [%s|all|off]...",
+
+	// Status messages
+	"copy.done":          "Letzte Antwort in die Zwischenablage kopiert.",
+	"copy.empty":         "Keine Antwort zum Kopieren.",
+	"error.screen":       "Ein Fehler ist aufgetreten: %v\n\nStrg+C zum Beenden.",
+	"editor.failed":      "Editor fehlgeschlagen: %v",
+	"editor.read_failed": "Editor-Datei konnte nicht gelesen werden: %v",
+	"open.nothing":       "Nichts zu öffnen. /open <Pfad> oder /open <N> für einen Codeblock verwenden.",
+	"open.out_of_range":  "Die letzte Antwort hat %d Codeblock/Codeblöcke; %d liegt außerhalb des Bereichs.",
+	"open.failed":        "'%s' kann nicht geöffnet werden: %v",
+	"open.remote":        "%s kann nicht geöffnet werden: Der Arbeitsbereich liegt auf einem entfernten Host.",
+	"open.temp_failed":   "Temporäre Datei konnte nicht geschrieben werden: %v",
+	"image.failed":       "Kein Bild aus der Zwischenablage lesbar: %v",
+	"image.attached":     "Bild unter %s gespeichert und an die nächste Nachricht angehängt.",
+	"image.attach_error": "Bilder konnten nicht angehängt werden: %v",
+	"history.usage":      "Verwendung: /history <Suche> durchsucht gespeicherte Sitzungen, danach /history open <N>.",
+	"history.open_usage": "Verwendung: /history open <N> mit N zwischen 1 und %d.",
+	"history.open_error": "Sitzung %s konnte nicht geöffnet werden: %v",
+	"history.failed":     "Suche fehlgeschlagen: %v",
+	"history.none":       "Keine gespeicherten Nachrichten passen zu %q.",
+	"history.found":      "%d Treffer für %q:",
+	"history.hint":       "Mit /history open <N> die Sitzung an der passenden Nachricht öffnen.",
+	"run.usage":          "Verwendung: /run [-i] <Befehl>. Mit -i wird die Ausgabe an die nächste Nachricht angehängt.",
+	"run.suggested":      "Vorschläge für dieses Projekt: %s",
+	"persona.title":      "Personas:",
+	"persona.active":     "(aktiv)",
+	"persona.hint":       "Mit /persona <Name> wechseln oder mit /persona off zurücksetzen.",
+	"persona.unknown":    "Unbekannte Persona %q. Verfügbar: %s",
+	"persona.cleared":    "Persona zurückgesetzt.",
+	"persona.set":        "Persona auf %s gesetzt.",
+
+	// Accessible mode
+	"a11y.waiting":          "Warte auf Antwort.",
+	"a11y.done":             "Antwort vollständig.",
+	"a11y.tool_call":        "Assistent fordert Werkzeug %s an.",
+	"a11y.error":            "Fehler",
+	"a11y.permission_keys":  "Bestätigung erforderlich. A für einmal erlauben, Y für immer erlauben, N für ablehnen.",
+	"a11y.focus_input":      "Fokus: Eingabe",
+	"a11y.focus_transcript": "Fokus: Verlauf",
+
+	// Text to speech
+	"speak.enabled":  "Antworten werden vorgelesen mit: %s",
+	"speak.disabled": "Vorlesen deaktiviert.",
+	"speak.failed":   "Sprachausgabe fehlgeschlagen: %v",
+
+	// Dictation
+	"dictation.not_configured": "stt_command in config.json setzen, um das Diktat zu nutzen.",
+	"dictation.failed":         "Diktat fehlgeschlagen: %v",
+
+	// Tool output folding
+	"fold.summary":     "▸ `%s` · %s · %s (Esc, dann Enter zum Aufklappen)",
+	"fold.ok":          "ok",
+	"fold.error":       "Fehler",
+	"fold.error_class": "Fehler: %s",
+	"fold.truncated":   "gekürzt",
+	"fold.formatted":   "formatiert",
+	"fold.stderr":      "stderr:",
+	"fold.cached":      "aus Cache",
+	"fold.suspicious":  "⚠ mögliche Prompt-Injection",
+
+	// Agent mode
+	"agent.on":         "Agent-Modus an: Tools sind verfügbar.",
+	"agent.off":        "Agent-Modus aus: reiner Chat, keine Tool-Anweisungen werden gesendet.",
+	"agent.status_on":  "Agent-Modus ist an. /agent off für reinen Chat.",
+	"agent.status_off": "Agent-Modus ist aus. /agent on aktiviert Tools.",
+	"agent.usage":      "Verwendung: /agent [on|off]",
+
+	// Prompt injection guard
+	"guard.suspicious": "⚠ %s hat anweisungsähnlichen Text geliefert; Inhalt mit Vorsicht behandeln.",
+
+	// Approval queue
+	"batch.title":       "Das Modell möchte %d Tool-Aufrufe ausführen. Prüfe die, die eine Erlaubnis brauchen:",
+	"batch.no_approval": "(keine Freigabe nötig)",
+	"batch.changeset":   "(die Änderungen werden danach geprüft)",
+	"batch.keys":        "↑/↓ bewegen · Leertaste umschalten · A alle erlauben · N alle ablehnen · Enter ausführen · Esc ablehnen und stoppen",
+
+	// Context bundles
+	"bundle.title":       "Kontext-Bündel:",
+	"bundle.none":        "Noch keine Kontext-Bündel. Lege eins mit /bundle save <Name> <Dateien|Globs|URLs>... an.",
+	"bundle.saved":       "Bündel %s mit %d Eintrag/Einträgen gespeichert.",
+	"bundle.deleted":     "Bündel %s gelöscht.",
+	"bundle.unknown":     "Unbekanntes Bündel %q.",
+	"bundle.loading":     "Lade Bündel %s...",
+	"bundle.loaded":      "Bündel %s: %d Eintrag/Einträge an die nächste Nachricht angehängt.",
+	"bundle.load_failed": "Konnte nicht geladen werden: %s",
+	"bundle.failed":      "Bündel-Fehler: %v",
+	"bundle.usage":       "Verwendung: /bundle [list] | /bundle save <Name> <Datei|Glob|URL>... | /bundle load <Name> | /bundle delete <Name>",
+
+	// Last file
+	"last.none": "Bisher hat kein Tool eine Datei verwendet.",
+
+	// Transcript retention
+	"older.usage":        "Verwendung: /older [N]",
+	"older.none":         "Diese Sitzung hat keine archivierten Nachrichten.",
+	"older.failed":       "Archivierte Nachrichten konnten nicht geladen werden: %v",
+	"older.restored":     "%d archivierte Nachrichten wiederhergestellt (%d verbleibend).",
+	"older.restored_all": "%d archivierte Nachrichten wiederhergestellt; der gesamte Verlauf ist geladen.",
+
+	// Model capabilities
+	"model.no_tools":       "%s unterstützt offiziell keine Tools; der Agentenmodus verlässt sich auf JSON-Antworten und kann unzuverlässig sein. Mit /agent off wird normal gechattet.",
+	"model.no_vision":      "%s unterstützt keine Bilder; der Anhang wird wahrscheinlich ignoriert.",
+	"model.thinking":       "Überlegungen",
+	"model.embedding_only": "%s ist ein Embedding-Modell und kann nicht zum Chatten verwendet werden.",
+
+	// Context size
+	"ctx.status":    "Kontextgröße: %d Tokens.",
+	"ctx.session":   "(für diese Sitzung gesetzt; /ctx reset stellt den konfigurierten Wert wieder her)",
+	"ctx.max":       "Das Modell unterstützt bis zu %d.",
+	"ctx.usage":     "Verwendung: /ctx [N|Nk|reset], z. B. /ctx 16k",
+	"ctx.too_large": "%d ist mehr, als %s unterstützt (%d).",
+	"ctx.clamped":   "context_length %d ist mehr, als %s unterstützt; verwende %d.",
+
+	// Model picker
+	"picker.title":         "Modell auswählen",
+	"picker.filter":        "zum Filtern tippen",
+	"picker.empty":         "Kein Modell passt zum Filter.",
+	"picker.keys":          "↑/↓ bewegen · tippen zum Filtern · Enter auswählen · Esc abbrechen",
+	"picker.list_failed":   "Modelle konnten nicht aufgelistet werden: %v",
+	"picker.switch_failed": "Wechsel zu %s fehlgeschlagen: %v",
+	"picker.switched":      "Zu %s gewechselt.",
+
+	// Sharing
+	"share.empty":  "Noch nichts zum Teilen.",
+	"share.failed": "Die HTML-Seite konnte nicht geschrieben werden: %v",
+	"share.saved":  "Gespräch gespeichert unter %s",
+
+	// Translation
+	"translate.usage":   "Verwendung: /translate <Sprache>, oder respond_language in config.json setzen",
+	"translate.nothing": "Es gibt noch keine Antwort zum Übersetzen.",
+	"translate.failed":  "Übersetzung fehlgeschlagen: %v",
+
+	// Write conflicts
+	"conflict.warning": "Achtung: %s wurde geändert, nachdem der Agent die Datei gelesen hat. Eine Freigabe überschreibt diese Änderungen.",
+	"conflict.batch":   "(seit dem Lesen auf der Festplatte geändert)",
+
+	// Trash
+	"undo.empty":    "Nichts rückgängig zu machen: in dieser Sitzung wurde weder ein Änderungspaket angewendet noch eine Datei gelöscht.",
+	"undo.restored": "%s aus dem Papierkorb wiederhergestellt.",
+	"undo.failed":   "Die Änderung konnte nicht rückgängig gemacht werden: %v",
+
+	// Replay
+	"replay.footer":    "Schritt %d/%d · %s · %s · Leertaste weiter · b zurück · g/G erster/letzter · q beenden",
+	"replay.tool_call": "**Werkzeugaufruf:**",
+	"replay.images":    "*%d Bild(er) angehängt*",
+
+	// Forwarding between sessions
+	"sendto.usage":      "Verwendung: /send-to <Sitzung> [Text|@Datei]. Ohne Text wird die letzte Antwort gesendet. Diese Sitzung ist %s.",
+	"sendto.self":       "Das ist diese Sitzung.",
+	"sendto.nothing":    "Es gibt noch keine Antwort zum Weiterleiten.",
+	"sendto.read_error": "%s konnte nicht gelesen werden: %v",
+	"sendto.failed":     "Weiterleiten an %s fehlgeschlagen: %v",
+	"sendto.sent":       "An Sitzung %s weitergeleitet.",
+	"sendto.received":   "%d weitergeleitete Nachricht(en) von %s empfangen.",
+
+	// Planner and executor
+	"plan.usage":    "Verwendung: /plan <Aufgabe>. Das Planungsmodell zerlegt die Aufgabe in Schritte, die das ausführende Modell nacheinander erledigt. /plan next springt zum nächsten Schritt, /plan stop beendet den Plan.",
+	"plan.planning": "Plane mit %s...",
+	"plan.failed":   "Planung fehlgeschlagen: %v",
+	"plan.empty":    "Das Planungsmodell hat keine Liste von Schritten geliefert.",
+	"plan.title":    "**Plan** (Ausführung: %s)",
+	"plan.step":     "**Schritt %d/%d:** %s",
+	"plan.done":     "Alle Schritte des Plans sind erledigt.",
+	"plan.stopped":  "Plan beendet.",
+	"plan.none":     "Es läuft kein Plan.",
+
+	// Critic
+	"critic.usage":    "Verwendung: /critic [on|off|auto]. Bei auto wird geprüft, wenn die Persona oder die Konfiguration einen Kritiker festlegt.",
+	"critic.active":   "Antworten und Dateiänderungen werden von %s geprüft.",
+	"critic.inactive": "Keine Prüfung aktiv.",
+	"critic.pending":  "wird geprüft...",
+	"critic.failed":   "Prüfung fehlgeschlagen: %v",
+	"critic.title":    "**Prüfung** (%s)",
+	"critic.change":   "Prüfung: %s",
+
+	// Task list
+	"tasks.title": "Aufgaben: %d/%d erledigt",
+	"tasks.more":  "… %d weitere",
+
+	// Mention preview
+	"mention.none":       "Keine @-Erwähnung in der Eingabe für die Vorschau.",
+	"mention.read_error": "%s konnte nicht gelesen werden: %v",
+	"mention.title":      "@%s: %d Zeilen, %s, ~%d Tokens werden gesendet",
+	"mention.range":      "Zeilen: ",
+	"mention.keys":       "↑/↓ Bild↑/Bild↓ blättern · Bereich wie 10-40 eingeben · Enter übernehmen · Esc schließen",
+
+	// Queued prompts
+	"queue.item": "⏳ in der Warteschlange: %s",
+
+	// Interrupt and redirect
+	"redirect.interrupted": "_Unterbrochen_",
+	"redirect.placeholder": "Hinweis für das Modell (Enter fragt erneut, Esc behält die Teilantwort)",
+	"redirect.note":        "↪ %s",
+	"footer.redirect":      "Unterbrochen: Hinweis eingeben",
+
+	// Forget
+	"forget.title":    "Letzte Nachrichten (/forget N schließt eine aus künftigen Anfragen aus):",
+	"forget.marker":   "(vergessen)",
+	"forget.excluded": "Nachricht #%d wird in künftigen Anfragen weggelassen.",
+	"forget.included": "Nachricht #%d wird wieder an das Modell gesendet.",
+	"forget.usage":    "Verwendung: /forget [N...] mit Nachrichtennummern von 1 bis %d (siehe /forget).",
+
+	// Ollama errors and /pull
+	"remedy.not_found": "Das Modell %s ist auf dem Ollama-Server nicht installiert. Enter lädt es jetzt herunter, oder wähle mit /model ein anderes Modell.",
+	"remedy.memory":    "%s passt nicht in den freien Speicher (%s).\n\nVersuche eines davon und sende die Nachricht erneut (↑ holt sie zurück):\n- einen kleineren Kontext: /ctx %d (jetzt %d)\n- eine kleinere Quantisierung, z. B. ein q4_K_M-Tag, oder ein Modell mit weniger Parametern (/model)\n- andere Modelle entladen: ollama ps, dann ollama stop <Modell>",
+	"remedy.server":    "Ollama ist mit %s fehlgeschlagen (%d Wiederholungen): %s\n\nPrüfe das Log von ollama serve und sende die Nachricht erneut (↑ holt sie zurück). Wenn es weiter fehlschlägt, versuche einen kleineren Kontext mit /ctx oder ein anderes Modell mit /model.",
+	"remedy.other":     "Ollama hat die Anfrage abgelehnt: %v",
+	"pull.busy":        "%s wird bereits heruntergeladen.",
+	"pull.failed":      "%s konnte nicht heruntergeladen werden: %v",
+	"pull.done":        "%s wurde heruntergeladen. Sende deine Nachricht erneut (↑ holt sie zurück).",
+	"footer.pulling":   "Lade %s herunter: %s",
+
+	// Context guard
+	"guard.prompt":         "Diese Anfrage hat etwa %d Tokens, der Kontext (num_ctx) fasst aber %d. Ollama würde den Anfang der Unterhaltung samt Systemprompt stillschweigend verwerfen.",
+	"guard.compact":        "(C) Ältere Nachrichten verdichten",
+	"guard.drop":           "(D) Anhänge weglassen",
+	"guard.send":           "(S) Trotzdem senden   (Esc) Zurück zur Eingabe",
+	"guard.compacting":     "Fasse ältere Nachrichten zusammen...",
+	"guard.nothing":        "Es gibt keine älteren Nachrichten zum Verdichten.",
+	"guard.compact_failed": "Verdichten fehlgeschlagen: %v",
+	"guard.compacted":      "%d Nachrichten zu dieser Zusammenfassung verdichtet, die sie in Anfragen ersetzt:",
+
+	// Presets
+	"preset.title":    "Options-Presets (eigene unter \"presets\" in config.json hinzufügen):",
+	"preset.active":   "(aktiv)",
+	"preset.off":      "Preset aus. Anfragen verwenden die konfigurierten Optionen und die der Persona.",
+	"preset.unknown":  "Unbekanntes Preset %q. /preset listet sie auf.",
+	"preset.selected": "Preset %s: %s",
+
+	// server
+	"server.old": "Ollama %s ist zu alt für: %s. Diese Funktionen sind in dieser Sitzung ausgeschaltet; aktualisiere Ollama, um sie zu nutzen.",
+
+	// json
+	"json.usage":          "Verwendung: /json <Schema-Datei oder Inline-Schema> [Prompt]. Die nächste Antwort wird auf das Schema beschränkt, dagegen geprüft und formatiert angezeigt.",
+	"json.pending":        "Die nächste Antwort folgt dem Schema aus %s. Mit /json off abbrechen.",
+	"json.set":            "Die nächste Antwort folgt dem Schema aus %s.",
+	"json.off":            "Die nächste Antwort ist nicht mehr an ein Schema gebunden.",
+	"json.unsupported":    "Ollama %s unterstützt keine strukturierten Ausgaben; aktualisiere auf 0.5 oder neuer, um /json zu nutzen.",
+	"json.invalid_schema": "Das Schema aus %s ist kein gültiges JSON: %v",
+	"json.read_failed":    "Das Schema %s konnte nicht gelesen werden: %v",
+	"json.not_json":       "_Die Antwort ist kein gültiges JSON: %v_",
+	"json.valid":          "_Entspricht dem Schema aus %s._",
+	"json.invalid":        "**Entspricht nicht dem Schema aus %s:**",
+
+	// artifacts
+	"artifacts.none":              "Der Agent hat in dieser Sitzung keine Dateien geschrieben.",
+	"artifacts.title":             "Vom Agenten geschriebene Dateien (%d)",
+	"artifacts.created":           "erstellt",
+	"artifacts.modified":          "geändert",
+	"artifacts.deleted":           "gelöscht",
+	"artifacts.unchanged":         "unverändert",
+	"artifacts.unreadable":        "nicht lesbar",
+	"artifacts.no_diff":           "Keine Änderungen gegenüber dem Stand vor dem Schreiben durch den Agenten.",
+	"artifacts.keys":              "↑/↓ auswählen · Enter Diff · Bild↑/Bild↓ blättern · o öffnen · r zurücksetzen · c Pfad kopieren · Esc schließen",
+	"artifacts.missing":           "%s existiert nicht.",
+	"artifacts.copied":            "%s in die Zwischenablage kopiert.",
+	"artifacts.copy_failed":       "Kopieren in die Zwischenablage fehlgeschlagen: %v",
+	"artifacts.nothing_to_revert": "%s ist wie vor dem Schreiben durch den Agenten.",
+	"artifacts.confirm_revert":    "Nochmals r drücken, um %s auf den Stand vor dem Agenten zurückzusetzen. Erstellte Dateien kommen in den Papierkorb (/undo stellt sie wieder her).",
+	"artifacts.reverted":          "%s zurückgesetzt.",
+	"artifacts.revert_failed":     "%s konnte nicht zurückgesetzt werden: %v",
+
+	// stall
+	"stall.prompt":   "⚠ Die Antwort hängt: Seit %s kam nichts von Ollama.",
+	"stall.options":  "[R] Erneut versuchen   [C] Abbrechen   [W] Weiter warten",
+	"stall.canceled": "Die hängende Antwort wurde abgebrochen.",
+
+	// stream
+	"stream.busy": "Es läuft noch eine Anfrage, daher wurde diese nicht gesendet. Warte auf die Antwort oder brich sie mit /stop ab.",
+
+	// Permission rules
+	"permission.rule_dir": "(D) Immer erlauben für %s",
+	"permission.rule_ext": "(E) Immer erlauben für %s",
+	"permissions.added":   "Regel gespeichert: %s. Sie gilt ab jetzt in diesem Arbeitsbereich; /permissions zeigt die Regeln.",
+	"permissions.deleted": "Regel entfernt: %s.",
+	"permissions.failed":  "Die Berechtigungsregeln konnten nicht gespeichert werden: %v",
+	"permissions.none":    "Keine Berechtigungsregeln gespeichert. Drücke D oder E in einer Bestätigungsabfrage, um eine hinzuzufügen.",
+	"permissions.title":   "**Berechtigungsregeln** (Werkzeuge laufen für passende Dateien ohne Nachfrage):",
+	"permissions.unknown": "Keine Berechtigungsregel %s.",
+	"permissions.usage":   "Verwendung: /permissions [delete <n>]",
+
+	// History expansion
+	"expand.empty":   "Keine frühere Eingabe, die !! wiederholen könnte.",
+	"expand.unknown": "Keine Eingabe !%d; diese Sitzung hat %d. /history listet sie auf.",
+	"expand.title":   "**Eingaben dieser Sitzung:**",
+	"expand.hint":    "Mit !N eine erneut senden, mit !! die letzte. Text dahinter wird angehängt, z. B. `!! in Go`.",
+
+	// Tool statistics
+	"toolstats.none":   "In dieser Sitzung wurden noch keine Werkzeuge aufgerufen.",
+	"toolstats.title":  "**Werkzeugnutzung:** %d Aufrufe von %d Werkzeugen, %d fehlgeschlagen. Nach Gesamtzeit sortiert.",
+	"toolstats.header": "| Werkzeug | Aufrufe | Fehler | Mittel | Gesamt | Zeitanteil |",
+
+	// Changesets
+	"changeset.title":    "Das Modell schlägt ein Änderungspaket mit %d Dateien vor, die gemeinsam angewendet werden:",
+	"changeset.conflict": "seit dem Lesen geändert: %s",
+	"changeset.keys":     "↑/↓ Datei  Bild↑/Bild↓ Diff blättern  (A) Alle anwenden  (N) Nein",
+	"changeset.denied":   "Änderungspaket mit %d Dateien vom Benutzer abgelehnt: %s",
+	"undo.changeset":     "Änderungspaket mit %d Dateien zurückgenommen: %s.",
+
+	// Explain
+	"explain.usage":      "Verwendung: /explain <Pfad[:Zeile]>, z. B. /explain main.go:42",
+	"explain.read_error": "%s konnte nicht gelesen werden: %v",
+	"explain.no_line":    "%s hat nur %d Zeilen.",
+
+	// Sources
+	"sources.heading":     "Quellen",
+	"sources.title":       "%d Webquelle(n) in dieser Sitzung (/sources N öffnet eine):",
+	"sources.none":        "Noch keine Webquellen. Sie werden aus web_search, visit_url und read_feed gesammelt.",
+	"sources.unknown":     "Keine Quelle %s. /sources listet sie auf.",
+	"sources.opened":      "%s geöffnet",
+	"sources.open_failed": "%s konnte nicht geöffnet werden: %v",
+
+	// Long replies, see spool.go
+	"spool.more":       "… %d weitere Zeilen (%s) nicht angezeigt. /open-full öffnet die ganze Antwort.",
+	"spool.more_saved": "… %d weitere Zeilen (%s) nicht angezeigt. /open-full öffnet die ganze Antwort, gespeichert in `%s`.",
+	"spool.none":       "Keine Antwort war zu lang für die Anzeige. Mit /open-full <N> Nachricht N öffnen.",
+	"spool.unknown":    "Nachricht %s ist keine Antwort des Modells.",
+	"spool.failed":     "Antwort konnte nicht gespeichert werden: %v",
+
+	// Message timestamps, see timestamps.go
+	"timestamps.usage":    "Verwendung: /timestamps [off|absolute|relative]",
+	"timestamps.off":      "Nachrichtenzeiten werden ausgeblendet.",
+	"timestamps.absolute": "Nachrichtenköpfe zeigen die Uhrzeit, zu der die Nachricht hinzukam.",
+	"timestamps.relative": "Nachrichtenköpfe zeigen, wie lange die Nachricht her ist.",
+	"time.just_now":       "gerade eben",
+	"time.minutes_ago":    "vor %d Min.",
+	"time.hours_ago":      "vor %d Std.",
+	"time.days_ago":       "vor %d Tg.",
+
+	// Scroll lock, see followStream
+	"footer.new_below": "↓ Neuer Inhalt unten (Esc, dann G)",
+}
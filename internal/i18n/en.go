@@ -0,0 +1,404 @@
+package i18n
+
+var english = Catalog{
+	// Input and footer
+	"input.placeholder":   "Send a message... (Ctrl+V to paste)",
+	"footer.waiting":      "Waiting for response...",
+	"footer.quit_confirm": "Press Ctrl-C again to exit the application. Press Esc to cancel.",
+	"footer.file_search":  "File search: %s",
+	"footer.no_matches":   "No matches found",
+	"footer.recording":    "● Recording... (Ctrl+T to stop)",
+	"footer.translating":  "Translating...",
+	"footer.planning":     "Planning...",
+	"footer.reviewing":    "Reviewing...",
+	"footer.plan":         "Plan: step %d/%d on %s",
+	"footer.multiline":    "Multiline: Alt+Enter sends",
+	"footer.no_stats":     "Tokens/sec: N/A",
+	"footer.no_context":   "Context: N/A",
+	"footer.chat":         "Chat",
+
+	// Help
+	"help.title":       "Commands:",
+	"help.new":         "/new - Start a new chat session",
+	"help.bye":         "/bye - Exit the application",
+	"help.help":        "/help - Show this help message",
+	"help.stop":        "/stop - Stop the current response",
+	"help.log":         "/log - Toggle logging to a file",
+	"help.copy":        "/copy - Copy the last response to the clipboard",
+	"help.explain":     "/explain <path[:line]> - Explain a file, or the function or type around the line",
+	"help.open":        "/open [N|path] - Open a file or the Nth code block of the last response in the editor",
+	"help.open_full":   "/open-full [N] - Open the whole text of the last long reply, or of message N, in the editor",
+	"help.paste_image": "/paste-image - Attach the clipboard image to the next message",
+	"help.history":     "/history [query] - List this session's inputs for !N, or search saved sessions (/history open <N> to resume one)",
+	"help.timestamps":  "/timestamps [off|absolute|relative] - Show the time of each message in its header; without an argument switch to the next mode",
+	"help.share":       "/share [file] - Save the conversation as a self-contained HTML page",
+	"help.undo":        "/undo - Revert the last changeset or restore the last file the agent deleted",
+	"help.sources":     "/sources [N] - List the web pages answers cited, or open source N in the browser",
+	"help.translate":   "/translate [language] - Translate the last answer (default: respond_language)",
+	"help.older":       "/older [N] - Bring back the N most recent archived messages (default 20)",
+	"help.forget":      "/forget [N...] - List messages, or leave message N out of future requests (again to bring it back)",
+	"help.model":       "/model [name] - Pick another model, or switch to the named one",
+	"help.pull":        "/pull [model] - Download a model (default: the current one) to the Ollama server",
+	"help.ctx":         "/ctx [N|Nk|reset] - Show or override the context size (num_ctx) for this session",
+	"help.run":         "/run [-i] <command> - Run a shell command (-i includes the output in your next message)",
+	"help.bundle":      "/bundle [save <name> <files|globs|urls>...|load <name>|delete <name>] - Manage named context bundles",
+	"help.persona":     "/persona [name|off] - List or switch personas",
+	"help.preset":      "/preset [name|off] - List or switch option presets such as precise or creative",
+	"help.json":        "/json <schema> [prompt] - Constrain the next answer to a JSON schema file or inline schema; /json off cancels",
+	"help.artifacts":   "/artifacts - List the files the agent wrote with their changes; open, revert or copy their path",
+	"help.toolstats":   "/toolstats - Show tool calls, failure rates and durations of this session",
+	"help.agent":       "/agent [on|off] - Switch between agent mode (tools) and plain chat",
+	"help.yolo":        "/yolo [files|git|web|shell|all|off] - Auto-approve tool calls in the given scopes",
+	"help.permissions": "/permissions [delete <n>] - List or remove the saved always-allow rules",
+	"help.speak":       "/speak - Toggle reading responses aloud",
+	"help.critic":      "/critic [on|off|auto] - Have a second model pass review answers and file changes",
+	"help.plan":        "/plan <task>|next|stop - Let the planner model split a task into steps for the executor model",
+	"help.send_to":     "/send-to <session> [text|@file] - Forward text, a file or the last answer to another session",
+	"help.ctrl_e":      "Ctrl+E - Compose the prompt in your editor",
+	"help.ctrl_t":      "Ctrl+T - Start/stop dictation (speech to text)",
+	"help.ctrl_l":      "Ctrl+L - Insert the file the agent changed last (or type @last)",
+	"help.ctrl_o":      "Ctrl+O - Preview the last @ mention and pick a line range to send",
+	"help.ctrl_r":      "Ctrl+R - Interrupt the reply and re-ask with a steering note",
+	"help.fold":        "Esc, then Enter - Expand/collapse the tool output in view",
+	"help.jump":        "Esc, then [ / ] / g / G - Jump to the previous/next message, top or bottom of the transcript",
+
+	// Permissions
+	"permission.prompt":  "The model wants to execute the following command:\n\n%s\nDo you want to proceed?",
+	"permission.options": "(A)llow Once   (Y)es to All   (N)o / Display Command",
+	"yolo.enabled":       "YOLO mode enabled. All commands will be executed without permission.",
+	"yolo.disabled":      "YOLO mode disabled. Destructive commands will require permission.",
+	"yolo.status_off":    "YOLO mode is off. Every tool that changes something asks for permission.",
+	"yolo.status":        "YOLO scopes: %s. Use /yolo <scope> to toggle or /yolo off.",
+	"yolo.scoped":        "YOLO on for: %s. Other tools still ask for permission.",
+	"yolo.usage":         "Usage: /yolo [%s|all|off]...",
+
+	// Status messages
+	"copy.done":          "Copied last response to clipboard.",
+	"copy.empty":         "No response to copy.",
+	"error.screen":       "An error occurred: %v\n\nPress Ctrl+C to quit.",
+	"editor.failed":      "Editor failed: %v",
+	"editor.read_failed": "Could not read editor file: %v",
+	"open.nothing":       "Nothing to open. Use /open <path> or /open <N> for a code block.",
+	"open.out_of_range":  "The last response has %d code block(s); %d is out of range.",
+	"open.failed":        "Cannot open '%s': %v",
+	"open.remote":        "Cannot open %s: the workspace is on a remote host.",
+	"open.temp_failed":   "Could not write temp file: %v",
+	"image.failed":       "Could not read an image from the clipboard: %v",
+	"image.attached":     "Image saved to %s and attached to your next message.",
+	"image.attach_error": "Could not attach images: %v",
+	"history.usage":      "Usage: /history <query> to search saved sessions, then /history open <N>.",
+	"history.open_usage": "Usage: /history open <N> where N is between 1 and %d.",
+	"history.open_error": "Could not open session %s: %v",
+	"history.failed":     "Search failed: %v",
+	"history.none":       "No saved messages match %q.",
+	"history.found":      "Found %d match(es) for %q:",
+	"history.hint":       "Use /history open <N> to open a session at the matching message.",
+	"run.usage":          "Usage: /run [-i] <command>. Use -i to include the output in your next message.",
+	"run.suggested":      "Suggested for this project: %s",
+	"persona.title":      "Personas:",
+	"persona.active":     "(active)",
+	"persona.hint":       "Use /persona <name> to switch or /persona off to clear.",
+	"persona.unknown":    "Unknown persona %q. Available: %s",
+	"persona.cleared":    "Persona cleared.",
+	"persona.set":        "Persona set to %s.",
+
+	// Accessible mode
+	"a11y.waiting":          "Waiting for response.",
+	"a11y.done":             "Response complete.",
+	"a11y.tool_call":        "Assistant requested tool %s.",
+	"a11y.error":            "Error",
+	"a11y.permission_keys":  "Permission required. Press A to allow once, Y to always allow, N to deny.",
+	"a11y.focus_input":      "Focus: input",
+	"a11y.focus_transcript": "Focus: transcript",
+
+	// Text to speech
+	"speak.enabled":  "Responses will be read aloud with: %s",
+	"speak.disabled": "Read-aloud disabled.",
+	"speak.failed":   "Text-to-speech failed: %v",
+
+	// Dictation
+	"dictation.not_configured": "Set stt_command in config.json to use dictation.",
+	"dictation.failed":         "Dictation failed: %v",
+
+	// Tool output folding
+	"fold.summary":     "▸ `%s` · %s · %s (Esc, then Enter to expand)",
+	"fold.ok":          "ok",
+	"fold.error":       "error",
+	"fold.error_class": "error: %s",
+	"fold.truncated":   "truncated",
+	"fold.formatted":   "formatted",
+	"fold.stderr":      "stderr:",
+	"fold.cached":      "cached",
+	"fold.suspicious":  "⚠ possible prompt injection",
+
+	// Agent mode
+	"agent.on":         "Agent mode on: tools are available.",
+	"agent.off":        "Agent mode off: plain chat, no tool instructions are sent.",
+	"agent.status_on":  "Agent mode is on. Use /agent off for plain chat.",
+	"agent.status_off": "Agent mode is off. Use /agent on to enable tools.",
+	"agent.usage":      "Usage: /agent [on|off]",
+
+	// Prompt injection guard
+	"guard.suspicious": "⚠ %s returned instruction-like text; treat its content with care.",
+
+	// Approval queue
+	"batch.title":       "The model wants to run %d tool calls. Review the ones that need permission:",
+	"batch.no_approval": "(no approval needed)",
+	"batch.changeset":   "(its changes are reviewed next)",
+	"batch.keys":        "↑/↓ move · Space toggle · A allow all · N deny all · Enter run · Esc deny and stop",
+
+	// Context bundles
+	"bundle.title":       "Context bundles:",
+	"bundle.none":        "No context bundles yet. Create one with /bundle save <name> <files|globs|urls>...",
+	"bundle.saved":       "Saved bundle %s with %d item(s).",
+	"bundle.deleted":     "Deleted bundle %s.",
+	"bundle.unknown":     "Unknown bundle %q.",
+	"bundle.loading":     "Loading bundle %s...",
+	"bundle.loaded":      "Bundle %s: %d item(s) attached to your next message.",
+	"bundle.load_failed": "Could not load: %s",
+	"bundle.failed":      "Bundle error: %v",
+	"bundle.usage":       "Usage: /bundle [list] | /bundle save <name> <file|glob|url>... | /bundle load <name> | /bundle delete <name>",
+
+	// Last file
+	"last.none": "No file has been touched by a tool yet.",
+
+	// Transcript retention
+	"older.usage":        "Usage: /older [N]",
+	"older.none":         "No archived messages in this session.",
+	"older.failed":       "Could not load archived messages: %v",
+	"older.restored":     "Restored %d archived messages (%d remaining).",
+	"older.restored_all": "Restored %d archived messages; the full transcript is loaded.",
+
+	// Model capabilities
+	"model.no_tools":       "%s does not advertise tool support; agent mode relies on it replying in JSON and may be unreliable. Use /agent off for plain chat.",
+	"model.no_vision":      "%s does not support images; the attachment will probably be ignored.",
+	"model.thinking":       "Thinking",
+	"model.embedding_only": "%s is an embedding model and cannot be used for chat.",
+
+	// Context size
+	"ctx.status":    "Context size: %d tokens.",
+	"ctx.session":   "(set for this session; /ctx reset restores the configured value)",
+	"ctx.max":       "The model supports up to %d.",
+	"ctx.usage":     "Usage: /ctx [N|Nk|reset], e.g. /ctx 16k",
+	"ctx.too_large": "%d is more than %s supports (%d).",
+	"ctx.clamped":   "context_length %d is more than %s supports; using %d.",
+
+	// Model picker
+	"picker.title":         "Select a model",
+	"picker.filter":        "type to filter",
+	"picker.empty":         "No model matches the filter.",
+	"picker.keys":          "↑/↓ move · type to filter · Enter select · Esc cancel",
+	"picker.list_failed":   "Could not list models: %v",
+	"picker.switch_failed": "Could not switch to %s: %v",
+	"picker.switched":      "Switched to %s.",
+
+	// Sharing
+	"share.empty":  "Nothing to share yet.",
+	"share.failed": "Could not write the HTML page: %v",
+	"share.saved":  "Conversation saved to %s",
+
+	// Translation
+	"translate.usage":   "Usage: /translate <language>, or set respond_language in config.json",
+	"translate.nothing": "There is no answer to translate yet.",
+	"translate.failed":  "Translation failed: %v",
+
+	// Write conflicts
+	"conflict.warning": "Warning: %s changed on disk since the agent read it. Approving overwrites those changes.",
+	"conflict.batch":   "(changed on disk since read)",
+
+	// Trash
+	"undo.empty":    "Nothing to undo: no changeset was applied and no file was deleted in this session.",
+	"undo.restored": "Restored %s from the trash.",
+	"undo.failed":   "Could not undo the change: %v",
+
+	// Replay
+	"replay.footer":    "Step %d/%d · %s · %s · Space next · b back · g/G first/last · q quit",
+	"replay.tool_call": "**Tool call:**",
+	"replay.images":    "*%d image(s) attached*",
+
+	// Forwarding between sessions
+	"sendto.usage":      "Usage: /send-to <session> [text|@file]. Without text the last answer is sent. This session is %s.",
+	"sendto.self":       "That is this session.",
+	"sendto.nothing":    "There is no answer to forward yet.",
+	"sendto.read_error": "Could not read %s: %v",
+	"sendto.failed":     "Could not forward to %s: %v",
+	"sendto.sent":       "Forwarded to session %s.",
+	"sendto.received":   "Received %d forwarded message(s) from %s.",
+
+	// Planner and executor
+	"plan.usage":    "Usage: /plan <task>. The planner model splits the task into steps and the executor model carries them out one by one. /plan next skips to the next step, /plan stop ends the plan.",
+	"plan.planning": "Planning with %s...",
+	"plan.failed":   "Planning failed: %v",
+	"plan.empty":    "The planner did not reply with a list of steps.",
+	"plan.title":    "**Plan** (executor: %s)",
+	"plan.step":     "**Step %d/%d:** %s",
+	"plan.done":     "All steps of the plan are done.",
+	"plan.stopped":  "Plan stopped.",
+	"plan.none":     "No plan is running.",
+
+	// Critic
+	"critic.usage":    "Usage: /critic [on|off|auto]. Auto reviews when the persona or config sets a critic.",
+	"critic.active":   "Answers and file changes are reviewed by %s.",
+	"critic.inactive": "No review pass is active.",
+	"critic.pending":  "reviewing...",
+	"critic.failed":   "Review failed: %v",
+	"critic.title":    "**Review** (%s)",
+	"critic.change":   "Review: %s",
+
+	// Task list
+	"tasks.title": "Tasks %d/%d done",
+	"tasks.more":  "… %d more",
+
+	// Mention preview
+	"mention.none":       "No @ mention in the input to preview.",
+	"mention.read_error": "Could not read %s: %v",
+	"mention.title":      "@%s: %d lines, %s, ~%d tokens will be sent",
+	"mention.range":      "Lines: ",
+	"mention.keys":       "↑/↓ PgUp/PgDn scroll · type a range like 10-40 · Enter apply · Esc close",
+
+	// Queued prompts
+	"queue.item": "⏳ queued: %s",
+
+	// Interrupt and redirect
+	"redirect.interrupted": "_Interrupted_",
+	"redirect.placeholder": "Steering note for the model (Enter to re-ask, Esc to keep the partial answer)",
+	"redirect.note":        "↪ %s",
+	"footer.redirect":      "Interrupted: type a note",
+
+	// Forget
+	"forget.title":    "Recent messages (use /forget N to leave one out of future requests):",
+	"forget.marker":   "(forgotten)",
+	"forget.excluded": "Message #%d is left out of future requests.",
+	"forget.included": "Message #%d is sent to the model again.",
+	"forget.usage":    "Usage: /forget [N...] with message numbers from 1 to %d (see /forget).",
+
+	// Ollama errors and /pull
+	"remedy.not_found": "The model %s is not installed on the Ollama server. Press Enter to pull it now, or pick another model with /model.",
+	"remedy.memory":    "%s does not fit into the free memory (%s).\n\nTry one of these, then send your message again (↑ recalls it):\n- a smaller context: /ctx %d (now %d)\n- a smaller quantization of the model, e.g. a q4_K_M tag, or a model with fewer parameters (/model)\n- unloading other models: ollama ps, then ollama stop <model>",
+	"remedy.server":    "Ollama failed with %s (retried %d times): %s\n\nCheck the log of ollama serve and send your message again (↑ recalls it). If it keeps failing, try a smaller context with /ctx or another model with /model.",
+	"remedy.other":     "Ollama rejected the request: %v",
+	"pull.busy":        "Already pulling %s.",
+	"pull.failed":      "Could not pull %s: %v",
+	"pull.done":        "Pulled %s. Send your message again (↑ recalls it).",
+	"footer.pulling":   "Pulling %s: %s",
+
+	// Context guard
+	"guard.prompt":         "This request is about %d tokens, but the context (num_ctx) holds %d. Ollama would silently drop the start of the conversation, including the system prompt.",
+	"guard.compact":        "(C)ompact older messages",
+	"guard.drop":           "(D)rop attachments",
+	"guard.send":           "(S)end anyway   (Esc) Back to the input",
+	"guard.compacting":     "Summarizing older messages...",
+	"guard.nothing":        "There are no older messages to compact.",
+	"guard.compact_failed": "Compaction failed: %v",
+	"guard.compacted":      "Compacted %d messages into this summary, which replaces them in requests:",
+
+	// Presets
+	"preset.title":    "Option presets (add your own under \"presets\" in config.json):",
+	"preset.active":   "(active)",
+	"preset.off":      "Preset off. Requests use the configured and persona options.",
+	"preset.unknown":  "Unknown preset %q. Use /preset to list them.",
+	"preset.selected": "Preset %s: %s",
+
+	// server
+	"server.old": "Ollama %s is too old for: %s. These features are turned off for this session; update Ollama to use them.",
+
+	// json
+	"json.usage":          "Usage: /json <schema file or inline schema> [prompt]. The next answer is constrained to the schema, checked against it and shown pretty-printed.",
+	"json.pending":        "The next answer follows the schema from %s. Use /json off to cancel.",
+	"json.set":            "The next answer will follow the schema from %s.",
+	"json.off":            "The next answer is no longer constrained to a schema.",
+	"json.unsupported":    "Ollama %s does not support structured outputs; update to 0.5 or later to use /json.",
+	"json.invalid_schema": "The schema from %s is not valid JSON: %v",
+	"json.read_failed":    "Could not read the schema %s: %v",
+	"json.not_json":       "_The answer is not valid JSON: %v_",
+	"json.valid":          "_Matches the schema from %s._",
+	"json.invalid":        "**Does not match the schema from %s:**",
+
+	// artifacts
+	"artifacts.none":              "The agent has not written any files in this session.",
+	"artifacts.title":             "Files written by the agent (%d)",
+	"artifacts.created":           "created",
+	"artifacts.modified":          "modified",
+	"artifacts.deleted":           "deleted",
+	"artifacts.unchanged":         "unchanged",
+	"artifacts.unreadable":        "unreadable",
+	"artifacts.no_diff":           "No changes compared to before the agent wrote it.",
+	"artifacts.keys":              "↑/↓ select · Enter diff · PgUp/PgDn scroll · o open · r revert · c copy path · Esc close",
+	"artifacts.missing":           "%s does not exist.",
+	"artifacts.copied":            "Copied %s to the clipboard.",
+	"artifacts.copy_failed":       "Could not copy to the clipboard: %v",
+	"artifacts.nothing_to_revert": "%s is as it was before the agent wrote it.",
+	"artifacts.confirm_revert":    "Press r again to revert %s to how it was before the agent wrote it. Created files go to the trash (/undo restores them).",
+	"artifacts.reverted":          "Reverted %s.",
+	"artifacts.revert_failed":     "Could not revert %s: %v",
+
+	// stall
+	"stall.prompt":   "⚠ The reply has stalled: nothing arrived from Ollama for %s.",
+	"stall.options":  "[R] Retry   [C] Cancel   [W] Keep waiting",
+	"stall.canceled": "Canceled the stalled reply.",
+
+	// stream
+	"stream.busy": "A request is still running, so this one was not sent. Wait for the reply or stop it with /stop.",
+
+	// Permission rules
+	"permission.rule_dir": "(D) Always allow for %s",
+	"permission.rule_ext": "(E) Always allow for %s",
+	"permissions.added":   "Saved rule: %s. It applies from now on in this workspace; /permissions lists the rules.",
+	"permissions.deleted": "Removed rule: %s.",
+	"permissions.failed":  "Could not save the permission rules: %v",
+	"permissions.none":    "No permission rules saved. Press D or E in a permission prompt to add one.",
+	"permissions.title":   "**Permission rules** (tools run without asking for matching files):",
+	"permissions.unknown": "No permission rule %s.",
+	"permissions.usage":   "Usage: /permissions [delete <n>]",
+
+	// History expansion
+	"expand.empty":   "No earlier input to repeat with !!.",
+	"expand.unknown": "No input !%d; this session has %d. /history lists them.",
+	"expand.title":   "**Inputs of this session:**",
+	"expand.hint":    "Send one again with !N, the last one with !!. Text after it is appended, e.g. `!! in Go`.",
+
+	// Tool statistics
+	"toolstats.none":   "No tools have been called in this session yet.",
+	"toolstats.title":  "**Tool usage:** %d calls of %d tools, %d failed. Sorted by total time.",
+	"toolstats.header": "| Tool | Calls | Failed | Average | Total | Share of time |",
+
+	// Changesets
+	"changeset.title":    "The model proposes a changeset of %d files, applied together:",
+	"changeset.conflict": "changed since read: %s",
+	"changeset.keys":     "↑/↓ file  PgUp/PgDn scroll diff  (A)pply all  (N)o",
+	"changeset.denied":   "Changeset of %d files denied by user: %s",
+	"undo.changeset":     "Reverted the changeset of %d files: %s.",
+
+	// Explain
+	"explain.usage":      "Usage: /explain <path[:line]>, e.g. /explain main.go:42",
+	"explain.read_error": "Could not read %s: %v",
+	"explain.no_line":    "%s has only %d lines.",
+
+	// Sources
+	"sources.heading":     "Sources",
+	"sources.title":       "%d web source(s) in this session (/sources N opens one):",
+	"sources.none":        "No web sources yet. They are collected from web_search, visit_url and read_feed.",
+	"sources.unknown":     "No source %s. /sources lists them.",
+	"sources.opened":      "Opened %s",
+	"sources.open_failed": "Could not open %s: %v",
+
+	// Long replies, see spool.go
+	"spool.more":       "… %d more lines (%s) not shown. /open-full opens the whole reply.",
+	"spool.more_saved": "… %d more lines (%s) not shown. /open-full opens the whole reply, saved in `%s`.",
+	"spool.none":       "No reply was too long to show. Use /open-full <N> to open message N.",
+	"spool.unknown":    "Message %s is not a reply of the model.",
+	"spool.failed":     "Could not save the reply: %v",
+
+	// Message timestamps, see timestamps.go
+	"timestamps.usage":    "Usage: /timestamps [off|absolute|relative]",
+	"timestamps.off":      "Message times are hidden.",
+	"timestamps.absolute": "Message headers show the time the message was added.",
+	"timestamps.relative": "Message headers show how long ago the message was added.",
+	"time.just_now":       "just now",
+	"time.minutes_ago":    "%d min ago",
+	"time.hours_ago":      "%d h ago",
+	"time.days_ago":       "%d d ago",
+
+	// Scroll lock, see followStream
+	"footer.new_below": "↓ New content below (Esc, then G)",
+}
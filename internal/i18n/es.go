@@ -0,0 +1,404 @@
+package i18n
+
+var spanish = Catalog{
+	// Input and footer
+	"input.placeholder":   "Envía un mensaje... (Ctrl+V para pegar)",
+	"footer.waiting":      "Esperando respuesta...",
+	"footer.quit_confirm": "Pulsa Ctrl-C otra vez para salir. Pulsa Esc para cancelar.",
+	"footer.file_search":  "Búsqueda de archivos: %s",
+	"footer.no_matches":   "Sin coincidencias",
+	"footer.recording":    "● Grabando... (Ctrl+T para detener)",
+	"footer.translating":  "Traduciendo...",
+	"footer.planning":     "Planificando...",
+	"footer.reviewing":    "Revisando...",
+	"footer.plan":         "Plan: paso %d/%d con %s",
+	"footer.multiline":    "Multilínea: Alt+Enter envía",
+	"footer.no_stats":     "Tokens/s: N/D",
+	"footer.no_context":   "Contexto: N/D",
+	"footer.chat":         "Chat",
+
+	// Help
+	"help.title":       "Comandos:",
+	"help.new":         "/new - Iniciar una nueva sesión de chat",
+	"help.bye":         "/bye - Salir de la aplicación",
+	"help.help":        "/help - Mostrar esta ayuda",
+	"help.stop":        "/stop - Detener la respuesta actual",
+	"help.log":         "/log - Activar o desactivar el registro en archivo",
+	"help.copy":        "/copy - Copiar la última respuesta al portapapeles",
+	"help.explain":     "/explain <ruta[:línea]> - Explicar un archivo, o la función o el tipo alrededor de la línea",
+	"help.open":        "/open [N|ruta] - Abrir un archivo o el bloque de código N de la última respuesta en el editor",
+	"help.open_full":   "/open-full [N] - Abrir en el editor el texto completo de la última respuesta larga o del mensaje N",
+	"help.paste_image": "/paste-image - Adjuntar la imagen del portapapeles al siguiente mensaje",
+	"help.history":     "/history [consulta] - Mostrar las entradas de esta sesión para !N o buscar en sesiones guardadas (/history open <N> para reanudar una)",
+	"help.timestamps":  "/timestamps [off|absolute|relative] - Mostrar la hora de cada mensaje en su encabezado; sin argumento cambia al siguiente modo",
+	"help.share":       "/share [archivo] - Guardar la conversación como página HTML independiente",
+	"help.undo":        "/undo - Revertir el último conjunto de cambios o restaurar el último archivo que borró el agente",
+	"help.sources":     "/sources [N] - Listar las páginas web citadas en las respuestas o abrir la fuente N en el navegador",
+	"help.translate":   "/translate [idioma] - Traducir la última respuesta (por defecto: respond_language)",
+	"help.older":       "/older [N] - Recuperar los N mensajes archivados más recientes (20 por defecto)",
+	"help.forget":      "/forget [N...] - Listar mensajes o excluir el mensaje N de futuras peticiones (otra vez para recuperarlo)",
+	"help.model":       "/model [nombre] - Elegir otro modelo o cambiar directamente al indicado",
+	"help.pull":        "/pull [modelo] - Descargar un modelo (por defecto: el actual) al servidor de Ollama",
+	"help.ctx":         "/ctx [N|Nk|reset] - Mostrar o cambiar el tamaño de contexto (num_ctx) de esta sesión",
+	"help.run":         "/run [-i] <comando> - Ejecutar un comando de shell (-i incluye la salida en tu siguiente mensaje)",
+	"help.bundle":      "/bundle [save <nombre> <archivos|globs|urls>...|load <nombre>|delete <nombre>] - Gestionar paquetes de contexto con nombre",
+	"help.persona":     "/persona [nombre|off] - Listar o cambiar de persona",
+	"help.preset":      "/preset [nombre|off] - Listar o cambiar presets de opciones como precise o creative",
+	"help.json":        "/json <esquema> [prompt] - Restringe la próxima respuesta a un esquema JSON (archivo o en línea); /json off cancela",
+	"help.artifacts":   "/artifacts - Lista los archivos que escribió el agente con sus cambios; abrir, revertir o copiar la ruta",
+	"help.toolstats":   "/toolstats - Mostrar llamadas a herramientas, tasas de error y duraciones de esta sesión",
+	"help.agent":       "/agent [on|off] - Cambiar entre modo agente (herramientas) y chat simple",
+	"help.yolo":        "/yolo [files|git|web|shell|all|off] - Aprobar automáticamente las herramientas de esos ámbitos",
+	"help.permissions": "/permissions [delete <n>] - Mostrar o quitar las reglas guardadas de permitir siempre",
+	"help.speak":       "/speak - Activar o desactivar la lectura en voz alta",
+	"help.critic":      "/critic [on|off|auto] - Revisar respuestas y cambios de archivos en una segunda pasada",
+	"help.plan":        "/plan <tarea>|next|stop - El modelo planificador divide una tarea en pasos para el modelo ejecutor",
+	"help.send_to":     "/send-to <sesión> [texto|@archivo] - Reenviar texto, un archivo o la última respuesta a otra sesión",
+	"help.ctrl_e":      "Ctrl+E - Redactar el mensaje en tu editor",
+	"help.ctrl_t":      "Ctrl+T - Iniciar/detener dictado (voz a texto)",
+	"help.ctrl_l":      "Ctrl+L - Insertar el último archivo que cambió el agente (o escribe @last)",
+	"help.ctrl_o":      "Ctrl+O - Previsualizar la última mención @ y elegir un rango de líneas para enviar",
+	"help.ctrl_r":      "Ctrl+R - Interrumpir la respuesta y volver a preguntar con una nota",
+	"help.fold":        "Esc, luego Enter - Expandir/contraer la salida de herramienta visible",
+	"help.jump":        "Esc, luego [ / ] / g / G - Saltar al mensaje anterior/siguiente, al inicio o al final",
+
+	// Permissions
+	"permission.prompt":  "El modelo quiere ejecutar el siguiente comando:\n\n%s\n¿Deseas continuar?",
+	"permission.options": "(A) Permitir una vez   (Y) Sí a todo   (N) No / Mostrar comando",
+	"yolo.enabled":       "Modo YOLO activado. Todos los comandos se ejecutarán sin pedir permiso.",
+	"yolo.disabled":      "Modo YOLO desactivado. Los comandos destructivos requerirán permiso.",
+	"yolo.status_off":    "El modo YOLO está desactivado. Toda herramienta que cambie algo pide permiso.",
+	"yolo.status":        "Ámbitos YOLO: %s. Usa /yolo <ámbito> para alternar o /yolo off.",
+	"yolo.scoped":        "YOLO activo para: %s. Las demás herramientas siguen pidiendo permiso.",
+	"yolo.usage":         "Uso: /yolo [%s|all|off]...",
+
+	// Status messages
+	"copy.done":          "Última respuesta copiada al portapapeles.",
+	"copy.empty":         "No hay respuesta para copiar.",
+	"error.screen":       "Se produjo un error: %v\n\nPulsa Ctrl+C para salir.",
+	"editor.failed":      "Falló el editor: %v",
+	"editor.read_failed": "No se pudo leer el archivo del editor: %v",
+	"open.nothing":       "Nada que abrir. Usa /open <ruta> o /open <N> para un bloque de código.",
+	"open.out_of_range":  "La última respuesta tiene %d bloque(s) de código; %d está fuera de rango.",
+	"open.failed":        "No se puede abrir '%s': %v",
+	"open.remote":        "No se puede abrir %s: el espacio de trabajo está en un host remoto.",
+	"open.temp_failed":   "No se pudo escribir el archivo temporal: %v",
+	"image.failed":       "No se pudo leer una imagen del portapapeles: %v",
+	"image.attached":     "Imagen guardada en %s y adjuntada a tu siguiente mensaje.",
+	"image.attach_error": "No se pudieron adjuntar las imágenes: %v",
+	"history.usage":      "Uso: /history <consulta> para buscar en sesiones guardadas, luego /history open <N>.",
+	"history.open_usage": "Uso: /history open <N> con N entre 1 y %d.",
+	"history.open_error": "No se pudo abrir la sesión %s: %v",
+	"history.failed":     "La búsqueda falló: %v",
+	"history.none":       "Ningún mensaje guardado coincide con %q.",
+	"history.found":      "%d coincidencia(s) para %q:",
+	"history.hint":       "Usa /history open <N> para abrir una sesión en el mensaje coincidente.",
+	"run.usage":          "Uso: /run [-i] <comando>. Usa -i para incluir la salida en tu siguiente mensaje.",
+	"run.suggested":      "Sugerencias para este proyecto: %s",
+	"persona.title":      "Personas:",
+	"persona.active":     "(activa)",
+	"persona.hint":       "Usa /persona <nombre> para cambiar o /persona off para quitarla.",
+	"persona.unknown":    "Persona desconocida %q. Disponibles: %s",
+	"persona.cleared":    "Persona eliminada.",
+	"persona.set":        "Persona cambiada a %s.",
+
+	// Accessible mode
+	"a11y.waiting":          "Esperando respuesta.",
+	"a11y.done":             "Respuesta completa.",
+	"a11y.tool_call":        "El asistente solicitó la herramienta %s.",
+	"a11y.error":            "Error",
+	"a11y.permission_keys":  "Se requiere permiso. Pulsa A para permitir una vez, Y para permitir siempre, N para denegar.",
+	"a11y.focus_input":      "Foco: entrada",
+	"a11y.focus_transcript": "Foco: conversación",
+
+	// Text to speech
+	"speak.enabled":  "Las respuestas se leerán en voz alta con: %s",
+	"speak.disabled": "Lectura en voz alta desactivada.",
+	"speak.failed":   "Falló la síntesis de voz: %v",
+
+	// Dictation
+	"dictation.not_configured": "Configura stt_command en config.json para usar el dictado.",
+	"dictation.failed":         "Falló el dictado: %v",
+
+	// Tool output folding
+	"fold.summary":     "▸ `%s` · %s · %s (Esc, luego Enter para expandir)",
+	"fold.ok":          "ok",
+	"fold.error":       "error",
+	"fold.error_class": "error: %s",
+	"fold.truncated":   "truncado",
+	"fold.formatted":   "formateado",
+	"fold.stderr":      "stderr:",
+	"fold.cached":      "en caché",
+	"fold.suspicious":  "⚠ posible inyección de prompt",
+
+	// Agent mode
+	"agent.on":         "Modo agente activado: las herramientas están disponibles.",
+	"agent.off":        "Modo agente desactivado: chat simple, no se envían instrucciones de herramientas.",
+	"agent.status_on":  "El modo agente está activado. Usa /agent off para chat simple.",
+	"agent.status_off": "El modo agente está desactivado. Usa /agent on para activar herramientas.",
+	"agent.usage":      "Uso: /agent [on|off]",
+
+	// Prompt injection guard
+	"guard.suspicious": "⚠ %s devolvió texto con apariencia de instrucciones; trata su contenido con cuidado.",
+
+	// Approval queue
+	"batch.title":       "El modelo quiere ejecutar %d llamadas a herramientas. Revisa las que necesitan permiso:",
+	"batch.no_approval": "(no requiere aprobación)",
+	"batch.changeset":   "(sus cambios se revisan después)",
+	"batch.keys":        "↑/↓ mover · Espacio alternar · A permitir todo · N denegar todo · Enter ejecutar · Esc denegar y parar",
+
+	// Context bundles
+	"bundle.title":       "Paquetes de contexto:",
+	"bundle.none":        "Aún no hay paquetes de contexto. Crea uno con /bundle save <nombre> <archivos|globs|urls>...",
+	"bundle.saved":       "Paquete %s guardado con %d elemento(s).",
+	"bundle.deleted":     "Paquete %s eliminado.",
+	"bundle.unknown":     "Paquete desconocido %q.",
+	"bundle.loading":     "Cargando paquete %s...",
+	"bundle.loaded":      "Paquete %s: %d elemento(s) adjuntos a tu próximo mensaje.",
+	"bundle.load_failed": "No se pudo cargar: %s",
+	"bundle.failed":      "Error de paquete: %v",
+	"bundle.usage":       "Uso: /bundle [list] | /bundle save <nombre> <archivo|glob|url>... | /bundle load <nombre> | /bundle delete <nombre>",
+
+	// Last file
+	"last.none": "Ninguna herramienta ha usado un archivo todavía.",
+
+	// Transcript retention
+	"older.usage":        "Uso: /older [N]",
+	"older.none":         "No hay mensajes archivados en esta sesión.",
+	"older.failed":       "No se pudieron cargar los mensajes archivados: %v",
+	"older.restored":     "Se recuperaron %d mensajes archivados (quedan %d).",
+	"older.restored_all": "Se recuperaron %d mensajes archivados; la conversación completa está cargada.",
+
+	// Model capabilities
+	"model.no_tools":       "%s no anuncia soporte de herramientas; el modo agente depende de que responda en JSON y puede no ser fiable. Usa /agent off para chatear sin herramientas.",
+	"model.no_vision":      "%s no admite imágenes; probablemente se ignorará el adjunto.",
+	"model.thinking":       "Razonamiento",
+	"model.embedding_only": "%s es un modelo de embeddings y no se puede usar para chatear.",
+
+	// Context size
+	"ctx.status":    "Tamaño de contexto: %d tokens.",
+	"ctx.session":   "(fijado para esta sesión; /ctx reset restaura el valor configurado)",
+	"ctx.max":       "El modelo admite hasta %d.",
+	"ctx.usage":     "Uso: /ctx [N|Nk|reset], p. ej. /ctx 16k",
+	"ctx.too_large": "%d es más de lo que admite %s (%d).",
+	"ctx.clamped":   "context_length %d es más de lo que admite %s; se usará %d.",
+
+	// Model picker
+	"picker.title":         "Selecciona un modelo",
+	"picker.filter":        "escribe para filtrar",
+	"picker.empty":         "Ningún modelo coincide con el filtro.",
+	"picker.keys":          "↑/↓ mover · escribe para filtrar · Enter seleccionar · Esc cancelar",
+	"picker.list_failed":   "No se pudieron listar los modelos: %v",
+	"picker.switch_failed": "No se pudo cambiar a %s: %v",
+	"picker.switched":      "Cambiado a %s.",
+
+	// Sharing
+	"share.empty":  "Todavía no hay nada que compartir.",
+	"share.failed": "No se pudo escribir la página HTML: %v",
+	"share.saved":  "Conversación guardada en %s",
+
+	// Translation
+	"translate.usage":   "Uso: /translate <idioma>, o define respond_language en config.json",
+	"translate.nothing": "Todavía no hay ninguna respuesta que traducir.",
+	"translate.failed":  "La traducción falló: %v",
+
+	// Write conflicts
+	"conflict.warning": "Atención: %s cambió en disco después de que el agente lo leyera. Aprobar sobrescribe esos cambios.",
+	"conflict.batch":   "(modificado en disco desde la lectura)",
+
+	// Trash
+	"undo.empty":    "Nada que deshacer: en esta sesión no se aplicó ningún conjunto de cambios ni se borró ningún archivo.",
+	"undo.restored": "%s restaurado desde la papelera.",
+	"undo.failed":   "No se pudo deshacer el cambio: %v",
+
+	// Replay
+	"replay.footer":    "Paso %d/%d · %s · %s · Espacio siguiente · b atrás · g/G primero/último · q salir",
+	"replay.tool_call": "**Llamada a herramienta:**",
+	"replay.images":    "*%d imagen(es) adjunta(s)*",
+
+	// Forwarding between sessions
+	"sendto.usage":      "Uso: /send-to <sesión> [texto|@archivo]. Sin texto se envía la última respuesta. Esta sesión es %s.",
+	"sendto.self":       "Esa es esta sesión.",
+	"sendto.nothing":    "Todavía no hay ninguna respuesta para reenviar.",
+	"sendto.read_error": "No se pudo leer %s: %v",
+	"sendto.failed":     "No se pudo reenviar a %s: %v",
+	"sendto.sent":       "Reenviado a la sesión %s.",
+	"sendto.received":   "Se recibieron %d mensaje(s) reenviado(s) de %s.",
+
+	// Planner and executor
+	"plan.usage":    "Uso: /plan <tarea>. El modelo planificador divide la tarea en pasos y el modelo ejecutor los realiza uno a uno. /plan next pasa al siguiente paso, /plan stop termina el plan.",
+	"plan.planning": "Planificando con %s...",
+	"plan.failed":   "La planificación falló: %v",
+	"plan.empty":    "El planificador no respondió con una lista de pasos.",
+	"plan.title":    "**Plan** (ejecutor: %s)",
+	"plan.step":     "**Paso %d/%d:** %s",
+	"plan.done":     "Todos los pasos del plan están hechos.",
+	"plan.stopped":  "Plan detenido.",
+	"plan.none":     "No hay ningún plan en curso.",
+
+	// Critic
+	"critic.usage":    "Uso: /critic [on|off|auto]. Con auto se revisa cuando la persona o la configuración define un crítico.",
+	"critic.active":   "%s revisa las respuestas y los cambios de archivos.",
+	"critic.inactive": "No hay ninguna revisión activa.",
+	"critic.pending":  "revisando...",
+	"critic.failed":   "La revisión falló: %v",
+	"critic.title":    "**Revisión** (%s)",
+	"critic.change":   "Revisión: %s",
+
+	// Task list
+	"tasks.title": "Tareas: %d/%d hechas",
+	"tasks.more":  "… %d más",
+
+	// Mention preview
+	"mention.none":       "No hay ninguna mención @ en la entrada para previsualizar.",
+	"mention.read_error": "No se pudo leer %s: %v",
+	"mention.title":      "@%s: se enviarán %d líneas, %s, ~%d tokens",
+	"mention.range":      "Líneas: ",
+	"mention.keys":       "↑/↓ RePág/AvPág desplazar · escribe un rango como 10-40 · Enter aplicar · Esc cerrar",
+
+	// Queued prompts
+	"queue.item": "⏳ en cola: %s",
+
+	// Interrupt and redirect
+	"redirect.interrupted": "_Interrumpido_",
+	"redirect.placeholder": "Nota para el modelo (Enter para volver a preguntar, Esc para conservar la respuesta parcial)",
+	"redirect.note":        "↪ %s",
+	"footer.redirect":      "Interrumpido: escribe una nota",
+
+	// Forget
+	"forget.title":    "Mensajes recientes (usa /forget N para excluir uno de futuras peticiones):",
+	"forget.marker":   "(olvidado)",
+	"forget.excluded": "El mensaje #%d se omite en futuras peticiones.",
+	"forget.included": "El mensaje #%d se vuelve a enviar al modelo.",
+	"forget.usage":    "Uso: /forget [N...] con números de mensaje del 1 al %d (ver /forget).",
+
+	// Ollama errors and /pull
+	"remedy.not_found": "El modelo %s no está instalado en el servidor de Ollama. Pulsa Enter para descargarlo ahora o elige otro modelo con /model.",
+	"remedy.memory":    "%s no cabe en la memoria libre (%s).\n\nPrueba una de estas opciones y vuelve a enviar tu mensaje (↑ lo recupera):\n- un contexto más pequeño: /ctx %d (ahora %d)\n- una cuantización más pequeña, p. ej. una etiqueta q4_K_M, o un modelo con menos parámetros (/model)\n- descargar otros modelos: ollama ps y luego ollama stop <modelo>",
+	"remedy.server":    "Ollama falló con %s (reintentado %d veces): %s\n\nRevisa el log de ollama serve y vuelve a enviar tu mensaje (↑ lo recupera). Si sigue fallando, prueba un contexto más pequeño con /ctx u otro modelo con /model.",
+	"remedy.other":     "Ollama rechazó la petición: %v",
+	"pull.busy":        "Ya se está descargando %s.",
+	"pull.failed":      "No se pudo descargar %s: %v",
+	"pull.done":        "%s descargado. Vuelve a enviar tu mensaje (↑ lo recupera).",
+	"footer.pulling":   "Descargando %s: %s",
+
+	// Context guard
+	"guard.prompt":         "Esta petición tiene unos %d tokens, pero el contexto (num_ctx) admite %d. Ollama descartaría en silencio el inicio de la conversación, incluido el prompt del sistema.",
+	"guard.compact":        "(C) Compactar mensajes antiguos",
+	"guard.drop":           "(D) Descartar adjuntos",
+	"guard.send":           "(S) Enviar de todos modos   (Esc) Volver a la entrada",
+	"guard.compacting":     "Resumiendo mensajes antiguos...",
+	"guard.nothing":        "No hay mensajes antiguos que compactar.",
+	"guard.compact_failed": "La compactación falló: %v",
+	"guard.compacted":      "%d mensajes compactados en este resumen, que los sustituye en las peticiones:",
+
+	// Presets
+	"preset.title":    "Presets de opciones (añade los tuyos en \"presets\" de config.json):",
+	"preset.active":   "(activo)",
+	"preset.off":      "Preset desactivado. Las peticiones usan las opciones configuradas y las de la persona.",
+	"preset.unknown":  "Preset desconocido %q. Usa /preset para listarlos.",
+	"preset.selected": "Preset %s: %s",
+
+	// server
+	"server.old": "Ollama %s es demasiado antiguo para: %s. Estas funciones quedan desactivadas en esta sesión; actualiza Ollama para usarlas.",
+
+	// json
+	"json.usage":          "Uso: /json <archivo de esquema o esquema en línea> [prompt]. La próxima respuesta se restringe al esquema, se valida contra él y se muestra formateada.",
+	"json.pending":        "La próxima respuesta sigue el esquema de %s. Usa /json off para cancelar.",
+	"json.set":            "La próxima respuesta seguirá el esquema de %s.",
+	"json.off":            "La próxima respuesta ya no está restringida a un esquema.",
+	"json.unsupported":    "Ollama %s no admite salidas estructuradas; actualiza a 0.5 o posterior para usar /json.",
+	"json.invalid_schema": "El esquema de %s no es JSON válido: %v",
+	"json.read_failed":    "No se pudo leer el esquema %s: %v",
+	"json.not_json":       "_La respuesta no es JSON válido: %v_",
+	"json.valid":          "_Coincide con el esquema de %s._",
+	"json.invalid":        "**No coincide con el esquema de %s:**",
+
+	// artifacts
+	"artifacts.none":              "El agente no ha escrito archivos en esta sesión.",
+	"artifacts.title":             "Archivos escritos por el agente (%d)",
+	"artifacts.created":           "creado",
+	"artifacts.modified":          "modificado",
+	"artifacts.deleted":           "eliminado",
+	"artifacts.unchanged":         "sin cambios",
+	"artifacts.unreadable":        "ilegible",
+	"artifacts.no_diff":           "Sin cambios respecto a antes de que el agente lo escribiera.",
+	"artifacts.keys":              "↑/↓ seleccionar · Enter diff · RePág/AvPág desplazar · o abrir · r revertir · c copiar ruta · Esc cerrar",
+	"artifacts.missing":           "%s no existe.",
+	"artifacts.copied":            "%s copiado al portapapeles.",
+	"artifacts.copy_failed":       "No se pudo copiar al portapapeles: %v",
+	"artifacts.nothing_to_revert": "%s está como antes de que el agente lo escribiera.",
+	"artifacts.confirm_revert":    "Pulsa r de nuevo para revertir %s a como estaba antes del agente. Los archivos creados van a la papelera (/undo los restaura).",
+	"artifacts.reverted":          "%s revertido.",
+	"artifacts.revert_failed":     "No se pudo revertir %s: %v",
+
+	// stall
+	"stall.prompt":   "⚠ La respuesta se ha detenido: no llega nada de Ollama desde hace %s.",
+	"stall.options":  "[R] Reintentar   [C] Cancelar   [W] Seguir esperando",
+	"stall.canceled": "Se canceló la respuesta detenida.",
+
+	// stream
+	"stream.busy": "Todavía hay una petición en curso, así que esta no se envió. Espera la respuesta o detenla con /stop.",
+
+	// Permission rules
+	"permission.rule_dir": "(D) Permitir siempre para %s",
+	"permission.rule_ext": "(E) Permitir siempre para %s",
+	"permissions.added":   "Regla guardada: %s. Se aplica desde ahora en este espacio de trabajo; /permissions muestra las reglas.",
+	"permissions.deleted": "Regla eliminada: %s.",
+	"permissions.failed":  "No se pudieron guardar las reglas de permisos: %v",
+	"permissions.none":    "No hay reglas de permisos guardadas. Pulsa D o E en una solicitud de permiso para añadir una.",
+	"permissions.title":   "**Reglas de permisos** (las herramientas se ejecutan sin preguntar para los archivos que coinciden):",
+	"permissions.unknown": "No existe la regla de permisos %s.",
+	"permissions.usage":   "Uso: /permissions [delete <n>]",
+
+	// History expansion
+	"expand.empty":   "No hay ninguna entrada anterior que repetir con !!.",
+	"expand.unknown": "No existe la entrada !%d; esta sesión tiene %d. /history las muestra.",
+	"expand.title":   "**Entradas de esta sesión:**",
+	"expand.hint":    "Vuelve a enviar una con !N, la última con !!. El texto que sigue se añade, p. ej. `!! in Go`.",
+
+	// Tool statistics
+	"toolstats.none":   "Todavía no se ha llamado a ninguna herramienta en esta sesión.",
+	"toolstats.title":  "**Uso de herramientas:** %d llamadas a %d herramientas, %d fallidas. Ordenado por tiempo total.",
+	"toolstats.header": "| Herramienta | Llamadas | Fallidas | Media | Total | Parte del tiempo |",
+
+	// Changesets
+	"changeset.title":    "El modelo propone un conjunto de cambios de %d archivos que se aplican juntos:",
+	"changeset.conflict": "cambiado desde la lectura: %s",
+	"changeset.keys":     "↑/↓ archivo  RePág/AvPág desplazar diff  (A) Aplicar todo  (N) No",
+	"changeset.denied":   "Conjunto de cambios de %d archivos rechazado por el usuario: %s",
+	"undo.changeset":     "Conjunto de cambios de %d archivos revertido: %s.",
+
+	// Explain
+	"explain.usage":      "Uso: /explain <ruta[:línea]>, p. ej. /explain main.go:42",
+	"explain.read_error": "No se pudo leer %s: %v",
+	"explain.no_line":    "%s solo tiene %d líneas.",
+
+	// Sources
+	"sources.heading":     "Fuentes",
+	"sources.title":       "%d fuente(s) web en esta sesión (/sources N abre una):",
+	"sources.none":        "Aún no hay fuentes web. Se recogen de web_search, visit_url y read_feed.",
+	"sources.unknown":     "No existe la fuente %s. /sources las lista.",
+	"sources.opened":      "Se abrió %s",
+	"sources.open_failed": "No se pudo abrir %s: %v",
+
+	// Long replies, see spool.go
+	"spool.more":       "… %d líneas más (%s) no se muestran. /open-full abre la respuesta completa.",
+	"spool.more_saved": "… %d líneas más (%s) no se muestran. /open-full abre la respuesta completa, guardada en `%s`.",
+	"spool.none":       "Ninguna respuesta era demasiado larga para mostrarla. Usa /open-full <N> para abrir el mensaje N.",
+	"spool.unknown":    "El mensaje %s no es una respuesta del modelo.",
+	"spool.failed":     "No se pudo guardar la respuesta: %v",
+
+	// Message timestamps, see timestamps.go
+	"timestamps.usage":    "Uso: /timestamps [off|absolute|relative]",
+	"timestamps.off":      "Las horas de los mensajes están ocultas.",
+	"timestamps.absolute": "Los encabezados muestran la hora en que se añadió el mensaje.",
+	"timestamps.relative": "Los encabezados muestran hace cuánto se añadió el mensaje.",
+	"time.just_now":       "ahora mismo",
+	"time.minutes_ago":    "hace %d min",
+	"time.hours_ago":      "hace %d h",
+	"time.days_ago":       "hace %d d",
+
+	// Scroll lock, see followStream
+	"footer.new_below": "↓ Contenido nuevo abajo (Esc, luego G)",
+}
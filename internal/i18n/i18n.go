@@ -0,0 +1,84 @@
+// Package i18n holds the message catalogs for user interface strings. Only
+// the interface is translated; prompts and tool results exchanged with the
+// model stay in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Catalog maps a message key to a format string.
+type Catalog map[string]string
+
+// catalogs holds every supported locale. English is the fallback for keys
+// missing from other catalogs.
+var catalogs = map[string]Catalog{
+	"en": english,
+	"de": german,
+	"es": spanish,
+}
+
+var current = "en"
+
+// SetLocale selects the catalog used by T. Values such as "de_DE.UTF-8" are
+// reduced to their language code. An empty value is detected from the
+// environment. Unknown locales fall back to English. It returns the locale
+// in effect.
+func SetLocale(locale string) string {
+	if locale == "" {
+		locale = Detect()
+	}
+	lang := normalize(locale)
+	if _, ok := catalogs[lang]; ok {
+		current = lang
+	} else {
+		current = "en"
+	}
+	return current
+}
+
+// Detect returns the locale from LC_ALL, LC_MESSAGES or LANG.
+func Detect() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" && v != "C" && v != "POSIX" {
+			return v
+		}
+	}
+	return "en"
+}
+
+// Locales returns the supported locale codes.
+func Locales() []string {
+	var locales []string
+	for lang := range catalogs {
+		locales = append(locales, lang)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// T returns the translated message for key, formatted with args.
+func T(key string, args ...interface{}) string {
+	format, ok := catalogs[current][key]
+	if !ok {
+		format, ok = english[key]
+	}
+	if !ok {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func normalize(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "_-.@"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"prompt-cli/internal/appdir"
 )
 
 // Logger handles file-based logging.
@@ -23,13 +24,13 @@ func NewLogger() *Logger {
 
 // Setup creates the necessary log directory.
 func (l *Logger) Setup() {
-	exePath, err := os.Executable()
+	appDir, err := appdir.Dir()
 	if err != nil {
-		// For now, we'll just log the error to stderr if we can't find the executable path.
-		log.Printf("Error getting executable path for logging: %v", err)
+		// For now, we'll just log the error to stderr if we can't find the application directory.
+		log.Printf("Error getting application directory for logging: %v", err)
 		return
 	}
-	logDir := filepath.Join(filepath.Dir(exePath), "logs")
+	logDir := filepath.Join(appDir, "logs")
 	if _, err := os.Stat(logDir); os.IsNotExist(err) {
 		os.Mkdir(logDir, 0755)
 	}
@@ -49,11 +50,11 @@ func (l *Logger) Toggle() string {
 	var logMsg string
 	if l.enabled {
 		var err error
-		exePath, err := os.Executable()
+		appDir, err := appdir.Dir()
 		if err != nil {
-			logMsg = fmt.Sprintf("Error getting executable path: %v", err)
+			logMsg = fmt.Sprintf("Error getting application directory: %v", err)
 		} else {
-			logPath := filepath.Join(filepath.Dir(exePath), "logs", "log.txt")
+			logPath := filepath.Join(appDir, "logs", "log.txt")
 			l.logFile, err = os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 			if err != nil {
 				logMsg = fmt.Sprintf("Error opening log file: %v", err)
@@ -0,0 +1,99 @@
+package ollama
+
+import (
+	"prompt-cli/internal/types"
+	"strings"
+)
+
+// Capabilities describes what a model can do according to the
+// "capabilities" list of /api/show. Older Ollama servers do not report
+// capabilities; Reported is false then and callers should assume the
+// model can do everything rather than disable features.
+type Capabilities struct {
+	Reported   bool
+	Completion bool
+	Tools      bool
+	Vision     bool
+	Thinking   bool
+	Embedding  bool
+}
+
+// ParseCapabilities turns the capability names reported by Ollama into
+// Capabilities. Unknown names are ignored.
+func ParseCapabilities(names []string) Capabilities {
+	caps := Capabilities{Reported: len(names) > 0}
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "completion":
+			caps.Completion = true
+		case "tools":
+			caps.Tools = true
+		case "vision":
+			caps.Vision = true
+		case "thinking":
+			caps.Thinking = true
+		case "embedding":
+			caps.Embedding = true
+		}
+	}
+	return caps
+}
+
+// GetCapabilities fetches the capabilities of a model. An error leaves the
+// result unreported.
+func GetCapabilities(baseURL, modelName string) (Capabilities, error) {
+	details, err := GetModelDetails(baseURL, modelName)
+	if err != nil {
+		return Capabilities{}, err
+	}
+	return ParseCapabilities(details.Capabilities), nil
+}
+
+// ListCapabilities fetches the capabilities of every model, skipping
+// models whose details cannot be read.
+func ListCapabilities(baseURL string, models []types.Model) map[string]Capabilities {
+	capabilities := make(map[string]Capabilities, len(models))
+	for _, model := range models {
+		if caps, err := GetCapabilities(baseURL, model.Name); err == nil {
+			capabilities[model.Name] = caps
+		}
+	}
+	return capabilities
+}
+
+// CanChat reports whether the model can be used for chat. Embedding-only
+// models cannot.
+func (c Capabilities) CanChat() bool {
+	return !c.Reported || c.Completion || !c.Embedding
+}
+
+// HasTools reports whether the model supports tool calling, assuming it
+// does when nothing was reported.
+func (c Capabilities) HasTools() bool {
+	return !c.Reported || c.Tools
+}
+
+// HasVision reports whether the model accepts images, assuming it does when
+// nothing was reported.
+func (c Capabilities) HasVision() bool {
+	return !c.Reported || c.Vision
+}
+
+// String lists the notable capabilities, e.g. "tools, vision".
+func (c Capabilities) String() string {
+	var names []string
+	for _, capability := range []struct {
+		on   bool
+		name string
+	}{
+		{c.Tools, "tools"},
+		{c.Vision, "vision"},
+		{c.Thinking, "thinking"},
+		{c.Embedding, "embedding"},
+	} {
+		if capability.on {
+			names = append(names, capability.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
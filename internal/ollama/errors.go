@@ -0,0 +1,100 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxRetries is how often a request that failed with a server error is
+// repeated before the error is reported.
+const maxRetries = 2
+
+// retryDelay is the wait before the first retry; it doubles for each one.
+const retryDelay = time.Second
+
+// APIError is an error response of the Ollama API.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string // The "error" field of the response, or its body.
+	Retries    int    // How often the request was repeated before giving up.
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ollama returned %s: %s", e.Status, e.Message)
+}
+
+// ModelNotFound reports whether the requested model is not installed.
+func (e *APIError) ModelNotFound() bool {
+	return e.StatusCode == http.StatusNotFound && strings.Contains(strings.ToLower(e.Message), "not found")
+}
+
+// OutOfMemory reports whether the model could not be loaded because it
+// needs more (V)RAM than is free.
+func (e *APIError) OutOfMemory() bool {
+	message := strings.ToLower(e.Message)
+	for _, hint := range []string{"requires more system memory", "out of memory", "cudamalloc", "insufficient memory", "not enough memory"} {
+		if strings.Contains(message, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryable reports whether repeating the request may help: a server
+// error that is not about memory, which would only fail again.
+func (e *APIError) retryable() bool {
+	return e.StatusCode >= 500 && !e.OutOfMemory()
+}
+
+// newAPIError reads the error response resp.
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(resp.Body)
+	apiErr := &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Message: string(bytes.TrimSpace(body))}
+	var parsed struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error != "" {
+		apiErr.Message = parsed.Error
+	}
+	return apiErr
+}
+
+// post sends body to the API endpoint path and returns the response if it
+// succeeded. Server errors are retried with a growing delay; the final
+// failure is returned as an *APIError.
+func (c *OllamaClient) post(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", c.apiURL+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		apiErr := newAPIError(resp)
+		resp.Body.Close()
+		apiErr.Retries = attempt
+		if !apiErr.retryable() || attempt == maxRetries {
+			return nil, apiErr
+		}
+		c.logger.Log(fmt.Sprintf("Ollama request failed (%v), retrying in %s", apiErr, delay))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
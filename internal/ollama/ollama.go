@@ -66,44 +66,29 @@ func GetModelDetails(baseURL, modelName string) (*types.ShowModelResponse, error
 }
 
 // ExtractContextLength extracts the context length from a
-// ModelInfo structure. Because different model architectures
-// expose the context length under different field names, this
-// function checks the architecture string and pulls the value
-// from the appropriate field. If the architecture is
-// unknown, it falls back to checking all known fields.
-func ExtractContextLength(modelInfo *types.ModelInfo) int64 {
-	// Determine the architecture in a case‑insensitive way.
-	arch := strings.ToLower(modelInfo.Architecture)
-
-	// Use the architecture to decide which field holds the
-	// context length. This mirrors the mapping used by
-	// Ollama’s own SDKs.
-	switch arch {
-	case "llama", "llama2", "llama3":
-		return ConvertToInteger(modelInfo.LlamaContextLength)
-	case "gemma", "gemma2", "gemma3":
-		return ConvertToInteger(modelInfo.GemmaContextLength)
-	case "mistral":
-		return ConvertToInteger(modelInfo.MistralContextLength)
-	case "gptoss": // gpt‑oss models
-		return ConvertToInteger(modelInfo.GptossContextLength)
-	default:
-		// Architecture not recognised – try each known field.
-		if result := ConvertToInteger(modelInfo.LlamaContextLength); result > 0 {
+// model's model_info. Ollama stores it under
+// "<architecture>.context_length", so the key for the reported
+// general.architecture is tried first. For anything else, e.g.
+// an architecture that names its key differently, every
+// "*.context_length" key is scanned and the largest value wins.
+// New architectures therefore need no code changes.
+func ExtractContextLength(modelInfo types.ModelInfo) int64 {
+	if arch, ok := modelInfo["general.architecture"].(string); ok && arch != "" {
+		if result := ConvertToInteger(modelInfo[arch+".context_length"]); result > 0 {
 			return result
 		}
-		if result := ConvertToInteger(modelInfo.GemmaContextLength); result > 0 {
-			return result
-		}
-		if result := ConvertToInteger(modelInfo.MistralContextLength); result > 0 {
-			return result
+	}
+
+	var result int64
+	for key, value := range modelInfo {
+		if !strings.HasSuffix(key, ".context_length") {
+			continue
 		}
-		if result := ConvertToInteger(modelInfo.GptossContextLength); result > 0 {
-			return result
+		if n := ConvertToInteger(value); n > result {
+			result = n
 		}
 	}
-
-	return 0
+	return result
 }
 
 // ConvertToInteger safely converts an arbitrary interface{} value
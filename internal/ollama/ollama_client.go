@@ -1,24 +1,25 @@
 package ollama
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"prompt-cli/internal/logger"
 	"prompt-cli/internal/types"
 	"sync"
 	"time"
-
-
 )
 
 // OllamaClient is responsible for communicating with the Ollama API.
 type OllamaClient struct {
-	apiURL string
-	logger *logger.Logger
+	apiURL       string
+	logger       *logger.Logger
+	cache        promptCache     // Prefix of the last streamed request, see prompt_cache.go.
+	think        bool            // Request separate reasoning from thinking models.
+	features     Features        // What the server supports, see version.go.
+	format       json.RawMessage // JSON schema for streamed answers, see SetFormat.
+	hideThoughts bool            // Leave the thoughts of agent replies out, see thoughts.go.
 }
 
 // NewOllamaClient creates a new OllamaClient.
@@ -29,37 +30,56 @@ func NewOllamaClient(apiURL string, logger *logger.Logger) *OllamaClient {
 	}
 }
 
+// SetThink makes requests ask for the model's reasoning, which thinking
+// models then return in Message.Thinking instead of the content.
+func (c *OllamaClient) SetThink(think bool) {
+	c.think = think
+}
 
+// SetFormat constrains streamed answers to the JSON schema format, or lifts
+// the constraint when it is nil.
+func (c *OllamaClient) SetFormat(format json.RawMessage) {
+	c.format = format
+}
 
-func (c *OllamaClient) StartStream(ctx context.Context, modelName string, messages []types.Message, contextLength int64, stream chan interface{}, wg *sync.WaitGroup) {
+func (c *OllamaClient) StartStream(ctx context.Context, modelName string, messages []types.Message, options types.Options, stream chan interface{}, wg *sync.WaitGroup) {
 	go func() {
 		defer close(stream)
+		// A canceled reply is no longer read from the channel, so every send
+		// gives up with the context instead of blocking forever.
+		send := func(event interface{}) bool {
+			select {
+			case stream <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
 
 		req := types.ChatRequest{
 			Model:    modelName,
-			Messages: messages,
+			Messages: c.adaptMessages(messages),
 			Stream:   true,
-			Options:  types.Options{NumCtx: contextLength},
+			Options:  options,
+			Think:    c.think && c.features.HasThinking(),
+			Format:   c.format,
 		}
 		reqBody, err := json.Marshal(req)
 		if err != nil {
-			stream <- types.ErrorMsg{Err: err}
+			send(types.ErrorMsg{Err: err})
 			return
 		}
 
 		c.logger.Log(fmt.Sprintf("Sending request to Ollama: %s", string(reqBody)))
-
-		httpReq, err := http.NewRequestWithContext(ctx, "POST", c.apiURL+"/api/chat", bytes.NewBuffer(reqBody))
-		if err != nil {
-			c.logger.Log(fmt.Sprintf("Error creating request: %v", err))
-			stream <- types.ErrorMsg{Err: err}
-			return
+		reused := c.cache.reusable(modelName, messages)
+		if reused == 0 {
+			c.logger.Log("Conversation prefix changed; Ollama cannot reuse its prompt cache.")
 		}
 
-		resp, err := http.DefaultClient.Do(httpReq)
+		resp, err := c.post(ctx, "/api/chat", reqBody)
 		if err != nil {
 			c.logger.Log(fmt.Sprintf("Error sending request: %v", err))
-			stream <- types.ErrorMsg{Err: err}
+			send(types.ErrorMsg{Err: err})
 			return
 		}
 		defer resp.Body.Close()
@@ -68,7 +88,7 @@ func (c *OllamaClient) StartStream(ctx context.Context, modelName string, messag
 
 		startTime := time.Now()
 		var finalResponse types.ChatResponse // For stats at the end
-		var accumulatedMessage types.Message    // Accumulate the full message here
+		var accumulatedMessage types.Message // Accumulate the full message here
 
 		decoder := json.NewDecoder(resp.Body)
 		for {
@@ -76,14 +96,17 @@ func (c *OllamaClient) StartStream(ctx context.Context, modelName string, messag
 			if err := decoder.Decode(&chatResp); err == io.EOF {
 				break
 			} else if err != nil {
-				stream <- types.ErrorMsg{Err: fmt.Errorf("error decoding stream chunk: %v", err)}
+				send(types.ErrorMsg{Err: fmt.Errorf("error decoding stream chunk: %v", err)})
 				break
 			}
 
 			// Send content chunk for live display
 			if chatResp.Message.Content != "" {
 				wg.Add(1)
-				stream <- types.StreamChunkMsg(chatResp.Message.Content)
+				if !send(types.StreamChunkMsg(chatResp.Message.Content)) {
+					wg.Done()
+					return
+				}
 			}
 
 			// Accumulate the complete message object
@@ -91,6 +114,7 @@ func (c *OllamaClient) StartStream(ctx context.Context, modelName string, messag
 				accumulatedMessage.Role = chatResp.Message.Role
 			}
 			accumulatedMessage.Content += chatResp.Message.Content
+			accumulatedMessage.Thinking += chatResp.Message.Thinking
 			if len(chatResp.Message.ToolCalls) > 0 {
 				accumulatedMessage.ToolCalls = append(accumulatedMessage.ToolCalls, chatResp.Message.ToolCalls...)
 			}
@@ -108,7 +132,46 @@ func (c *OllamaClient) StartStream(ctx context.Context, modelName string, messag
 		if duration.Seconds() > 0 {
 			tokensPerSecond = float64(finalResponse.EvalCount) / duration.Seconds()
 		}
-		stats := fmt.Sprintf("Time: %.2fs | Tokens/sec: %.2f", duration.Seconds(), tokensPerSecond)
-		stream <- types.StreamDoneMsg{Stats: stats, FinalMessage: accumulatedMessage} // Send the *accumulated* message
+		stats := fmt.Sprintf("Time: %.2fs | Tokens/sec: %.2f | %s", duration.Seconds(), tokensPerSecond, promptStats(finalResponse.PromptEvalCount, reused))
+		if finalResponse.Done {
+			c.cache.update(modelName, messages, accumulatedMessage, reused+finalResponse.PromptEvalCount, finalResponse.EvalCount)
+		}
+		send(types.StreamDoneMsg{
+			Stats:        stats,
+			FinalMessage: accumulatedMessage, // Send the *accumulated* message
+			PromptTokens: finalResponse.PromptEvalCount,
+			OutputTokens: finalResponse.EvalCount,
+			Duration:     duration,
+		})
 	}()
-}
\ No newline at end of file
+}
+
+// Chat sends a single non-streaming chat request and returns the complete
+// response. It is used by the non-interactive subcommands.
+func (c *OllamaClient) Chat(ctx context.Context, modelName string, messages []types.Message, options types.Options) (types.ChatResponse, error) {
+	req := types.ChatRequest{
+		Model:    modelName,
+		Messages: c.adaptMessages(messages),
+		Stream:   false,
+		Options:  options,
+		Think:    c.think && c.features.HasThinking(),
+	}
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return types.ChatResponse{}, err
+	}
+
+	c.logger.Log(fmt.Sprintf("Sending request to Ollama: %s", string(reqBody)))
+
+	resp, err := c.post(ctx, "/api/chat", reqBody)
+	if err != nil {
+		return types.ChatResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var chatResp types.ChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return types.ChatResponse{}, fmt.Errorf("error decoding response: %v", err)
+	}
+	return chatResp, nil
+}
@@ -0,0 +1,69 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"prompt-cli/internal/types"
+	"sync"
+)
+
+// promptCache tracks the conversation sent in the previous request so the
+// client can tell whether Ollama is able to reuse its cached prompt. Ollama
+// keeps the evaluated context of the last request per model and only has to
+// process the new tokens when the next prompt starts with the same messages.
+type promptCache struct {
+	mu       sync.Mutex
+	model    string
+	messages []string // Serialized messages of the last request plus its reply.
+	tokens   int      // Tokens in that context: prompt plus generated.
+}
+
+// reusable estimates how many tokens of the request can come from the cache.
+// It returns 0 when the model changed or an earlier message was edited.
+func (c *promptCache) reusable(model string, messages []types.Message) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if model != c.model || len(c.messages) == 0 || len(messages) < len(c.messages) {
+		return 0
+	}
+	for i, cached := range c.messages {
+		if serializeMessage(messages[i]) != cached {
+			return 0
+		}
+	}
+	return c.tokens
+}
+
+// update records a finished request and the reply it produced.
+func (c *promptCache) update(model string, messages []types.Message, reply types.Message, promptTokens, evalTokens int) {
+	serialized := make([]string, 0, len(messages)+1)
+	for _, msg := range messages {
+		serialized = append(serialized, serializeMessage(msg))
+	}
+	reply.Role = "assistant"
+	serialized = append(serialized, serializeMessage(reply))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.model = model
+	c.messages = serialized
+	c.tokens = promptTokens + evalTokens
+}
+
+func serializeMessage(msg types.Message) string {
+	data, err := json.Marshal(wireMessage(msg))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// promptStats formats the prompt token counts for the stats line. Ollama
+// reports only the tokens it had to evaluate, so the reused part is the
+// estimate from the previous request.
+func promptStats(evaluated, reused int) string {
+	if reused > 0 {
+		return fmt.Sprintf("Prompt: %d new, ~%d reused", evaluated, reused)
+	}
+	return fmt.Sprintf("Prompt: %d tokens", evaluated)
+}
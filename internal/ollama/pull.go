@@ -0,0 +1,56 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// PullProgress is a status update of /api/pull.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Percent returns how much of the current layer is downloaded, or -1 if
+// the update has no size.
+func (p PullProgress) Percent() int {
+	if p.Total <= 0 {
+		return -1
+	}
+	return int(p.Completed * 100 / p.Total)
+}
+
+// Pull downloads model to the Ollama server, calling progress for each
+// status update.
+func (c *OllamaClient) Pull(ctx context.Context, model string, progress func(PullProgress)) error {
+	body, err := json.Marshal(map[string]interface{}{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	c.logger.Log(fmt.Sprintf("Pulling model %s", model))
+	resp, err := c.post(ctx, "/api/pull", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var update PullProgress
+		if err := decoder.Decode(&update); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error decoding pull progress: %v", err)
+		}
+		if update.Error != "" {
+			return errors.New(update.Error)
+		}
+		progress(update)
+	}
+}
@@ -0,0 +1,40 @@
+package ollama
+
+import (
+	"encoding/json"
+	"prompt-cli/internal/types"
+	"strings"
+)
+
+// SetHideThoughts makes requests leave the "thoughts" of earlier agent
+// replies out, keeping only their actions, so long tool loops grow the
+// context more slowly. The replies then no longer match what the model
+// generated, which costs a little prompt cache reuse after each of them.
+func (c *OllamaClient) SetHideThoughts(hide bool) {
+	c.hideThoughts = hide
+}
+
+// stripThoughts removes the "thoughts" key from an agent JSON reply. Other
+// content is returned unchanged.
+func stripThoughts(content string) string {
+	if !strings.Contains(content, `"thoughts"`) {
+		return content
+	}
+	jsonStr, err := types.ExtractJSON(content)
+	if err != nil {
+		return content
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(jsonStr), &fields); err != nil {
+		return content
+	}
+	if _, ok := fields["thoughts"]; !ok {
+		return content
+	}
+	delete(fields, "thoughts")
+	stripped, err := json.Marshal(fields)
+	if err != nil {
+		return content
+	}
+	return string(stripped)
+}
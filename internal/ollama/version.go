@@ -0,0 +1,179 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"prompt-cli/internal/types"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server versions that introduced the features the client relies on.
+var (
+	toolsVersion      = [3]int{0, 3, 0} // The "tool" message role.
+	structuredVersion = [3]int{0, 5, 0} // A JSON schema as "format".
+	thinkingVersion   = [3]int{0, 9, 0} // The "think" request field.
+)
+
+// Features describes what the Ollama server supports according to its
+// version. Like Capabilities, Known is false when the version could not be
+// read, e.g. for development builds, and callers should then assume
+// everything is supported.
+type Features struct {
+	Known             bool
+	Version           string
+	Tools             bool
+	StructuredOutputs bool
+	Thinking          bool
+}
+
+// FeaturesFor derives the features of an Ollama server from its version,
+// e.g. "0.5.7" or "v0.9.0-rc1".
+func FeaturesFor(version string) Features {
+	parsed, ok := parseVersion(version)
+	if !ok || parsed == [3]int{} {
+		return Features{Version: version}
+	}
+	return Features{
+		Known:             true,
+		Version:           version,
+		Tools:             !olderThan(parsed, toolsVersion),
+		StructuredOutputs: !olderThan(parsed, structuredVersion),
+		Thinking:          !olderThan(parsed, thinkingVersion),
+	}
+}
+
+// GetVersion asks the server for its version via /api/version.
+func GetVersion(baseURL string) (string, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(baseURL + "/api/version")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	}
+	var versionResponse struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&versionResponse); err != nil {
+		return "", err
+	}
+	return versionResponse.Version, nil
+}
+
+// Probe reads the server version and adapts the requests of the client to
+// it. A failed probe leaves the features unknown, so nothing is disabled.
+func (c *OllamaClient) Probe() Features {
+	version, err := GetVersion(c.apiURL)
+	if err != nil {
+		c.logger.Log(fmt.Sprintf("Could not read the Ollama version: %v", err))
+		return c.features
+	}
+	c.features = FeaturesFor(version)
+	c.logger.Log(fmt.Sprintf("Ollama server version %s", version))
+	return c.features
+}
+
+// Features returns what the server was found to support.
+func (c *OllamaClient) Features() Features {
+	return c.features
+}
+
+// HasTools reports whether the server knows the "tool" message role,
+// assuming it does when the version is unknown.
+func (f Features) HasTools() bool {
+	return !f.Known || f.Tools
+}
+
+// HasStructuredOutputs reports whether the server accepts a JSON schema as
+// "format", assuming it does when the version is unknown.
+func (f Features) HasStructuredOutputs() bool {
+	return !f.Known || f.StructuredOutputs
+}
+
+// HasThinking reports whether the server accepts "think", assuming it does
+// when the version is unknown.
+func (f Features) HasThinking() bool {
+	return !f.Known || f.Thinking
+}
+
+// Disabled lists the features the server is too old for, e.g. "thinking".
+func (f Features) Disabled() []string {
+	var names []string
+	for _, feature := range []struct {
+		on   bool
+		name string
+	}{
+		{f.HasTools(), "tools"},
+		{f.HasStructuredOutputs(), "structured outputs"},
+		{f.HasThinking(), "thinking"},
+	} {
+		if !feature.on {
+			names = append(names, feature.name)
+		}
+	}
+	return names
+}
+
+// adaptMessages builds the messages of a request, see wireMessage. It
+// rewrites messages for servers that predate the "tool" role, which would
+// otherwise drop the tool results from the prompt, and leaves out the
+// thoughts of agent replies if SetHideThoughts asked for it.
+func (c *OllamaClient) adaptMessages(messages []types.Message) []types.Message {
+	adapted := make([]types.Message, len(messages))
+	for i, msg := range messages {
+		msg = wireMessage(msg)
+		if msg.Role == "tool" && !c.features.HasTools() {
+			msg.Role = "user"
+			msg.Content = "Tool result:\n" + msg.Content
+		}
+		if msg.Role == "assistant" && c.hideThoughts {
+			msg.Content = stripThoughts(msg.Content)
+		}
+		adapted[i] = msg
+	}
+	return adapted
+}
+
+// wireMessage keeps only the fields of a message that the Ollama API
+// knows. The others, like the time of a message, only matter to the UI and
+// its sessions.
+func wireMessage(msg types.Message) types.Message {
+	return types.Message{Role: msg.Role, Content: msg.Content, Thinking: msg.Thinking, ToolCalls: msg.ToolCalls, Images: msg.Images}
+}
+
+// parseVersion reads the major, minor and patch numbers of version,
+// ignoring a "v" prefix and a pre-release or build suffix.
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return parsed, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
+
+// olderThan reports whether version a precedes version b.
+func olderThan(a, b [3]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
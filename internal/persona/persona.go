@@ -0,0 +1,123 @@
+// Package persona defines role presets that change the system prompt,
+// sampling options and allowed tools of a conversation.
+package persona
+
+import (
+	"fmt"
+	"prompt-cli/internal/types"
+	"prompt-cli/internal/workspace"
+	"sort"
+	"strings"
+)
+
+// stateFile stores the persona selected in the current workspace.
+const stateFile = "persona.json"
+
+// Persona is a named preset. AllowedTools restricts which tools the model
+// may call; an empty list allows all of them.
+type Persona struct {
+	Description  string        `json:"description"`
+	SystemPrompt string        `json:"system_prompt"`
+	Options      types.Options `json:"options,omitempty"`
+	AllowedTools []string      `json:"allowed_tools,omitempty"`
+	// Critic, if set, has every answer and proposed file change reviewed
+	// while the persona is active.
+	Critic *Critic `json:"critic,omitempty"`
+}
+
+// Critic configures the second pass in which a model reviews the draft
+// answer or proposed change. Model defaults to the current model and
+// Prompt to a general review prompt.
+type Critic struct {
+	Model  string `json:"model,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+}
+
+func float(v float64) *float64 { return &v }
+
+// builtins are always available; config entries with the same name replace
+// them.
+var builtins = map[string]Persona{
+	"reviewer": {
+		Description:  "Strict code reviewer",
+		SystemPrompt: "Act as a strict senior code reviewer. Point out bugs, unclear naming, missing error handling and missing tests. Be direct and specific, cite file and line, and do not modify files.",
+		Options:      types.Options{Temperature: float(0.2)},
+		AllowedTools: []string{"read_file", "read_all_files", "list_files", "git"},
+	},
+	"tester": {
+		Description:  "Test writer",
+		SystemPrompt: "Act as a test engineer. Read the code under test, then write focused, table-driven tests that follow the conventions already used in the project. Cover edge cases and error paths.",
+		Options:      types.Options{Temperature: float(0.3)},
+	},
+	"docs": {
+		Description:  "Documentation author",
+		SystemPrompt: "Act as a technical writer. Produce clear, concise documentation and doc comments that match the tone of the existing docs. Prefer examples over long explanations.",
+		Options:      types.Options{Temperature: float(0.6)},
+		AllowedTools: []string{"read_file", "read_all_files", "list_files", "write_file", "append_file", "git"},
+	},
+}
+
+// All returns the built-in personas merged with those from the config.
+func All(custom map[string]Persona) map[string]Persona {
+	all := make(map[string]Persona, len(builtins)+len(custom))
+	for name, p := range builtins {
+		all[name] = p
+	}
+	for name, p := range custom {
+		all[name] = p
+	}
+	return all
+}
+
+// Names returns the persona names in sorted order.
+func Names(personas map[string]Persona) []string {
+	names := make([]string, 0, len(personas))
+	for name := range personas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Allows reports whether the persona permits the given tool. "respond" is
+// always allowed so the model can answer.
+func (p Persona) Allows(tool string) bool {
+	if len(p.AllowedTools) == 0 || tool == "respond" {
+		return true
+	}
+	for _, allowed := range p.AllowedTools {
+		if allowed == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// PromptSection returns the text appended to the system prompt while the
+// persona is active.
+func (p Persona) PromptSection(name string) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Persona: %s\n%s\n", name, p.SystemPrompt))
+	if len(p.AllowedTools) > 0 {
+		builder.WriteString(fmt.Sprintf("You may only use these tools: %s, respond.\n", strings.Join(p.AllowedTools, ", ")))
+	}
+	return builder.String()
+}
+
+type state struct {
+	Persona string `json:"persona"`
+}
+
+// LoadSelected returns the persona saved for the current workspace, or "".
+func LoadSelected() string {
+	var s state
+	if err := workspace.ReadJSON(stateFile, &s); err != nil {
+		return ""
+	}
+	return s.Persona
+}
+
+// SaveSelected remembers the persona for the current workspace.
+func SaveSelected(name string) error {
+	return workspace.WriteJSON(stateFile, state{Persona: name})
+}
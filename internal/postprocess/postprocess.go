@@ -0,0 +1,198 @@
+// Package postprocess cleans model replies before they are shown. Different
+// model families leak different artefacts into their output: chat template
+// tokens, gpt-oss channel markers, reasoning tags or a JSON wrapper around a
+// plain answer. A Pipeline is an ordered list of named steps chosen per
+// family, see For.
+package postprocess
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Step rewrites a reply. Steps must cope with partial replies because they
+// also run while a reply is streaming.
+type Step func(string) string
+
+// Steps holds the available steps by the name used in the configuration.
+var Steps = map[string]Step{
+	"channel_tokens": stripChannelTokens,
+	"special_tokens": stripSpecialTokens,
+	"think_tags":     stripThinkTags,
+	"json_wrapper":   unwrapJSON,
+}
+
+// DefaultSteps is the pipeline for families without their own entry.
+var DefaultSteps = []string{"special_tokens", "think_tags", "json_wrapper"}
+
+// familySteps are the built-in pipelines of families that need more than
+// the default.
+var familySteps = map[string][]string{
+	"gptoss": {"channel_tokens", "special_tokens", "json_wrapper"},
+}
+
+// Pipeline runs its steps in order.
+type Pipeline []Step
+
+// For returns the pipeline of a model family. Configured pipelines, keyed by
+// family or "default", take precedence over the built-in ones. Unknown step
+// names are reported so a typo in the configuration does not go unnoticed.
+func For(family string, configured map[string][]string) (Pipeline, error) {
+	family = strings.ToLower(family)
+	names, ok := configured[family]
+	if !ok {
+		names, ok = familySteps[family]
+	}
+	if !ok {
+		names, ok = configured["default"]
+	}
+	if !ok {
+		names = DefaultSteps
+	}
+	return Build(names)
+}
+
+// Build creates a pipeline from step names.
+func Build(names []string) (Pipeline, error) {
+	pipeline := make(Pipeline, 0, len(names))
+	for _, name := range names {
+		step, ok := Steps[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown post-processing step %q (available: %s)", name, strings.Join(StepNames(), ", "))
+		}
+		pipeline = append(pipeline, step)
+	}
+	return pipeline, nil
+}
+
+// StepNames lists the available steps in sorted order.
+func StepNames() []string {
+	names := make([]string, 0, len(Steps))
+	for name := range Steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Clean runs the reply through every step.
+func (p Pipeline) Clean(reply string) string {
+	for _, step := range p {
+		reply = step(reply)
+	}
+	return reply
+}
+
+// structuredKeys are the top-level keys of the agent's JSON reply format.
+var structuredKeys = []string{"version", "thoughts", "action", "actions", "tool_calls"}
+
+// Structured reports whether a reply, possibly still incomplete, is or may
+// become an agent JSON reply rather than text meant for display. A reply
+// that is only "{" or an opening code fence so far counts as structured
+// until more of it arrives.
+func (p Pipeline) Structured(reply string) bool {
+	text := strings.TrimSpace(p.Clean(reply))
+	text = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(text, "```json"), "```"))
+	if text == "" {
+		// Only markers or a fence so far: wait for more.
+		return strings.TrimSpace(reply) != ""
+	}
+	if !strings.HasPrefix(text, "{") {
+		return false
+	}
+	rest := strings.TrimSpace(text[1:])
+	if rest == "" {
+		return true
+	}
+	if !strings.HasPrefix(rest, `"`) {
+		return false
+	}
+	key, complete := rest[1:], false
+	if end := strings.Index(key, `"`); end >= 0 {
+		key, complete = key[:end], true
+	}
+	for _, candidate := range structuredKeys {
+		if key == candidate || !complete && strings.HasPrefix(candidate, key) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// channelRegex matches gpt-oss harmony markers such as
+	// "<|start|>assistant<|channel|>final<|message|>".
+	channelRegex = regexp.MustCompile(`<\|(?:start|channel|message|end|return|call|constrain)\|>`)
+	// analysisRegex matches a whole analysis channel that leaked into the
+	// content; only the final channel is meant for the user.
+	analysisRegex = regexp.MustCompile(`(?s)<\|channel\|>\s*analysis\s*<\|message\|>.*?(?:<\|end\|>|$)`)
+	// channelNameRegex matches the channel name left between markers.
+	channelNameRegex = regexp.MustCompile(`(?m)^(?:assistant)?\s*(?:final|commentary|analysis)\s*(?:to=\S+)?\s*$`)
+
+	// specialTokenRegex matches the end-of-turn and template tokens of the
+	// common chat formats.
+	specialTokenRegex = regexp.MustCompile(`<\|(?:im_start|im_end|eot_id|end_of_text|endoftext|start_header_id|end_header_id|begin_of_text|assistant|user|system|end)\|>|</s>|<s>|<end_of_turn>|<start_of_turn>(?:model|user)?|\[/?INST\]`)
+
+	// thinkRegex matches reasoning blocks, including one still open at
+	// the end of a streaming reply.
+	thinkRegex = regexp.MustCompile(`(?s)<think>.*?(?:</think>|$)`)
+)
+
+// stripChannelTokens keeps the final channel of a gpt-oss reply and drops
+// the harmony markers.
+func stripChannelTokens(reply string) string {
+	if !strings.Contains(reply, "<|") {
+		return reply
+	}
+	reply = analysisRegex.ReplaceAllString(reply, "")
+	reply = channelRegex.ReplaceAllString(reply, "\n")
+	reply = channelNameRegex.ReplaceAllString(reply, "")
+	return strings.TrimSpace(reply)
+}
+
+// stripSpecialTokens removes chat template tokens.
+func stripSpecialTokens(reply string) string {
+	if !strings.ContainsAny(reply, "<[") {
+		return reply
+	}
+	return specialTokenRegex.ReplaceAllString(reply, "")
+}
+
+// stripThinkTags removes <think> blocks that models emit when reasoning is
+// not requested separately.
+func stripThinkTags(reply string) string {
+	if !strings.Contains(reply, "<think>") {
+		return reply
+	}
+	return strings.TrimLeft(thinkRegex.ReplaceAllString(reply, ""), "\n")
+}
+
+// wrapperKeys are the fields models put a plain answer in when they wrap it
+// in JSON unasked.
+var wrapperKeys = []string{"response", "message", "answer", "content", "text"}
+
+// unwrapJSON turns a complete reply of the form {"response": "..."} into
+// the text it wraps. Anything else, including agent replies and partial
+// JSON, is left alone.
+func unwrapJSON(reply string) string {
+	text := strings.TrimSpace(reply)
+	if fenced := strings.TrimPrefix(text, "```json"); fenced != text && strings.HasSuffix(fenced, "```") {
+		text = strings.TrimSpace(strings.TrimSuffix(fenced, "```"))
+	}
+	if !strings.HasPrefix(text, "{") || !strings.HasSuffix(text, "}") {
+		return reply
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &fields); err != nil || len(fields) != 1 {
+		return reply
+	}
+	for _, key := range wrapperKeys {
+		if value, ok := fields[key].(string); ok {
+			return value
+		}
+	}
+	return reply
+}
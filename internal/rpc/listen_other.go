@@ -0,0 +1,16 @@
+//go:build !windows
+
+package rpc
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate creates the socket under a umask of 077, so it is private
+// from the moment it exists rather than only after the chmod in Listen.
+func listenPrivate(path string) (net.Listener, error) {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
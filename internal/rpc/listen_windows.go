@@ -0,0 +1,9 @@
+package rpc
+
+import "net"
+
+// listenPrivate creates the socket. Windows has no umask; the socket file
+// gets the permissions of its directory.
+func listenPrivate(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
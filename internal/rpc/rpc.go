@@ -0,0 +1,231 @@
+// Package rpc serves JSON-RPC 2.0 over newline-delimited JSON, one request
+// or response per line, on a Unix socket or any other stream.
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Standard JSON-RPC error codes, plus ErrCodeFailed for errors returned by
+// a method.
+const (
+	ErrCodeParse          = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	ErrCodeFailed         = -32000
+)
+
+// Request is a JSON-RPC call. Requests without an ID are notifications and
+// get no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response answers a Request with either a result or an error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object. Handlers return it to choose the code;
+// any other error is reported as ErrCodeFailed.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// MethodNotFound is the error for an unknown method.
+func MethodNotFound(method string) *Error {
+	return &Error{Code: ErrCodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", method)}
+}
+
+// InvalidParams is the error for parameters a method cannot use.
+func InvalidParams(format string, args ...interface{}) *Error {
+	return &Error{Code: ErrCodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Handler runs one method call and returns its result.
+type Handler func(method string, params json.RawMessage) (interface{}, error)
+
+// DecodeParams unmarshals params into v. Missing params leave v unchanged.
+func DecodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return InvalidParams("invalid params: %v", err)
+	}
+	return nil
+}
+
+// Listen listens on a Unix socket at path that only the current user can
+// connect to. A socket left behind by a process that is gone is replaced.
+func Listen(path string) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", path)
+		}
+		os.Remove(path)
+	}
+	listener, err := listenPrivate(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Serve accepts connections until the listener is closed and serves each
+// one with ServeConn.
+func Serve(listener net.Listener, handler Handler) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			ServeConn(conn, conn, handler)
+		}()
+	}
+}
+
+// ServeConn reads requests from r until EOF and writes the responses to w.
+// Requests are handled one at a time, in order.
+func ServeConn(r io.Reader, w io.Writer, handler Handler) error {
+	encoder := json.NewEncoder(w)
+	send := func(resp Response) error {
+		resp.JSONRPC = "2.0"
+		return encoder.Encode(resp)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 64<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := send(Response{ID: json.RawMessage("null"), Error: &Error{Code: ErrCodeParse, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "" {
+			if err := send(Response{ID: idOrNull(req.ID), Error: &Error{Code: ErrCodeInvalidRequest, Message: "method is required"}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := handler(req.Method, req.Params)
+		if len(req.ID) == 0 {
+			continue
+		}
+		resp := Response{ID: req.ID, Result: result}
+		if err != nil {
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &Error{Code: ErrCodeFailed, Message: err.Error()}
+			}
+			resp.Result, resp.Error = nil, rpcErr
+		} else if result == nil {
+			resp.Result = struct{}{}
+		}
+		if err := send(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// idOrNull returns id, or JSON null if the request had none.
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+// Client calls methods on a server over a Unix socket, one call at a time.
+type Client struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	encoder *json.Encoder
+	nextID  int
+}
+
+// Dial connects to the server listening on the Unix socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, reader: bufio.NewReader(conn), encoder: json.NewEncoder(conn)}, nil
+}
+
+// Call runs method with params and unmarshals its result into result,
+// unless result is nil. A JSON-RPC error is returned as an *Error.
+func (c *Client) Call(method string, params interface{}, result interface{}) error {
+	c.nextID++
+	request := struct {
+		JSONRPC string      `json:"jsonrpc"`
+		ID      int         `json:"id"`
+		Method  string      `json:"method"`
+		Params  interface{} `json:"params,omitempty"`
+	}{"2.0", c.nextID, method, params}
+	if err := c.encoder.Encode(request); err != nil {
+		return err
+	}
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
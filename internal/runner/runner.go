@@ -0,0 +1,130 @@
+// Package runner drives the agent loop without the TUI: it sends a prompt,
+// executes the tool calls the model asks for and returns the final answer.
+// The non-interactive subcommands are built on it.
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/ollama"
+	"prompt-cli/internal/types"
+	"strings"
+)
+
+// defaultMaxSteps bounds the tool calls made for one prompt.
+const defaultMaxSteps = 10
+
+// Runner holds what is needed to answer prompts headlessly. Without a
+// permission prompt to ask, tools that need permission are refused unless
+// AllowWrites is set.
+type Runner struct {
+	Client       *ollama.OllamaClient
+	Agent        *agent.Agent
+	Model        string
+	SystemPrompt string
+	Options      types.Options
+	AllowWrites  bool
+	MaxSteps     int
+}
+
+// Result is the outcome of one prompt.
+type Result struct {
+	Answer   string
+	Messages []types.Message // Full transcript, including tool calls and results.
+	Steps    int             // Number of tools executed.
+}
+
+// Run answers a single prompt, following tool calls until the model
+// responds or the step limit is reached.
+func (r *Runner) Run(ctx context.Context, prompt string) (Result, error) {
+	var result Result
+	if r.SystemPrompt != "" {
+		result.Messages = append(result.Messages, types.Message{Role: "system", Content: r.SystemPrompt})
+	}
+	result.Messages = append(result.Messages, types.Message{Role: "user", Content: prompt})
+
+	maxSteps := r.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxSteps
+	}
+
+	for {
+		resp, err := r.Client.Chat(ctx, r.Model, result.Messages, r.Options)
+		if err != nil {
+			return result, err
+		}
+		reply := resp.Message
+		reply.Role = "assistant"
+
+		action := ParseAction(reply)
+		if action == nil {
+			result.Messages = append(result.Messages, reply)
+			result.Answer = reply.Content
+			return result, nil
+		}
+		if action.Tool == "respond" {
+			result.Answer = RespondMessage(action.Input)
+			result.Messages = append(result.Messages, types.Message{Role: "assistant", Content: result.Answer})
+			return result, nil
+		}
+
+		reply.Content = ""
+		reply.ToolCalls = []types.ToolCall{{Function: types.FunctionCall{Name: action.Tool, Arguments: action.Input}}}
+		result.Messages = append(result.Messages, reply)
+
+		if result.Steps >= maxSteps {
+			return result, fmt.Errorf("stopped after %d tool calls without a final answer", maxSteps)
+		}
+		result.Steps++
+
+		var output types.ToolResult
+		if r.Agent.NeedsPermission(action.Tool, action.Input) && !r.AllowWrites {
+			output = agent.ErrorResult(action.Tool, types.ErrorPermission, fmt.Sprintf("Error: %s is not allowed in this non-interactive run.", action.Tool))
+		} else {
+			output = r.Agent.ExecuteTool(action.Tool, action.Input)
+		}
+		result.Messages = append(result.Messages, types.Message{Role: "tool", Content: output.String(), Result: &output})
+	}
+}
+
+// ParseAction extracts the tool call from a model reply, either a native
+// tool call or the JSON action format described in Prompt.MD. It returns nil
+// for a plain text answer.
+func ParseAction(msg types.Message) *types.Action {
+	if len(msg.ToolCalls) > 0 {
+		call := msg.ToolCalls[0]
+		return &types.Action{Tool: call.Function.Name, Input: call.Function.Arguments}
+	}
+	if msg.Content == "" {
+		return nil
+	}
+	jsonStr, err := types.ExtractJSON(msg.Content)
+	if err != nil {
+		return nil
+	}
+	var llmResponse types.LLMResponse
+	if err := json.Unmarshal([]byte(jsonStr), &llmResponse); err != nil || llmResponse.Action.Tool == "" {
+		return nil
+	}
+	return &llmResponse.Action
+}
+
+// RespondMessage returns the text of a respond action, joining messages
+// that were sent as a list of strings.
+func RespondMessage(input map[string]interface{}) string {
+	if msgStr, ok := input["message"].(string); ok {
+		return msgStr
+	}
+	if msgArr, ok := input["message"].([]interface{}); ok {
+		var parts []string
+		for _, item := range msgArr {
+			if part, ok := item.(string); ok {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// The Keychain is used through the security command, which ships with
+// macOS.
+
+func set(name, value string) error {
+	return run(exec.Command("security", "add-generic-password", "-U", "-s", service, "-a", name, "-w", value))
+}
+
+func get(name string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", name, "-w").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func remove(name string) error {
+	err := run(exec.Command("security", "delete-generic-password", "-s", service, "-a", name))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+		return ErrNotFound
+	}
+	return err
+}
+
+// run runs cmd and returns its error output as the error if it fails.
+func run(cmd *exec.Cmd) error {
+	out, err := cmd.CombinedOutput()
+	if err != nil && len(out) > 0 {
+		return errors.New(strings.TrimSpace(string(out)))
+	}
+	return err
+}
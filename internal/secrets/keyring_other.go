@@ -0,0 +1,63 @@
+//go:build !darwin && !windows
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The Secret Service is used through secret-tool, which comes with
+// libsecret (the libsecret-tools package on Debian and Ubuntu).
+
+func secretTool(args ...string) (*exec.Cmd, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, fmt.Errorf("secret-tool was not found; install libsecret-tools or the equivalent package of your distribution")
+	}
+	return exec.Command(path, args...), nil
+}
+
+func set(name, value string) error {
+	cmd, err := secretTool("store", "--label", service+": "+name, "service", service, "account", name)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = strings.NewReader(value)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return errors.New(strings.TrimSpace(string(out)))
+		}
+		return err
+	}
+	return nil
+}
+
+func get(name string) (string, error) {
+	cmd, err := secretTool("lookup", "service", service, "account", name)
+	if err != nil {
+		return "", err
+	}
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+		return "", ErrNotFound // secret-tool exits with 1 and says nothing.
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func remove(name string) error {
+	if _, err := get(name); err != nil {
+		return err
+	}
+	cmd, err := secretTool("clear", "service", service, "account", name)
+	if err != nil {
+		return err
+	}
+	return cmd.Run()
+}
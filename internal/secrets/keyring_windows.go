@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	credWriteW     = advapi32.NewProc("CredWriteW")
+	credReadW      = advapi32.NewProc("CredReadW")
+	credDeleteW    = advapi32.NewProc("CredDeleteW")
+	credFree       = advapi32.NewProc("CredFree")
+	errNotFoundWin = syscall.Errno(1168) // ERROR_NOT_FOUND
+)
+
+const (
+	credTypeGeneric      = 1
+	credPersistLocalUser = 2 // CRED_PERSIST_LOCAL_MACHINE: kept for this user on this computer.
+)
+
+// credential mirrors CREDENTIALW of the Credential Manager API.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// target is the Credential Manager name of a secret.
+func target(name string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(service + ":" + name)
+}
+
+func set(name, value string) error {
+	targetName, err := target(name)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	blob := []byte(value)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         targetName,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalUser,
+		UserName:           userName,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if ok, _, err := credWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); ok == 0 {
+		return err
+	}
+	return nil
+}
+
+func get(name string) (string, error) {
+	targetName, err := target(name)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	if ok, _, err := credReadW.Call(uintptr(unsafe.Pointer(targetName)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred))); ok == 0 {
+		if errors.Is(err, errNotFoundWin) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	defer credFree.Call(uintptr(unsafe.Pointer(cred)))
+	if cred.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func remove(name string) error {
+	targetName, err := target(name)
+	if err != nil {
+		return err
+	}
+	if ok, _, err := credDeleteW.Call(uintptr(unsafe.Pointer(targetName)), credTypeGeneric, 0); ok == 0 {
+		if errors.Is(err, errNotFoundWin) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Package secrets keeps API tokens in the operating system's keyring
+// instead of config.json: the macOS Keychain, the Windows Credential
+// Manager or the Secret Service (GNOME Keyring, KWallet) elsewhere. The
+// config refers to a stored secret as "keyring:<name>".
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// service is the name the secrets are stored under in the keyring.
+const service = "prompt-cli"
+
+// Prefix marks a config value that names a secret in the keyring.
+const Prefix = "keyring:"
+
+// ErrNotFound is returned by Get for a name that has no secret.
+var ErrNotFound = errors.New("secret not found in the keyring")
+
+// Set stores value under name, replacing an existing secret.
+func Set(name, value string) error {
+	if name == "" {
+		return errors.New("the secret needs a name")
+	}
+	return set(name, value)
+}
+
+// Get returns the secret stored under name.
+func Get(name string) (string, error) {
+	return get(name)
+}
+
+// Delete removes the secret stored under name.
+func Delete(name string) error {
+	return remove(name)
+}
+
+// Resolve returns value, or the secret it names if it starts with Prefix.
+func Resolve(value string) (string, error) {
+	name, ok := strings.CutPrefix(value, Prefix)
+	if !ok {
+		return value, nil
+	}
+	secret, err := Get(name)
+	if err != nil {
+		return "", fmt.Errorf("reading secret %q: %w", name, err)
+	}
+	return secret, nil
+}
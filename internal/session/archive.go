@@ -0,0 +1,115 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"prompt-cli/internal/types"
+)
+
+// archivePath returns the JSONL file holding the messages pruned from the
+// session's transcript, oldest first.
+func (s *Session) archivePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, s.ID+".archive.jsonl"), nil
+}
+
+// Archive appends messages dropped from the in-memory transcript to the
+// session's archive file, so long sessions keep their full history on disk.
+func (s *Session) Archive(messages []types.Message) error {
+	path, err := s.archivePath()
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening archive: %w", err)
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	for _, msg := range messages {
+		if err := encoder.Encode(msg); err != nil {
+			return fmt.Errorf("error writing archive: %w", err)
+		}
+	}
+	s.Archived += len(messages)
+	return nil
+}
+
+// readArchive loads every archived message.
+func (s *Session) readArchive() ([]types.Message, error) {
+	if s.Archived == 0 {
+		return nil, nil
+	}
+	path, err := s.archivePath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading archive: %w", err)
+	}
+	defer f.Close()
+
+	var messages []types.Message
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var msg types.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return nil, fmt.Errorf("error decoding archive: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, scanner.Err()
+}
+
+// Unarchive removes the n most recent archived messages from the archive
+// and returns them, oldest first.
+func (s *Session) Unarchive(n int) ([]types.Message, error) {
+	messages, err := s.readArchive()
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	if n > len(messages) {
+		n = len(messages)
+	}
+	keep, restored := messages[:len(messages)-n], messages[len(messages)-n:]
+
+	path, err := s.archivePath()
+	if err != nil {
+		return nil, err
+	}
+	s.Archived = 0
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("error rewriting archive: %w", err)
+	}
+	if len(keep) > 0 {
+		if err := s.Archive(keep); err != nil {
+			return nil, err
+		}
+	}
+	return restored, nil
+}
+
+// FullMessages returns the whole conversation, with archived messages
+// placed after the system prompt.
+func (s *Session) FullMessages() ([]types.Message, error) {
+	archived, err := s.readArchive()
+	if err != nil || len(archived) == 0 {
+		return s.Messages, err
+	}
+	messages := make([]types.Message, 0, len(archived)+len(s.Messages))
+	rest := s.Messages
+	if len(rest) > 0 && rest[0].Role == "system" {
+		messages = append(messages, rest[0])
+		rest = rest[1:]
+	}
+	messages = append(messages, archived...)
+	return append(messages, rest...), nil
+}
@@ -0,0 +1,121 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// FineTuneExample is one line of a chat fine-tuning JSONL file in the format
+// used by OpenAI and most local fine-tuning tools.
+type FineTuneExample struct {
+	Messages []FineTuneMessage `json:"messages"`
+}
+
+// FineTuneMessage is a chat message in fine-tuning format. Tool calls carry
+// their arguments as a JSON string and tool results point back at the call
+// they answer.
+type FineTuneMessage struct {
+	Role       string             `json:"role"`
+	Content    string             `json:"content,omitempty"`
+	ToolCalls  []FineTuneToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string             `json:"tool_call_id,omitempty"`
+}
+
+// FineTuneToolCall is a function call made by the assistant.
+type FineTuneToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function FineTuneFunction `json:"function"`
+}
+
+// FineTuneFunction names the called function and its encoded arguments.
+type FineTuneFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// FineTuneExampleFor converts a session into a fine-tuning example. Messages
+// that were only shown locally, empty assistant turns and roles the format
+// does not know are dropped.
+func FineTuneExampleFor(s *Session) (FineTuneExample, error) {
+	var example FineTuneExample
+	messages, err := s.FullMessages()
+	if err != nil {
+		return FineTuneExample{}, fmt.Errorf("loading session %s: %w", s.ID, err)
+	}
+	lastCallID := ""
+	calls := 0
+	for _, msg := range messages {
+		if msg.Local {
+			continue
+		}
+		switch msg.Role {
+		case "system", "user":
+			example.Messages = append(example.Messages, FineTuneMessage{Role: msg.Role, Content: msg.Content})
+
+		case "assistant":
+			out := FineTuneMessage{Role: "assistant", Content: msg.Content}
+			for _, call := range msg.ToolCalls {
+				args, err := json.Marshal(call.Function.Arguments)
+				if err != nil {
+					return FineTuneExample{}, fmt.Errorf("encoding arguments of %s in session %s: %w", call.Function.Name, s.ID, err)
+				}
+				calls++
+				lastCallID = fmt.Sprintf("call_%d", calls)
+				out.ToolCalls = append(out.ToolCalls, FineTuneToolCall{
+					ID:       lastCallID,
+					Type:     "function",
+					Function: FineTuneFunction{Name: call.Function.Name, Arguments: string(args)},
+				})
+			}
+			if out.Content == "" && len(out.ToolCalls) == 0 {
+				continue
+			}
+			example.Messages = append(example.Messages, out)
+
+		case "tool":
+			// A result without a preceding call cannot be expressed in the
+			// format, so it is kept as plain context for the assistant.
+			if lastCallID == "" {
+				example.Messages = append(example.Messages, FineTuneMessage{Role: "user", Content: msg.Content})
+				continue
+			}
+			example.Messages = append(example.Messages, FineTuneMessage{Role: "tool", Content: msg.Content, ToolCallID: lastCallID})
+			lastCallID = ""
+		}
+	}
+	return example, nil
+}
+
+// ExportFineTune writes one JSONL line per session to w. Sessions without an
+// assistant reply are skipped since they teach nothing. It returns the number
+// of examples written.
+func ExportFineTune(w io.Writer, sessions []*Session) (int, error) {
+	encoder := json.NewEncoder(w)
+	written := 0
+	for _, s := range sessions {
+		example, err := FineTuneExampleFor(s)
+		if err != nil {
+			return written, err
+		}
+		if !hasAssistantTurn(example) {
+			continue
+		}
+		if err := encoder.Encode(example); err != nil {
+			return written, fmt.Errorf("writing session %s: %w", s.ID, err)
+		}
+		written++
+	}
+	return written, nil
+}
+
+// hasAssistantTurn reports whether the example contains a reply to learn from.
+func hasAssistantTurn(example FineTuneExample) bool {
+	for _, msg := range example.Messages {
+		if msg.Role == "assistant" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Forwarded is a message or file one session sent to another with
+// /send-to, e.g. a planner handing a task to an executor.
+type Forwarded struct {
+	From    string    `json:"from"`
+	Sent    time.Time `json:"sent"`
+	Content string    `json:"content"`
+	File    string    `json:"file,omitempty"` // Name of the forwarded file, if any.
+}
+
+// Prompt is the text the receiving session sends to its model.
+func (f Forwarded) Prompt() string {
+	if f.File != "" {
+		return fmt.Sprintf("File %s forwarded from session %s:\n\n```\n%s\n```", f.File, f.From, strings.TrimRight(f.Content, "\n"))
+	}
+	return fmt.Sprintf("Message forwarded from session %s:\n\n%s", f.From, f.Content)
+}
+
+// inboxDir is the folder messages for session id are delivered to, inside
+// the sessions directory.
+func inboxDir(id string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "inbox", id), nil
+}
+
+// OpenInbox creates the inbox of a running session, so other sessions can
+// send to it before it is first saved.
+func OpenInbox(id string) error {
+	dir, err := inboxDir(id)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// Send delivers msg to the inbox of session to. The session must be saved
+// or running; it picks the message up when it is next idle.
+func Send(to string, msg Forwarded) error {
+	if to == "" || filepath.Base(to) != to || strings.HasPrefix(to, ".") {
+		return fmt.Errorf("invalid session ID %q", to)
+	}
+	dir, err := inboxDir(to)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		if _, err := Load(to); err != nil {
+			return fmt.Errorf("no session %s", to)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	// Write under a temporary name so the receiver never reads half a file.
+	name := fmt.Sprintf("%s-%s", msg.Sent.Format("20060102-150405.000000000"), msg.From)
+	tmp := filepath.Join(dir, "."+name)
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, name+".json"))
+}
+
+// Receive takes the messages waiting in the inbox of session id, oldest
+// first.
+func Receive(id string) ([]Forwarded, error) {
+	dir, err := inboxDir(id)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var received []Forwarded
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return received, err
+		}
+		os.Remove(path)
+		var msg Forwarded
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		received = append(received, msg)
+	}
+	return received, nil
+}
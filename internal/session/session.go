@@ -0,0 +1,207 @@
+// Package session persists chat transcripts so they can be searched and
+// resumed later. Sessions are stored as JSON files in a "sessions" folder
+// in the application directory (see appdir), alongside the "logs" folder.
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"prompt-cli/internal/appdir"
+	"prompt-cli/internal/types"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Session is a saved conversation.
+type Session struct {
+	ID       string          `json:"id"`
+	Created  time.Time       `json:"created"`
+	Updated  time.Time       `json:"updated"`
+	Model    string          `json:"model"`
+	Messages []types.Message `json:"messages"`
+	// Archived counts the older messages moved to the archive file, see
+	// Archive.
+	Archived int `json:"archived,omitempty"`
+	// NumCtx is the context size chosen with /ctx for this session, 0 to
+	// use the configured one.
+	NumCtx int64 `json:"num_ctx,omitempty"`
+	// Preset is the option set chosen with /preset, "" for none.
+	Preset string `json:"preset,omitempty"`
+	// Summaries holds one entry per run that ended on this session.
+	Summaries []Summary `json:"summaries,omitempty"`
+	// Tasks is the agent's checklist, see the task_list tool.
+	Tasks []types.Task `json:"tasks,omitempty"`
+	// Compacted summarizes the messages left out by compaction. It is sent
+	// right after the system prompt.
+	Compacted string `json:"compacted,omitempty"`
+}
+
+// Match is a single search hit inside a saved session.
+type Match struct {
+	SessionID string
+	Updated   time.Time
+	Index     int // Index of the matching message in Session.Messages.
+	Role      string
+	Snippet   string
+}
+
+// Dir returns the directory sessions are stored in, creating it if needed.
+func Dir() (string, error) {
+	appDir, err := appdir.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(appDir, "sessions")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating sessions directory: %w", err)
+	}
+	return dir, nil
+}
+
+// New creates an empty session for the given model. Its ID is derived from
+// the start time so files sort chronologically.
+func New(model string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:      now.Format("20060102-150405.000"),
+		Created: now,
+		Updated: now,
+		Model:   model,
+	}
+}
+
+// Path returns the file the session is saved to.
+func (s *Session) Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, s.ID+".json"), nil
+}
+
+// Save writes the session to disk.
+func (s *Session) Save() error {
+	path, err := s.Path()
+	if err != nil {
+		return err
+	}
+	s.Updated = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding session: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load reads a saved session by ID.
+func Load(id string) (*Session, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return loadFile(filepath.Join(dir, strings.TrimSuffix(id, ".json")+".json"))
+}
+
+func loadFile(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading session: %w", err)
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("error decoding session %s: %w", filepath.Base(path), err)
+	}
+	return &s, nil
+}
+
+// List returns all saved sessions, most recently updated first.
+func List() ([]*Session, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var sessions []*Session
+	for _, path := range paths {
+		s, err := loadFile(path)
+		if err != nil {
+			continue // Skip unreadable files rather than failing the listing.
+		}
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Updated.After(sessions[j].Updated)
+	})
+	return sessions, nil
+}
+
+// Search does a case-insensitive full-text search over the user, assistant
+// and tool messages of every saved session.
+func Search(query string) ([]Match, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+	sessions, err := List()
+	if err != nil {
+		return nil, err
+	}
+	var matches []Match
+	for _, s := range sessions {
+		for i, msg := range s.Messages {
+			if msg.Role == "system" {
+				continue
+			}
+			pos := strings.Index(strings.ToLower(msg.Content), query)
+			if pos < 0 {
+				continue
+			}
+			matches = append(matches, Match{
+				SessionID: s.ID,
+				Updated:   s.Updated,
+				Index:     i,
+				Role:      msg.Role,
+				Snippet:   snippet(msg.Content, pos, len(query)),
+			})
+		}
+	}
+	return matches, nil
+}
+
+// snippet returns the text surrounding a match on a single line.
+func snippet(content string, pos, length int) string {
+	const context = 40
+	start := pos - context
+	if start < 0 {
+		start = 0
+	}
+	end := pos + length + context
+	if end > len(content) {
+		end = len(content)
+	}
+	if start > end {
+		start = end
+	}
+	// Don't cut multi-byte characters in half.
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	for end < len(content) && !utf8.RuneStart(content[end]) {
+		end++
+	}
+	text := strings.Join(strings.Fields(content[start:end]), " ")
+	if start > 0 {
+		text = "..." + text
+	}
+	if end < len(content) {
+		text += "..."
+	}
+	return text
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// shareStyle is the page stylesheet. Everything is inlined so the file can
+// be attached to a PR or mailed around on its own.
+const shareStyle = `
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 900px; margin: 2em auto; padding: 0 1em; color: #1f2328; line-height: 1.5; }
+h1 { font-size: 1.4em; margin-bottom: 0; }
+.meta { color: #59636e; margin-bottom: 2em; }
+.message { border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.5em 1em; margin: 1em 0; }
+.message.user { background: #f6f8fa; }
+.role { font-weight: 600; text-transform: capitalize; color: #59636e; font-size: 0.9em; }
+details { margin: 0.5em 0; }
+summary { cursor: pointer; color: #59636e; }
+pre { overflow-x: auto; padding: 0.8em; border-radius: 6px; background: #f6f8fa; font-size: 0.9em; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+blockquote { color: #59636e; border-left: 3px solid #d1d9e0; margin: 0; padding-left: 1em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #d1d9e0; padding: 0.3em 0.6em; }
+`
+
+// WriteHTML renders the session, including archived messages, as a single
+// self-contained HTML page. Markdown is converted with code blocks
+// highlighted inline; tool calls, tool output, reasoning and the system
+// prompt are collapsible. Raw HTML in messages is not passed through.
+func WriteHTML(w io.Writer, s *Session) error {
+	messages, err := s.FullMessages()
+	if err != nil {
+		return fmt.Errorf("loading session %s: %w", s.ID, err)
+	}
+	markdown := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(codeRenderer{}, 100))),
+	)
+
+	var page bytes.Buffer
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&page, "<title>PromptCLI session %s</title>\n<style>%s</style>\n</head>\n<body>\n", html.EscapeString(s.ID), shareStyle)
+	fmt.Fprintf(&page, "<h1>PromptCLI session %s</h1>\n", html.EscapeString(s.ID))
+	fmt.Fprintf(&page, "<div class=\"meta\">%s · %s</div>\n", html.EscapeString(s.Model), s.Created.Format("2006-01-02 15:04"))
+
+	for _, msg := range messages {
+		if msg.Local {
+			continue
+		}
+		switch msg.Role {
+		case "system":
+			writeDetails(&page, "System prompt", msg.Content)
+
+		case "tool":
+			fmt.Fprintf(&page, "<div class=\"message tool\">\n")
+			writeDetails(&page, fmt.Sprintf("Tool output (%d bytes)", len(msg.Content)), msg.Content)
+			page.WriteString("</div>\n")
+
+		default:
+			if msg.Content == "" && msg.Thinking == "" && len(msg.ToolCalls) == 0 {
+				continue
+			}
+			fmt.Fprintf(&page, "<div class=\"message %s\">\n<div class=\"role\">%s</div>\n", html.EscapeString(msg.Role), html.EscapeString(msg.Role))
+			if msg.Thinking != "" {
+				writeDetails(&page, "Thinking", msg.Thinking)
+			}
+			if err := markdown.Convert([]byte(msg.Content), &page); err != nil {
+				return fmt.Errorf("rendering message: %w", err)
+			}
+			for _, call := range msg.ToolCalls {
+				args, err := json.MarshalIndent(call.Function.Arguments, "", "  ")
+				if err != nil {
+					return fmt.Errorf("encoding arguments of %s: %w", call.Function.Name, err)
+				}
+				writeDetails(&page, "Tool call: "+call.Function.Name, string(args))
+			}
+			page.WriteString("</div>\n")
+		}
+	}
+	page.WriteString("</body>\n</html>\n")
+
+	_, err = page.WriteTo(w)
+	return err
+}
+
+// writeDetails writes a collapsed block of preformatted text.
+func writeDetails(w io.Writer, summary, content string) {
+	fmt.Fprintf(w, "<details><summary>%s</summary><pre><code>%s</code></pre></details>\n",
+		html.EscapeString(summary), html.EscapeString(content))
+}
+
+// codeRenderer highlights fenced code blocks with inline styles, so the page
+// needs no external stylesheet.
+type codeRenderer struct{}
+
+func (codeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, renderFencedCode)
+}
+
+func renderFencedCode(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	block := node.(*ast.FencedCodeBlock)
+	var code strings.Builder
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		code.Write(segment.Value(source))
+	}
+
+	lexer := lexers.Get(string(block.Language(source)))
+	if lexer == nil {
+		lexer = lexers.Analyse(code.String())
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code.String())
+	if err == nil {
+		err = chromahtml.New(chromahtml.WithClasses(false), chromahtml.TabWidth(4)).Format(w, styles.Get("github"), iterator)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "<pre><code>%s</code></pre>\n", html.EscapeString(code.String()))
+	}
+	return ast.WalkSkipChildren, nil
+}
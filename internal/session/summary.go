@@ -0,0 +1,63 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary records what happened during one run of the TUI on a session, for
+// comparing models and prompts afterwards.
+type Summary struct {
+	Started        time.Time      `json:"started"`
+	Ended          time.Time      `json:"ended"`
+	Model          string         `json:"model"`
+	Turns          int            `json:"turns"`
+	ToolCalls      map[string]int `json:"tool_calls,omitempty"`
+	TokensIn       int            `json:"tokens_in"`
+	TokensOut      int            `json:"tokens_out"`
+	GenerationTime time.Duration  `json:"generation_time_ns"` // Time spent streaming replies.
+	FilesModified  []string       `json:"files_modified,omitempty"`
+}
+
+// TokensPerSecond is the average output rate over all replies.
+func (s Summary) TokensPerSecond() float64 {
+	if s.GenerationTime <= 0 {
+		return 0
+	}
+	return float64(s.TokensOut) / s.GenerationTime.Seconds()
+}
+
+// Format renders the summary for the terminal.
+func (s Summary) Format() string {
+	var builder strings.Builder
+	builder.WriteString("Session summary\n")
+	builder.WriteString(fmt.Sprintf("  Model:        %s\n", s.Model))
+	builder.WriteString(fmt.Sprintf("  Wall time:    %s\n", s.Ended.Sub(s.Started).Round(time.Second)))
+	builder.WriteString(fmt.Sprintf("  Turns:        %d\n", s.Turns))
+
+	total := 0
+	names := make([]string, 0, len(s.ToolCalls))
+	for name, count := range s.ToolCalls {
+		names = append(names, name)
+		total += count
+	}
+	sort.Strings(names)
+	var calls []string
+	for _, name := range names {
+		calls = append(calls, fmt.Sprintf("%s %d", name, s.ToolCalls[name]))
+	}
+	if total > 0 {
+		builder.WriteString(fmt.Sprintf("  Tool calls:   %d (%s)\n", total, strings.Join(calls, ", ")))
+	} else {
+		builder.WriteString("  Tool calls:   0\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("  Tokens:       %d in, %d out\n", s.TokensIn, s.TokensOut))
+	builder.WriteString(fmt.Sprintf("  Tokens/sec:   %.2f\n", s.TokensPerSecond()))
+	if len(s.FilesModified) > 0 {
+		builder.WriteString(fmt.Sprintf("  Files changed: %s\n", strings.Join(s.FilesModified, ", ")))
+	}
+	return builder.String()
+}
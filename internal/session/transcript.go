@@ -0,0 +1,68 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"prompt-cli/internal/types"
+	"time"
+)
+
+// TranscriptEntry is one line of a session's transcript log.
+type TranscriptEntry struct {
+	Time      time.Time        `json:"time"`
+	Session   string           `json:"session"`
+	Model     string           `json:"model"`
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	ToolCalls []types.ToolCall `json:"tool_calls,omitempty"`
+	Error     bool             `json:"error,omitempty"`
+	Local     bool             `json:"local,omitempty"`
+}
+
+// TranscriptPath returns the append-only JSONL log of the session's
+// messages.
+func (s *Session) TranscriptPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, s.ID+".transcript.jsonl"), nil
+}
+
+// LogTranscript appends messages to the transcript log, one entry per line
+// stamped with the current time. Unlike the session file the log is never
+// rewritten, so it can be tailed or collected for analysis.
+func (s *Session) LogTranscript(messages []types.Message) error {
+	path, err := s.TranscriptPath()
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening transcript log: %w", err)
+	}
+	defer f.Close()
+
+	now := time.Now()
+	encoder := json.NewEncoder(f)
+	for _, msg := range messages {
+		entry := TranscriptEntry{
+			Time:      now,
+			Session:   s.ID,
+			Model:     s.Model,
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Thinking:  msg.Thinking,
+			ToolCalls: msg.ToolCalls,
+			Error:     msg.IsError,
+			Local:     msg.Local,
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("error writing transcript log: %w", err)
+		}
+	}
+	return nil
+}
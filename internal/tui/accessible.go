@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Accessible mode is meant for terminal screen readers. The program runs
+// without the alternate screen, finished messages and state changes are
+// printed once as plain lines above the input, and the live part of the
+// screen is reduced to the input and a textual status line.
+
+// applyAccessibleStyles removes borders and colors from the components.
+func (m *Model) applyAccessibleStyles() {
+	plain := lipgloss.NewStyle()
+	m.textarea.FocusedStyle.Base = plain
+	m.textarea.BlurredStyle.Base = plain
+	m.textarea.FocusedStyle.Placeholder = plain
+	m.textarea.BlurredStyle.Placeholder = plain
+	m.viewport.Style = plain
+}
+
+// announce prints the messages completed since the last call and any change
+// in the request state.
+func (m *Model) announce() tea.Cmd {
+	var lines []string
+
+	if m.printedMessages > len(m.messages) {
+		m.printedMessages = len(m.messages) // The conversation was reset.
+	}
+	end := len(m.messages)
+	if m.streaming && end > 0 && m.messages[end-1].Role == "assistant" {
+		end-- // Still being written.
+	}
+	for i := m.printedMessages; i < end; i++ {
+		if text := plainMessage(m.messages[i].Role, m.messages[i].Content, m.messages[i].DisplayContent, m.messages[i].IsError); text != "" {
+			lines = append(lines, text)
+		}
+		for _, call := range m.messages[i].ToolCalls {
+			lines = append(lines, i18n.T("a11y.tool_call", call.Function.Name))
+		}
+	}
+	if end > m.printedMessages {
+		m.printedMessages = end
+	}
+
+	if m.sending != m.announcedSending {
+		m.announcedSending = m.sending
+		if m.sending {
+			lines = append(lines, i18n.T("a11y.waiting"))
+		} else {
+			lines = append(lines, i18n.T("a11y.done"))
+		}
+	}
+	if waiting := m.permissionRequest != nil; waiting != m.announcedPermission {
+		m.announcedPermission = waiting
+		if waiting {
+			lines = append(lines, i18n.T("permission.prompt", m.renderCommandDetails(m.permissionRequest)), i18n.T("a11y.permission_keys"))
+		}
+	}
+
+	if m.reviewingBatch != m.announcedBatch {
+		m.announcedBatch = m.reviewingBatch
+		if m.reviewingBatch {
+			lines = append(lines, m.renderBatch())
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+	return tea.Println(strings.Join(lines, "\n\n"))
+}
+
+// plainMessage formats a transcript message as unstyled text.
+func plainMessage(role, content, displayContent string, isError bool) string {
+	if role == "system" {
+		return ""
+	}
+	if displayContent != "" {
+		content = displayContent
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return ""
+	}
+	label := strings.Title(role)
+	if isError {
+		label = i18n.T("a11y.error")
+	}
+	return fmt.Sprintf("%s: %s", label, content)
+}
+
+// accessibleView renders the input and a plain status line.
+func (m *Model) accessibleView() string {
+	if m.picker != nil {
+		return m.picker.view()
+	}
+	if m.reviewingBatch {
+		return m.renderBatch()
+	}
+	if m.changeset != nil {
+		return m.changesetView(m.viewport.Width)
+	}
+	if m.permissionRequest != nil {
+		if rules := m.ruleOptions(m.permissionRequest); rules != "" {
+			return i18n.T("a11y.permission_keys") + "\n" + rules
+		}
+		return i18n.T("a11y.permission_keys")
+	}
+	if m.ctrlCpressed {
+		return m.textarea.View() + "\n" + i18n.T("footer.quit_confirm")
+	}
+
+	focus := i18n.T("a11y.focus_input")
+	if m.focused == focusViewport {
+		focus = i18n.T("a11y.focus_transcript")
+	}
+	status := m.footerText() + " | " + focus
+	if m.sending {
+		status += " | " + i18n.T("footer.waiting")
+	}
+	if m.dictation != nil {
+		status += " | " + i18n.T("footer.recording")
+	}
+	return m.textarea.View() + "\n" + status
+}
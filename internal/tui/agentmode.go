@@ -0,0 +1,31 @@
+package tui
+
+import "prompt-cli/internal/i18n"
+
+// SetAgentMode switches between the tool-using agent prompt and the plain
+// chat prompt. In chat mode replies are never treated as tool calls.
+func (m *Model) SetAgentMode(on bool) {
+	m.chatMode = !on
+	m.rebuildSystemPrompt()
+}
+
+// handleAgentMode implements "/agent", "/agent on" and "/agent off".
+func (m *Model) handleAgentMode(args string) {
+	switch args {
+	case "":
+		if m.chatMode {
+			m.showStatus(i18n.T("agent.status_off"))
+		} else {
+			m.showStatus(i18n.T("agent.status_on"))
+		}
+	case "on":
+		m.SetAgentMode(true)
+		m.showStatus(i18n.T("agent.on"))
+		m.warnNoTools()
+	case "off":
+		m.SetAgentMode(false)
+		m.showStatus(i18n.T("agent.off"))
+	default:
+		m.showError(i18n.T("agent.usage"))
+	}
+}
@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// batchItem is one tool call of a batch the model asked for in a single
+// turn.
+type batchItem struct {
+	action        types.Action
+	needsApproval bool   // Whether the user has to approve the call.
+	allowed       bool   // The user's choice; calls start out allowed.
+	conflict      string // How the target file changed since the agent read it, if it did.
+	changeset     bool   // An apply_changeset call still to approve in its own review after the list.
+}
+
+// startBatch runs several tool calls from one reply. Calls that need
+// permission are collected into one reviewable list instead of a series of
+// prompts, and changesets get the same review as a single call; if there
+// is nothing to approve the batch runs straight away. Calls the persona
+// does not allow are refused in runBatch without asking.
+func (m *Model) startBatch(actions []types.Action) (tea.Model, tea.Cmd) {
+	var calls []types.ToolCall
+	m.batch = nil
+	review := false
+	persona, hasPersona := m.personas[m.personaName]
+	for _, action := range actions {
+		calls = append(calls, types.ToolCall{Function: types.FunctionCall{Name: action.Tool, Arguments: action.Input}})
+		conflict := m.agent.WriteConflict(action.Tool, action.Input)
+		needs := (!hasPersona || persona.Allows(action.Tool)) && m.needsApproval(&action, conflict)
+		changeset := needs && action.Tool == "apply_changeset"
+		review = review || needs && !changeset
+		m.batch = append(m.batch, batchItem{action: action, needsApproval: needs, allowed: true, conflict: conflict, changeset: changeset})
+	}
+	m.messages[len(m.messages)-1].ToolCalls = calls
+	m.messages[len(m.messages)-1].Content = ""
+
+	if !review {
+		return m.continueBatch()
+	}
+	m.batchCursor = m.nextReviewable(-1, 1)
+	m.reviewingBatch = true
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// continueBatch opens the review of the next changeset of the batch, or
+// runs the batch once all of them are decided.
+func (m *Model) continueBatch() (tea.Model, tea.Cmd) {
+	for i := range m.batch {
+		if item := &m.batch[i]; item.changeset && item.allowed {
+			item.changeset = false
+			if m.reviewBatchChangeset(i) {
+				return m, nil
+			}
+		}
+	}
+	return m.runBatch()
+}
+
+// needsApproval reports whether the user has to approve a tool call. Calls
+// that would overwrite changes made since the agent read the file always
+// ask, even if they were allowed before or YOLO mode is on.
+func (m *Model) needsApproval(action *types.Action, conflict string) bool {
+	if conflict != "" {
+		return true
+	}
+	return m.agent.NeedsPermission(action.Tool, action.Input) &&
+		!m.alwaysAllow[m.permissionKey(action)] && !m.ruleAllows(action) && !m.autoApproved(action.Tool)
+}
+
+// toolActions drops "respond" entries from a batch; a reply that mixes
+// answers and tool calls is handled as the tool calls alone.
+func toolActions(actions []types.Action) []types.Action {
+	var tools []types.Action
+	for _, action := range actions {
+		if action.Tool != "" && action.Tool != "respond" {
+			tools = append(tools, action)
+		}
+	}
+	return tools
+}
+
+// handleBatchKey handles the keys of the approval list.
+func (m *Model) handleBatchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch strings.ToLower(msg.String()) {
+	case "up", "k":
+		m.batchCursor = m.nextReviewable(m.batchCursor, -1)
+	case "down", "j":
+		m.batchCursor = m.nextReviewable(m.batchCursor, 1)
+	case " ":
+		if m.batchCursor >= 0 {
+			m.batch[m.batchCursor].allowed = !m.batch[m.batchCursor].allowed
+		}
+	case "a":
+		m.setBatchAllowed(true)
+	case "n":
+		m.setBatchAllowed(false)
+	case "enter":
+		m.reviewingBatch = false
+		m.focused = focusTextarea
+		model, cmd := m.continueBatch()
+		return model, tea.Batch(m.textarea.Focus(), cmd, tea.ClearScreen)
+	case "esc":
+		// Deny everything and hand control back to the user, like N on a
+		// single permission prompt.
+		var details []string
+		for _, item := range m.batch {
+			details = append(details, actionSummary(item.action))
+		}
+		m.messages[len(m.messages)-1].Content = fmt.Sprintf("Commands denied by user:\n\n%s", strings.Join(details, "\n"))
+		m.messages[len(m.messages)-1].Raw = nil
+		m.batch = nil
+		m.reviewingBatch = false
+		m.focused = focusTextarea
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		m.saveSession()
+		return m, tea.Batch(m.textarea.Focus(), tea.ClearScreen)
+	}
+	return m, nil
+}
+
+// setBatchAllowed checks or clears every call that needs approval.
+func (m *Model) setBatchAllowed(allowed bool) {
+	for i := range m.batch {
+		if m.batch[i].needsApproval && !m.batch[i].changeset {
+			m.batch[i].allowed = allowed
+		}
+	}
+}
+
+// nextReviewable returns the index of the next call needing approval from
+// index in direction step, staying put at either end.
+func (m *Model) nextReviewable(index, step int) int {
+	for i := index + step; i >= 0 && i < len(m.batch); i += step {
+		if m.batch[i].needsApproval && !m.batch[i].changeset {
+			return i
+		}
+	}
+	return index
+}
+
+// runBatch executes the allowed calls in order and sends all results to the
+// model as one tool message. Denied calls are reported so the model can
+// adjust its plan.
+func (m *Model) runBatch() (tea.Model, tea.Cmd) {
+	items := m.batch
+	m.batch = nil
+
+	var results strings.Builder
+	sources := sessionSources(m.messages)
+	for i, item := range items {
+		action := item.action
+		var result types.ToolResult
+		switch {
+		case item.needsApproval && !item.allowed:
+			result = agent.ErrorResult(action.Tool, types.ErrorPermission, "Error: the user denied this call.")
+		default:
+			if p, ok := m.personas[m.personaName]; ok && !p.Allows(action.Tool) {
+				result = agent.ErrorResult(action.Tool, types.ErrorPermission, fmt.Sprintf("Error: tool '%s' is not allowed for the '%s' persona.", action.Tool, m.personaName))
+				break
+			}
+			if item.needsApproval {
+				m.agent.AcceptChanges(action.Tool, action.Input)
+			}
+			m.snapshotArtifact(action.Tool, action.Input)
+			result = m.agent.ExecuteTool(action.Tool, action.Input)
+			sources = numberSources(sources, &result)
+			m.trackTouchedFile(action.Input)
+			m.recordToolCall(action.Tool, action.Input, result)
+			if agent.Suspicious(result.Output) {
+				m.showError(i18n.T("guard.suspicious", action.Tool))
+			}
+		}
+		results.WriteString(fmt.Sprintf("[%d/%d] %s\n%s\n\n", i+1, len(items), actionSummary(action), result))
+	}
+	return m.sendToolMessage(types.Message{Role: "tool", Content: strings.TrimRight(results.String(), "\n")})
+}
+
+// renderBatch draws the approval list.
+func (m *Model) renderBatch() string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T("batch.title", len(m.batch)) + "\n\n")
+	for i, item := range m.batch {
+		cursor := "  "
+		if i == m.batchCursor {
+			cursor = "> "
+		}
+		box := "   "
+		if item.needsApproval && !item.changeset {
+			box = "[ ]"
+			if item.allowed {
+				box = "[x]"
+			}
+		}
+		line := fmt.Sprintf("%s%s %d. %s", cursor, box, i+1, actionSummary(item.action))
+		switch {
+		case item.changeset:
+			line += " " + i18n.T("batch.changeset")
+		case !item.needsApproval:
+			line += " " + i18n.T("batch.no_approval")
+		}
+		if item.conflict != "" {
+			line += " " + i18n.T("conflict.batch")
+		}
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString("\n" + i18n.T("batch.keys"))
+	return builder.String()
+}
+
+// actionSummary renders a tool call on one line, leaving out file contents.
+func actionSummary(action types.Action) string {
+	keys := make([]string, 0, len(action.Input))
+	for key := range action.Input {
+		if key != "content" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := []string{action.Tool}
+	for _, key := range keys {
+		value := fmt.Sprintf("%v", action.Input[key])
+		if s, ok := action.Input[key].(string); ok {
+			value = fmt.Sprintf("%q", s)
+		}
+		if len(value) > 60 {
+			value = value[:57] + "..."
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
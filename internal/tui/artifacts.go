@@ -0,0 +1,253 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/diff"
+	"prompt-cli/internal/i18n"
+	"slices"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	artifactAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	artifactRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// artifact is a file the agent created, changed or deleted in this session,
+// with its content from before the first change so it can be diffed and
+// reverted.
+type artifact struct {
+	path     string // Display path.
+	original []byte
+	existed  bool
+}
+
+// artifactRow is what the /artifacts view shows of an artifact, read when
+// the view opens or changes.
+type artifactRow struct {
+	*artifact
+	state            string
+	size             int
+	diff             string
+	added, removed   int
+	current, missing bool
+}
+
+// artifactList is the open /artifacts view.
+type artifactList struct {
+	rows     []artifactRow
+	cursor   int
+	showDiff bool
+	offset   int    // First diff line in view.
+	confirm  bool   // "r" was pressed once; the next "r" reverts.
+	status   string // Result of the last action.
+}
+
+// writingTools are the tools whose files are tracked as artifacts.
+var writingTools = map[string]bool{"write_file": true, "append_file": true, "delete_file": true, "apply_changeset": true, "download_file": true}
+
+// writtenFiles returns the files a writing tool call changes, with the
+// paths as the model named them. Content is not filled in.
+func writtenFiles(toolName string, input map[string]interface{}) []agent.FileChange {
+	if toolName == "apply_changeset" {
+		files, _ := agent.ParseChangeset(input)
+		return files
+	}
+	if path, _ := input["path"].(string); path != "" && writingTools[toolName] {
+		return []agent.FileChange{{Path: path, Delete: toolName == "delete_file"}}
+	}
+	return nil
+}
+
+// snapshotArtifact remembers the content of the files a writing tool is
+// about to change, unless they are already tracked. recordArtifact keeps
+// them once the call succeeded.
+func (m *Model) snapshotArtifact(toolName string, input map[string]interface{}) {
+	m.pendingArtifacts = nil
+	for _, file := range writtenFiles(toolName, input) {
+		path := file.Path
+		if resolved, err := m.agent.ResolvePath(path); err == nil {
+			path = m.agent.DisplayPath(resolved)
+		}
+		if slices.ContainsFunc(m.artifacts, func(a *artifact) bool { return a.path == path }) {
+			continue
+		}
+		original, existed, err := m.agent.FileState(path)
+		if err != nil {
+			continue
+		}
+		m.pendingArtifacts = append(m.pendingArtifacts, &artifact{path: path, original: original, existed: existed})
+	}
+}
+
+// recordArtifact tracks the file at the display path after a successful
+// write.
+func (m *Model) recordArtifact(path string) {
+	for i, a := range m.pendingArtifacts {
+		if a.path == path {
+			m.artifacts = append(m.artifacts, a)
+			m.pendingArtifacts = slices.Delete(m.pendingArtifacts, i, i+1)
+			return
+		}
+	}
+}
+
+// openArtifacts implements "/artifacts", which lists the files the agent
+// wrote with their size and changes and lets the user open, revert or copy
+// them.
+func (m *Model) openArtifacts() {
+	if len(m.artifacts) == 0 {
+		m.showStatus(i18n.T("artifacts.none"))
+		return
+	}
+	m.artifactList = &artifactList{}
+	m.refreshArtifacts()
+	m.textarea.Blur()
+}
+
+// refreshArtifacts reads the current state of the tracked files.
+func (m *Model) refreshArtifacts() {
+	l := m.artifactList
+	l.rows = l.rows[:0]
+	for _, a := range m.artifacts {
+		row := artifactRow{artifact: a}
+		content, exists, err := m.agent.FileState(a.path)
+		switch {
+		case err != nil:
+			row.state = i18n.T("artifacts.unreadable")
+		case !exists && !a.existed, exists && a.existed && bytes.Equal(content, a.original):
+			row.state = i18n.T("artifacts.unchanged")
+			row.current = true
+		case !exists:
+			row.state = i18n.T("artifacts.deleted")
+		case !a.existed:
+			row.state = i18n.T("artifacts.created")
+		default:
+			row.state = i18n.T("artifacts.modified")
+		}
+		row.missing = !exists
+		row.size = len(content)
+		row.diff = diff.Unified(a.path, string(a.original), string(content))
+		row.added, row.removed = diffCounts(row.diff)
+		l.rows = append(l.rows, row)
+	}
+	l.cursor = min(l.cursor, len(l.rows)-1)
+	l.offset = 0
+}
+
+// handleArtifactsKey moves through the list and runs the quick actions.
+func (m *Model) handleArtifactsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	l := m.artifactList
+	row := l.rows[l.cursor]
+	confirm := l.confirm
+	l.confirm = false
+	switch msg.String() {
+	case "up", "k":
+		l.cursor = max(0, l.cursor-1)
+		l.offset = 0
+	case "down", "j":
+		l.cursor = min(len(l.rows)-1, l.cursor+1)
+		l.offset = 0
+	case "pgup":
+		l.offset = max(0, l.offset-previewRows)
+	case "pgdown":
+		if lines := len(diff.Lines(row.diff)); l.offset+previewRows < lines {
+			l.offset += previewRows
+		}
+	case "enter", "d":
+		l.showDiff = !l.showDiff
+		l.offset = 0
+	case "o":
+		if row.missing {
+			l.status = i18n.T("artifacts.missing", row.path)
+			return m, nil
+		}
+		m.artifactList = nil
+		m.textarea.Focus()
+		return m, m.openPath(row.path)
+	case "c":
+		if err := clipboard.WriteAll(row.path); err != nil {
+			l.status = i18n.T("artifacts.copy_failed", err)
+		} else {
+			l.status = i18n.T("artifacts.copied", row.path)
+		}
+	case "r":
+		if row.current {
+			l.status = i18n.T("artifacts.nothing_to_revert", row.path)
+			return m, nil
+		}
+		if !confirm {
+			l.confirm = true
+			l.status = i18n.T("artifacts.confirm_revert", row.path)
+			return m, nil
+		}
+		if err := m.agent.RevertFile(row.path, row.original, row.existed); err != nil {
+			l.status = i18n.T("artifacts.revert_failed", row.path, err)
+			return m, nil
+		}
+		m.updateFileList()
+		m.refreshArtifacts()
+		l.status = i18n.T("artifacts.reverted", row.path)
+	case "esc", "q", "ctrl+c":
+		m.artifactList = nil
+		return m, m.textarea.Focus()
+	}
+	return m, nil
+}
+
+// artifactsView renders the list and, if toggled, the diff of the selected
+// file.
+func (m *Model) artifactsView(width int) string {
+	l := m.artifactList
+	var builder strings.Builder
+	builder.WriteString(i18n.T("artifacts.title", len(l.rows)) + "\n\n")
+	for i, row := range l.rows {
+		cursor := "  "
+		if i == l.cursor {
+			cursor = "> "
+		}
+		changes := artifactAddedStyle.Render(fmt.Sprintf("+%d", row.added)) + " " + artifactRemovedStyle.Render(fmt.Sprintf("-%d", row.removed))
+		line := fmt.Sprintf("%s%s  %s  %s  %s", cursor, row.path, row.state, formatBytes(row.size), changes)
+		if i == l.cursor {
+			line = previewSelectedStyle.Render(cursor+row.path) + strings.TrimPrefix(line, cursor+row.path)
+		}
+		builder.WriteString(truncateLine(line, width) + "\n")
+	}
+
+	if l.showDiff {
+		row := l.rows[l.cursor]
+		lines := diff.Lines(row.diff)
+		builder.WriteString("\n")
+		if len(lines) == 0 {
+			builder.WriteString(previewLineNumberStyle.Render(i18n.T("artifacts.no_diff")) + "\n")
+		}
+		end := min(len(lines), l.offset+previewRows)
+		for _, line := range lines[l.offset:end] {
+			line = strings.ReplaceAll(line, "\t", "    ")
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				line = previewLineNumberStyle.Render(line)
+			case strings.HasPrefix(line, "+"):
+				line = artifactAddedStyle.Render(line)
+			case strings.HasPrefix(line, "-"):
+				line = artifactRemovedStyle.Render(line)
+			}
+			builder.WriteString(truncateLine(line, width) + "\n")
+		}
+		if len(lines) > previewRows {
+			builder.WriteString(previewLineNumberStyle.Render(fmt.Sprintf("%d-%d/%d", l.offset+1, end, len(lines))) + "\n")
+		}
+	}
+
+	if l.status != "" {
+		builder.WriteString("\n" + l.status + "\n")
+	}
+	builder.WriteString("\n" + i18n.T("artifacts.keys"))
+	return builder.String()
+}
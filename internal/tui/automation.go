@@ -0,0 +1,197 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/rpc"
+	"prompt-cli/internal/types"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// automationTimeout bounds how long a socket request waits for the TUI,
+// e.g. while it is blocked in an external editor.
+const automationTimeout = 10 * time.Second
+
+// errBusy is returned for requests that need the TUI to be idle.
+var errBusy = errors.New("busy: a reply is being generated or a tool call is waiting for approval")
+
+// automationMsg carries a request from the automation socket into the
+// program, so it is handled on the UI goroutine like a key press.
+type automationMsg struct {
+	method string
+	params json.RawMessage
+	reply  chan automationReply
+}
+
+type automationReply struct {
+	result interface{}
+	err    error
+}
+
+// automationStatus is the result of the "status" method.
+type automationStatus struct {
+	Session   string   `json:"session"`
+	Model     string   `json:"model"`
+	AgentMode bool     `json:"agent_mode"`
+	Persona   string   `json:"persona,omitempty"`
+	Busy      bool     `json:"busy"`
+	Streaming bool     `json:"streaming"`
+	Pending   []string `json:"pending_tools,omitempty"` // Tool calls waiting for approval.
+	Messages  int      `json:"messages"`
+	Stats     string   `json:"stats,omitempty"`
+}
+
+// AutomationHandler returns the handler of the automation socket. Requests
+// are passed to the running program p and answered from there:
+//
+//   - send {"text"}: submit text as if typed and sent, slash commands included
+//   - status: session, model and whether the TUI is busy
+//   - inject_tool_result {"output", "tool"}: answer the tool call waiting for
+//     approval with output instead of running it, or add a tool result and
+//     let the model continue
+//   - transcript {"since"}: the conversation from message index since on
+func AutomationHandler(p *tea.Program) rpc.Handler {
+	return func(method string, params json.RawMessage) (interface{}, error) {
+		reply := make(chan automationReply, 1)
+		go p.Send(automationMsg{method: method, params: params, reply: reply})
+		select {
+		case r := <-reply:
+			return r.result, r.err
+		case <-time.After(automationTimeout):
+			return nil, fmt.Errorf("the TUI did not answer within %s", automationTimeout)
+		}
+	}
+}
+
+// handleAutomation runs a socket request and sends back its result.
+func (m *Model) handleAutomation(msg automationMsg) (tea.Model, tea.Cmd) {
+	result, cmd, err := m.automationCall(msg.method, msg.params)
+	msg.reply <- automationReply{result: result, err: err}
+	return m, cmd
+}
+
+func (m *Model) automationCall(method string, params json.RawMessage) (interface{}, tea.Cmd, error) {
+	switch method {
+	case "status":
+		return m.automationStatus(), nil, nil
+
+	case "transcript":
+		var args struct {
+			Since int `json:"since"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, nil, err
+		}
+		return m.automationTranscript(args.Since), nil, nil
+
+	case "send":
+		var args struct {
+			Text string `json:"text"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, nil, err
+		}
+		if args.Text == "" {
+			return nil, nil, rpc.InvalidParams("text is required")
+		}
+		if m.busy() {
+			return nil, nil, errBusy
+		}
+		m.textarea.SetValue(args.Text)
+		_, cmd := m.handleEnter()
+		return map[string]interface{}{"sending": m.sending}, cmd, nil
+
+	case "inject_tool_result":
+		var args struct {
+			Tool   string `json:"tool"`
+			Output string `json:"output"`
+		}
+		if err := rpc.DecodeParams(params, &args); err != nil {
+			return nil, nil, err
+		}
+		if args.Output == "" {
+			return nil, nil, rpc.InvalidParams("output is required")
+		}
+		result, cmd, err := m.injectToolResult(args.Tool, args.Output)
+		if err != nil {
+			return nil, nil, err
+		}
+		return result, cmd, nil
+	}
+	return nil, nil, rpc.MethodNotFound(method)
+}
+
+// busy reports whether the TUI is streaming or waiting for the user.
+func (m *Model) busy() bool {
+	return m.sending || m.permissionRequest != nil || m.reviewingBatch || m.picker != nil || m.redirecting || m.guard != nil || m.artifactList != nil || m.changeset != nil
+}
+
+func (m *Model) automationStatus() automationStatus {
+	status := automationStatus{
+		Model:     m.modelName,
+		AgentMode: !m.chatMode,
+		Persona:   m.personaName,
+		Busy:      m.busy(),
+		Streaming: m.streaming,
+		Messages:  len(m.messages),
+		Stats:     m.stats,
+	}
+	if m.session != nil {
+		status.Session = m.session.ID
+	}
+	if m.permissionRequest != nil {
+		status.Pending = []string{m.permissionRequest.Tool}
+	}
+	if m.reviewingBatch {
+		for _, item := range m.batch {
+			if item.needsApproval {
+				status.Pending = append(status.Pending, item.action.Tool)
+			}
+		}
+	}
+	return status
+}
+
+// automationTranscript returns the messages from index since on. Images
+// are left out to keep the response small.
+func (m *Model) automationTranscript(since int) map[string]interface{} {
+	since = max(0, min(since, len(m.messages)))
+	messages := make([]types.Message, 0, len(m.messages)-since)
+	for _, msg := range m.messages[since:] {
+		msg.Images = nil
+		msg.Raw = nil
+		messages = append(messages, msg)
+	}
+	return map[string]interface{}{"from": since, "messages": messages}
+}
+
+// injectToolResult answers the tool call waiting for approval with output,
+// as if it had run. Without a pending call it appends a result for tool and
+// asks the model to continue.
+func (m *Model) injectToolResult(tool, output string) (types.ToolResult, tea.Cmd, error) {
+	if m.reviewingBatch {
+		return types.ToolResult{}, nil, errors.New("several tool calls are waiting for approval; answer them in the TUI")
+	}
+	if action := m.permissionRequest; action != nil {
+		if tool != "" && tool != action.Tool {
+			return types.ToolResult{}, nil, fmt.Errorf("the pending tool call is %s, not %s", action.Tool, tool)
+		}
+		m.permissionRequest = nil
+		result := agent.ClassifyResult(action.Tool, action.Input, output)
+		_, cmd := m.sendToolResult(result)
+		return result, cmd, nil
+	}
+	if m.busy() {
+		return types.ToolResult{}, nil, errBusy
+	}
+	if tool == "" {
+		return types.ToolResult{}, nil, rpc.InvalidParams("tool is required when no tool call is pending")
+	}
+	result := agent.ClassifyResult(tool, nil, output)
+	_, cmd := m.sendToolResult(result)
+	return result, cmd, nil
+}
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/workspace"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bundlesFile stores the named context bundles of the workspace.
+const bundlesFile = "bundles.json"
+
+// bundleLoadedMsg carries the content gathered for a context bundle.
+type bundleLoadedMsg struct {
+	name    string
+	context []string
+	failed  []string
+}
+
+func loadBundles() (map[string][]string, error) {
+	bundles := make(map[string][]string)
+	err := workspace.ReadJSON(bundlesFile, &bundles)
+	return bundles, err
+}
+
+// handleBundle implements "/bundle [list]", "/bundle save <name> <item>...",
+// "/bundle load <name>" and "/bundle delete <name>". Items are workspace
+// files, globs such as "internal/parser/**/*.go", or URLs.
+func (m *Model) handleBundle(args string) tea.Cmd {
+	bundles, err := loadBundles()
+	if err != nil {
+		m.showError(i18n.T("bundle.failed", err))
+		return nil
+	}
+
+	fields := strings.Fields(args)
+	command := "list"
+	if len(fields) > 0 {
+		command, fields = fields[0], fields[1:]
+	}
+
+	switch {
+	case command == "list" && len(fields) == 0:
+		if len(bundles) == 0 {
+			m.showStatus(i18n.T("bundle.none"))
+			return nil
+		}
+		names := make([]string, 0, len(bundles))
+		for name := range bundles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var builder strings.Builder
+		builder.WriteString(i18n.T("bundle.title") + "\n\n")
+		for _, name := range names {
+			builder.WriteString(fmt.Sprintf("- **%s**: %s\n", name, strings.Join(bundles[name], ", ")))
+		}
+		m.showStatus(builder.String())
+	case command == "save" && len(fields) >= 2:
+		bundles[fields[0]] = fields[1:]
+		if err := workspace.WriteJSON(bundlesFile, bundles); err != nil {
+			m.showError(i18n.T("bundle.failed", err))
+			return nil
+		}
+		m.showStatus(i18n.T("bundle.saved", fields[0], len(fields)-1))
+	case command == "delete" && len(fields) == 1:
+		if _, ok := bundles[fields[0]]; !ok {
+			m.showError(i18n.T("bundle.unknown", fields[0]))
+			return nil
+		}
+		delete(bundles, fields[0])
+		if err := workspace.WriteJSON(bundlesFile, bundles); err != nil {
+			m.showError(i18n.T("bundle.failed", err))
+			return nil
+		}
+		m.showStatus(i18n.T("bundle.deleted", fields[0]))
+	case command == "load" && len(fields) == 1:
+		items, ok := bundles[fields[0]]
+		if !ok {
+			m.showError(i18n.T("bundle.unknown", fields[0]))
+			return nil
+		}
+		m.showStatus(i18n.T("bundle.loading", fields[0]))
+		return loadBundle(m.agent, fields[0], items)
+	default:
+		m.showError(i18n.T("bundle.usage"))
+	}
+	return nil
+}
+
+// loadBundle gathers the bundle's files and pages in the background. URLs
+// and globs go through the agent's tools, so they get the same fetching
+// rules, limits and prompt injection guard as tool calls.
+func loadBundle(a *agent.Agent, name string, items []string) tea.Cmd {
+	return func() tea.Msg {
+		msg := bundleLoadedMsg{name: name}
+		for _, item := range items {
+			var content string
+			switch {
+			case strings.HasPrefix(item, "http://") || strings.HasPrefix(item, "https://"):
+				content = a.ExecuteCommand("visit_url", map[string]interface{}{"url": item})
+				content = strings.TrimPrefix(content, agent.CachedMarker)
+			case strings.ContainsAny(item, "*?["):
+				content = a.ExecuteCommand("read_all_files", map[string]interface{}{"glob": item})
+				content = strings.TrimPrefix(content, agent.CachedMarker)
+				if strings.HasPrefix(content, "No files found") {
+					content = "Error: " + content
+				}
+			default:
+				data, err := a.ReadWorkspaceFile(item)
+				if err != nil {
+					content = fmt.Sprintf("Error: %v", err)
+				} else {
+					content = fence(string(data), fenceLanguage(item))
+				}
+			}
+			if strings.HasPrefix(content, "Error") || strings.HasPrefix(content, "Request to") {
+				msg.failed = append(msg.failed, item)
+				continue
+			}
+			msg.context = append(msg.context, fmt.Sprintf("---\nContext: %s\n%s", item, content))
+		}
+		return msg
+	}
+}
+
+// handleBundleLoaded attaches the bundle content to the next prompt.
+func (m *Model) handleBundleLoaded(msg bundleLoadedMsg) (tea.Model, tea.Cmd) {
+	m.pendingContext = append(m.pendingContext, msg.context...)
+	m.showStatus(i18n.T("bundle.loaded", msg.name, len(msg.context)))
+	if len(msg.failed) > 0 {
+		m.showError(i18n.T("bundle.load_failed", strings.Join(msg.failed, ", ")))
+	}
+	return m, nil
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/ollama"
+	"strings"
+)
+
+// SetCapabilities records what the selected model supports and adapts the
+// session: thinking models are asked for their reasoning separately, and
+// agent mode warns when the model does not advertise tool support.
+func (m *Model) SetCapabilities(caps ollama.Capabilities) {
+	m.capabilities = caps
+	m.ollamaClient.SetThink(caps.Thinking)
+	m.warnNoTools()
+}
+
+// ProbeServer reads the Ollama server version and warns once about the
+// features it is too old for, which requests then leave out instead of
+// failing mid-session.
+func (m *Model) ProbeServer() {
+	features := m.ollamaClient.Probe()
+	if disabled := features.Disabled(); len(disabled) > 0 {
+		m.showNotice(i18n.T("server.old", features.Version, strings.Join(disabled, ", ")))
+	}
+}
+
+// warnNoTools tells the user that agent mode may be unreliable with a model
+// that was not trained for tool calling.
+func (m *Model) warnNoTools() {
+	if !m.chatMode && !m.capabilities.HasTools() {
+		m.showNotice(i18n.T("model.no_tools", m.modelName))
+	}
+}
+
+// thinkingBlock renders a thinking model's reasoning as a Markdown quote
+// shown above its answer.
+func thinkingBlock(thinking string) string {
+	lines := strings.Split(strings.TrimSpace(thinking), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return "*" + i18n.T("model.thinking") + "*\n\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/diff"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// changesetReview is the open review of an apply_changeset call: every file
+// with its diff, approved or denied as a whole instead of one permission
+// prompt per file.
+type changesetReview struct {
+	action *types.Action
+	files  []agent.ChangePreview
+	diffs  []string
+	cursor int
+	offset int // First diff line in view.
+	batch  int // Index of the call in m.batch, or -1 for a call of its own.
+}
+
+// reviewChangeset opens the review of a changeset. Invalid changesets are
+// reported to the model without asking the user.
+func (m *Model) reviewChangeset(action *types.Action) (tea.Model, tea.Cmd) {
+	if err := m.openChangeset(action, -1); err != nil {
+		return m.sendToolResult(agent.ErrorResult(action.Tool, types.ErrorInvalidInput, fmt.Sprintf("Error: %v", err)))
+	}
+	return m, nil
+}
+
+// reviewBatchChangeset opens the review of the changeset at index of the
+// batch. It returns false for an invalid changeset: the call runs with the
+// batch and reports the error, having nothing to write.
+func (m *Model) reviewBatchChangeset(index int) bool {
+	if err := m.openChangeset(&m.batch[index].action, index); err != nil {
+		m.batch[index].needsApproval = false
+		return false
+	}
+	return true
+}
+
+// openChangeset shows the files of a changeset with their diffs.
+func (m *Model) openChangeset(action *types.Action, batch int) error {
+	files, err := m.agent.PreviewChangeset(action.Input)
+	if err != nil {
+		return err
+	}
+	review := &changesetReview{action: action, files: files, batch: batch}
+	for _, file := range files {
+		after := file.Content
+		if file.Delete {
+			after = ""
+		}
+		review.diffs = append(review.diffs, diff.Unified(file.Path, string(file.Original), after))
+	}
+	m.changeset = review
+	m.textarea.Blur()
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return nil
+}
+
+// handleChangesetKey moves through the files and applies or denies the
+// changeset.
+func (m *Model) handleChangesetKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	r := m.changeset
+	switch strings.ToLower(msg.String()) {
+	case "up", "k":
+		r.cursor = max(0, r.cursor-1)
+		r.offset = 0
+	case "down", "j":
+		r.cursor = min(len(r.files)-1, r.cursor+1)
+		r.offset = 0
+	case "pgup":
+		r.offset = max(0, r.offset-previewRows)
+	case "pgdown":
+		if lines := len(diff.Lines(r.diffs[r.cursor])); r.offset+previewRows < lines {
+			r.offset += previewRows
+		}
+	case "a", "y", "enter":
+		m.changeset = nil
+		if r.batch >= 0 {
+			// The batch accepts the changes when it runs the call.
+			model, cmd := m.continueBatch()
+			return model, tea.Batch(m.textarea.Focus(), cmd)
+		}
+		m.agent.AcceptChanges(r.action.Tool, r.action.Input)
+		model, cmd := m.executeAndRespond(r.action.Tool, r.action.Input)
+		m.updateFileList()
+		return model, tea.Batch(m.textarea.Focus(), cmd)
+	case "n", "esc", "ctrl+c":
+		m.changeset = nil
+		if r.batch >= 0 {
+			m.batch[r.batch].allowed = false
+			model, cmd := m.continueBatch()
+			return model, tea.Batch(m.textarea.Focus(), cmd)
+		}
+		m.messages[len(m.messages)-1].Content = i18n.T("changeset.denied", len(r.files), strings.Join(changesetFilePaths(r.files), ", "))
+		m.messages[len(m.messages)-1].Raw = nil
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		m.saveSession()
+		return m, m.textarea.Focus()
+	}
+	return m, nil
+}
+
+// changesetFilePaths lists the paths of a changeset.
+func changesetFilePaths(files []agent.ChangePreview) []string {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	return paths
+}
+
+// changesetView renders the files of the changeset and the diff of the
+// selected one.
+func (m *Model) changesetView(width int) string {
+	r := m.changeset
+	var builder strings.Builder
+	builder.WriteString(i18n.T("changeset.title", len(r.files)) + "\n")
+	if description, _ := r.action.Input["description"].(string); description != "" {
+		builder.WriteString(description + "\n")
+	}
+	builder.WriteString("\n")
+	for i, file := range r.files {
+		cursor := "  "
+		if i == r.cursor {
+			cursor = "> "
+		}
+		state := i18n.T("artifacts.modified")
+		switch {
+		case file.Delete:
+			state = i18n.T("artifacts.deleted")
+		case !file.Existed:
+			state = i18n.T("artifacts.created")
+		}
+		added, removed := diffCounts(r.diffs[i])
+		changes := artifactAddedStyle.Render(fmt.Sprintf("+%d", added)) + " " + artifactRemovedStyle.Render(fmt.Sprintf("-%d", removed))
+		line := fmt.Sprintf("%s%s  %s  %s", cursor, file.Path, state, changes)
+		if i == r.cursor {
+			line = previewSelectedStyle.Render(cursor+file.Path) + strings.TrimPrefix(line, cursor+file.Path)
+		}
+		if file.Conflict != "" {
+			line += "  " + artifactRemovedStyle.Render(i18n.T("changeset.conflict", file.Conflict))
+		}
+		builder.WriteString(truncateLine(line, width) + "\n")
+	}
+
+	lines := diff.Lines(r.diffs[r.cursor])
+	builder.WriteString("\n")
+	if len(lines) == 0 {
+		builder.WriteString(previewLineNumberStyle.Render(i18n.T("artifacts.no_diff")) + "\n")
+	}
+	end := min(len(lines), r.offset+previewRows)
+	for _, line := range lines[r.offset:end] {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			line = previewLineNumberStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = artifactAddedStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = artifactRemovedStyle.Render(line)
+		}
+		builder.WriteString(truncateLine(line, width) + "\n")
+	}
+	if len(lines) > previewRows {
+		builder.WriteString(previewLineNumberStyle.Render(fmt.Sprintf("%d-%d/%d", r.offset+1, end, len(lines))) + "\n")
+	}
+	builder.WriteString("\n" + i18n.T("changeset.keys"))
+	return builder.String()
+}
+
+// diffCounts counts the added and removed lines of a unified diff.
+func diffCounts(unified string) (added, removed int) {
+	lines := diff.Lines(unified)
+	for _, line := range lines[min(2, len(lines)):] { // After the file headers.
+		switch {
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
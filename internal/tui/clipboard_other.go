@@ -0,0 +1,9 @@
+//go:build !windows
+
+package tui
+
+// clipboardDIB is only available on Windows; elsewhere the image is read
+// with the commands from clipboardImageCommands.
+func clipboardDIB() ([]byte, error) {
+	return nil, errNoNativeClipboard
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                     = syscall.NewLazyDLL("user32.dll")
+	kernel32                   = syscall.NewLazyDLL("kernel32.dll")
+	isClipboardFormatAvailable = user32.NewProc("IsClipboardFormatAvailable")
+	openClipboard              = user32.NewProc("OpenClipboard")
+	closeClipboard             = user32.NewProc("CloseClipboard")
+	getClipboardData           = user32.NewProc("GetClipboardData")
+	globalLock                 = kernel32.NewProc("GlobalLock")
+	globalUnlock               = kernel32.NewProc("GlobalUnlock")
+	globalSize                 = kernel32.NewProc("GlobalSize")
+	moveMemory                 = kernel32.NewProc("RtlMoveMemory")
+)
+
+// cfDIB is the clipboard format of a device-independent bitmap.
+const cfDIB = 8
+
+// clipboardDIB reads the clipboard image through the Windows API, without
+// starting PowerShell.
+func clipboardDIB() ([]byte, error) {
+	if ok, _, _ := isClipboardFormatAvailable.Call(cfDIB); ok == 0 {
+		return nil, fmt.Errorf("the clipboard holds no image")
+	}
+	if ok, _, err := openClipboard.Call(0); ok == 0 {
+		return nil, fmt.Errorf("OpenClipboard: %v", err)
+	}
+	defer closeClipboard.Call()
+
+	handle, _, err := getClipboardData.Call(cfDIB)
+	if handle == 0 {
+		return nil, fmt.Errorf("GetClipboardData: %v", err)
+	}
+	size, _, _ := globalSize.Call(handle)
+	ptr, _, err := globalLock.Call(handle)
+	if ptr == 0 || size == 0 {
+		return nil, fmt.Errorf("GlobalLock: %v", err)
+	}
+	defer globalUnlock.Call(handle)
+
+	data := make([]byte, size)
+	moveMemory.Call(uintptr(unsafe.Pointer(&data[0])), ptr, size)
+	return data, nil
+}
@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/persona"
+	"prompt-cli/internal/types"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultCriticPrompt is the system prompt of the review pass unless the
+// persona or config sets one.
+const defaultCriticPrompt = "You are a critical reviewer. Check the draft below for factual mistakes, bugs, unhandled edge cases and code that would not compile or run. List the concrete problems with a fix for each, most important first. If you find no problems, reply only with \"LGTM\"."
+
+// criticMsg carries the result of a review pass. change is set when a
+// proposed file change was reviewed rather than an answer.
+type criticMsg struct {
+	model  string
+	text   string
+	err    error
+	change *types.Action
+}
+
+// critic returns the review settings in effect: those of the active
+// persona, then the config's, or the defaults after "/critic on". It is nil
+// when no review pass runs.
+func (m *Model) critic() *persona.Critic {
+	if m.criticMode == "off" {
+		return nil
+	}
+	if p, ok := m.personas[m.personaName]; ok && p.Critic != nil {
+		return p.Critic
+	}
+	if m.config.Critic != nil {
+		return m.config.Critic
+	}
+	if m.criticMode == "on" {
+		return &persona.Critic{}
+	}
+	return nil
+}
+
+// handleCritic implements "/critic [on|off|auto]". Auto runs the review
+// pass when the persona or config asks for it.
+func (m *Model) handleCritic(args string) {
+	switch args {
+	case "":
+	case "on", "off":
+		m.criticMode = args
+	case "auto":
+		m.criticMode = ""
+	default:
+		m.showError(i18n.T("critic.usage"))
+		return
+	}
+	if c := m.critic(); c != nil {
+		m.showStatus(i18n.T("critic.active", m.criticModel(c)))
+	} else {
+		m.showStatus(i18n.T("critic.inactive"))
+	}
+}
+
+// criticModel is the model that reviews: the configured one or the current
+// model.
+func (m *Model) criticModel(c *persona.Critic) string {
+	if c.Model != "" {
+		return c.Model
+	}
+	return m.modelName
+}
+
+// review asks the critic model about draft, the answer to or change for
+// request. The request stays busy until the review is in.
+func (m *Model) review(c *persona.Critic, request, draft string, change *types.Action) tea.Cmd {
+	prompt := c.Prompt
+	if prompt == "" {
+		prompt = defaultCriticPrompt
+	}
+	messages := []types.Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: fmt.Sprintf("Request:\n%s\n\nDraft:\n%s", request, draft)},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if change == nil {
+		m.cancel = cancel
+	}
+	client, model, options, clean := m.ollamaClient, m.criticModel(c), m.requestOptions(), m.postProcess
+	return func() tea.Msg {
+		defer cancel()
+		resp, err := client.Chat(ctx, model, messages, options)
+		return criticMsg{model: model, text: clean.Clean(resp.Message.Content), err: err, change: change}
+	}
+}
+
+// reviewAnswer starts the review of the answer just given, if a critic is
+// active. The answer counts as finished once the review is shown.
+func (m *Model) reviewAnswer() tea.Cmd {
+	c := m.critic()
+	if c == nil || len(m.messages) == 0 {
+		return nil
+	}
+	draft := m.messages[len(m.messages)-1]
+	if draft.Role != "assistant" || strings.TrimSpace(draft.Content) == "" {
+		return nil
+	}
+	m.sending = true
+	m.reviewing = true
+	return m.review(c, m.lastRequest(), draft.Content, nil)
+}
+
+// reviewChange starts the review of a proposed file change while the user
+// is asked to approve it. The review is shown in the permission prompt.
+func (m *Model) reviewChange(action *types.Action) tea.Cmd {
+	c := m.critic()
+	if c == nil {
+		return nil
+	}
+	content, ok := action.Input["content"].(string)
+	path, _ := action.Input["path"].(string)
+	if !ok || action.Tool != "write_file" && action.Tool != "append_file" {
+		return nil
+	}
+	m.permissionReview = i18n.T("critic.pending")
+	draft := fmt.Sprintf("%s to %s:\n```\n%s\n```", action.Tool, path, content)
+	return m.review(c, m.lastRequest(), draft, action)
+}
+
+// handleCriticDone shows a finished review: below the answer it reviewed,
+// or in the permission prompt of the change.
+func (m *Model) handleCriticDone(msg criticMsg) tea.Cmd {
+	text := msg.text
+	if msg.err != nil {
+		text = i18n.T("critic.failed", msg.err)
+	}
+	if msg.change != nil {
+		if m.permissionRequest == msg.change {
+			m.permissionReview = text
+		}
+		return nil
+	}
+	if !m.reviewing {
+		return nil // Stopped with /stop.
+	}
+	m.reviewing = false
+	m.sending = false
+	m.addMessage(types.Message{
+		Role:    "assistant",
+		Content: i18n.T("critic.title", msg.model) + "\n\n" + text,
+		IsError: msg.err != nil,
+		Local:   true,
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	m.saveSession()
+	return m.afterAnswer()
+}
+
+// answerFinished runs when the model has given a final answer, as opposed
+// to a tool call.
+func (m *Model) answerFinished() tea.Cmd {
+	m.spoolReply()
+	if cmd := m.reviewAnswer(); cmd != nil {
+		return cmd
+	}
+	return m.afterAnswer()
+}
+
+// afterAnswer reads the answer aloud and moves a running plan on.
+func (m *Model) afterAnswer() tea.Cmd {
+	if m.plan != nil {
+		return tea.Batch(m.speakLastResponse(), m.advancePlan())
+	}
+	return m.speakLastResponse()
+}
+
+// lastRequest returns the user's most recent message.
+func (m *Model) lastRequest() string {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if msg := m.messages[i]; msg.Role == "user" && !msg.Local {
+			return msg.Content
+		}
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// compactKeep is how many of the latest messages compaction leaves as they
+// are.
+const compactKeep = 4
+
+// compactPrompt asks the model to summarize the messages being compacted.
+const compactPrompt = "Summarize the conversation below so it can replace it as context for continuing the work. Keep the user's goals and preferences, decisions made, file paths, names, numbers and open questions; drop pleasantries and tool output that is no longer needed. Reply with the summary only."
+
+// compactedPrefix introduces the summary in requests.
+const compactedPrefix = "Summary of the earlier conversation, which was compacted to fit the context:\n\n"
+
+// contextGuard holds a prompt that would not fit into the context while the
+// user decides what to do about it.
+type contextGuard struct {
+	prompt string // As typed.
+	input  string // With @ mentions expanded.
+	tokens int
+}
+
+// compactedMsg carries the summary of the compacted messages.
+type compactedMsg struct {
+	summary string
+	indexes []int // Messages the summary replaces.
+	err     error
+}
+
+// guardedSend sends input, the expanded form of prompt, unless the request
+// would exceed num_ctx. Ollama then silently drops the start of the
+// conversation, system prompt included, so the user is asked first.
+func (m *Model) guardedSend(prompt, input string) tea.Cmd {
+	limit := m.contextSize()
+	tokens := m.estimateRequest(input)
+	if limit <= 0 || int64(tokens) <= limit {
+		return m.send(input)
+	}
+	m.guard = &contextGuard{prompt: prompt, input: input, tokens: tokens}
+	m.textarea.Blur()
+	return nil
+}
+
+// estimateRequest approximates the prompt tokens of a request that sends
+// input with the pending context.
+func (m *Model) estimateRequest(input string) int {
+	tokens := estimateTokens(input)
+	for _, msg := range m.requestMessages() {
+		tokens += estimateTokens(msg.Content)
+	}
+	for _, item := range m.pendingContext {
+		tokens += estimateTokens(item)
+	}
+	return tokens
+}
+
+// hasAttachments reports whether the guarded prompt carries anything that
+// can be dropped: inlined files, pending context or images.
+func (g *contextGuard) hasAttachments(m *Model) bool {
+	return g.input != g.prompt || len(m.pendingContext) > 0 || len(m.pendingImages) > 0
+}
+
+// handleGuardKey acts on the user's choice in the context guard prompt.
+func (m *Model) handleGuardKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	g := m.guard
+	switch strings.ToLower(msg.String()) {
+	case "c":
+		if m.compacting {
+			return m, nil
+		}
+		return m, m.compact()
+	case "d":
+		if !g.hasAttachments(m) {
+			return m, nil
+		}
+		m.guard = nil
+		m.pendingContext = nil
+		m.pendingImages = nil
+		m.textarea.Focus()
+		return m, m.guardedSend(g.prompt, g.prompt)
+	case "s":
+		m.guard = nil
+		m.textarea.Focus()
+		return m, m.send(g.input)
+	case "esc":
+		m.guard = nil
+		m.compacting = false
+		m.textarea.SetValue(g.prompt)
+		m.textarea.CursorEnd()
+		return m, m.textarea.Focus()
+	}
+	return m, nil
+}
+
+// guardView renders the context guard prompt.
+func (m *Model) guardView() string {
+	g := m.guard
+	text := i18n.T("guard.prompt", g.tokens, m.contextSize())
+	if m.compacting {
+		return text + "\n\n" + m.spinner.View() + " " + i18n.T("guard.compacting")
+	}
+	options := i18n.T("guard.compact") + "   "
+	if g.hasAttachments(m) {
+		options += i18n.T("guard.drop") + "   "
+	}
+	return text + "\n\n" + options + i18n.T("guard.send")
+}
+
+// compact asks the model to summarize all but the latest messages. Once the
+// summary is in, they are left out of requests like with /forget and the
+// summary is sent after the system prompt instead.
+func (m *Model) compact() tea.Cmd {
+	var indexes []int
+	var conversation strings.Builder
+	if m.session.Compacted != "" {
+		conversation.WriteString(compactedPrefix + m.session.Compacted + "\n\n")
+	}
+	last := len(m.messages) - compactKeep
+	for i := 1; i < last; i++ {
+		msg := m.messages[i]
+		if msg.Local || msg.Excluded || msg.Role == "system" {
+			continue
+		}
+		indexes = append(indexes, i)
+		content := msg.Content
+		if len(msg.ToolCalls) > 0 {
+			content = fmt.Sprintf("(calls %s with %v)", msg.ToolCalls[0].Function.Name, msg.ToolCalls[0].Function.Arguments)
+		}
+		fmt.Fprintf(&conversation, "%s: %s\n\n", msg.Role, content)
+	}
+	if len(indexes) == 0 {
+		m.showError(i18n.T("guard.nothing"))
+		return nil
+	}
+
+	m.compacting = true
+	client, model, options, clean := m.ollamaClient, m.modelName, m.requestOptions(), m.postProcess
+	messages := []types.Message{
+		{Role: "system", Content: compactPrompt},
+		{Role: "user", Content: conversation.String()},
+	}
+	return func() tea.Msg {
+		resp, err := client.Chat(context.Background(), model, messages, options)
+		return compactedMsg{summary: clean.Clean(resp.Message.Content), indexes: indexes, err: err}
+	}
+}
+
+// handleCompacted replaces the compacted messages with their summary and
+// checks the guarded prompt again.
+func (m *Model) handleCompacted(msg compactedMsg) tea.Cmd {
+	if !m.compacting {
+		return nil // Canceled with Esc.
+	}
+	m.compacting = false
+	if msg.err == nil && strings.TrimSpace(msg.summary) == "" {
+		msg.err = errors.New("the model returned an empty summary")
+	}
+	if msg.err != nil {
+		m.showError(i18n.T("guard.compact_failed", msg.err))
+		return nil
+	}
+	for _, i := range msg.indexes {
+		if i < len(m.messages) {
+			m.messages[i].Excluded = true
+		}
+	}
+	m.session.Compacted = msg.summary
+	m.addMessage(types.Message{
+		Role:    "assistant",
+		Content: i18n.T("guard.compacted", len(msg.indexes)) + "\n\n" + msg.summary,
+		Local:   true,
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	m.saveSession()
+
+	g := m.guard
+	m.guard = nil
+	m.textarea.Focus()
+	return m.guardedSend(g.prompt, g.input)
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/i18n"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dictationFinishedMsg carries the transcript printed by the STT command.
+type dictationFinishedMsg struct {
+	text string
+	err  error
+}
+
+// toggleDictation implements push-to-talk. The first press starts the
+// configured STT command, which is expected to record until it is
+// interrupted and then print the transcript. The second press interrupts
+// it.
+func (m *Model) toggleDictation() tea.Cmd {
+	if m.dictation != nil {
+		if runtime.GOOS == "windows" {
+			m.dictation.Process.Kill()
+		} else {
+			m.dictation.Process.Signal(os.Interrupt)
+		}
+		return nil
+	}
+
+	if m.config == nil || m.config.STTCommand == "" {
+		m.showError(i18n.T("dictation.not_configured"))
+		return nil
+	}
+
+	shell, args := agent.ShellCommand(m.config.STTCommand)
+	cmd := exec.Command(shell, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		m.showError(i18n.T("dictation.failed", err))
+		return nil
+	}
+	m.dictation = cmd
+
+	return func() tea.Msg {
+		err := cmd.Wait()
+		text := strings.TrimSpace(stdout.String())
+		// An interrupted recorder may exit non-zero; the transcript is still
+		// usable if it printed one.
+		if err != nil && text == "" {
+			return dictationFinishedMsg{err: fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))}
+		}
+		return dictationFinishedMsg{text: text}
+	}
+}
+
+// handleDictationFinished inserts the transcript into the textarea so it can
+// be reviewed before pressing Enter.
+func (m *Model) handleDictationFinished(msg dictationFinishedMsg) (tea.Model, tea.Cmd) {
+	m.dictation = nil
+	if msg.err != nil {
+		m.showError(i18n.T("dictation.failed", msg.err))
+		return m, nil
+	}
+	if msg.text == "" {
+		return m, nil
+	}
+
+	value := m.textarea.Value()
+	if value != "" && !strings.HasSuffix(value, " ") {
+		value += " "
+	}
+	m.textarea.SetValue(value + msg.text)
+	m.textarea.CursorEnd()
+	m.focused = focusTextarea
+	return m, m.textarea.Focus()
+}
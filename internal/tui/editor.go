@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"prompt-cli/internal/i18n"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorFinishedMsg is sent when the external editor used to compose a
+// prompt exits.
+type editorFinishedMsg struct {
+	path string
+	err  error
+}
+
+// openFinishedMsg is sent when an editor started by /open exits. Temporary
+// files holding code blocks are removed afterwards.
+type openFinishedMsg struct {
+	path      string
+	temporary bool
+	err       error
+}
+
+// codeBlockRegex matches fenced Markdown code blocks and captures the
+// language tag and the body.
+var codeBlockRegex = regexp.MustCompile("(?s)```([\\w+-]*)[^\\n]*\\n(.*?)```")
+
+// codeBlockExtensions maps common fence language tags to file extensions so
+// the editor picks the right syntax highlighting.
+var codeBlockExtensions = map[string]string{
+	"go":         ".go",
+	"python":     ".py",
+	"py":         ".py",
+	"javascript": ".js",
+	"js":         ".js",
+	"typescript": ".ts",
+	"ts":         ".ts",
+	"json":       ".json",
+	"yaml":       ".yaml",
+	"yml":        ".yaml",
+	"bash":       ".sh",
+	"sh":         ".sh",
+	"shell":      ".sh",
+	"rust":       ".rs",
+	"c":          ".c",
+	"cpp":        ".cpp",
+	"java":       ".java",
+	"html":       ".html",
+	"css":        ".css",
+	"sql":        ".sql",
+	"markdown":   ".md",
+	"md":         ".md",
+}
+
+// editorCommand returns the editor to launch. The config value wins, then
+// $VISUAL and $EDITOR, then a platform default. The value may contain
+// arguments, e.g. "code --wait".
+func (m *Model) editorCommand() []string {
+	if m.config != nil {
+		if fields := strings.Fields(m.config.Editor); len(fields) > 0 {
+			return fields
+		}
+	}
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// editorProcess builds the command that opens path in the editor.
+func (m *Model) editorProcess(path string) *exec.Cmd {
+	editor := m.editorCommand()
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
+
+// openEditor writes the current textarea content to a temporary file and
+// suspends the TUI while the user edits it.
+func (m *Model) openEditor() tea.Cmd {
+	f, err := os.CreateTemp("", "promptcli-*.md")
+	if err != nil {
+		return func() tea.Msg { return editorFinishedMsg{err: err} }
+	}
+	if _, err := f.WriteString(m.textarea.Value()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return func() tea.Msg { return editorFinishedMsg{err: err} }
+	}
+	f.Close()
+
+	path := f.Name()
+	return tea.ExecProcess(m.editorProcess(path), func(err error) tea.Msg {
+		return editorFinishedMsg{path: path, err: err}
+	})
+}
+
+// handleEditorFinished reads the edited prompt back into the textarea.
+func (m *Model) handleEditorFinished(msg editorFinishedMsg) (tea.Model, tea.Cmd) {
+	if msg.path != "" {
+		defer os.Remove(msg.path)
+	}
+	if msg.err != nil {
+		m.showError(i18n.T("editor.failed", msg.err))
+		return m, nil
+	}
+
+	content, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.showError(i18n.T("editor.read_failed", err))
+		return m, nil
+	}
+
+	m.textarea.SetValue(strings.TrimRight(string(content), "\n"))
+	m.textarea.CursorEnd()
+	m.focused = focusTextarea
+	return m, m.textarea.Focus()
+}
+
+// openReference implements /open. With no argument it opens the file most
+// recently touched by a tool, or the last code block if no file was touched.
+// A number opens that code block from the last assistant response, and
+// anything else is treated as a path.
+func (m *Model) openReference(arg string) tea.Cmd {
+	if arg == "" {
+		if len(m.touchedFiles) > 0 {
+			return m.openPath(m.touchedFiles[len(m.touchedFiles)-1])
+		}
+		blocks := m.lastCodeBlocks()
+		if len(blocks) == 0 {
+			m.showStatus(i18n.T("open.nothing"))
+			return nil
+		}
+		return m.openCodeBlock(blocks[len(blocks)-1])
+	}
+
+	if n, err := strconv.Atoi(arg); err == nil {
+		blocks := m.lastCodeBlocks()
+		if n < 1 || n > len(blocks) {
+			m.showError(i18n.T("open.out_of_range", len(blocks), n))
+			return nil
+		}
+		return m.openCodeBlock(blocks[n-1])
+	}
+
+	return m.openPath(arg)
+}
+
+// openPath opens a workspace file in the editor.
+func (m *Model) openPath(path string) tea.Cmd {
+	if m.agent.Remote() {
+		m.showError(i18n.T("open.remote", path))
+		return nil
+	}
+	fullPath, err := m.agent.ResolvePath(path)
+	if err != nil {
+		m.showError(i18n.T("open.failed", path, err))
+		return nil
+	}
+	return tea.ExecProcess(m.editorProcess(fullPath), func(err error) tea.Msg {
+		return openFinishedMsg{path: fullPath, err: err}
+	})
+}
+
+// openCodeBlock dumps a code block to a temporary file and opens it.
+func (m *Model) openCodeBlock(block []string) tea.Cmd {
+	lang, body := block[0], block[1]
+	ext, ok := codeBlockExtensions[strings.ToLower(lang)]
+	if !ok {
+		ext = ".txt"
+	}
+	f, err := os.CreateTemp("", "promptcli-block-*"+ext)
+	if err != nil {
+		m.showError(i18n.T("open.temp_failed", err))
+		return nil
+	}
+	_, err = f.WriteString(body)
+	f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		m.showError(i18n.T("open.temp_failed", err))
+		return nil
+	}
+
+	path := f.Name()
+	return tea.ExecProcess(m.editorProcess(path), func(err error) tea.Msg {
+		return openFinishedMsg{path: path, temporary: true, err: err}
+	})
+}
+
+// handleOpenFinished cleans up after /open and refreshes the file list in
+// case the user created or renamed files.
+func (m *Model) handleOpenFinished(msg openFinishedMsg) (tea.Model, tea.Cmd) {
+	if msg.temporary {
+		os.Remove(msg.path)
+	}
+	if msg.err != nil {
+		m.showError(i18n.T("editor.failed", msg.err))
+	}
+	m.updateFileList()
+	m.focused = focusTextarea
+	return m, m.textarea.Focus()
+}
+
+// lastCodeBlocks returns the fenced code blocks of the most recent assistant
+// message that has any, as [language, body] pairs.
+func (m *Model) lastCodeBlocks() [][]string {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Role != "assistant" {
+			continue
+		}
+		matches := codeBlockRegex.FindAllStringSubmatch(m.messages[i].Content, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		blocks := make([][]string, 0, len(matches))
+		for _, match := range matches {
+			blocks = append(blocks, []string{match[1], match[2]})
+		}
+		return blocks
+	}
+	return nil
+}
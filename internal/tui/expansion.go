@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"strconv"
+	"strings"
+)
+
+// expandHistory implements shell-style history expansion: "!!" stands for
+// the previous input and "!N" for the Nth input of the session, as numbered
+// by /history. Text after the reference is appended, so "!! in Go" asks the
+// last question again with a twist. ok is false for input that does not
+// start with a reference, e.g. "!important".
+func (m *Model) expandHistory(input string) (expanded string, ok bool, err error) {
+	if rest, found := strings.CutPrefix(input, "!!"); found {
+		if len(m.prompts) == 0 {
+			return "", true, fmt.Errorf("%s", i18n.T("expand.empty"))
+		}
+		return m.prompts[len(m.prompts)-1] + rest, true, nil
+	}
+	if !strings.HasPrefix(input, "!") {
+		return input, false, nil
+	}
+	digits := len(input[1:]) - len(strings.TrimLeft(input[1:], "0123456789"))
+	if digits == 0 {
+		return input, false, nil
+	}
+	n, _ := strconv.Atoi(input[1 : 1+digits])
+	if n < 1 || n > len(m.prompts) {
+		return "", true, fmt.Errorf("%s", i18n.T("expand.unknown", n, len(m.prompts)))
+	}
+	return m.prompts[n-1] + input[1+digits:], true, nil
+}
+
+// recordPrompt numbers an input for "!N".
+func (m *Model) recordPrompt(input string) {
+	if input != "" {
+		m.prompts = append(m.prompts, input)
+	}
+}
+
+// listPrompts shows the numbered inputs of the session for "!N".
+func (m *Model) listPrompts() {
+	var builder strings.Builder
+	builder.WriteString(i18n.T("expand.title") + "\n\n")
+	for i, prompt := range m.prompts {
+		if line, _, cut := strings.Cut(prompt, "\n"); cut {
+			prompt = line + " …"
+		}
+		builder.WriteString(fmt.Sprintf("%d. `%s`\n", i+1, prompt))
+	}
+	builder.WriteString("\n" + i18n.T("expand.hint") + "\n" + i18n.T("history.usage"))
+	m.showStatus(builder.String())
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/i18n"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// explainContext is how many lines around the target line /explain sends
+// when no function encloses it.
+const explainContext = 20
+
+// explainTargetRegex splits "/explain path:line" into the path and line.
+var explainTargetRegex = regexp.MustCompile(`^(.+):(\d+)$`)
+
+// explainPrompt asks for the explanation; %s names what to explain.
+const explainPrompt = "Explain %s. Start with a one-paragraph summary of what it does and why, then walk through how it works step by step, covering inputs, outputs, side effects, error handling and non-obvious details. Mention how it fits into the rest of the code if the excerpt shows it. Answer directly with the explanation; do not change any files."
+
+// handleExplain implements "/explain <path[:line]>". It sends the file,
+// or with a line the function or type around it, with a prompt asking for
+// an explanation.
+func (m *Model) handleExplain(args string) tea.Cmd {
+	if args == "" {
+		m.showStatus(i18n.T("explain.usage"))
+		return nil
+	}
+	path, line := args, 0
+	if match := explainTargetRegex.FindStringSubmatch(args); match != nil {
+		path = match[1]
+		line, _ = strconv.Atoi(match[2])
+	}
+	content, err := m.agent.ReadWorkspaceFile(path)
+	if err != nil {
+		m.showError(i18n.T("explain.read_error", path, err))
+		return nil
+	}
+
+	subject := fmt.Sprintf("the file %s", path)
+	r := lineRange{}
+	if line > 0 {
+		total := strings.Count(string(content), "\n") + 1
+		if line > total {
+			m.showError(i18n.T("explain.no_line", path, total))
+			return nil
+		}
+		if block, ok := agent.EnclosingBlock(path, content, line); ok {
+			subject = fmt.Sprintf("the %s %s in %s (lines %d-%d)", block.Kind, block.Name, path, block.Start, block.End)
+			r = lineRange{block.Start, block.End}
+		} else {
+			r = lineRange{max(1, line-explainContext), min(total, line+explainContext)}
+			subject = fmt.Sprintf("the code around line %d of %s", line, path)
+		}
+	}
+	// Without the file, e.g. when the context guard drops it, the model
+	// can still read it with its tools.
+	prompt := fmt.Sprintf(explainPrompt, subject)
+	return m.guardedSend(prompt, prompt+mentionBlock(path, string(content), r))
+}
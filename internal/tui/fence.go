@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"path/filepath"
+	"prompt-cli/internal/types"
+	"strings"
+)
+
+// fenceLanguages maps file extensions to the language tag of the code fence
+// their content is inlined in, so glamour highlights it.
+var fenceLanguages = map[string]string{
+	".go":    "go",
+	".mod":   "go",
+	".py":    "python",
+	".js":    "javascript",
+	".mjs":   "javascript",
+	".cjs":   "javascript",
+	".jsx":   "jsx",
+	".ts":    "typescript",
+	".tsx":   "tsx",
+	".json":  "json",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".xml":   "xml",
+	".html":  "html",
+	".htm":   "html",
+	".css":   "css",
+	".scss":  "scss",
+	".md":    "markdown",
+	".sh":    "bash",
+	".bash":  "bash",
+	".zsh":   "zsh",
+	".ps1":   "powershell",
+	".bat":   "batch",
+	".rs":    "rust",
+	".c":     "c",
+	".h":     "c",
+	".cpp":   "cpp",
+	".cc":    "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".java":  "java",
+	".kt":    "kotlin",
+	".swift": "swift",
+	".rb":    "ruby",
+	".php":   "php",
+	".lua":   "lua",
+	".sql":   "sql",
+	".proto": "protobuf",
+	".tf":    "hcl",
+	".ini":   "ini",
+	".diff":  "diff",
+	".patch": "diff",
+}
+
+// fenceFileNames covers files whose name, not extension, gives the language
+// away.
+var fenceFileNames = map[string]string{
+	"makefile":   "makefile",
+	"dockerfile": "dockerfile",
+	"go.sum":     "text",
+}
+
+// fenceLanguage returns the code fence language tag for path, or "" if it
+// is not known.
+func fenceLanguage(path string) string {
+	name := strings.ToLower(filepath.Base(path))
+	if lang, ok := fenceFileNames[name]; ok {
+		return lang
+	}
+	return fenceLanguages[filepath.Ext(name)]
+}
+
+// fence wraps content in a Markdown code block tagged with lang. The fence
+// is longer than any run of backticks in content, so backticks in the file
+// can neither close the block early nor break the surrounding Markdown.
+func fence(content, lang string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	marker := strings.Repeat("`", max(3, longest+1))
+	return marker + lang + "\n" + strings.TrimSuffix(content, "\n") + "\n" + marker
+}
+
+// resultLanguage returns the fence language of the tool message at index:
+// that of the file for read_file results, otherwise "".
+func resultLanguage(messages []types.Message, index int) string {
+	if index == 0 || len(messages[index-1].ToolCalls) == 0 {
+		return ""
+	}
+	call := messages[index-1].ToolCalls[0].Function
+	if call.Name != "read_file" {
+		return ""
+	}
+	path, _ := call.Arguments["path"].(string)
+	return fenceLanguage(path)
+}
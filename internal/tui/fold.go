@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toolOutputSummary describes a folded tool message: the tool that produced
+// it, its size and whether it succeeded.
+func (m *Model) toolOutputSummary(messages []types.Message, index int) string {
+	msg := messages[index]
+	toolName := "tool"
+	if index > 0 && len(messages[index-1].ToolCalls) > 0 {
+		toolName = messages[index-1].ToolCalls[0].Function.Name
+	}
+
+	if msg.Result != nil {
+		return i18n.T("fold.summary", toolName, formatBytes(len(msg.Result.Output)), resultStatus(msg.Result))
+	}
+
+	status := i18n.T("fold.ok")
+	content := msg.Content
+	if strings.HasPrefix(content, agent.CachedMarker) {
+		status = i18n.T("fold.cached")
+		content = strings.TrimPrefix(content, agent.CachedMarker)
+	}
+	if strings.HasPrefix(content, "Error") {
+		status = i18n.T("fold.error")
+	}
+	if agent.Suspicious(content) {
+		status = i18n.T("fold.suspicious")
+	}
+
+	return i18n.T("fold.summary", toolName, formatBytes(len(msg.Content)), status)
+}
+
+// resultStatus describes a structured tool result for the fold summary:
+// the status or error class, timing and whether the output was cut.
+func resultStatus(result *types.ToolResult) string {
+	parts := []string{i18n.T("fold.ok")}
+	switch {
+	case result.Failed():
+		parts[0] = i18n.T("fold.error_class", result.ErrorClass)
+	case agent.Suspicious(result.Output):
+		parts[0] = i18n.T("fold.suspicious")
+	case result.Cached:
+		parts[0] = i18n.T("fold.cached")
+	}
+	parts = append(parts, fmt.Sprintf("%d ms", result.DurationMs))
+	if result.Truncated {
+		parts = append(parts, i18n.T("fold.truncated"))
+	}
+	if strings.Contains(result.Output, "\n"+agent.FormattedMarker) {
+		parts = append(parts, i18n.T("fold.formatted"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// expandedResult renders a structured tool result in full: the status, the
+// error message, the output and stderr, each in its own block. lang tags
+// the output block.
+func expandedResult(result *types.ToolResult, lang string) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("`%s` · %s\n\n", result.Tool, resultStatus(result)))
+	if result.Error != "" {
+		builder.WriteString(fmt.Sprintf("**%s**\n\n", result.Error))
+	}
+	if result.Output != "" {
+		builder.WriteString(fence(result.Output, lang) + "\n\n")
+	}
+	if result.Stderr != "" {
+		builder.WriteString(fmt.Sprintf("%s\n%s\n", i18n.T("fold.stderr"), fence(result.Stderr, "")))
+	}
+	return builder.String()
+}
+
+// isExpanded reports whether the tool message at index is shown in full.
+func (m *Model) isExpanded(index int) bool {
+	return m.expanded[index]
+}
+
+// toggleFold expands or collapses the tool message the user is looking at:
+// the first one whose header is visible, or else the one the viewport is
+// scrolled into.
+func (m *Model) toggleFold() (tea.Model, tea.Cmd) {
+	top := m.viewport.YOffset
+	bottom := top + m.viewport.Height
+	target := -1
+	for i, offset := range m.messageOffsets {
+		if i >= len(m.messages) || m.messages[i].Role != "tool" {
+			continue
+		}
+		if offset >= top && offset < bottom {
+			target = i
+			break
+		}
+		if offset < top {
+			target = i
+		}
+	}
+	if target < 0 {
+		return m, nil
+	}
+
+	m.expanded[target] = !m.expanded[target]
+	offset := m.viewport.YOffset
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.SetYOffset(offset)
+	// Keep the toggled message on screen when collapsing it from below.
+	if target < len(m.messageOffsets) && m.messageOffsets[target] < m.viewport.YOffset {
+		m.viewport.SetYOffset(m.messageOffsets[target])
+	}
+	return m, nil
+}
+
+// formatBytes renders a byte count for the fold summary.
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
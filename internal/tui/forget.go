@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// forgetListSize is how many recent messages "/forget" lists.
+const forgetListSize = 20
+
+var forgottenStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+// handleForget implements "/forget", which lists the recent messages with
+// their numbers, and "/forget <N>...", which leaves the numbered messages
+// out of future requests, or brings them back if they already are. The
+// messages stay in the transcript, greyed out. Message numbers count from
+// the first message after the system prompt.
+func (m *Model) handleForget(args string) {
+	if args == "" {
+		m.showStatus(m.forgetList())
+		return
+	}
+	var changed []string
+	for _, field := range strings.Fields(args) {
+		n, err := strconv.Atoi(strings.TrimPrefix(field, "#"))
+		if err != nil || n < 1 || n >= len(m.messages) {
+			m.showError(i18n.T("forget.usage", len(m.messages)-1))
+			return
+		}
+		excluded := !m.messages[n].Excluded
+		for _, i := range m.turnMessages(n) {
+			m.messages[i].Excluded = excluded
+		}
+		key := "forget.included"
+		if excluded {
+			key = "forget.excluded"
+		}
+		changed = append(changed, i18n.T(key, n))
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.saveSession()
+	m.showStatus(strings.Join(changed, "\n"))
+}
+
+// turnMessages returns the index of message n together with the tool call
+// or tool results that belong to it, which the model only understands as a
+// whole.
+func (m *Model) turnMessages(n int) []int {
+	call := n
+	if m.messages[n].Role == "tool" {
+		for call > 1 && m.messages[call].Role == "tool" {
+			call--
+		}
+	}
+	if len(m.messages[call].ToolCalls) == 0 {
+		return []int{n}
+	}
+	indexes := []int{call}
+	for i := call + 1; i < len(m.messages) && m.messages[i].Role == "tool"; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// forgetList lists the most recent messages with their numbers for
+// "/forget".
+func (m *Model) forgetList() string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T("forget.title") + "\n\n")
+	for i := max(1, len(m.messages)-forgetListSize); i < len(m.messages); i++ {
+		msg := m.messages[i]
+		summary := msg.Content
+		if msg.DisplayContent != "" {
+			summary = msg.DisplayContent
+		}
+		if len(msg.ToolCalls) > 0 {
+			summary = msg.ToolCalls[0].Function.Name
+		}
+		summary, _, _ = strings.Cut(strings.TrimSpace(summary), "\n")
+		if len(summary) > 60 {
+			summary = strings.ToValidUTF8(summary[:60], "") + "…"
+		}
+		marker := ""
+		if msg.Excluded {
+			marker = " " + i18n.T("forget.marker")
+		}
+		fmt.Fprintf(&builder, "- `#%d` %s%s: %s\n", i, msg.Role, marker, summary)
+	}
+	return builder.String()
+}
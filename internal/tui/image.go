@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"prompt-cli/internal/i18n"
+	"runtime"
+)
+
+// clipboardImageCommands lists, per platform, the external commands tried in
+// order to read a PNG image from the system clipboard. Some write the image
+// to outPath themselves, the others print it to stdout.
+func clipboardImageCommands(outPath string) [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{
+			{"pngpaste", outPath},
+			{"osascript",
+				"-e", "set png to (the clipboard as «class PNGf»)",
+				"-e", fmt.Sprintf("set f to open for access POSIX file %q with write permission", outPath),
+				"-e", "write png to f",
+				"-e", "close access f"},
+		}
+	case "windows":
+		script := fmt.Sprintf("Add-Type -AssemblyName System.Windows.Forms; $img = [System.Windows.Forms.Clipboard]::GetImage(); if ($img -eq $null) { exit 1 }; $img.Save('%s', [System.Drawing.Imaging.ImageFormat]::Png)", outPath)
+		return [][]string{{"powershell", "-NoProfile", "-STA", "-Command", script}}
+	default:
+		var cmds [][]string
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmds = append(cmds, []string{"wl-paste", "--no-newline", "--type", "image/png"})
+		}
+		return append(cmds, []string{"xclip", "-selection", "clipboard", "-t", "image/png", "-o"})
+	}
+}
+
+// errNoNativeClipboard is returned by clipboardDIB on platforms without a
+// native clipboard reader.
+var errNoNativeClipboard = errors.New("no native clipboard reader")
+
+// captureClipboardImage saves the clipboard image to a temporary PNG file and
+// returns its path.
+func captureClipboardImage() (string, error) {
+	f, err := os.CreateTemp("", "promptcli-clip-*.png")
+	if err != nil {
+		return "", err
+	}
+	outPath := f.Name()
+	f.Close()
+
+	var lastErr error
+	if data, err := clipboardDIB(); err == nil {
+		if err = writeDIBAsPNG(data, outPath); err == nil {
+			return outPath, nil
+		}
+		lastErr = err
+	} else if !errors.Is(err, errNoNativeClipboard) {
+		lastErr = err
+	}
+	for _, args := range clipboardImageCommands(outPath) {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			lastErr = fmt.Errorf("%s not found", args[0])
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			lastErr = fmt.Errorf("%s: %v %s", args[0], err, stderr.String())
+			continue
+		}
+		// Commands that print the image to stdout are written out here.
+		if stdout.Len() > 0 {
+			if err := os.WriteFile(outPath, stdout.Bytes(), 0644); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+		if info, err := os.Stat(outPath); err == nil && info.Size() > 0 {
+			return outPath, nil
+		}
+		lastErr = fmt.Errorf("%s returned no image data", args[0])
+	}
+
+	os.Remove(outPath)
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no clipboard backend available")
+	}
+	return "", lastErr
+}
+
+// handlePasteImage implements /paste-image. The captured image is attached
+// to the next prompt that is sent.
+func (m *Model) handlePasteImage() {
+	path, err := captureClipboardImage()
+	if err != nil {
+		m.showError(i18n.T("image.failed", err))
+		return
+	}
+	m.pendingImages = append(m.pendingImages, path)
+	m.showStatus(i18n.T("image.attached", filepath.ToSlash(path)))
+	if !m.capabilities.HasVision() {
+		m.showError(i18n.T("model.no_vision", m.modelName))
+	}
+}
+
+// encodeImages base64-encodes the image files for the Ollama "images" field.
+func encodeImages(paths []string) ([]string, error) {
+	var images []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading image %s: %w", path, err)
+		}
+		images = append(images, base64.StdEncoding.EncodeToString(data))
+	}
+	return images, nil
+}
+
+// writeDIBAsPNG converts a device-independent bitmap from the Windows
+// clipboard to a PNG file.
+func writeDIBAsPNG(data []byte, outPath string) error {
+	img, err := decodeDIB(data)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+// decodeDIB decodes a device-independent bitmap: a BITMAPINFOHEADER (or a
+// larger V4/V5 header) followed by the pixels, bottom-up unless the height
+// is negative. Uncompressed 24 and 32 bit images are supported, which is
+// what screenshots and browsers put on the clipboard.
+func decodeDIB(data []byte) (image.Image, error) {
+	if len(data) < 40 {
+		return nil, errors.New("bitmap header too short")
+	}
+	headerSize := int(binary.LittleEndian.Uint32(data[0:]))
+	width := int(int32(binary.LittleEndian.Uint32(data[4:])))
+	height := int(int32(binary.LittleEndian.Uint32(data[8:])))
+	bitCount := int(binary.LittleEndian.Uint16(data[14:]))
+	compression := binary.LittleEndian.Uint32(data[16:])
+	colorsUsed := int(binary.LittleEndian.Uint32(data[32:]))
+
+	topDown := height < 0
+	if topDown {
+		height = -height
+	}
+	if width <= 0 || height == 0 || (bitCount != 24 && bitCount != 32) {
+		return nil, fmt.Errorf("unsupported bitmap: %dx%d, %d bits per pixel", width, height, bitCount)
+	}
+	offset := headerSize + colorsUsed*4
+	switch compression {
+	case 0: // BI_RGB
+	case 3: // BI_BITFIELDS; the masks follow a plain header, assumed to be BGRA.
+		if headerSize == 40 {
+			offset += 12
+		}
+	default:
+		return nil, fmt.Errorf("unsupported bitmap compression %d", compression)
+	}
+	bytesPerPixel := bitCount / 8
+	stride := (width*bitCount + 31) / 32 * 4
+	if len(data) < offset+stride*height {
+		return nil, errors.New("bitmap data truncated")
+	}
+
+	// Many programs leave the alpha channel of 32 bit bitmaps at zero.
+	hasAlpha := false
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		row := height - 1 - y
+		if topDown {
+			row = y
+		}
+		for x := 0; x < width; x++ {
+			p := offset + row*stride + x*bytesPerPixel
+			alpha := uint8(255)
+			if bytesPerPixel == 4 {
+				alpha = data[p+3]
+				hasAlpha = hasAlpha || alpha != 0
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: data[p+2], G: data[p+1], B: data[p], A: alpha})
+		}
+	}
+	if bytesPerPixel == 4 && !hasAlpha {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 255
+		}
+	}
+	return img, nil
+}
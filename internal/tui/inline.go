@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Inline mode is for tmux and terminals whose own scrollback is preferred
+// over the viewport. The program runs without the alternate screen or mouse
+// capture, each finished message is printed once above the input, where it
+// stays in the terminal's buffer, and only the reply being streamed is
+// drawn live.
+
+// inlineLiveLines caps the streamed reply shown below the printed
+// transcript; it is printed in full once finished.
+const inlineLiveLines = 12
+
+// SetInline turns inline mode on. It must be called before the program
+// starts.
+func (m *Model) SetInline(on bool) {
+	m.inline = on
+}
+
+// printFinished prints the messages completed since the last call, rendered
+// as in the viewport.
+func (m *Model) printFinished() tea.Cmd {
+	if m.printedMessages > len(m.messages) {
+		m.printedMessages = len(m.messages) // The conversation was reset.
+	}
+	end := len(m.messages)
+	if m.streaming && end > 0 && m.messages[end-1].Role == "assistant" {
+		end-- // Still being written.
+	}
+	var blocks []string
+	for i := m.printedMessages; i < end; i++ {
+		if text := strings.TrimRight(m.renderCache.block(m, m.messages, i).text, "\n"); text != "" {
+			blocks = append(blocks, text)
+		}
+	}
+	if end > m.printedMessages {
+		m.printedMessages = end
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	return tea.Println(strings.Join(blocks, "\n"))
+}
+
+// transcriptView is the part of the screen above the input: the viewport,
+// or in inline mode the tail of the reply being streamed.
+func (m *Model) transcriptView() string {
+	if !m.inline {
+		return m.viewport.View()
+	}
+	end := len(m.messages)
+	if !m.streaming || end == 0 || m.messages[end-1].Role != "assistant" {
+		return ""
+	}
+	text := strings.TrimRight(m.renderCache.block(m, m.messages, end-1).text, "\n")
+	lines := strings.Split(text, "\n")
+	return strings.Join(lines[max(0, len(lines)-inlineLiveLines):], "\n")
+}
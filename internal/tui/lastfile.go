@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"regexp"
+	"strings"
+)
+
+// lastToken matches "@last" and whatever follows it up to the next space.
+var lastToken = regexp.MustCompile(`@last(\S*)`)
+
+// lastFile returns the file the agent created or changed most recently,
+// falling back to the last file any tool referenced.
+func (m *Model) lastFile() string {
+	if m.lastWritten != "" {
+		return m.lastWritten
+	}
+	if len(m.touchedFiles) > 0 {
+		return m.touchedFiles[len(m.touchedFiles)-1]
+	}
+	return ""
+}
+
+// expandLastFile replaces "@last" in a prompt with a mention of the last
+// file, so the usual @ mention handling injects its content.
+func (m *Model) expandLastFile(input string) string {
+	path := m.lastFile()
+	if path == "" {
+		return input
+	}
+	return lastToken.ReplaceAllStringFunc(input, func(match string) string {
+		// Trailing punctuation ends the sentence; anything else means a
+		// file whose name starts with "last".
+		suffix := strings.TrimPrefix(match, "@last")
+		if strings.Trim(suffix, ".,;:!?)") != "" {
+			return match
+		}
+		return "@" + path + " " + suffix
+	})
+}
+
+// insertLastFile implements Ctrl+L: it inserts a mention of the last file at
+// the cursor.
+func (m *Model) insertLastFile() {
+	path := m.lastFile()
+	if path == "" {
+		m.showStatus(i18n.T("last.none"))
+		return
+	}
+	m.textarea.InsertString("@" + path + " ")
+}
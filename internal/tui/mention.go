@@ -0,0 +1,257 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// previewRows is how many lines of the file the mention preview shows at
+// once.
+const previewRows = 15
+
+var (
+	// mentionRegex matches an @ mention in a prompt.
+	mentionRegex = regexp.MustCompile(`@(\S+)`)
+	// mentionRangeRegex splits a mention into its path and a line range.
+	mentionRangeRegex = regexp.MustCompile(`^(.+):(\d+)-(\d+)$`)
+	// rangeSuffixRegex matches a line range, or the start of one, typed
+	// after the path of a mention.
+	rangeSuffixRegex = regexp.MustCompile(`:[\d-]*$`)
+	// rangeInputRegex matches what the range field of the preview accepts.
+	rangeInputRegex = regexp.MustCompile(`^\s*(\d+)\s*-\s*(\d+)\s*$`)
+
+	previewLineNumberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	previewSelectedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+)
+
+// lineRange is a 1-based, inclusive range of lines. The zero value stands
+// for the whole file.
+type lineRange struct {
+	start, end int
+}
+
+func (r lineRange) whole() bool {
+	return r.start == 0
+}
+
+// contains reports whether line (1-based) is in the range.
+func (r lineRange) contains(line int) bool {
+	return r.whole() || line >= r.start && line <= r.end
+}
+
+// parseMention splits "path" or "path:start-end" into the path and range.
+func parseMention(mention string) (string, lineRange) {
+	match := mentionRangeRegex.FindStringSubmatch(mention)
+	if match == nil {
+		return mention, lineRange{}
+	}
+	start, _ := strconv.Atoi(match[2])
+	end, _ := strconv.Atoi(match[3])
+	if start < 1 || end < start {
+		return mention, lineRange{}
+	}
+	return match[1], lineRange{start, end}
+}
+
+// clamp limits r to a file of total lines.
+func (r lineRange) clamp(total int) lineRange {
+	if r.whole() {
+		return r
+	}
+	return lineRange{min(r.start, total), min(r.end, total)}
+}
+
+// cutLines returns the lines of content in r.
+func cutLines(content string, r lineRange) string {
+	if r.whole() {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	r = r.clamp(len(lines))
+	return strings.Join(lines[r.start-1:r.end], "\n")
+}
+
+// mentionBlock is what an @ mention of r in path is replaced with when the
+// prompt is sent. A range is noted in the header so the model knows it
+// sees only part of the file.
+func mentionBlock(path, content string, r lineRange) string {
+	header := path
+	if !r.whole() {
+		total := strings.Count(content, "\n") + 1
+		r = r.clamp(total)
+		header = fmt.Sprintf("%s (lines %d-%d of %d)", path, r.start, r.end, total)
+		content = cutLines(content, r)
+	}
+	return fmt.Sprintf("\n\n---\nFile: %s\n%s\n", header, fence(content, fenceLanguage(path)))
+}
+
+// expandMentions replaces the @ mentions in input with the content of the
+// files. "@path:start-end" inlines only those lines. Mentions of files that
+// cannot be read are left as they are.
+func (m *Model) expandMentions(input string) string {
+	for _, match := range mentionRegex.FindAllStringSubmatch(input, -1) {
+		path, r := parseMention(match[1])
+		content, err := m.agent.ReadWorkspaceFile(path)
+		if err != nil {
+			continue
+		}
+		input = strings.Replace(input, match[0], mentionBlock(path, string(content), r), 1)
+	}
+	return input
+}
+
+// estimateTokens approximates the tokens in text with the heuristic of 3
+// words ~= 4 tokens, or 4 bytes per token for dense text such as code,
+// whichever is more.
+func estimateTokens(text string) int {
+	return max(len(strings.Fields(text))*4/3, len(text)/4)
+}
+
+// mentionPreview shows the file of an @ mention as it will be sent and lets
+// the user narrow it down to a line range.
+type mentionPreview struct {
+	mention string // The mention as typed, without the "@".
+	path    string
+	lines   []string
+	offset  int // First line in view, 0-based.
+	input   textinput.Model
+}
+
+// openMentionPreview implements Ctrl+O: it previews the last @ mention in
+// the input.
+func (m *Model) openMentionPreview() tea.Cmd {
+	if m.fileSearchActive && m.fileSearchResult != "" {
+		if _, err := m.agent.ReadWorkspaceFile(m.fileSearchTerm); err != nil {
+			m.handleTabKey()
+		}
+	}
+	if value := m.textarea.Value(); lastToken.MatchString(value) {
+		m.textarea.SetValue(m.expandLastFile(value))
+	}
+	matches := mentionRegex.FindAllStringSubmatch(m.textarea.Value(), -1)
+	if len(matches) == 0 {
+		m.showStatus(i18n.T("mention.none"))
+		return nil
+	}
+	mention := matches[len(matches)-1][1]
+	path, r := parseMention(mention)
+	content, err := m.agent.ReadWorkspaceFile(path)
+	if err != nil {
+		m.showError(i18n.T("mention.read_error", path, err))
+		return nil
+	}
+
+	input := textinput.New()
+	input.Prompt = i18n.T("mention.range")
+	input.Placeholder = "1-" + strconv.Itoa(strings.Count(string(content), "\n")+1)
+	input.CharLimit = 20
+	if !r.whole() {
+		input.SetValue(fmt.Sprintf("%d-%d", r.start, r.end))
+	}
+	input.Focus()
+	m.preview = &mentionPreview{mention: mention, path: path, lines: strings.Split(string(content), "\n"), input: input}
+	m.preview.scrollTo(r)
+	m.textarea.Blur()
+	return textinput.Blink
+}
+
+// selection returns the range typed into the preview, which is the whole
+// file while the field is empty or invalid.
+func (p *mentionPreview) selection() lineRange {
+	match := rangeInputRegex.FindStringSubmatch(p.input.Value())
+	if match == nil {
+		return lineRange{}
+	}
+	start, _ := strconv.Atoi(match[1])
+	end, _ := strconv.Atoi(match[2])
+	if start < 1 || end < start || start > len(p.lines) {
+		return lineRange{}
+	}
+	return lineRange{start, min(end, len(p.lines))}
+}
+
+// scrollTo brings the start of r into view.
+func (p *mentionPreview) scrollTo(r lineRange) {
+	if !r.whole() {
+		p.scroll(r.start - 1 - p.offset)
+	}
+}
+
+func (p *mentionPreview) scroll(delta int) {
+	p.offset = max(0, min(p.offset+delta, len(p.lines)-previewRows))
+}
+
+// handlePreviewKey scrolls the preview or edits the range. Enter puts the
+// range into the mention and Esc closes the preview without changes.
+func (m *Model) handlePreviewKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.preview
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "up":
+		p.scroll(-1)
+	case "down":
+		p.scroll(1)
+	case "pgup":
+		p.scroll(-previewRows)
+	case "pgdown":
+		p.scroll(previewRows)
+	case "enter":
+		mention := p.path
+		if r := p.selection(); !r.whole() {
+			mention = fmt.Sprintf("%s:%d-%d", p.path, r.start, r.end)
+		}
+		value := m.textarea.Value()
+		if i := strings.LastIndex(value, "@"+p.mention); i >= 0 {
+			m.textarea.SetValue(value[:i] + "@" + mention + value[i+1+len(p.mention):])
+			m.textarea.CursorEnd()
+		}
+		m.preview = nil
+		return m, m.textarea.Focus()
+	case "esc", "ctrl+c":
+		m.preview = nil
+		return m, m.textarea.Focus()
+	default:
+		before := p.selection()
+		p.input, cmd = p.input.Update(msg)
+		if r := p.selection(); r != before {
+			p.scrollTo(r)
+		}
+	}
+	return m, cmd
+}
+
+// view renders the size of what will be sent, the visible lines and the
+// range field.
+func (p *mentionPreview) view(width int) string {
+	r := p.selection()
+	sent := mentionBlock(p.path, strings.Join(p.lines, "\n"), r)
+	lineCount := len(p.lines)
+	if !r.whole() {
+		lineCount = r.end - r.start + 1
+	}
+
+	var builder strings.Builder
+	builder.WriteString(i18n.T("mention.title", p.path, lineCount, formatBytes(len(sent)), estimateTokens(sent)) + "\n\n")
+	digits := len(strconv.Itoa(len(p.lines)))
+	end := min(len(p.lines), p.offset+previewRows)
+	for i := p.offset; i < end; i++ {
+		line := strings.ReplaceAll(p.lines[i], "\t", "    ")
+		if !r.whole() && r.contains(i+1) {
+			line = previewSelectedStyle.Render(line)
+		}
+		line = previewLineNumberStyle.Render(fmt.Sprintf("%*d ", digits, i+1)) + line
+		builder.WriteString(truncateLine(line, width) + "\n")
+	}
+	if len(p.lines) > previewRows {
+		builder.WriteString(previewLineNumberStyle.Render(fmt.Sprintf("%d-%d/%d", p.offset+1, end, len(p.lines))) + "\n")
+	}
+	builder.WriteString("\n" + p.input.View() + "\n\n" + i18n.T("mention.keys"))
+	return builder.String()
+}
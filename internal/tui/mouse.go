@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ansiStyleRegex matches the escape sequences that color rendered text.
+var ansiStyleRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// inputLine is one line of the input as the textarea wraps it: a part of
+// line row of the value, from rune start on.
+type inputLine struct {
+	row, start, width int
+	last              bool // The last part of its row.
+}
+
+// text returns the runes of the line, given the lines of the value.
+func (l inputLine) text(values []string) []rune {
+	runes := []rune(values[l.row])
+	return runes[min(l.start, len(runes)):min(l.start+l.width, len(runes))]
+}
+
+// handleMouse focuses the transcript or the input when it is clicked and
+// places the cursor where the input was clicked. Everything else, like the
+// wheel, scrolls the transcript.
+func (m *Model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft || !m.clickable() {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return cmd
+	}
+	top := m.viewport.Height
+	for _, panel := range m.panels() {
+		top += lipgloss.Height(panel)
+	}
+	switch {
+	case msg.Y < m.viewport.Height:
+		m.focused = focusViewport
+		m.textarea.Blur()
+	case msg.Y >= top && msg.Y < top+lipgloss.Height(m.textarea.View()):
+		m.focused = focusTextarea
+		cmd := m.textarea.Focus()
+		// The input has a border of one cell on each side.
+		m.placeCursor(msg.X-1, msg.Y-top-1)
+		return cmd
+	}
+	return nil
+}
+
+// clickable reports whether the screen shows the transcript, the panels and
+// the input; dialogs and the other modes are left to the keyboard.
+func (m *Model) clickable() bool {
+	return !m.inline && !m.accessible && !m.ctrlCpressed && m.picker == nil && m.preview == nil &&
+		m.artifactList == nil && m.changeset == nil && !m.stalled && !m.reviewingBatch &&
+		m.guard == nil && m.permissionRequest == nil
+}
+
+// placeCursor moves the input's cursor to column x of visible line y.
+// A click past the end of a line lands at its end, and one below the text
+// at the end of the input.
+func (m *Model) placeCursor(x, y int) {
+	if m.textarea.Value() == "" {
+		return
+	}
+	lines, cursor := m.inputLines()
+	y = min(max(y, 0), m.textarea.Height()-1)
+	target := min(m.inputScroll(lines, cursor)+y, len(lines)-1)
+	line := lines[target]
+
+	for m.textarea.Line() > 0 {
+		m.textarea.CursorUp()
+	}
+	m.textarea.SetCursor(0)
+	for i := 0; i < target; i++ {
+		m.textarea.CursorDown()
+	}
+
+	runes := line.text(strings.Split(m.textarea.Value(), "\n"))
+	col, width := 0, 0
+	for col < len(runes) {
+		width += lipgloss.Width(string(runes[col]))
+		if width > max(x, 0) {
+			break
+		}
+		col++
+	}
+	// The end of a wrapped part is the start of the next one.
+	if !line.last {
+		col = min(col, line.width-1)
+	}
+	m.textarea.SetCursor(line.start + col)
+}
+
+// inputLines lists the lines of the input as they are shown and the index
+// of the one with the cursor. It walks a copy of the textarea, so the
+// input's own cursor stays where it is.
+func (m *Model) inputLines() (lines []inputLine, cursor int) {
+	row, start := m.textarea.Line(), m.textarea.LineInfo().StartColumn
+	ta := m.textarea
+	for ta.Line() > 0 {
+		ta.CursorUp()
+	}
+	ta.SetCursor(0)
+	for {
+		info := ta.LineInfo()
+		line := inputLine{row: ta.Line(), start: info.StartColumn, width: info.Width, last: info.RowOffset >= info.Height-1}
+		if line.row == row && line.start == start {
+			cursor = len(lines)
+		}
+		lines = append(lines, line)
+		if line.last && line.row >= ta.LineCount()-1 {
+			return lines, cursor
+		}
+		ta.CursorDown()
+	}
+}
+
+// inputScroll finds how far the input is scrolled, which the textarea does
+// not tell: the position whose lines match what the input shows, preferring
+// those that keep the cursor in view.
+func (m *Model) inputScroll(lines []inputLine, cursor int) int {
+	height := m.textarea.Height()
+	first := max(0, cursor-height+1)
+	shown := strings.Split(ansiStyleRegex.ReplaceAllString(m.textarea.View(), ""), "\n")
+	if len(shown) < height+2 {
+		return first
+	}
+	shown = shown[1 : height+1] // Without the border.
+	values := strings.Split(m.textarea.Value(), "\n")
+
+	found := -1
+	for offset := range lines {
+		matches := true
+		for i, text := range shown {
+			want := ""
+			if offset+i < len(lines) {
+				want = string(lines[offset+i].text(values))
+			}
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "│"), "│")
+			if strings.TrimRight(text, " ") != strings.TrimRight(want, " ") {
+				matches = false
+				break
+			}
+		}
+		if matches && offset >= first && offset <= cursor {
+			return offset
+		}
+		if matches && found < 0 {
+			found = offset
+		}
+	}
+	if found < 0 {
+		return first
+	}
+	return found
+}
@@ -0,0 +1,33 @@
+package tui
+
+// jumpViewport handles the transcript navigation keys while the viewport is
+// focused: "[" and "]" move to the previous and next message, "g" and "G" to
+// the top and bottom. It reports whether the key was handled.
+func (m *Model) jumpViewport(key string) bool {
+	switch key {
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "[":
+		target := 0
+		for _, offset := range m.messageOffsets {
+			if offset >= m.viewport.YOffset {
+				break
+			}
+			target = offset
+		}
+		m.viewport.SetYOffset(target)
+	case "]":
+		for _, offset := range m.messageOffsets {
+			if offset > m.viewport.YOffset {
+				m.viewport.SetYOffset(offset)
+				return true
+			}
+		}
+		m.viewport.GotoBottom()
+	default:
+		return false
+	}
+	return true
+}
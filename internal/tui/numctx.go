@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"strconv"
+	"strings"
+)
+
+// SetModelMaxContext records the longest context the model supports, as
+// read from its metadata. A configured context_length above it is lowered
+// to the maximum because Ollama cannot use more anyway.
+func (m *Model) SetModelMaxContext(max int64) {
+	m.modelMaxContext = max
+	if max > 0 && m.modelContextSize > max {
+		m.showStatus(i18n.T("ctx.clamped", m.modelContextSize, m.modelName, max))
+		m.modelContextSize = max
+	}
+}
+
+// contextSize returns the num_ctx sent with the next request.
+func (m *Model) contextSize() int64 {
+	return m.requestOptions().NumCtx
+}
+
+// handleContextSize implements "/ctx", "/ctx <N>" and "/ctx reset". The
+// override applies to the current session only and is saved with it, so
+// e.g. a 128k model can run at 16k to fit into VRAM.
+func (m *Model) handleContextSize(args string) {
+	switch args {
+	case "":
+		m.showStatus(m.contextStatus())
+		return
+	case "reset":
+		m.numCtxOverride = 0
+		m.session.NumCtx = 0
+		m.showStatus(m.contextStatus())
+		return
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSuffix(strings.ToLower(args), "k"), 10, 64)
+	if err != nil || n <= 0 {
+		m.showError(i18n.T("ctx.usage"))
+		return
+	}
+	if strings.HasSuffix(strings.ToLower(args), "k") {
+		n *= 1024
+	}
+	if m.modelMaxContext > 0 && n > m.modelMaxContext {
+		m.showError(i18n.T("ctx.too_large", n, m.modelName, m.modelMaxContext))
+		return
+	}
+	m.numCtxOverride = n
+	m.session.NumCtx = n
+	m.showStatus(m.contextStatus())
+}
+
+// contextStatus describes the effective context size and where it comes
+// from.
+func (m *Model) contextStatus() string {
+	status := i18n.T("ctx.status", m.contextSize())
+	if m.numCtxOverride > 0 {
+		status += " " + i18n.T("ctx.session")
+	}
+	if m.modelMaxContext > 0 {
+		status += " " + i18n.T("ctx.max", m.modelMaxContext)
+	}
+	return status
+}
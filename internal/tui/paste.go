@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pasteFenceBytes is the paste size above which text is always fenced, even
+// if it looks like prose, so it reaches the model verbatim.
+const pasteFenceBytes = 2000
+
+// languageHints maps fence language tags to patterns that give a language
+// away. They are tried in order, so more specific languages come first.
+var languageHints = []struct {
+	lang    string
+	pattern *regexp.Regexp
+}{
+	{"go", regexp.MustCompile(`(?m)^package \w+$|^func (\(\w+ \*?\w+\) )?\w+\(|:= `)},
+	{"rust", regexp.MustCompile(`(?m)^\s*(pub )?fn \w+|let mut |^use \w+::|impl \w+`)},
+	{"python", regexp.MustCompile(`(?m)^\s*def \w+\(.*\):$|^\s*(from \S+ )?import \w+$|^\s*class \w+.*:$|__name__`)},
+	{"php", regexp.MustCompile(`<\?php`)},
+	{"java", regexp.MustCompile(`public (static )?(class|void)|System\.out\.`)},
+	{"cpp", regexp.MustCompile(`(?m)^#include\s*[<"]|std::`)},
+	{"typescript", regexp.MustCompile(`(?m)^\s*(export )?(interface|type) \w+ (=|\{)|: (string|number|boolean)\b`)},
+	{"javascript", regexp.MustCompile(`(?m)\bfunction\s*\w*\(|^\s*(const|let|var) \w+ = |=> |console\.log|require\(`)},
+	{"html", regexp.MustCompile(`(?i)<(!doctype|html|div|body|span|head)\b`)},
+	{"sql", regexp.MustCompile(`(?i)\b(select .+ from|insert into|create table|update \w+ set)\b`)},
+	{"bash", regexp.MustCompile(`(?m)^#!/(usr/)?bin/(env )?(ba)?sh|^\s*\$ \w|^\s*(sudo|apt|cd|echo|export) `)},
+	{"yaml", regexp.MustCompile(`(?m)^\w[\w-]*:\s*$|^\s+- \w[\w-]*:`)},
+}
+
+// codeLineRegex matches lines that look like code rather than prose:
+// indented, or ending in typical statement or block punctuation.
+var codeLineRegex = regexp.MustCompile(`^(\t| {2,})\S|[;{}()\[\]:,]\s*$|^\s*(//|#|/\*|\*)`)
+
+// handlePaste inserts pasted text into the prompt. Large pastes and code are
+// wrapped in a fenced block with a guessed language so they are not mangled
+// by Markdown, and the prompt switches to multiline mode so a stray newline
+// cannot send it early.
+func (m *Model) handlePaste(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	text := strings.ReplaceAll(string(msg.Runes), "\r\n", "\n")
+	lang, isCode := guessLanguage(text)
+	multiline := strings.Contains(strings.TrimSpace(text), "\n")
+	if strings.Contains(text, "```") || !multiline && len(text) <= pasteFenceBytes {
+		m.textarea.InsertString(text)
+		return m, nil
+	}
+	if isCode || len(text) > pasteFenceBytes {
+		text = "```" + lang + "\n" + strings.Trim(text, "\n") + "\n```\n"
+		// The fence has to start on its own line.
+		if value := m.textarea.Value(); value != "" && !strings.HasSuffix(value, "\n") {
+			text = "\n" + text
+		}
+	}
+	m.textarea.InsertString(text)
+	m.setMultiline(true)
+	return m, nil
+}
+
+// setMultiline switches the prompt between single-line mode, where Enter
+// sends, and multiline mode, where Enter adds a line and Alt+Enter sends.
+func (m *Model) setMultiline(on bool) {
+	m.multiline = on
+	m.textarea.KeyMap.InsertNewline.SetEnabled(on)
+}
+
+// guessLanguage returns the fence language tag for pasted text and whether
+// the text looks like code at all.
+func guessLanguage(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return "json", true
+	}
+	for _, hint := range languageHints {
+		if hint.pattern.MatchString(text) {
+			return hint.lang, true
+		}
+	}
+
+	lines := strings.Split(trimmed, "\n")
+	codeLines := 0
+	for _, line := range lines {
+		if codeLineRegex.MatchString(line) {
+			codeLines++
+		}
+	}
+	return "", len(lines) >= 3 && codeLines*2 >= len(lines)
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/persona"
+	"prompt-cli/internal/types"
+	"strings"
+)
+
+// applyPersona activates a persona (or clears it when name is "") by
+// rebuilding the system prompt.
+func (m *Model) applyPersona(name string) {
+	m.personaName = name
+	m.rebuildSystemPrompt()
+}
+
+// rebuildSystemPrompt sets the system message from the prompt of the current
+// mode (agent or chat) and the active persona.
+func (m *Model) rebuildSystemPrompt() {
+	prompt := m.baseSystemPrompt
+	if m.chatMode {
+		prompt = m.config.ChatPrompt
+	} else if scratch := m.agent.ScratchSummary(); scratch != "" {
+		prompt += "\n\n" + scratch
+	}
+	if p, ok := m.personas[m.personaName]; ok {
+		prompt += "\n\n" + p.PromptSection(m.personaName)
+	}
+	if m.config.RespondLanguage != "" {
+		prompt += "\n\n" + fmt.Sprintf(respondLanguageRule, m.config.RespondLanguage)
+	}
+	if len(m.messages) > 0 && m.messages[0].Role == "system" {
+		m.messages[0].Content = prompt
+	}
+}
+
+// requestOptions returns the model options for the next request.
+func (m *Model) requestOptions() types.Options {
+	options := types.Options{NumCtx: m.modelContextSize}
+	if p, ok := m.personas[m.personaName]; ok {
+		options = options.Merge(p.Options)
+	}
+	options = options.Merge(m.presetOptions())
+	if m.numCtxOverride > 0 {
+		options.NumCtx = m.numCtxOverride
+	}
+	return options
+}
+
+// handlePersona implements "/persona", "/persona <name>" and "/persona off".
+// The choice is remembered for the current workspace.
+func (m *Model) handlePersona(args string) {
+	switch args {
+	case "":
+		var builder strings.Builder
+		builder.WriteString(i18n.T("persona.title") + "\n\n")
+		for _, name := range persona.Names(m.personas) {
+			marker := ""
+			if name == m.personaName {
+				marker = " " + i18n.T("persona.active")
+			}
+			builder.WriteString(fmt.Sprintf("- **%s**%s – %s\n", name, marker, m.personas[name].Description))
+		}
+		builder.WriteString("\n" + i18n.T("persona.hint"))
+		m.showStatus(builder.String())
+		return
+	case "off":
+		args = ""
+	default:
+		if _, ok := m.personas[args]; !ok {
+			m.showError(i18n.T("persona.unknown", args, strings.Join(persona.Names(m.personas), ", ")))
+			return
+		}
+	}
+
+	m.applyPersona(args)
+	if err := persona.SaveSelected(args); err != nil {
+		m.logger.Log(fmt.Sprintf("Error saving persona: %v", err))
+	}
+	if args == "" {
+		m.showStatus(i18n.T("persona.cleared"))
+	} else {
+		m.showStatus(i18n.T("persona.set", args))
+	}
+}
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/ollama"
+	"prompt-cli/internal/types"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pickerRows is how many models the picker shows at once.
+const pickerRows = 12
+
+var (
+	pickerCursorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true)
+	pickerDetailsStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+// modelPicker is a filterable list of the models on the Ollama server. It
+// is used full screen at startup (see PickModel) and inside the chat by
+// /model.
+type modelPicker struct {
+	models       []types.Model
+	capabilities map[string]ollama.Capabilities
+	current      string // Model in use, marked in the list.
+	filter       textinput.Model
+	matches      []int // Indexes into models that match the filter.
+	cursor       int   // Position in matches.
+}
+
+// newModelPicker creates a picker with the cursor on the current model.
+func newModelPicker(models []types.Model, capabilities map[string]ollama.Capabilities, current string) *modelPicker {
+	filter := textinput.New()
+	filter.Placeholder = i18n.T("picker.filter")
+	filter.Prompt = "/ "
+	filter.Focus()
+
+	p := &modelPicker{models: models, capabilities: capabilities, current: current, filter: filter}
+	p.refilter()
+	for i, index := range p.matches {
+		if models[index].Name == current {
+			p.cursor = i
+		}
+	}
+	return p
+}
+
+// refilter keeps the models whose name contains every word of the filter.
+func (p *modelPicker) refilter() {
+	words := strings.Fields(strings.ToLower(p.filter.Value()))
+	p.matches = p.matches[:0]
+	for i, model := range p.models {
+		name := strings.ToLower(model.Name)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(name, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			p.matches = append(p.matches, i)
+		}
+	}
+	if p.cursor >= len(p.matches) {
+		p.cursor = max(0, len(p.matches)-1)
+	}
+}
+
+// handleKey moves the cursor or edits the filter. It returns the chosen
+// model on Enter and done on Enter or Esc.
+func (p *modelPicker) handleKey(msg tea.KeyMsg) (choice string, done bool, cmd tea.Cmd) {
+	switch msg.String() {
+	case "up", "ctrl+p":
+		p.cursor = max(0, p.cursor-1)
+	case "down", "ctrl+n":
+		p.cursor = min(len(p.matches)-1, p.cursor+1)
+	case "pgup":
+		p.cursor = max(0, p.cursor-pickerRows)
+	case "pgdown":
+		p.cursor = max(0, min(len(p.matches)-1, p.cursor+pickerRows))
+	case "enter":
+		if len(p.matches) == 0 {
+			return "", false, nil
+		}
+		return p.models[p.matches[p.cursor]].Name, true, nil
+	case "esc", "ctrl+c":
+		return "", true, nil
+	default:
+		p.filter, cmd = p.filter.Update(msg)
+		p.refilter()
+	}
+	return "", false, cmd
+}
+
+// view renders the filter and the visible part of the list.
+func (p *modelPicker) view() string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T("picker.title") + "\n\n")
+	builder.WriteString(p.filter.View() + "\n\n")
+	if len(p.matches) == 0 {
+		builder.WriteString(i18n.T("picker.empty") + "\n")
+	}
+
+	start := max(0, min(p.cursor-pickerRows/2, len(p.matches)-pickerRows))
+	end := min(len(p.matches), start+pickerRows)
+	width := 0
+	for _, index := range p.matches[start:end] {
+		width = max(width, len(p.models[index].Name))
+	}
+	for i := start; i < end; i++ {
+		model := p.models[p.matches[i]]
+		marker := "  "
+		if model.Name == p.current {
+			marker = "* "
+		}
+		line := fmt.Sprintf("%s%-*s  %s", marker, width, model.Name, pickerDetailsStyle.Render(p.details(model)))
+		if i == p.cursor {
+			line = pickerCursorStyle.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		builder.WriteString(line + "\n")
+	}
+	if len(p.matches) > pickerRows {
+		builder.WriteString(fmt.Sprintf("\n%d/%d\n", p.cursor+1, len(p.matches)))
+	}
+	builder.WriteString("\n" + i18n.T("picker.keys"))
+	return builder.String()
+}
+
+// details describes a model for its list row: the variant, modification
+// date and capabilities.
+func (p *modelPicker) details(model types.Model) string {
+	parts := modelFacts(model)
+	if !model.ModifiedAt.IsZero() {
+		parts = append(parts, model.ModifiedAt.Format("2006-01-02"))
+	}
+	if caps := p.capabilities[model.Name].String(); caps != "" {
+		parts = append(parts, caps)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// modelFacts lists what tells variants of a model apart: parameter size,
+// quantization, family and size on disk. Unknown values are left out.
+func modelFacts(model types.Model) []string {
+	var facts []string
+	for _, fact := range []string{model.Details.ParameterSize, model.Details.QuantizationLevel, model.Details.Family} {
+		if fact != "" {
+			facts = append(facts, fact)
+		}
+	}
+	if model.Size > 0 {
+		facts = append(facts, formatBytes(int(model.Size)))
+	}
+	return facts
+}
+
+// pickerProgram runs the picker on its own before the chat starts.
+type pickerProgram struct {
+	picker *modelPicker
+	choice string
+}
+
+func (p *pickerProgram) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (p *pickerProgram) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		choice, done, cmd := p.picker.handleKey(msg)
+		if done {
+			p.choice = choice
+			return p, tea.Quit
+		}
+		return p, cmd
+	}
+	var cmd tea.Cmd
+	p.picker.filter, cmd = p.picker.filter.Update(msg)
+	return p, cmd
+}
+
+func (p *pickerProgram) View() string {
+	return lipgloss.NewStyle().Padding(1, 2).Render(p.picker.view())
+}
+
+// PickModel shows the full-screen model picker and returns the chosen
+// model, or "" if the user cancelled.
+func PickModel(models []types.Model, capabilities map[string]ollama.Capabilities) (string, error) {
+	program := &pickerProgram{picker: newModelPicker(models, capabilities, "")}
+	if _, err := tea.NewProgram(program, tea.WithAltScreen()).Run(); err != nil {
+		return "", err
+	}
+	return program.choice, nil
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxPlanSteps caps the steps taken from the planner's list.
+const maxPlanSteps = 10
+
+// plannerPrompt asks the planner model for a step list.
+const plannerPrompt = "You plan work for an assistant that carries it out one step at a time with tools such as reading and writing files, running commands and searching the web. Break the user's task into at most %d concrete, self-contained steps, in order. Reply with only a numbered list, one step per line, without explanations."
+
+// stepPrompt is sent to the executor model for each step.
+const stepPrompt = "Task: %s\n\nPlan:\n%s\n\nNow carry out step %d only: %s\n\nUse tools as needed and finish with a short summary of what you did."
+
+// planStepRegex matches an entry of the planner's list.
+var planStepRegex = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*])\s+(.+)$`)
+
+// plan is a task split into steps by the planner model and carried out one
+// step at a time by the executor model.
+type plan struct {
+	task     string
+	steps    []string
+	current  int    // Index of the step being carried out.
+	executor string // Model running the steps.
+	message  int    // Index of the plan's progress message in the transcript.
+}
+
+// planReadyMsg carries the planner's reply.
+type planReadyMsg struct {
+	task  string
+	reply string
+	err   error
+}
+
+// handlePlan implements "/plan <task>", "/plan next" and "/plan stop". The
+// planner model (planner_model, or the current model) splits the task into
+// steps, then the executor model (executor_model, or the current model)
+// carries them out one by one with tools.
+func (m *Model) handlePlan(args string) tea.Cmd {
+	switch args {
+	case "":
+		if m.plan == nil {
+			m.showStatus(i18n.T("plan.usage"))
+		} else {
+			m.showStatus(m.plan.render())
+		}
+		return nil
+	case "stop":
+		if m.plan == nil {
+			m.showStatus(i18n.T("plan.none"))
+			return nil
+		}
+		m.plan = nil
+		m.showStatus(i18n.T("plan.stopped"))
+		return nil
+	case "next":
+		if m.plan == nil {
+			m.showStatus(i18n.T("plan.none"))
+			return nil
+		}
+		return m.advancePlan()
+	}
+	if m.planning {
+		return nil
+	}
+
+	planner := m.config.PlannerModel
+	if planner == "" {
+		planner = m.modelName
+	}
+	m.planning = true
+	m.plan = nil
+	m.showStatus(i18n.T("plan.planning", planner))
+	client, options := m.ollamaClient, m.requestOptions()
+	messages := []types.Message{
+		{Role: "system", Content: fmt.Sprintf(plannerPrompt, maxPlanSteps)},
+		{Role: "user", Content: args},
+	}
+	return func() tea.Msg {
+		resp, err := client.Chat(context.Background(), planner, messages, options)
+		return planReadyMsg{task: args, reply: resp.Message.Content, err: err}
+	}
+}
+
+// handlePlanReady shows the plan and starts its first step.
+func (m *Model) handlePlanReady(msg planReadyMsg) tea.Cmd {
+	m.planning = false
+	if msg.err != nil {
+		m.showError(i18n.T("plan.failed", msg.err))
+		return nil
+	}
+	steps := parsePlanSteps(m.postProcess.Clean(msg.reply))
+	if len(steps) == 0 {
+		m.showError(i18n.T("plan.empty"))
+		return nil
+	}
+	executor := m.config.ExecutorModel
+	if executor == "" {
+		executor = m.modelName
+	}
+	m.plan = &plan{task: msg.task, steps: steps, executor: executor, message: len(m.messages)}
+	m.addMessage(types.Message{Role: "assistant", Content: m.plan.render(), Local: true})
+	return m.startStep()
+}
+
+// parsePlanSteps returns the entries of a numbered or bulleted list.
+func parsePlanSteps(reply string) []string {
+	var steps []string
+	for _, line := range strings.Split(reply, "\n") {
+		match := planStepRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		step := strings.TrimSpace(strings.ReplaceAll(match[1], "**", ""))
+		if step != "" && len(steps) < maxPlanSteps {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// startStep sends the current step to the executor model.
+func (m *Model) startStep() tea.Cmd {
+	p := m.plan
+	m.updatePlanMessage()
+	var list strings.Builder
+	for i, step := range p.steps {
+		fmt.Fprintf(&list, "%d. %s\n", i+1, step)
+	}
+	step := p.steps[p.current]
+	cmd := m.send(fmt.Sprintf(stepPrompt, p.task, strings.TrimRight(list.String(), "\n"), p.current+1, step))
+	if m.sending {
+		m.messages[len(m.messages)-2].DisplayContent = i18n.T("plan.step", p.current+1, len(p.steps), step)
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+	}
+	return cmd
+}
+
+// advancePlan moves on to the next step once the executor has answered,
+// or finishes the plan after the last one.
+func (m *Model) advancePlan() tea.Cmd {
+	if m.plan == nil {
+		return nil
+	}
+	m.plan.current++
+	if m.plan.current < len(m.plan.steps) {
+		return m.startStep()
+	}
+	m.updatePlanMessage()
+	m.plan = nil
+	m.showStatus(i18n.T("plan.done"))
+	return nil
+}
+
+// updatePlanMessage refreshes the progress shown in the plan message.
+func (m *Model) updatePlanMessage() {
+	if i := m.plan.message; i >= 0 && i < len(m.messages) {
+		m.messages[i].Content = m.plan.render()
+		m.viewport.SetContent(m.renderMessages())
+	}
+}
+
+// activeModel is the model replies are requested from: the executor while
+// a plan runs, otherwise the selected model.
+func (m *Model) activeModel() string {
+	if m.plan != nil {
+		return m.plan.executor
+	}
+	return m.modelName
+}
+
+// render lists the steps with their progress.
+func (p *plan) render() string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T("plan.title", p.executor) + "\n\n")
+	for i, step := range p.steps {
+		marker := "○"
+		switch {
+		case i < p.current:
+			marker = "✔"
+		case i == p.current:
+			marker = "▶"
+		}
+		fmt.Fprintf(&builder, "%d. %s %s\n", i+1, marker, step)
+	}
+	return builder.String()
+}
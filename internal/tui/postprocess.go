@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/postprocess"
+	"strings"
+)
+
+// familyReplacer normalises model name prefixes such as "gpt-oss" to the
+// family names Ollama reports, e.g. "gptoss".
+var familyReplacer = strings.NewReplacer("-", "", ".", "", "_", "")
+
+// modelFamily returns the family of the current model from its details,
+// falling back to the model name.
+func (m *Model) modelFamily() string {
+	if m.modelEntry.Details.Family != "" {
+		return m.modelEntry.Details.Family
+	}
+	name, _, _ := strings.Cut(m.modelName, ":")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return familyReplacer.Replace(strings.TrimRight(name, "0123456789."))
+}
+
+// updatePostProcess selects the reply post-processing pipeline for the
+// current model family. The configuration was validated at startup, so an
+// error here only falls back to the defaults.
+func (m *Model) updatePostProcess() {
+	pipeline, err := postprocess.For(m.modelFamily(), m.config.PostProcessors)
+	if err != nil {
+		m.logger.Log(fmt.Sprintf("Using default post-processing: %v", err))
+		pipeline, _ = postprocess.Build(postprocess.DefaultSteps)
+	}
+	m.postProcess = pipeline
+}
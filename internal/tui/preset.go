@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func float(v float64) *float64 { return &v }
+
+// builtinPresets are the option sets available without configuration;
+// "presets" in config.json adds more or replaces them.
+var builtinPresets = map[string]types.Options{
+	"precise":  {Temperature: float(0.1), TopP: float(0.5)},
+	"balanced": {Temperature: float(0.5)},
+	"creative": {Temperature: float(0.9), TopP: float(0.95)},
+}
+
+// presets returns the built-in presets merged with those from the config.
+func (m *Model) presets() map[string]types.Options {
+	presets := make(map[string]types.Options, len(builtinPresets))
+	for name, options := range builtinPresets {
+		presets[name] = options
+	}
+	if m.config != nil {
+		for name, options := range m.config.Presets {
+			presets[name] = options
+		}
+	}
+	return presets
+}
+
+// handlePreset implements "/preset", which lists the presets, "/preset
+// <name>" and "/preset off". The preset's options apply to all following
+// requests, over those of the persona.
+func (m *Model) handlePreset(args string) {
+	presets := m.presets()
+	switch args {
+	case "":
+		names := make([]string, 0, len(presets))
+		for name := range presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var builder strings.Builder
+		builder.WriteString(i18n.T("preset.title") + "\n\n")
+		for _, name := range names {
+			marker := ""
+			if name == m.preset {
+				marker = " " + i18n.T("preset.active")
+			}
+			builder.WriteString(fmt.Sprintf("- **%s**%s – %s\n", name, marker, describeOptions(presets[name])))
+		}
+		m.showStatus(builder.String())
+		return
+	case "off":
+		m.preset = ""
+		m.showStatus(i18n.T("preset.off"))
+		return
+	}
+	options, ok := presets[args]
+	if !ok {
+		m.showError(i18n.T("preset.unknown", args))
+		return
+	}
+	m.preset = args
+	m.showStatus(i18n.T("preset.selected", args, describeOptions(options)))
+}
+
+// presetOptions returns the options of the selected preset.
+func (m *Model) presetOptions() types.Options {
+	if m.preset == "" {
+		return types.Options{}
+	}
+	return m.presets()[m.preset]
+}
+
+// describeOptions lists the options that are set, e.g. "temperature 0.1,
+// top_p 0.5".
+func describeOptions(o types.Options) string {
+	var parts []string
+	number := func(name string, v *float64) {
+		if v != nil {
+			parts = append(parts, name+" "+strconv.FormatFloat(*v, 'f', -1, 64))
+		}
+	}
+	integer := func(name string, v *int) {
+		if v != nil {
+			parts = append(parts, fmt.Sprintf("%s %d", name, *v))
+		}
+	}
+	number("temperature", o.Temperature)
+	number("top_p", o.TopP)
+	integer("top_k", o.TopK)
+	number("repeat_penalty", o.RepeatPenalty)
+	integer("seed", o.Seed)
+	if o.NumCtx != 0 {
+		parts = append(parts, fmt.Sprintf("num_ctx %d", o.NumCtx))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
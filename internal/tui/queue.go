@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// queuePrompt keeps a prompt typed while a reply is running, to be sent
+// when the turn is over.
+func (m *Model) queuePrompt(input string) {
+	m.queued = append(m.queued, input)
+	m.textarea.Reset()
+}
+
+// sendQueued sends the oldest queued prompt once nothing else is running.
+// @ mentions are expanded now, so they see the files as the previous turn
+// left them.
+func (m *Model) sendQueued() tea.Cmd {
+	if len(m.queued) == 0 || m.busy() || m.planning || m.translating || m.preview != nil {
+		return nil
+	}
+	input := m.queued[0]
+	m.queued = m.queued[1:]
+	return m.guardedSend(input, m.expandMentions(m.expandLastFile(input)))
+}
+
+// queuePanel lists the queued prompts above the input, or "" if there are
+// none.
+func (m *Model) queuePanel() string {
+	if len(m.queued) == 0 {
+		return ""
+	}
+	width := max(m.viewport.Width, 1)
+	lines := make([]string, len(m.queued))
+	for i, input := range m.queued {
+		firstLine, _, _ := strings.Cut(input, "\n")
+		lines[i] = truncateLine(footerStyle.Render(i18n.T("queue.item", firstLine)), width)
+	}
+	return strings.Join(lines, "\n")
+}
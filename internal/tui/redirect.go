@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// redirectPrompt re-asks the model after an interruption. The partial
+// answer stays in the history right before it.
+const redirectPrompt = "I interrupted your previous answer; what you wrote so far is above. Take this note into account and continue from there, changing course where needed:\n\n%s"
+
+// interrupt implements Ctrl+R while a reply streams in: it stops the
+// reply, keeps what arrived so far and waits for a steering note, which is
+// sent with Enter. Esc keeps the partial reply and sends nothing.
+func (m *Model) interrupt() {
+	m.endStream()
+	last := &m.messages[len(m.messages)-1]
+	if partial := strings.TrimSpace(m.postProcess.Clean(m.streamText)); partial != "" {
+		last.Content = partial
+		last.DisplayContent = partial + "\n\n" + i18n.T("redirect.interrupted")
+	} else {
+		m.messages = m.messages[:len(m.messages)-1]
+	}
+	m.streamText = ""
+	m.redirecting = true
+	m.textarea.Placeholder = i18n.T("redirect.placeholder")
+	m.focused = focusTextarea
+	m.textarea.Focus()
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// endRedirect leaves the steering note prompt.
+func (m *Model) endRedirect() {
+	m.redirecting = false
+	m.textarea.Placeholder = i18n.T("input.placeholder")
+}
+
+// redirect re-asks the model with the steering note.
+func (m *Model) redirect(note string) tea.Cmd {
+	m.endRedirect()
+	m.textarea.Reset()
+	if note == "" || note == "/stop" {
+		m.saveSession()
+		return nil
+	}
+	cmd := m.send(fmt.Sprintf(redirectPrompt, m.expandMentions(m.expandLastFile(note))))
+	if m.sending {
+		m.messages[len(m.messages)-2].DisplayContent = i18n.T("redirect.note", note)
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+	}
+	return cmd
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/ollama"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// minSuggestedContext is the smallest num_ctx suggested when a model does
+// not fit into memory.
+const minSuggestedContext = 2048
+
+// pullProgressMsg carries a status update of a running /pull.
+type pullProgressMsg struct {
+	progress ollama.PullProgress
+	updates  chan tea.Msg
+}
+
+// pullFinishedMsg reports the end of a /pull.
+type pullFinishedMsg struct {
+	model string
+	err   error
+}
+
+// handleAPIError shows what to do about a failed chat request instead of
+// the error screen: pull a missing model, or make the model fit into
+// memory.
+func (m *Model) handleAPIError(err *ollama.APIError) tea.Cmd {
+	m.dropFailedRequest()
+	model := m.activeModel()
+	switch {
+	case err.ModelNotFound():
+		m.showError(i18n.T("remedy.not_found", model))
+		m.textarea.SetValue("/pull " + model)
+		m.textarea.CursorEnd()
+	case err.OutOfMemory():
+		ctx := m.contextSize()
+		m.showError(i18n.T("remedy.memory", model, err.Message, max(minSuggestedContext, ctx/2), ctx))
+	case err.StatusCode >= 500:
+		m.showError(i18n.T("remedy.server", err.Status, err.Retries, err.Message))
+	default:
+		m.showError(i18n.T("remedy.other", err))
+	}
+	return nil
+}
+
+// dropFailedRequest removes the empty reply of a request that failed, and
+// the prompt it answered, which is still in the input history.
+func (m *Model) dropFailedRequest() {
+	last := len(m.messages) - 1
+	if last > 0 && m.messages[last].Role == "assistant" && m.messages[last].Content == "" && len(m.messages[last].ToolCalls) == 0 {
+		m.messages = m.messages[:last]
+		last--
+	}
+	if last > 0 && m.messages[last].Role == "user" && !m.messages[last].Local {
+		m.messages = m.messages[:last]
+	}
+	m.viewport.SetContent(m.renderMessages())
+}
+
+// handlePull implements "/pull [model]", which downloads the model, by
+// default the current one, with progress in the footer.
+func (m *Model) handlePull(args string) tea.Cmd {
+	model := args
+	if model == "" {
+		model = m.modelName
+	}
+	if m.pulling != "" {
+		m.showError(i18n.T("pull.busy", m.pulling))
+		return nil
+	}
+	m.pulling = model
+	m.pullStatus = ""
+	updates := make(chan tea.Msg)
+	client := m.ollamaClient
+	go func() {
+		defer close(updates)
+		err := client.Pull(context.Background(), model, func(progress ollama.PullProgress) {
+			updates <- pullProgressMsg{progress: progress, updates: updates}
+		})
+		updates <- pullFinishedMsg{model: model, err: err}
+	}()
+	return waitForPull(updates)
+}
+
+func waitForPull(updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
+// handlePullProgress shows a pull status update in the footer.
+func (m *Model) handlePullProgress(msg pullProgressMsg) tea.Cmd {
+	status := msg.progress.Status
+	if percent := msg.progress.Percent(); percent >= 0 {
+		status = fmt.Sprintf("%s %d%%", status, percent)
+	}
+	m.pullStatus = status
+	return waitForPull(msg.updates)
+}
+
+// handlePullFinished reports the end of a pull.
+func (m *Model) handlePullFinished(msg pullFinishedMsg) {
+	m.pulling = ""
+	m.pullStatus = ""
+	if msg.err != nil {
+		m.showError(i18n.T("pull.failed", msg.model, msg.err))
+		return
+	}
+	m.showStatus(i18n.T("pull.done", msg.model))
+}
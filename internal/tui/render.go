@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"hash/fnv"
+	"prompt-cli/internal/types"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+)
+
+// renderedBlock is the viewport text of one message and its line count.
+type renderedBlock struct {
+	key   uint64
+	text  string
+	lines int
+}
+
+// renderCache keeps the rendered block of every transcript message so a
+// chunk, key press or status line only re-renders the messages that
+// changed. Blocks are stored by message index and checked against a hash
+// of everything that affects their output; the whole cache is dropped when
+// the width changes.
+type renderCache struct {
+	width  int
+	blocks []renderedBlock
+}
+
+// block returns the rendered block of messages[i], rendering it only when
+// the cached one is missing or stale.
+func (c *renderCache) block(m *Model, messages []types.Message, i int) renderedBlock {
+	if c.width != m.viewport.Width {
+		c.width = m.viewport.Width
+		c.blocks = nil
+	}
+	key := m.blockKey(messages, i)
+	if i < len(c.blocks) && c.blocks[i].key == key {
+		return c.blocks[i]
+	}
+
+	text := m.renderMessage(messages, i)
+	block := renderedBlock{key: key, text: text, lines: strings.Count(text, "\n")}
+	if i < len(c.blocks) {
+		c.blocks[i] = block
+	} else if i == len(c.blocks) {
+		c.blocks = append(c.blocks, block)
+	}
+	return block
+}
+
+// trim forgets blocks past the end of a transcript of n messages, e.g.
+// after /new or pruning.
+func (c *renderCache) trim(n int) {
+	if len(c.blocks) > n {
+		c.blocks = c.blocks[:n:n]
+	}
+}
+
+// blockKey hashes the inputs renderMessage depends on for messages[i].
+func (m *Model) blockKey(messages []types.Message, i int) uint64 {
+	msg := messages[i]
+	h := fnv.New64a()
+	for _, part := range []string{msg.Role, msg.Content, msg.DisplayContent, msg.Thinking, msg.Spool, m.timeLabel(msg.Time)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(strconv.FormatBool(msg.IsError)))
+	h.Write([]byte(strconv.FormatBool(msg.Excluded)))
+	if msg.Role == "tool" {
+		h.Write([]byte(strconv.FormatBool(m.isExpanded(i))))
+		if i > 0 && len(messages[i-1].ToolCalls) > 0 {
+			h.Write([]byte(messages[i-1].ToolCalls[0].Function.Name))
+		}
+	}
+	if i == len(messages)-1 && msg.Role == "assistant" && msg.Content == "" && m.sending {
+		h.Write([]byte{1})
+		h.Write([]byte(m.currentJoke))
+	}
+	return h.Sum64()
+}
+
+// textRenderer turns a transcript block into terminal text.
+type textRenderer interface {
+	Render(in string) (string, error)
+}
+
+// wrapRenderer only wraps text to the width. The low-memory mode uses it
+// instead of glamour, whose styles and syntax highlighting cost memory and
+// time on phones and small boards.
+type wrapRenderer struct {
+	width int
+}
+
+func (r wrapRenderer) Render(in string) (string, error) {
+	return "\n" + wrap.String(wordwrap.String(strings.TrimSpace(in), r.width), r.width) + "\n\n", nil
+}
+
+// renderers caches the glamour renderers for the current wrap width.
+// Building one is costly, and the auto style queries the terminal each time.
+type renderers struct {
+	width        int
+	lightweight  bool // Plain wrapped text instead of glamour, see wrapRenderer.
+	markdownTerm *glamour.TermRenderer
+	plainTerm    *glamour.TermRenderer
+}
+
+// reset drops the renderers when the wrap width changes.
+func (r *renderers) reset(width int) {
+	if r.width != width {
+		r.width = width
+		r.markdownTerm = nil
+		r.plainTerm = nil
+	}
+}
+
+// markdown returns the styled renderer used for transcript messages. Its
+// colors are limited to what the terminal supports, e.g. 16 colors in an old
+// Windows console, instead of glamour's default of true color.
+func (r *renderers) markdown(width int) textRenderer {
+	if r.lightweight {
+		return wrapRenderer{width: width}
+	}
+	r.reset(width)
+	if r.markdownTerm == nil {
+		r.markdownTerm, _ = glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithColorProfile(lipgloss.ColorProfile()),
+			glamour.WithWordWrap(width),
+		)
+	}
+	return r.markdownTerm
+}
+
+// plain returns a renderer that only wraps text, without colors.
+func (r *renderers) plain(width int) textRenderer {
+	if r.lightweight {
+		return wrapRenderer{width: width}
+	}
+	r.reset(width)
+	if r.plainTerm == nil {
+		r.plainTerm, _ = glamour.NewTermRenderer(
+			glamour.WithWordWrap(width),
+		)
+	}
+	return r.plainTerm
+}
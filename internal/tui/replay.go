@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/session"
+	"prompt-cli/internal/types"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// replayProgram steps through a saved session one message at a time, for
+// demos and for reviewing what an agent did and why.
+type replayProgram struct {
+	session   *session.Session
+	steps     []types.Message // Every message except the system prompt.
+	shown     int             // Number of steps on screen.
+	rendered  []string        // Rendered steps, filled as they are shown.
+	viewport  viewport.Model
+	renderers renderers
+}
+
+// Replay shows a saved session full screen. Space or → reveals the next
+// message, b or ← hides it again, and q quits.
+func Replay(s *session.Session) error {
+	messages, err := s.FullMessages()
+	if err != nil {
+		return fmt.Errorf("loading session %s: %w", s.ID, err)
+	}
+	p := &replayProgram{session: s, viewport: viewport.New(80, 20)}
+	for _, msg := range messages {
+		if msg.Role != "system" {
+			p.steps = append(p.steps, msg)
+		}
+	}
+	if len(p.steps) == 0 {
+		return fmt.Errorf("session %s has no messages", s.ID)
+	}
+	p.shown = 1
+	_, err = tea.NewProgram(p, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+	return err
+}
+
+func (p *replayProgram) Init() tea.Cmd {
+	return nil
+}
+
+func (p *replayProgram) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.viewport.Width = msg.Width
+		p.viewport.Height = msg.Height - 1
+		p.rendered = nil
+		p.refresh()
+		return p, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case " ", "right", "l", "n", "enter":
+			p.shown = min(len(p.steps), p.shown+1)
+		case "left", "h", "b", "backspace":
+			p.shown = max(1, p.shown-1)
+		case "home", "g":
+			p.shown = 1
+		case "end", "G":
+			p.shown = len(p.steps)
+		case "q", "esc", "ctrl+c":
+			return p, tea.Quit
+		default:
+			var cmd tea.Cmd
+			p.viewport, cmd = p.viewport.Update(msg)
+			return p, cmd
+		}
+		p.refresh()
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	p.viewport, cmd = p.viewport.Update(msg)
+	return p, cmd
+}
+
+// refresh renders the shown steps and scrolls to the newest one.
+func (p *replayProgram) refresh() {
+	for len(p.rendered) < p.shown {
+		p.rendered = append(p.rendered, p.renderStep(p.steps[len(p.rendered)]))
+	}
+	p.viewport.SetContent(strings.Join(p.rendered[:p.shown], ""))
+	p.viewport.GotoBottom()
+}
+
+// renderStep renders one message with its tool calls, or a tool result with
+// its status.
+func (p *replayProgram) renderStep(msg types.Message) string {
+	var body strings.Builder
+	switch msg.Role {
+	case "tool":
+		body.WriteString("## Tool Output\n\n")
+		if msg.Result != nil {
+			body.WriteString(expandedResult(msg.Result, ""))
+		} else {
+			body.WriteString(fence(msg.Content, "") + "\n")
+		}
+	default:
+		body.WriteString("## " + strings.Title(msg.Role) + "\n\n")
+		if msg.Thinking != "" {
+			body.WriteString(thinkingBlock(msg.Thinking) + "\n\n")
+		}
+		body.WriteString(msg.Content + "\n\n")
+		if len(msg.Images) > 0 {
+			body.WriteString(i18n.T("replay.images", len(msg.Images)) + "\n\n")
+		}
+		for _, call := range msg.ToolCalls {
+			args, _ := json.MarshalIndent(call.Function.Arguments, "", "  ")
+			body.WriteString(fmt.Sprintf("%s `%s`\n```json\n%s\n```\n", i18n.T("replay.tool_call"), call.Function.Name, args))
+		}
+	}
+	md, err := p.renderers.markdown(p.viewport.Width - 2).Render(body.String() + "\n---")
+	if err != nil {
+		return body.String()
+	}
+	return md
+}
+
+func (p *replayProgram) View() string {
+	footer := i18n.T("replay.footer", p.shown, len(p.steps), p.session.ID, p.session.Model)
+	return lipgloss.JoinVertical(lipgloss.Left, p.viewport.View(), footerStyle.Render(footer))
+}
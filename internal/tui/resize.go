@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resizeDebounce is how long the width has to stay put before the
+// transcript is re-rendered. Dragging a window edge emits a storm of
+// WindowSizeMsg events and re-wrapping every message for each one makes the
+// UI lag behind the pointer.
+const resizeDebounce = 120 * time.Millisecond
+
+// resizeSettledMsg fires resizeDebounce after a width change. Only the one
+// carrying the latest sequence number triggers a re-render.
+type resizeSettledMsg struct {
+	seq int
+}
+
+// scheduleRerender re-renders the transcript for the current viewport
+// width. Duplicate events with an unchanged width do nothing; the first
+// size report renders right away so startup shows no wrong-width frame.
+func (m *Model) scheduleRerender() tea.Cmd {
+	if m.viewport.Width == m.renderedWidth {
+		return nil
+	}
+	if m.renderedWidth == 0 {
+		m.rerender()
+		return nil
+	}
+	m.resizeSeq++
+	seq := m.resizeSeq
+	return tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+		return resizeSettledMsg{seq: seq}
+	})
+}
+
+// handleResizeSettled re-renders once the resize storm is over.
+func (m *Model) handleResizeSettled(msg resizeSettledMsg) {
+	if msg.seq != m.resizeSeq || m.viewport.Width == m.renderedWidth {
+		return
+	}
+	m.rerender()
+}
+
+// rerender wraps the transcript for the current width.
+func (m *Model) rerender() {
+	m.renderedWidth = m.viewport.Width
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"strconv"
+)
+
+// defaultOlderMessages is how many archived messages /older restores.
+const defaultOlderMessages = 20
+
+// pruneTranscript moves the oldest messages to the session archive once the
+// transcript exceeds max_transcript_messages. The system prompt stays, and
+// the cut is moved forward to a user message so the retained part starts
+// with a complete exchange.
+func (m *Model) pruneTranscript() {
+	limit := m.config.MaxTranscriptMessages
+	if limit <= 0 || m.session == nil || m.sending || len(m.messages) == 0 {
+		return
+	}
+	limit += m.restoredMessages
+	start := 0
+	if m.messages[0].Role == "system" {
+		start = 1
+	}
+	excess := len(m.messages) - start - limit
+	if excess <= 0 {
+		return
+	}
+
+	cut := start + excess
+	for i := cut; i < len(m.messages)-1; i++ {
+		if m.messages[i].Role == "user" {
+			cut = i
+			break
+		}
+	}
+	pruned := m.messages[start:cut]
+	if err := m.session.Archive(pruned); err != nil {
+		m.logger.Log(fmt.Sprintf("Error archiving messages: %v", err))
+		return
+	}
+
+	kept := make([]types.Message, 0, len(m.messages)-len(pruned))
+	kept = append(kept, m.messages[:start]...)
+	kept = append(kept, m.messages[cut:]...)
+	m.messages = kept
+	m.restoredMessages = 0
+
+	expanded := make(map[int]bool, len(m.expanded))
+	for index, open := range m.expanded {
+		if index >= cut {
+			expanded[index-len(pruned)] = open
+		}
+	}
+	m.expanded = expanded
+	if m.plan != nil {
+		m.plan.message -= len(pruned)
+	}
+	m.printedMessages = max(0, m.printedMessages-len(pruned))
+	m.loggedMessages = max(0, m.loggedMessages-len(pruned))
+}
+
+// handleOlder implements "/older [N]", which brings the N most recent
+// archived messages back into the transcript.
+func (m *Model) handleOlder(args string) {
+	n := defaultOlderMessages
+	if args != "" {
+		parsed, err := strconv.Atoi(args)
+		if err != nil || parsed < 1 {
+			m.showError(i18n.T("older.usage"))
+			return
+		}
+		n = parsed
+	}
+	if m.session == nil || m.session.Archived == 0 {
+		m.showStatus(i18n.T("older.none"))
+		return
+	}
+
+	restored, err := m.session.Unarchive(n)
+	if err != nil {
+		m.showError(i18n.T("older.failed", err))
+		return
+	}
+	start := 0
+	if len(m.messages) > 0 && m.messages[0].Role == "system" {
+		start = 1
+	}
+	messages := make([]types.Message, 0, len(m.messages)+len(restored))
+	messages = append(messages, m.messages[:start]...)
+	messages = append(messages, restored...)
+	messages = append(messages, m.messages[start:]...)
+	m.messages = messages
+	// Keep the restored messages until the transcript grows past the limit
+	// again.
+	m.restoredMessages += len(restored)
+
+	expanded := make(map[int]bool, len(m.expanded))
+	for index, open := range m.expanded {
+		expanded[index+len(restored)] = open
+	}
+	m.expanded = expanded
+
+	m.printedMessages += len(restored)
+	m.loggedMessages += len(restored)
+
+	m.saveSession()
+	if m.session.Archived > 0 {
+		m.showStatus(i18n.T("older.restored", len(restored), m.session.Archived))
+	} else {
+		m.showStatus(i18n.T("older.restored_all", len(restored)))
+	}
+	m.scrollToMessage(start)
+}
@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"prompt-cli/internal/workspace"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// permissionsFile stores the permission rules of the workspace.
+const permissionsFile = "permissions.json"
+
+// permissionRule lets a tool run without asking for files whose path (see
+// actionPath) matches Path, a glob such as "docs/**" or "**/*.md". Rules
+// are made from the answers to permission prompts, so the policy grows out
+// of the user's actual decisions.
+type permissionRule struct {
+	Tool string `json:"tool"`
+	Path string `json:"path"`
+}
+
+// String describes the rule, e.g. "write_file for docs/**".
+func (r permissionRule) String() string {
+	return fmt.Sprintf("%s for %s", r.Tool, r.Path)
+}
+
+// loadPermissionRules reads the rules saved for the workspace.
+func (m *Model) loadPermissionRules() {
+	if err := workspace.ReadJSON(permissionsFile, &m.permissionRules); err != nil {
+		m.logger.Log(fmt.Sprintf("Error loading permission rules: %v", err))
+	}
+}
+
+// actionPath returns the path rules are matched against: "name:rel" for a
+// file in a workspace root, otherwise the path relative to the current (or
+// remote) directory. Files outside of it have none, so no rule covers them
+// however broad its glob.
+func (m *Model) actionPath(action *types.Action) string {
+	target, ok := action.Input["path"].(string)
+	if !ok || target == "" {
+		return ""
+	}
+	resolved, err := m.agent.ResolvePath(target)
+	if err != nil {
+		return ""
+	}
+	target = filepath.ToSlash(m.agent.DisplayPath(resolved))
+	if filepath.IsAbs(target) || path.IsAbs(target) {
+		if m.agent.Remote() {
+			return ""
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return ""
+		}
+		rel, err := filepath.Rel(cwd, resolved)
+		if err != nil || filepath.IsAbs(rel) {
+			return ""
+		}
+		target = filepath.ToSlash(rel)
+	}
+	prefix, rel := "", target
+	if root, rest, found := strings.Cut(target, ":"); found && !strings.Contains(root, "/") {
+		prefix, rel = root+":", rest
+	}
+	rel = path.Clean(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+		return ""
+	}
+	return prefix + rel
+}
+
+// ruleAllows reports whether a saved rule covers the action.
+func (m *Model) ruleAllows(action *types.Action) bool {
+	target := m.actionPath(action)
+	if target == "" {
+		return false
+	}
+	for _, rule := range m.permissionRules {
+		if rule.Tool != action.Tool {
+			continue
+		}
+		if pathMatches(rule.Path, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathMatches matches a display path against a rule's glob. A "root:"
+// prefix is compared on its own, since "**" only spans whole path segments.
+func pathMatches(pattern, target string) bool {
+	if prefix, rest, ok := strings.Cut(pattern, ":"); ok && !strings.Contains(prefix, "/") {
+		remainder, found := strings.CutPrefix(target, prefix+":")
+		if !found {
+			return false
+		}
+		pattern, target = rest, remainder
+	}
+	ok, err := doublestar.Match(pattern, target)
+	return err == nil && ok
+}
+
+// ruleChoices returns the rules offered in the permission prompt: one for
+// the file's directory and one for its extension. Either is empty when it
+// does not apply.
+func (m *Model) ruleChoices(action *types.Action) (dir, ext permissionRule) {
+	target := m.actionPath(action)
+	if target == "" {
+		return
+	}
+	dirGlob := "**"
+	if parent := path.Dir(target); parent != "." {
+		dirGlob = parent + "/**"
+	} else if i := strings.Index(target, ":"); i >= 0 {
+		dirGlob = target[:i+1] + "**" // A file at the top of a workspace root.
+	}
+	dir = permissionRule{Tool: action.Tool, Path: dirGlob}
+	if extension := path.Ext(target); extension != "" && extension != target {
+		ext = permissionRule{Tool: action.Tool, Path: "**/*" + extension}
+	}
+	return
+}
+
+// ruleOptions renders the rule choices for the permission prompt.
+func (m *Model) ruleOptions(action *types.Action) string {
+	dir, ext := m.ruleChoices(action)
+	var options []string
+	if dir.Path != "" {
+		options = append(options, i18n.T("permission.rule_dir", dir.Path))
+	}
+	if ext.Path != "" {
+		options = append(options, i18n.T("permission.rule_ext", ext.Path))
+	}
+	return strings.Join(options, "   ")
+}
+
+// addPermissionRule saves a rule chosen in the permission prompt.
+func (m *Model) addPermissionRule(rule permissionRule) {
+	for _, existing := range m.permissionRules {
+		if existing == rule {
+			return
+		}
+	}
+	m.permissionRules = append(m.permissionRules, rule)
+	if err := workspace.WriteJSON(permissionsFile, m.permissionRules); err != nil {
+		m.showError(i18n.T("permissions.failed", err))
+		return
+	}
+	m.showStatus(i18n.T("permissions.added", rule))
+}
+
+// handlePermissions implements "/permissions [delete <n>]", which lists the
+// saved rules or removes one of them.
+func (m *Model) handlePermissions(args string) {
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) == 0:
+		if len(m.permissionRules) == 0 {
+			m.showStatus(i18n.T("permissions.none"))
+			return
+		}
+		var builder strings.Builder
+		builder.WriteString(i18n.T("permissions.title") + "\n\n")
+		for i, rule := range m.permissionRules {
+			builder.WriteString(fmt.Sprintf("%d. `%s` for `%s`\n", i+1, rule.Tool, rule.Path))
+		}
+		m.showStatus(builder.String())
+	case len(fields) == 2 && fields[0] == "delete":
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 || n > len(m.permissionRules) {
+			m.showError(i18n.T("permissions.unknown", fields[1]))
+			return
+		}
+		rule := m.permissionRules[n-1]
+		m.permissionRules = append(m.permissionRules[:n-1], m.permissionRules[n:]...)
+		if err := workspace.WriteJSON(permissionsFile, m.permissionRules); err != nil {
+			m.showError(i18n.T("permissions.failed", err))
+			return
+		}
+		m.showStatus(i18n.T("permissions.deleted", rule))
+	default:
+		m.showError(i18n.T("permissions.usage"))
+	}
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runFinishedMsg carries the result of a /run command.
+type runFinishedMsg struct {
+	command  string
+	output   string
+	exitCode int
+	include  bool
+	err      error
+}
+
+// runCommand implements "/run [-i] <command>". The command runs in the
+// background so the UI stays responsive; with -i its output is attached to
+// the next prompt.
+func (m *Model) runCommand(args string) tea.Cmd {
+	include := false
+	if rest, ok := commandArgs(args, "-i"); ok {
+		include = true
+		args = rest
+	}
+	if args == "" {
+		usage := i18n.T("run.usage")
+		if suggested := m.suggestedCommands(); len(suggested) > 0 {
+			usage += " " + i18n.T("run.suggested", strings.Join(suggested, ", "))
+		}
+		m.showStatus(usage)
+		return nil
+	}
+
+	timeout := m.agent.Timeout("run")
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		output, exitCode, err := m.agent.RunShell(ctx, args, "")
+		return runFinishedMsg{command: args, output: output, exitCode: exitCode, include: include, err: err}
+	}
+}
+
+// suggestedCommands returns the build, test and run commands of the
+// projects found in the workspace, for the /run usage.
+func (m *Model) suggestedCommands() []string {
+	var commands []string
+	for _, project := range m.agent.Projects() {
+		for _, command := range []string{project.Build, project.Test, project.Run} {
+			if quoted := "`" + command + "`"; command != "" && !slices.Contains(commands, quoted) {
+				commands = append(commands, quoted)
+			}
+		}
+	}
+	return commands
+}
+
+// handleRunFinished shows the command output in the transcript. The message
+// is local only and never sent to the model unless it was attached with -i.
+func (m *Model) handleRunFinished(msg runFinishedMsg) (tea.Model, tea.Cmd) {
+	status := fmt.Sprintf("exit code %d", msg.exitCode)
+	if msg.err != nil {
+		status = fmt.Sprintf("error: %v", msg.err)
+	}
+	output := strings.TrimRight(msg.output, "\n")
+
+	m.addMessage(types.Message{
+		Role:    "command",
+		Content: fmt.Sprintf("`$ %s` (%s)\n\n```\n%s\n```", msg.command, status, output),
+		Local:   true,
+	})
+	if msg.include {
+		m.pendingContext = append(m.pendingContext, fmt.Sprintf("Output of `%s` (%s):\n```\n%s\n```", msg.command, status, output))
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// requestMessages returns the messages to send to the model, leaving out
+// those that only exist for display. Assistant replies are sent as the
+// model produced them, not as edited for display. The summary of compacted
+// messages follows the system prompt.
+func (m *Model) requestMessages() []types.Message {
+	messages := make([]types.Message, 0, len(m.messages)+1)
+	for i, msg := range m.messages {
+		if msg.Local || msg.Excluded {
+			continue
+		}
+		if msg.Raw != nil {
+			msg = *msg.Raw
+		}
+		msg.Result = nil
+		messages = append(messages, msg)
+		if i == 0 && m.session != nil && m.session.Compacted != "" {
+			messages = append(messages, types.Message{Role: "user", Content: compactedPrefix + m.session.Compacted})
+		}
+	}
+	return messages
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/session"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// inboxPoll is how often the session's inbox is checked for messages
+// forwarded from other sessions.
+const inboxPoll = 2 * time.Second
+
+// inboxTickMsg triggers the next inbox check.
+type inboxTickMsg struct{}
+
+func inboxTick() tea.Cmd {
+	return tea.Tick(inboxPoll, func(time.Time) tea.Msg {
+		return inboxTickMsg{}
+	})
+}
+
+// handleSendTo implements "/send-to <session> [text|@file]". Without text
+// the last answer is forwarded, so a planner session can hand its plan to
+// an executor session.
+func (m *Model) handleSendTo(args string) {
+	if m.session == nil {
+		return
+	}
+	target, rest, _ := strings.Cut(args, " ")
+	rest = strings.TrimSpace(rest)
+	if target == "" {
+		m.showStatus(i18n.T("sendto.usage", m.session.ID))
+		return
+	}
+	if target == m.session.ID {
+		m.showError(i18n.T("sendto.self"))
+		return
+	}
+
+	msg := session.Forwarded{From: m.session.ID, Sent: time.Now(), Content: rest}
+	switch {
+	case rest == "":
+		msg.Content = m.lastAnswer()
+		if msg.Content == "" {
+			m.showError(i18n.T("sendto.nothing"))
+			return
+		}
+	case strings.HasPrefix(rest, "@") && !strings.ContainsAny(rest, " \n"):
+		msg.File = strings.TrimSpace(m.expandLastFile(rest))[1:]
+		path, lines := parseMention(msg.File)
+		content, err := m.agent.ReadWorkspaceFile(path)
+		if err != nil {
+			m.showError(i18n.T("sendto.read_error", path, err))
+			return
+		}
+		msg.Content = cutLines(string(content), lines)
+	}
+	if err := session.Send(target, msg); err != nil {
+		m.showError(i18n.T("sendto.failed", target, err))
+		return
+	}
+	m.showStatus(i18n.T("sendto.sent", target))
+}
+
+// checkInbox sends the messages forwarded to this session to the model, as
+// one prompt, once the session is idle.
+func (m *Model) checkInbox() tea.Cmd {
+	if m.session == nil || m.busy() {
+		return inboxTick()
+	}
+	received, err := session.Receive(m.session.ID)
+	if err != nil {
+		m.logger.Log(fmt.Sprintf("Error reading the session inbox: %v", err))
+	}
+	if len(received) == 0 {
+		return inboxTick()
+	}
+	prompts := make([]string, len(received))
+	var senders []string
+	for i, msg := range received {
+		prompts[i] = msg.Prompt()
+		if !slices.Contains(senders, msg.From) {
+			senders = append(senders, msg.From)
+		}
+	}
+	m.showStatus(i18n.T("sendto.received", len(received), strings.Join(senders, ", ")))
+	return tea.Batch(m.send(strings.Join(prompts, "\n\n---\n\n")), inboxTick())
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/session"
+	"strconv"
+	"strings"
+)
+
+// saveSession persists the transcript once the user has said something.
+func (m *Model) saveSession() {
+	if m.session == nil {
+		return
+	}
+	if !m.hasUserMessage() {
+		return
+	}
+	m.pruneTranscript()
+	m.session.Model = m.modelName
+	m.session.Messages = m.messages
+	m.session.Tasks = m.agent.Tasks()
+	m.session.Preset = m.preset
+	if err := m.session.Save(); err != nil {
+		m.logger.Log(fmt.Sprintf("Error saving session: %v", err))
+	}
+}
+
+// hasUserMessage reports whether the user has said anything yet.
+func (m *Model) hasUserMessage() bool {
+	for _, msg := range m.messages {
+		if msg.Role == "user" {
+			return true
+		}
+	}
+	return false
+}
+
+// ResumeSession replaces the current conversation with a saved one.
+func (m *Model) ResumeSession(s *session.Session) {
+	m.session = s
+	m.messages = s.Messages
+	m.useSessionDirs()
+	m.restoredMessages = 0
+	m.numCtxOverride = s.NumCtx
+	if _, ok := m.presets()[s.Preset]; ok || s.Preset == "" {
+		m.preset = s.Preset
+	}
+	m.printedMessages = 0
+	// Resumed messages are already in the session's transcript log.
+	m.loggedSession = s.ID
+	m.loggedMessages = len(s.Messages)
+	m.expanded = make(map[int]bool)
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// handleHistory implements "/history <query>" and "/history open <N>". On
+// its own, "/history" lists the inputs of this session for "!N".
+func (m *Model) handleHistory(args string) {
+	if rest, ok := commandArgs(args, "open"); ok {
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 1 || n > len(m.historyMatches) {
+			m.showError(i18n.T("history.open_usage", len(m.historyMatches)))
+			return
+		}
+		match := m.historyMatches[n-1]
+		s, err := session.Load(match.SessionID)
+		if err != nil {
+			m.showError(i18n.T("history.open_error", match.SessionID, err))
+			return
+		}
+		m.saveSession()
+		m.ResumeSession(s)
+		m.scrollToMessage(match.Index)
+		return
+	}
+
+	if args == "" {
+		m.listPrompts()
+		return
+	}
+
+	matches, err := session.Search(args)
+	if err != nil {
+		m.showError(i18n.T("history.failed", err))
+		return
+	}
+	m.historyMatches = matches
+	if len(matches) == 0 {
+		m.showStatus(i18n.T("history.none", args))
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString(i18n.T("history.found", len(matches), args) + "\n\n")
+	for i, match := range matches {
+		builder.WriteString(fmt.Sprintf("%d. `%s` #%d (%s): %s\n", i+1, match.SessionID, match.Index, match.Role, match.Snippet))
+	}
+	builder.WriteString("\n" + i18n.T("history.hint"))
+	m.showStatus(builder.String())
+}
+
+// scrollToMessage moves the viewport so that the given message is at the top.
+func (m *Model) scrollToMessage(index int) {
+	if index < 0 || index > len(m.messages) {
+		return
+	}
+	prefix := m.renderMessageList(m.messages[:index])
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.SetYOffset(strings.Count(prefix, "\n"))
+}
+
+// useSessionDirs switches the agent to the scratch directory and trash of
+// the current session and updates the system prompt, which names the
+// scratch directory.
+func (m *Model) useSessionDirs() {
+	if err := m.agent.UseScratchDir(m.session.ID); err != nil {
+		m.logger.Log(fmt.Sprintf("Error: %v", err))
+	}
+	m.agent.UseTrash(m.session.ID)
+	m.agent.SetTasks(m.session.Tasks)
+	if err := session.OpenInbox(m.session.ID); err != nil {
+		m.logger.Log(fmt.Sprintf("Error: %v", err))
+	}
+	m.rebuildSystemPrompt()
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/session"
+	"strings"
+)
+
+// handleShare implements "/share [file]", which writes the conversation
+// as a self-contained HTML page. Without a file name it is saved as
+// promptcli-<session id>.html in the current directory.
+func (m *Model) handleShare(args string) {
+	if m.session == nil || !m.hasUserMessage() {
+		m.showStatus(i18n.T("share.empty"))
+		return
+	}
+	m.saveSession()
+
+	path := args
+	if path == "" {
+		path = "promptcli-" + m.session.ID + ".html"
+	} else if !strings.HasSuffix(strings.ToLower(path), ".html") {
+		path += ".html"
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		m.showError(i18n.T("share.failed", err))
+		return
+	}
+	err = session.WriteHTML(f, m.session)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		m.showError(i18n.T("share.failed", err))
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	m.showStatus(i18n.T("share.saved", filepath.ToSlash(path)))
+}
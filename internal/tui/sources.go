@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// sourceLineRegex matches a numbered entry of the sources list of a
+	// tool result, see types.Source.String.
+	sourceLineRegex = regexp.MustCompile(`^\[(\d+)\] (.*) <(\S+)>$`)
+	// citationRegex matches a citation such as "[2]" in an answer, but not
+	// an index such as "a[2]" or a Markdown link text.
+	citationRegex = regexp.MustCompile(`(?:^|[^\w\])])\[(\d+)\]`)
+	// fencedCodeRegex matches code blocks, whose brackets are not
+	// citations.
+	fencedCodeRegex = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+)
+
+// sessionSources returns the numbered sources of the tool results in
+// messages, in the order they were numbered.
+func sessionSources(messages []types.Message) []types.Source {
+	var sources []types.Source
+	seen := map[int]bool{}
+	for _, msg := range messages {
+		if msg.Role != "tool" {
+			continue
+		}
+		_, list, ok := strings.Cut(msg.Content, types.SourcesMarker+"\n")
+		if !ok {
+			continue
+		}
+		for _, line := range strings.Split(list, "\n") {
+			match := sourceLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+			if match == nil {
+				continue
+			}
+			n, _ := strconv.Atoi(match[1])
+			if !seen[n] {
+				seen[n] = true
+				sources = append(sources, types.Source{N: n, Title: match[2], URL: match[3]})
+			}
+		}
+	}
+	return sources
+}
+
+// numberSources gives the sources of a tool result their citation numbers:
+// a page numbered before keeps its number, new ones continue the sequence
+// of known. It returns known with the new sources, for numbering the next
+// result of a batch before this one is in the transcript.
+func numberSources(known []types.Source, result *types.ToolResult) []types.Source {
+	next := 1
+	for _, source := range known {
+		next = max(next, source.N+1)
+	}
+	for i := range result.Sources {
+		source := &result.Sources[i]
+		if j := slices.IndexFunc(known, func(s types.Source) bool { return s.URL == source.URL }); j >= 0 {
+			source.N = known[j].N
+			continue
+		}
+		source.N = next
+		known = append(known, *source)
+		next++
+	}
+	return known
+}
+
+// citedSources returns the sources that the answer messages[i] cites, in
+// the order of their numbers.
+func citedSources(messages []types.Message, i int) []types.Source {
+	text := fencedCodeRegex.ReplaceAllString(messages[i].Content, "")
+	matches := citationRegex.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	known := sessionSources(messages[:i])
+	var cited []types.Source
+	for _, match := range matches {
+		n, _ := strconv.Atoi(match[1])
+		j := slices.IndexFunc(known, func(s types.Source) bool { return s.N == n })
+		if j >= 0 && !slices.ContainsFunc(cited, func(s types.Source) bool { return s.N == n }) {
+			cited = append(cited, known[j])
+		}
+	}
+	slices.SortFunc(cited, func(a, b types.Source) int { return a.N - b.N })
+	return cited
+}
+
+// sourcesList renders sources as a Markdown list with their numbers.
+func sourcesList(sources []types.Source) string {
+	var builder strings.Builder
+	for _, source := range sources {
+		title := source.Title
+		if title == "" {
+			title = source.URL
+		}
+		builder.WriteString(fmt.Sprintf("- [%d] %s  \n  <%s>\n", source.N, title, source.URL))
+	}
+	return builder.String()
+}
+
+// handleSources implements "/sources [N]": without a number it lists the
+// web pages the answers of this session were based on, with one it opens
+// that page in the browser.
+func (m *Model) handleSources(args string) {
+	sources := sessionSources(m.messages)
+	if len(sources) == 0 {
+		m.showStatus(i18n.T("sources.none"))
+		return
+	}
+	if args == "" {
+		m.addMessage(types.Message{
+			Role:    "assistant",
+			Content: i18n.T("sources.title", len(sources)) + "\n\n" + sourcesList(sources),
+			Local:   true,
+		})
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(args, "#"))
+	j := slices.IndexFunc(sources, func(s types.Source) bool { return s.N == n })
+	if err != nil || j < 0 {
+		m.showError(i18n.T("sources.unknown", args))
+		return
+	}
+	if err := openURL(sources[j].URL); err != nil {
+		m.showError(i18n.T("sources.open_failed", sources[j].URL, err))
+		return
+	}
+	m.showStatus(i18n.T("sources.opened", sources[j].URL))
+}
+
+// openURL opens a web page in the default browser.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
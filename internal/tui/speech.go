@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/i18n"
+	"regexp"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// speakFinishedMsg reports a failed text-to-speech run.
+type speakFinishedMsg struct{ err error }
+
+var (
+	speechCodeBlockRegex = regexp.MustCompile("(?s)```.*?```")
+	speechMarkupRegex    = regexp.MustCompile("[`*_#>|]+")
+)
+
+// ttsCommand returns the command line text is piped into. The config value
+// wins, otherwise a platform default is used.
+func (m *Model) ttsCommand() string {
+	if m.config != nil && m.config.TTSCommand != "" {
+		return m.config.TTSCommand
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return "say"
+	case "windows":
+		return `powershell -NoProfile -Command "Add-Type -AssemblyName System.Speech; (New-Object System.Speech.Synthesis.SpeechSynthesizer).Speak([Console]::In.ReadToEnd())"`
+	default:
+		return "espeak"
+	}
+}
+
+// handleSpeak toggles reading completed assistant messages aloud.
+func (m *Model) handleSpeak() {
+	m.speakEnabled = !m.speakEnabled
+	if !m.speakEnabled && m.speechCancel != nil {
+		m.speechCancel()
+		m.speechCancel = nil
+	}
+	if m.speakEnabled {
+		m.showStatus(i18n.T("speak.enabled", m.ttsCommand()))
+	} else {
+		m.showStatus(i18n.T("speak.disabled"))
+	}
+}
+
+// speakLastResponse reads the final assistant message aloud in the
+// background. Any speech still running is stopped first.
+func (m *Model) speakLastResponse() tea.Cmd {
+	if !m.speakEnabled || len(m.messages) == 0 {
+		return nil
+	}
+	last := m.messages[len(m.messages)-1]
+	if last.Role != "assistant" || last.IsError {
+		return nil
+	}
+	text := speechText(last.Content)
+	if text == "" {
+		return nil
+	}
+
+	if m.speechCancel != nil {
+		m.speechCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.speechCancel = cancel
+
+	shell, args := agent.ShellCommand(m.ttsCommand())
+	return func() tea.Msg {
+		cmd := exec.CommandContext(ctx, shell, args...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil && ctx.Err() == nil {
+			return speakFinishedMsg{err: fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))}
+		}
+		return speakFinishedMsg{}
+	}
+}
+
+// speechText strips Markdown that sounds bad when read aloud. Code blocks
+// are skipped entirely.
+func speechText(content string) string {
+	content = speechCodeBlockRegex.ReplaceAllString(content, " ")
+	content = speechMarkupRegex.ReplaceAllString(content, "")
+	return strings.TrimSpace(content)
+}
@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"prompt-cli/internal/i18n"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fenceLineRegex matches the lines that open or close a code block.
+var fenceLineRegex = regexp.MustCompile("(?m)^\\s*(```|~~~)")
+
+const (
+	// spoolLines and spoolBytes are the size above which an assistant
+	// reply is only shown as a preview of its beginning. Rendering a reply
+	// of thousands of lines on every chunk would freeze the TUI.
+	spoolLines = 400
+	spoolBytes = 40 << 10
+)
+
+// spoolPreview returns the beginning of a reply that is too long to show in
+// full and the number of lines left out, or ok false for a reply that fits.
+// A code block cut in the middle is closed so the rest renders as text.
+func spoolPreview(content string) (preview string, hidden int, ok bool) {
+	if len(content) <= spoolBytes && strings.Count(content, "\n") < spoolLines {
+		return content, 0, false
+	}
+	cut := min(len(content), spoolBytes)
+	if i := nthIndex(content[:cut], '\n', spoolLines); i >= 0 {
+		cut = i
+	} else if i := strings.LastIndexByte(content[:cut], '\n'); i > 0 {
+		cut = i
+	}
+	for cut < len(content) && cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	preview = content[:cut]
+	hidden = strings.Count(content[cut:], "\n")
+	if fences := len(fenceLineRegex.FindAllStringIndex(preview, -1)); fences%2 == 1 {
+		preview += "\n```"
+	}
+	return preview, hidden, true
+}
+
+// nthIndex returns the index of the nth occurrence of c in s, or -1.
+func nthIndex(s string, c byte, n int) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			n--
+			if n == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// spoolNote is shown below the preview of a long reply.
+func spoolNote(hidden, size int, spool string) string {
+	if spool != "" {
+		return i18n.T("spool.more_saved", hidden, formatBytes(size), spool)
+	}
+	return i18n.T("spool.more", hidden, formatBytes(size))
+}
+
+// spoolReply saves the last message to a file in the scratch directory if
+// it is an answer too long to show, so /open-full can open it.
+func (m *Model) spoolReply() {
+	i := len(m.messages) - 1
+	if i < 0 || m.messages[i].Role != "assistant" || m.messages[i].Spool != "" {
+		return
+	}
+	if _, _, long := spoolPreview(m.messages[i].Content); !long {
+		return
+	}
+	if path, err := m.writeSpool(m.messages[i].Content); err == nil {
+		m.messages[i].Spool = path
+		m.viewport.SetContent(m.renderMessages())
+	} else {
+		m.logger.Log("Could not spool the reply: " + err.Error())
+	}
+}
+
+// writeSpool writes a reply to a new file in the scratch directory, or in
+// the temp directory without one.
+func (m *Model) writeSpool(content string) (string, error) {
+	dir := m.agent.ScratchDir()
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "promptcli")
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", err
+		}
+	}
+	f, err := os.CreateTemp(dir, "reply-*.md")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// handleOpenFull implements "/open-full [N]", which opens the whole text of
+// the last long reply, or of message #N, in the editor. A reply still
+// streaming is opened as far as it got.
+func (m *Model) handleOpenFull(args string) tea.Cmd {
+	target := -1
+	if args != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(args, "#"))
+		if err != nil || n < 0 || n >= len(m.messages) || m.messages[n].Role != "assistant" {
+			m.showError(i18n.T("spool.unknown", args))
+			return nil
+		}
+		target = n
+	} else {
+		for i := len(m.messages) - 1; i >= 0; i-- {
+			if _, _, long := spoolPreview(m.messages[i].Content); long && m.messages[i].Role == "assistant" {
+				target = i
+				break
+			}
+		}
+		if target < 0 {
+			m.showStatus(i18n.T("spool.none"))
+			return nil
+		}
+	}
+
+	// A reply still streaming is saved to a temporary file each time.
+	msg := &m.messages[target]
+	streaming := m.streaming && target == len(m.messages)-1
+	path := msg.Spool
+	if _, err := os.Stat(path); path == "" || err != nil || streaming {
+		written, err := m.writeSpool(msg.Content)
+		if err != nil {
+			m.showError(i18n.T("spool.failed", err))
+			return nil
+		}
+		path = written
+		if !streaming {
+			msg.Spool = path
+		}
+	}
+	return tea.ExecProcess(m.editorProcess(path), func(err error) tea.Msg {
+		return openFinishedMsg{path: path, temporary: streaming, err: err}
+	})
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// heartbeatInterval is how often a running reply is checked for a stall.
+const heartbeatInterval = time.Second
+
+// heartbeatMsg triggers the next stall check.
+type heartbeatMsg struct{}
+
+func heartbeat() tea.Cmd {
+	return tea.Tick(heartbeatInterval, func(time.Time) tea.Msg {
+		return heartbeatMsg{}
+	})
+}
+
+// stallTimeout is how long a reply may go without a chunk before it counts
+// as stalled, or 0 if the check is off.
+func (m *Model) stallTimeout() time.Duration {
+	if m.config == nil || m.config.StallTimeoutMs <= 0 {
+		return 0
+	}
+	return time.Duration(m.config.StallTimeoutMs) * time.Millisecond
+}
+
+// checkStall offers to retry or cancel a reply that has not sent anything
+// for the stall timeout, which happens when Ollama hangs, instead of
+// waiting forever.
+func (m *Model) checkStall() tea.Cmd {
+	timeout := m.stallTimeout()
+	if m.streaming && !m.stalled && timeout > 0 && !m.lastChunk.IsZero() && time.Since(m.lastChunk) > timeout {
+		m.stalled = true
+		m.logger.Log("No chunk from Ollama for " + timeout.String() + "; the reply looks stalled.")
+	}
+	return heartbeat()
+}
+
+// handleStallKey acts on the choice in the stall warning.
+func (m *Model) handleStallKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch strings.ToLower(msg.String()) {
+	case "r":
+		return m, m.retryStalled()
+	case "c", "ctrl+c":
+		m.cancelStalled()
+		m.showStatus(i18n.T("stall.canceled"))
+		return m, m.textarea.Focus()
+	case "w", "esc":
+		m.stalled = false
+		m.lastChunk = time.Now()
+	}
+	return m, nil
+}
+
+// cancelStalled stops the stalled reply, keeping what arrived of it.
+func (m *Model) cancelStalled() {
+	m.endStream()
+	m.queued = nil
+	last := &m.messages[len(m.messages)-1]
+	if partial := strings.TrimSpace(m.postProcess.Clean(m.streamText)); partial != "" {
+		last.Content = partial
+	} else if last.Role == "assistant" && len(last.ToolCalls) == 0 {
+		m.messages = m.messages[:len(m.messages)-1]
+	}
+	m.streamText = ""
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// retryStalled cancels the stalled reply and sends the same request again.
+func (m *Model) retryStalled() tea.Cmd {
+	m.logger.Log("Retrying the stalled request.")
+	m.endStream()
+	ctx, _ := m.beginStream()
+	m.messages[len(m.messages)-1].Content = ""
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m.startStream(ctx)
+}
+
+// stallView renders the stall warning.
+func (m *Model) stallView() string {
+	return i18n.T("stall.prompt", time.Since(m.lastChunk).Round(time.Second)) + "\n\n" + i18n.T("stall.options")
+}
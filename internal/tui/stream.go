@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamEvent wraps a message of a streamed reply with the generation of
+// the stream it belongs to. Each stream gets a new generation, so events
+// of one that was canceled, e.g. by /stop or a stall retry, are dropped
+// instead of being mixed into the next reply.
+type streamEvent struct {
+	gen uint64
+	msg interface{}
+}
+
+// beginStream moves the conversation from idle to streaming and returns
+// the context of the new request. It refuses, with ok false, while another
+// request is running: all replies go through here, so a tool result and a
+// prompt sent at the same moment cannot start two streams that write into
+// the same message.
+func (m *Model) beginStream() (ctx context.Context, ok bool) {
+	if m.sending {
+		m.logger.Log("Refusing to start a request while another one is running.")
+		return nil, false
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.sending = true
+	m.streaming = true
+	m.streamText = ""
+	m.newBelow = false
+	m.stream = make(chan interface{})
+	m.streamGen++
+	m.lastChunk = time.Now()
+	return ctx, true
+}
+
+// startStream sends the conversation to the model on the stream opened by
+// beginStream.
+func (m *Model) startStream(ctx context.Context) tea.Cmd {
+	m.ollamaClient.StartStream(ctx, m.activeModel(), m.requestMessages(), m.requestOptions(), m.stream, m.wg)
+	return m.waitForStream()
+}
+
+// endStream moves the conversation back to idle. A reply still running is
+// canceled and whatever it sends afterwards is ignored.
+func (m *Model) endStream() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.streaming = false
+	m.sending = false
+	m.stalled = false
+	m.streamGen++
+	// Chunks of a canceled reply still in flight are never processed, so
+	// they must not hold up the wait for the next reply.
+	m.wg = &sync.WaitGroup{}
+}
+
+// followStream shows the latest text of the reply. The viewport only
+// follows it to the bottom if it was there: while the user scrolls back to
+// read, it stays put and the footer says that new content arrived below.
+// Scrolling back to the bottom resumes following.
+func (m *Model) followStream() {
+	atBottom := m.viewport.AtBottom()
+	m.viewport.SetContent(m.renderMessages())
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+	m.newBelow = !atBottom
+}
+
+// waitForStream reads the next message of the current stream.
+func (m *Model) waitForStream() tea.Cmd {
+	stream, gen := m.stream, m.streamGen
+	return func() tea.Msg {
+		msg, ok := <-stream
+		if !ok {
+			return nil
+		}
+		return streamEvent{gen: gen, msg: msg}
+	}
+}
+
+// refuseBusy tells the user that a request could not start because
+// another one is running.
+func (m *Model) refuseBusy(what string) {
+	m.logger.Log(fmt.Sprintf("Dropped %s: a request is running.", what))
+	m.showError(i18n.T("stream.busy"))
+}
@@ -0,0 +1,263 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"prompt-cli/internal/i18n"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// structuredAnswer is a JSON schema the next answer must follow, set with
+// /json.
+type structuredAnswer struct {
+	source string          // File name or "inline".
+	raw    json.RawMessage // Sent as "format".
+	schema map[string]interface{}
+	sent   bool // The request carrying the schema is running.
+}
+
+// handleJSON implements "/json <schema> [prompt]", which constrains the next
+// answer to a JSON schema given as a file or inline, and "/json off". With a
+// prompt it is sent right away.
+func (m *Model) handleJSON(args string) tea.Cmd {
+	switch args {
+	case "":
+		if m.structured == nil {
+			m.showStatus(i18n.T("json.usage"))
+		} else {
+			m.showStatus(i18n.T("json.pending", m.structured.source))
+		}
+		return nil
+	case "off":
+		m.structured = nil
+		m.showStatus(i18n.T("json.off"))
+		return nil
+	}
+	if features := m.ollamaClient.Features(); !features.HasStructuredOutputs() {
+		m.showError(i18n.T("json.unsupported", features.Version))
+		return nil
+	}
+
+	source, raw, prompt := "inline", []byte(nil), ""
+	if strings.HasPrefix(args, "{") {
+		decoder := json.NewDecoder(strings.NewReader(args))
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			m.showError(i18n.T("json.invalid_schema", source, err))
+			return nil
+		}
+		raw, prompt = value, strings.TrimSpace(args[decoder.InputOffset():])
+	} else {
+		path, rest, _ := strings.Cut(args, " ")
+		content, err := m.agent.ReadWorkspaceFile(path)
+		if err != nil {
+			m.showError(i18n.T("json.read_failed", path, err))
+			return nil
+		}
+		source, raw, prompt = path, content, strings.TrimSpace(rest)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		m.showError(i18n.T("json.invalid_schema", source, err))
+		return nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		m.showError(i18n.T("json.invalid_schema", source, err))
+		return nil
+	}
+	m.structured = &structuredAnswer{source: source, raw: compact.Bytes(), schema: schema}
+	if prompt == "" {
+		m.showStatus(i18n.T("json.set", source))
+		return nil
+	}
+	return m.guardedSend(prompt, m.expandMentions(prompt))
+}
+
+// requestFormat returns the schema to send with a new prompt, if any, and
+// marks it as sent.
+func (m *Model) requestFormat() json.RawMessage {
+	if m.structured == nil {
+		return nil
+	}
+	m.structured.sent = true
+	return m.structured.raw
+}
+
+// finishStructured validates the answer to a /json prompt against the
+// schema and shows it pretty-printed. The schema only applies to that one
+// answer.
+func (m *Model) finishStructured(content string) {
+	s := m.structured
+	m.structured = nil
+	m.ollamaClient.SetFormat(nil)
+
+	msg := &m.messages[len(m.messages)-1]
+	msg.Content = content
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, []byte(strings.TrimSpace(content)), "", "  "); err != nil {
+		msg.DisplayContent = content + "\n\n" + i18n.T("json.not_json", err)
+		return
+	}
+	msg.Content = pretty.String()
+
+	var value interface{}
+	json.Unmarshal(pretty.Bytes(), &value)
+	var problems []string
+	validateJSON(s.schema, value, "$", &problems)
+	result := i18n.T("json.valid", s.source)
+	if len(problems) > 0 {
+		result = i18n.T("json.invalid", s.source) + "\n\n- " + strings.Join(problems, "\n- ")
+	}
+	msg.DisplayContent = fence(msg.Content, "json") + "\n\n" + result
+}
+
+// validateJSON checks value against the commonly used part of JSON Schema –
+// type, enum, const, object properties, array items and the length and
+// range limits – and appends what does not match, prefixed with its path.
+// Other keywords, such as $ref or anyOf, are not checked.
+func validateJSON(schema interface{}, value interface{}, path string, problems *[]string) {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		if allowed, isBool := schema.(bool); isBool && !allowed {
+			*problems = append(*problems, path+": not allowed")
+		}
+		return
+	}
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, value) {
+		report("expected %s, got %s", typeNames(t), jsonType(value))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			found = found || reflect.DeepEqual(option, value)
+		}
+		if !found {
+			report("%v is not one of %v", value, enum)
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		report("expected %v", c)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := s["properties"].(map[string]interface{})
+		if required, ok := s["required"].([]interface{}); ok {
+			for _, name := range required {
+				if key, ok := name.(string); ok {
+					if _, present := v[key]; !present {
+						report("missing required property %q", key)
+					}
+				}
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := properties[key]; ok {
+				validateJSON(property, v[key], path+"."+key, problems)
+			} else if additional, ok := s["additionalProperties"]; ok {
+				validateJSON(additional, v[key], path+"."+key, problems)
+			}
+		}
+	case []interface{}:
+		if limit, ok := s["minItems"].(float64); ok && float64(len(v)) < limit {
+			report("%d items, at least %v required", len(v), limit)
+		}
+		if limit, ok := s["maxItems"].(float64); ok && float64(len(v)) > limit {
+			report("%d items, at most %v allowed", len(v), limit)
+		}
+		if items, ok := s["items"]; ok {
+			for i, item := range v {
+				validateJSON(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if limit, ok := s["minLength"].(float64); ok && length < limit {
+			report("shorter than %v characters", limit)
+		}
+		if limit, ok := s["maxLength"].(float64); ok && length > limit {
+			report("longer than %v characters", limit)
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				report("does not match %s", pattern)
+			}
+		}
+	case float64:
+		if limit, ok := s["minimum"].(float64); ok && v < limit {
+			report("%v is less than %v", v, limit)
+		}
+		if limit, ok := s["maximum"].(float64); ok && v > limit {
+			report("%v is more than %v", v, limit)
+		}
+	}
+}
+
+// matchesType reports whether value has the schema type t, a name or a list
+// of names.
+func matchesType(t interface{}, value interface{}) bool {
+	names, ok := t.([]interface{})
+	if !ok {
+		names = []interface{}{t}
+	}
+	actual := jsonType(value)
+	for _, name := range names {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeNames formats the schema type t for messages.
+func typeNames(t interface{}) string {
+	if names, ok := t.([]interface{}); ok {
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprint(name)
+		}
+		return strings.Join(parts, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/session"
+	"prompt-cli/internal/types"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// usage accumulates the numbers reported in the exit summary.
+type usage struct {
+	started   time.Time
+	turns     int
+	toolCalls map[string]int
+	tools     map[string]*toolStat // Failures and durations per tool, see /toolstats.
+	tokensIn  int
+	tokensOut int
+	genTime   time.Duration
+	modified  []string // Files changed by tools, in first-change order.
+}
+
+func newUsage() usage {
+	return usage{started: time.Now(), toolCalls: make(map[string]int), tools: make(map[string]*toolStat)}
+}
+
+// recordReply adds the token counts of a finished reply.
+func (m *Model) recordReply(msg types.StreamDoneMsg) {
+	m.usage.tokensIn += msg.PromptTokens
+	m.usage.tokensOut += msg.OutputTokens
+	m.usage.genTime += msg.Duration
+}
+
+// recordToolCall counts a tool call, with its outcome and how long it
+// took, and remembers files it changed.
+func (m *Model) recordToolCall(toolName string, input map[string]interface{}, result types.ToolResult) {
+	m.usage.toolCalls[toolName]++
+	stat := m.usage.tools[toolName]
+	if stat == nil {
+		stat = &toolStat{}
+		m.usage.tools[toolName] = stat
+	}
+	stat.calls++
+	stat.elapsed += time.Duration(result.DurationMs) * time.Millisecond
+	if result.Failed() {
+		stat.failures++
+	}
+	if result.Failed() {
+		return
+	}
+	for _, file := range writtenFiles(toolName, input) {
+		path := file.Path
+		if !file.Delete {
+			m.lastWritten = path
+		} else if m.lastWritten == path {
+			m.lastWritten = ""
+		}
+		if resolved, err := m.agent.ResolvePath(path); err == nil {
+			path = m.agent.DisplayPath(resolved)
+		}
+		m.recordArtifact(path)
+		if !slices.Contains(m.usage.modified, path) {
+			m.usage.modified = append(m.usage.modified, path)
+		}
+	}
+}
+
+// quit ends the program after recording the run's summary in the session
+// file. The summary is printed by ExitSummary once the TUI has closed.
+func (m *Model) quit() (tea.Model, tea.Cmd) {
+	if m.usage.turns > 0 {
+		summary := session.Summary{
+			Started:        m.usage.started,
+			Ended:          time.Now(),
+			Model:          m.modelName,
+			Turns:          m.usage.turns,
+			ToolCalls:      m.usage.toolCalls,
+			TokensIn:       m.usage.tokensIn,
+			TokensOut:      m.usage.tokensOut,
+			GenerationTime: m.usage.genTime,
+			FilesModified:  m.usage.modified,
+		}
+		m.exitSummary = summary.Format()
+		if m.session != nil {
+			m.session.Summaries = append(m.session.Summaries, summary)
+			m.saveSession()
+		}
+	}
+	return m, tea.Quit
+}
+
+// ExitSummary returns the session summary to print after the TUI exits, or
+// "" if nothing was sent.
+func (m *Model) ExitSummary() string {
+	if m.exitSummary == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n%s", m.exitSummary)
+}
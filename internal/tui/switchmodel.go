@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/ollama"
+	"prompt-cli/internal/types"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// modelsLoadedMsg carries the model list fetched for /model.
+type modelsLoadedMsg struct {
+	models       []types.Model
+	capabilities map[string]ollama.Capabilities
+	err          error
+}
+
+// modelDetailsMsg carries the /api/show data of the model being switched
+// to.
+type modelDetailsMsg struct {
+	name    string
+	details *types.ShowModelResponse
+	entry   types.Model // The model's /api/tags entry, for its size.
+	err     error
+}
+
+// handleModel implements "/model", which opens the picker, and
+// "/model <name>", which switches directly.
+func (m *Model) handleModel(args string) tea.Cmd {
+	if args != "" {
+		return m.loadModelDetails(args)
+	}
+	apiURL, logger := m.apiURL, m.logger
+	return func() tea.Msg {
+		models, err := ollama.GetModels(apiURL, logger)
+		if err != nil {
+			return modelsLoadedMsg{err: err}
+		}
+		return modelsLoadedMsg{models: models, capabilities: ollama.ListCapabilities(apiURL, models)}
+	}
+}
+
+// handleModelsLoaded opens the picker once the model list arrived.
+func (m *Model) handleModelsLoaded(msg modelsLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.showError(i18n.T("picker.list_failed", msg.err))
+		return nil
+	}
+	m.picker = newModelPicker(msg.models, msg.capabilities, m.modelName)
+	m.textarea.Blur()
+	return textinput.Blink
+}
+
+// handlePickerKey forwards keys to the open picker.
+func (m *Model) handlePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choice, done, cmd := m.picker.handleKey(msg)
+	if !done {
+		return m, cmd
+	}
+	m.picker = nil
+	m.focused = focusTextarea
+	cmds := []tea.Cmd{m.textarea.Focus(), tea.ClearScreen}
+	if choice != "" && choice != m.modelName {
+		cmds = append(cmds, m.loadModelDetails(choice))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// loadModelDetails fetches the details and list entry of a model before
+// switching to it; this also checks that the model exists.
+func (m *Model) loadModelDetails(name string) tea.Cmd {
+	apiURL, logger := m.apiURL, m.logger
+	return func() tea.Msg {
+		details, err := ollama.GetModelDetails(apiURL, name)
+		if err != nil {
+			return modelDetailsMsg{name: name, err: err}
+		}
+		entry := types.Model{Name: name, Details: details.Details}
+		if models, err := ollama.GetModels(apiURL, logger); err == nil {
+			for _, model := range models {
+				if model.Name == name {
+					entry = model
+				}
+			}
+		}
+		return modelDetailsMsg{name: name, details: details, entry: entry}
+	}
+}
+
+// handleModelDetails switches the conversation to the new model. The
+// transcript is kept; capabilities and the context limit are re-read.
+func (m *Model) handleModelDetails(msg modelDetailsMsg) {
+	if msg.err != nil {
+		m.showError(i18n.T("picker.switch_failed", msg.name, msg.err))
+		return
+	}
+	caps := ollama.ParseCapabilities(msg.details.Capabilities)
+	if !caps.CanChat() {
+		m.showError(i18n.T("model.embedding_only", msg.name))
+		return
+	}
+
+	m.modelName = msg.name
+	m.session.Model = msg.name
+	m.modelEntry = msg.entry
+	m.updatePostProcess()
+	m.modelContextSize = m.config.ContextLength
+	m.showStatus(i18n.T("picker.switched", msg.name))
+	m.SetCapabilities(caps)
+	m.SetModelMaxContext(ollama.ExtractContextLength(msg.details.ModelInfo))
+	if m.modelMaxContext > 0 && m.numCtxOverride > m.modelMaxContext {
+		m.numCtxOverride = m.modelMaxContext
+		m.session.NumCtx = m.numCtxOverride
+	}
+	m.saveSession()
+}
+
+// SetModelEntry records the /api/tags entry of the selected model, whose
+// details are shown in the footer.
+func (m *Model) SetModelEntry(entry types.Model) {
+	m.modelEntry = entry
+	m.updatePostProcess()
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxPanelTasks is how many items the task panel lists before it only
+// counts the rest.
+const maxPanelTasks = 8
+
+var (
+	taskPanelStyle = lipgloss.NewStyle().Border(lipgloss.NormalBorder(), false, false, true, false).BorderForeground(lipgloss.Color("62"))
+	taskDoneStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	taskOpenStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+)
+
+// taskPanel renders the agent's task_list as a checklist shown between the
+// transcript and the input while any item is open, or "" if there is none.
+func (m *Model) taskPanel() string {
+	tasks := m.agent.Tasks()
+	done := 0
+	for _, task := range tasks {
+		if task.Status == types.TaskDone {
+			done++
+		}
+	}
+	if done == len(tasks) {
+		return ""
+	}
+
+	// Skip finished items at the top so the open ones stay in view.
+	start := 0
+	for start < len(tasks)-maxPanelTasks && tasks[start].Status == types.TaskDone {
+		start++
+	}
+	lines := []string{i18n.T("tasks.title", done, len(tasks))}
+	shown := tasks[start:min(len(tasks), start+maxPanelTasks)]
+	for _, task := range shown {
+		switch task.Status {
+		case types.TaskDone:
+			lines = append(lines, taskDoneStyle.Render("✔ "+task.Text))
+		case types.TaskInProgress:
+			lines = append(lines, taskOpenStyle.Render("▶ "+task.Text))
+		default:
+			lines = append(lines, "○ "+task.Text)
+		}
+	}
+	if hidden := len(tasks) - len(shown); hidden > 0 {
+		lines = append(lines, footerStyle.Render(i18n.T("tasks.more", hidden)))
+	}
+	width := max(m.viewport.Width, 1)
+	for i, line := range lines {
+		lines[i] = truncateLine(line, width)
+	}
+	return taskPanelStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// truncateLine cuts a rendered line to width cells.
+func truncateLine(line string, width int) string {
+	if lipgloss.Width(line) <= width {
+		return line
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(line)
+}
+
+// panels returns the panels shown between the transcript and the input:
+// the task list and the queued prompts.
+func (m *Model) panels() []string {
+	var panels []string
+	for _, panel := range []string{m.taskPanel(), m.queuePanel()} {
+		if panel != "" {
+			panels = append(panels, panel)
+		}
+	}
+	return panels
+}
+
+// layout sizes the transcript to what the input, the footer and the panels
+// leave of the window.
+func (m *Model) layout() {
+	if m.height == 0 {
+		return
+	}
+	occupied := lipgloss.Height(m.textarea.View()) + 1 // +1 for the footer
+	for _, panel := range m.panels() {
+		occupied += lipgloss.Height(panel)
+	}
+	height := max(1, m.height-occupied)
+	if height == m.viewport.Height {
+		return
+	}
+	atBottom := m.viewport.AtBottom()
+	m.viewport.Height = height
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"time"
+)
+
+// timestampModes are the settings of /timestamps, in the order it cycles
+// through them.
+var timestampModes = []string{"off", "absolute", "relative"}
+
+// addMessage appends a message to the conversation with the time it was
+// added.
+func (m *Model) addMessage(msg types.Message) {
+	msg.Time = time.Now()
+	m.messages = append(m.messages, msg)
+}
+
+// timeLabel is the time shown after the role in a message header, "" when
+// timestamps are off or the message has none.
+func (m *Model) timeLabel(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	switch m.timestamps {
+	case "absolute":
+		return " · " + absoluteTime(t, time.Now())
+	case "relative":
+		return " · " + relativeTime(t, time.Now())
+	}
+	return ""
+}
+
+// absoluteTime shows the time of day for today and the date before that.
+func absoluteTime(t, now time.Time) string {
+	t = t.Local()
+	if y, m, d := t.Date(); y == now.Year() && m == now.Month() && d == now.Day() {
+		return t.Format("15:04:05")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// relativeTime describes how long ago t was, in minutes, hours or days.
+func relativeTime(t, now time.Time) string {
+	age := now.Sub(t)
+	switch {
+	case age < time.Minute:
+		return i18n.T("time.just_now")
+	case age < time.Hour:
+		return i18n.T("time.minutes_ago", int(age/time.Minute))
+	case age < 24*time.Hour:
+		return i18n.T("time.hours_ago", int(age/time.Hour))
+	}
+	return i18n.T("time.days_ago", int(age/(24*time.Hour)))
+}
+
+// handleTimestamps implements "/timestamps [off|absolute|relative]", which
+// sets how message headers show the time. Without an argument it switches
+// to the next mode.
+func (m *Model) handleTimestamps(args string) {
+	mode := args
+	if mode == "" {
+		mode = timestampModes[0]
+		for i, name := range timestampModes {
+			if name == m.timestamps {
+				mode = timestampModes[(i+1)%len(timestampModes)]
+			}
+		}
+	}
+	switch mode {
+	case "off", "absolute", "relative":
+	default:
+		m.showError(i18n.T("timestamps.usage"))
+		return
+	}
+	m.timestamps = mode
+	m.showStatus(i18n.T("timestamps." + mode))
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"sort"
+	"strings"
+	"time"
+)
+
+// toolStatBarWidth is the width of the time share bar in /toolstats.
+const toolStatBarWidth = 12
+
+// toolStat accumulates the calls of one tool for /toolstats.
+type toolStat struct {
+	calls    int
+	failures int
+	elapsed  time.Duration
+}
+
+// handleToolStats implements "/toolstats", a table of the tools called in
+// this session with their failure rate and duration. The bar shows each
+// tool's share of the total tool time, so tools the model leans on too much
+// stand out.
+func (m *Model) handleToolStats() {
+	if len(m.usage.tools) == 0 {
+		m.showStatus(i18n.T("toolstats.none"))
+		return
+	}
+	names := make([]string, 0, len(m.usage.tools))
+	var calls, failures int
+	var total time.Duration
+	for name, stat := range m.usage.tools {
+		names = append(names, name)
+		calls += stat.calls
+		failures += stat.failures
+		total += stat.elapsed
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := m.usage.tools[names[i]], m.usage.tools[names[j]]
+		if a.elapsed != b.elapsed {
+			return a.elapsed > b.elapsed
+		}
+		return names[i] < names[j]
+	})
+
+	var builder strings.Builder
+	builder.WriteString(i18n.T("toolstats.title", calls, len(names), failures) + "\n\n")
+	builder.WriteString(i18n.T("toolstats.header") + "\n|---|--:|--:|--:|--:|---|\n")
+	for _, name := range names {
+		stat := m.usage.tools[name]
+		share := 0.0
+		if total > 0 {
+			share = float64(stat.elapsed) / float64(total)
+		}
+		bar := strings.Repeat("█", int(share*toolStatBarWidth+0.5))
+		if bar == "" {
+			bar = "▏"
+		}
+		builder.WriteString(fmt.Sprintf("| `%s` | %d | %d%% | %s | %s | %s %d%% |\n",
+			name, stat.calls, stat.failures*100/stat.calls,
+			(stat.elapsed / time.Duration(stat.calls)).Round(time.Millisecond),
+			stat.elapsed.Round(time.Millisecond), bar, int(share*100+0.5)))
+	}
+	m.showStatus(builder.String())
+}
@@ -0,0 +1,32 @@
+package tui
+
+import "fmt"
+
+// logTranscript appends the messages completed since the last call to the
+// session's JSONL transcript log when transcript_log is on. Like announce,
+// it skips the reply still being streamed, and like saveSession it waits
+// until the user has said something.
+func (m *Model) logTranscript() {
+	if !m.config.TranscriptLog || m.session == nil || !m.hasUserMessage() {
+		return
+	}
+	if m.loggedSession != m.session.ID {
+		m.loggedSession = m.session.ID
+		m.loggedMessages = 0
+	}
+	if m.loggedMessages > len(m.messages) {
+		m.loggedMessages = len(m.messages)
+	}
+	end := len(m.messages)
+	if m.streaming && end > 0 && m.messages[end-1].Role == "assistant" {
+		end--
+	}
+	if end <= m.loggedMessages {
+		return
+	}
+	if err := m.session.LogTranscript(m.messages[m.loggedMessages:end]); err != nil {
+		m.logger.Log(fmt.Sprintf("Error logging transcript: %v", err))
+		return
+	}
+	m.loggedMessages = end
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"prompt-cli/internal/i18n"
+	"prompt-cli/internal/types"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// translatePrompt instructs the model for a /translate pass.
+const translatePrompt = "You are a translator. Translate the user's message into %s. Keep the Markdown formatting, and leave code blocks, inline code, identifiers, file paths and URLs unchanged. Reply with the translation only."
+
+// respondLanguageRule is added to the system prompt when respond_language
+// is set.
+const respondLanguageRule = "Always write your answers to the user in %s, whatever language the user writes in. Keep code, identifiers, file paths and tool names unchanged."
+
+// translatedMsg carries the result of a /translate pass.
+type translatedMsg struct {
+	language string
+	text     string
+	err      error
+}
+
+// handleTranslate implements "/translate [language]". The last answer is
+// sent to the model again with a translation prompt, outside of the
+// conversation. Without a language the configured respond_language is used.
+func (m *Model) handleTranslate(args string) tea.Cmd {
+	language := args
+	if language == "" {
+		language = m.config.RespondLanguage
+	}
+	if language == "" {
+		m.showError(i18n.T("translate.usage"))
+		return nil
+	}
+	answer := m.lastAnswer()
+	if answer == "" {
+		m.showStatus(i18n.T("translate.nothing"))
+		return nil
+	}
+	if m.translating {
+		return nil
+	}
+
+	m.translating = true
+	client, model, options := m.ollamaClient, m.modelName, m.requestOptions()
+	messages := []types.Message{
+		{Role: "system", Content: fmt.Sprintf(translatePrompt, language)},
+		{Role: "user", Content: answer},
+	}
+	return func() tea.Msg {
+		resp, err := client.Chat(context.Background(), model, messages, options)
+		return translatedMsg{language: language, text: resp.Message.Content, err: err}
+	}
+}
+
+// handleTranslated shows the translation below the original answer. It is
+// local to the UI and never sent to the model.
+func (m *Model) handleTranslated(msg translatedMsg) {
+	m.translating = false
+	if msg.err != nil {
+		m.showError(i18n.T("translate.failed", msg.err))
+		return
+	}
+	m.addMessage(types.Message{
+		Role:    "translation",
+		Content: fmt.Sprintf("*%s*\n\n%s", msg.language, strings.TrimSpace(m.postProcess.Clean(msg.text))),
+		Local:   true,
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// lastAnswer returns the text of the model's most recent reply. Status
+// lines shown by the UI have no raw reply and are skipped.
+func (m *Model) lastAnswer() string {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		msg := m.messages[i]
+		if msg.Role == "assistant" && msg.Raw != nil && msg.Content != "" && !msg.IsError {
+			return msg.Content
+		}
+	}
+	return ""
+}
@@ -0,0 +1,24 @@
+package tui
+
+import (
+	"prompt-cli/internal/i18n"
+	"strings"
+)
+
+// handleUndo implements "/undo", which reverts the last changeset the agent
+// applied, all files at once, or restores the file it deleted last from the
+// session trash, whichever came later.
+func (m *Model) handleUndo() {
+	undone, ok, err := m.agent.UndoLast()
+	switch {
+	case !ok:
+		m.showStatus(i18n.T("undo.empty"))
+	case err != nil:
+		m.showError(i18n.T("undo.failed", err))
+	case undone.Changeset:
+		m.updateFileList()
+		m.showStatus(i18n.T("undo.changeset", len(undone.Paths), strings.Join(undone.Paths, ", ")))
+	default:
+		m.showStatus(i18n.T("undo.restored", undone.Paths[0]))
+	}
+}
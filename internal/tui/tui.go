@@ -3,24 +3,30 @@ package tui
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math/rand"
-	"os"
+	"os/exec"
 	"prompt-cli/internal/agent"
+	"prompt-cli/internal/config"
+	"prompt-cli/internal/i18n"
 	"prompt-cli/internal/logger"
 	"prompt-cli/internal/ollama"
+	"prompt-cli/internal/persona"
+	"prompt-cli/internal/postprocess"
+	"prompt-cli/internal/runner"
+	"prompt-cli/internal/session"
 	"prompt-cli/internal/types"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -65,41 +71,111 @@ const (
 )
 
 type Model struct {
-	viewport          viewport.Model
-	textarea          textarea.Model
-	messages          []types.Message
-	modelName         string
-	modelContextSize  int64 // Store context window size
-	sending           bool
-	err               error
-	stats             string
-	focused           focusable
-	streaming         bool
-	stream            chan interface{}
-	cancel            context.CancelFunc
-	fileSearchActive  bool
-	fileSearchTerm    string
-	fileSearchResult  string
-	files             []string
-	spinner           spinner.Model
-	wg                *sync.WaitGroup
-	logger            *logger.Logger
-	agent             *agent.Agent
-	ollamaClient      *ollama.OllamaClient
-	history           []string
-	historyCursor     int
-	ctrlCpressed      bool
-	currentJoke       string
-	permissionRequest *types.Action   // Stores the command that needs permission. If nil, not waiting.
-	alwaysAllow       map[string]bool // Stores permissions for "Always Allow". Key combines toolName and relevant path.
-	yoloMode          bool            // When true, bypasses all permission checks.
-	isJsonResponse    bool            // Flag to indicate if the current stream is a JSON response
-}
-
-func NewModel(apiURL, modelName string, contextSize int64, systemPrompt string, logEnabled bool, logger *logger.Logger, agent *agent.Agent, ollamaClient *ollama.OllamaClient) *Model {
+	config              *config.Config
+	apiURL              string
+	viewport            viewport.Model
+	textarea            textarea.Model
+	messages            []types.Message
+	modelName           string
+	modelContextSize    int64       // Store context window size
+	modelMaxContext     int64       // Longest context the model supports, 0 if unknown.
+	modelEntry          types.Model // The model's /api/tags entry, see SetModelEntry.
+	numCtxOverride      int64       // Per-session num_ctx set with /ctx, 0 for none.
+	sending             bool
+	err                 error
+	stats               string
+	focused             focusable
+	streaming           bool
+	stream              chan interface{}
+	cancel              context.CancelFunc
+	fileSearchActive    bool
+	fileSearchTerm      string
+	fileSearchResult    string
+	files               []string
+	spinner             spinner.Model
+	wg                  *sync.WaitGroup
+	logger              *logger.Logger
+	agent               *agent.Agent
+	ollamaClient        *ollama.OllamaClient
+	history             []string
+	historyCursor       int
+	prompts             []string // Every input of the session, oldest first, for "!N".
+	ctrlCpressed        bool
+	currentJoke         string
+	permissionRequest   *types.Action        // Stores the command that needs permission. If nil, not waiting.
+	permissionConflict  string               // Set if permissionRequest would overwrite changes made since the agent read the file.
+	batch               []batchItem          // Tool calls from one reply, see startBatch.
+	batchCursor         int                  // Selected entry of the approval list.
+	reviewingBatch      bool                 // Whether the approval list is shown.
+	alwaysAllow         map[string]bool      // Stores permissions for "Always Allow". Key combines toolName and relevant path.
+	yoloScopes          map[string]bool      // Permission scopes approved without asking, see /yolo.
+	permissionRules     []permissionRule     // Saved "always allow" rules for directories and file types, see /permissions.
+	streamText          string               // Raw text of the reply being streamed.
+	translating         bool                 // A /translate pass is running.
+	planning            bool                 // The planner model is writing a plan.
+	plan                *plan                // Plan being carried out, see plan.go.
+	criticMode          string               // "on", "off" or "" to follow the persona and config, see critic.go.
+	reviewing           bool                 // The critic is reviewing the last answer.
+	permissionReview    string               // The critic's review of the change in permissionRequest.
+	multiline           bool                 // Enter adds a line, Alt+Enter sends; see paste.go.
+	postProcess         postprocess.Pipeline // Cleans replies before display, see postprocess.go.
+	touchedFiles        []string             // Files referenced by tool calls, most recent last.
+	lastWritten         string               // File most recently created or changed by a tool, see @last.
+	pendingImages       []string             // Image files attached to the next prompt.
+	pendingContext      []string             // Command output and bundle content attached to the next prompt.
+	session             *session.Session
+	historyMatches      []session.Match // Results of the last /history search.
+	restoredMessages    int             // Messages brought back by /older, kept on top of the retention limit.
+	baseSystemPrompt    string          // System prompt without persona additions.
+	personas            map[string]persona.Persona
+	personaName         string // Active persona, "" for none.
+	accessible          bool   // Screen-reader friendly output, see accessible.go.
+	inline              bool   // No alternate screen, finished messages go to the scrollback; see inline.go.
+	printedMessages     int    // Messages already printed in accessible or inline mode.
+	loggedMessages      int    // Messages already in the transcript log, see transcript.go.
+	loggedSession       string // Session the transcript log belongs to.
+	announcedSending    bool
+	announcedPermission bool
+	announcedBatch      bool
+	usage               usage               // Counters for the exit summary.
+	exitSummary         string              // Printed after the TUI closes, see quit.
+	speakEnabled        bool                // Read finished responses aloud, see speech.go.
+	speechCancel        context.CancelFunc  // Stops the speech in progress.
+	dictation           *exec.Cmd           // Running STT command, see dictation.go.
+	expanded            map[int]bool        // Tool messages unfolded with Enter, by index.
+	messageOffsets      []int               // First viewport line of each rendered message.
+	renderCache         renderCache         // Rendered transcript blocks, see render.go.
+	renderers           renderers           // Glamour renderers reused across renders.
+	renderedWidth       int                 // Viewport width the transcript was last wrapped for.
+	height              int                 // Window height, see layout.
+	resizeSeq           int                 // Latest pending re-render, see resize.go.
+	chatMode            bool                // Agent mode off: chat prompt, no tool calls.
+	capabilities        ollama.Capabilities // What the model supports, see capabilities.go.
+	picker              *modelPicker        // Open /model picker, nil if none.
+	preview             *mentionPreview     // Open @ mention preview, nil if none.
+	queued              []string            // Prompts typed during a reply, sent in order after it.
+	redirecting         bool                // Waiting for a steering note after Ctrl+R.
+	guard               *contextGuard       // Prompt held back because it exceeds num_ctx.
+	compacting          bool                // The model is summarizing older messages.
+	preset              string              // Option set chosen with /preset.
+	structured          *structuredAnswer   // Schema for the next answer, see /json.
+	artifacts           []*artifact         // Files written by tools, in first-change order.
+	pendingArtifacts    []*artifact         // Snapshots of the files the running tool writes.
+	artifactList        *artifactList       // Open /artifacts view, nil if none.
+	changeset           *changesetReview    // apply_changeset call awaiting review, nil if none.
+	lastChunk           time.Time           // When the running reply last sent something.
+	stalled             bool                // The running reply sent nothing for the stall timeout.
+	streamGen           uint64              // Generation of the current stream, see stream.go.
+	pulling             string              // Model being downloaded with /pull.
+	pullStatus          string              // Last progress update of the pull.
+	newBelow            bool                // The reply went on below while the user read further up, see followStream.
+	timestamps          string              // Time shown in message headers: "off", "absolute" or "relative".
+}
+
+func NewModel(apiURL, modelName, systemPrompt string, configs *config.Config, logger *logger.Logger, agent *agent.Agent, ollamaClient *ollama.OllamaClient) *Model {
 	// --- Text Area (Input) ---
 	ta := textarea.New()
-	ta.Placeholder = "Send a message... (Ctrl+V to paste)"
+	ta.Placeholder = i18n.T("input.placeholder")
 	ta.Focus()
 	ta.Prompt = ""
 	ta.SetHeight(3)
@@ -107,6 +183,7 @@ func NewModel(apiURL, modelName string, contextSize int64, systemPrompt string,
 	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
 	ta.ShowLineNumbers = false
 	ta.KeyMap.InsertNewline.SetEnabled(false) // Use Enter to send
+	ta.KeyMap.LineEnd.SetKeys("end")          // Ctrl+E opens the external editor
 
 	// --- Viewport (Chat History) ---
 	vp := viewport.New(80, 20) // Default size, will be updated by WindowSizeMsg
@@ -137,21 +214,16 @@ func NewModel(apiURL, modelName string, contextSize int64, systemPrompt string,
 		Padding(0)                              // Ensure no extra padding that could cause double border effect
 
 	// --- File list ---
-	files, err := os.ReadDir(".")
-	if err != nil {
-		log.Println("could not list files:", err)
-	}
-	var fileNames []string
-	for _, file := range files {
-		fileNames = append(fileNames, file.Name())
-	}
+	fileNames := agent.WorkspaceFiles()
 
 	m := &Model{
+		config:           configs,
+		apiURL:           apiURL,
 		textarea:         ta,
 		viewport:         vp,
 		messages:         []types.Message{{Role: "system", Content: systemPrompt}},
 		modelName:        modelName,
-		modelContextSize: contextSize,
+		modelContextSize: configs.ContextLength,
 		sending:          false,
 		stats:            "",
 		focused:          focusTextarea,
@@ -168,24 +240,139 @@ func NewModel(apiURL, modelName string, contextSize int64, systemPrompt string,
 		history:          []string{},
 		historyCursor:    -1,
 		alwaysAllow:      make(map[string]bool), // Initialize the map
-		yoloMode:         false,                 // Default to false
-		isJsonResponse:   false,
+		yoloScopes:       make(map[string]bool),
+		usage:            newUsage(),
+		expanded:         make(map[int]bool),
+		session:          session.New(modelName),
+		baseSystemPrompt: systemPrompt,
+		personas:         persona.All(configs.Personas),
+		timestamps:       "off",
+	}
+	if configs.Timestamps != "" {
+		m.timestamps = configs.Timestamps
+	}
+
+	m.renderers.lightweight = configs.LowMemoryMode()
+	m.updatePostProcess()
+	m.useSessionDirs()
+	m.loadPermissionRules()
+	if configs.Accessible {
+		m.accessible = true
+		m.applyAccessibleStyles()
+	}
+
+	if name := persona.LoadSelected(); name != "" {
+		if _, ok := m.personas[name]; ok {
+			m.applyPersona(name)
+		}
 	}
 
 	return m
 }
 
 func (m *Model) Init() tea.Cmd {
-	return textarea.Blink
+	return tea.Batch(textarea.Blink, inboxTick(), heartbeat())
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if queuedCmd := m.sendQueued(); queuedCmd != nil {
+		cmd = tea.Batch(cmd, queuedCmd)
+	}
+	m.logTranscript()
+	m.layout()
+	if m.accessible {
+		return model, tea.Batch(cmd, m.announce())
+	}
+	if m.inline {
+		return model, tea.Batch(cmd, m.printFinished())
+	}
+	return model, cmd
+}
+
+func (m *Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		taCmd tea.Cmd
 		vpCmd tea.Cmd
 	)
 
-	// Handle permission request state first
+	// Requests from the automation socket are answered in every state.
+	if msg, ok := msg.(automationMsg); ok {
+		return m.handleAutomation(msg)
+	}
+	if _, ok := msg.(inboxTickMsg); ok {
+		return m, m.checkInbox()
+	}
+	if _, ok := msg.(heartbeatMsg); ok {
+		return m, m.checkStall()
+	}
+	if event, ok := msg.(streamEvent); ok {
+		if event.gen != m.streamGen {
+			return m, nil // From a reply that was canceled.
+		}
+		msg = event.msg
+	}
+	if msg, ok := msg.(criticMsg); ok {
+		return m, m.handleCriticDone(msg)
+	}
+	if msg, ok := msg.(compactedMsg); ok {
+		return m, m.handleCompacted(msg)
+	}
+	if msg, ok := msg.(pullProgressMsg); ok {
+		return m, m.handlePullProgress(msg)
+	}
+	if msg, ok := msg.(pullFinishedMsg); ok {
+		m.handlePullFinished(msg)
+		return m, nil
+	}
+
+	// Handle the model picker, the approval list and permission requests first
+	if m.picker != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.handlePickerKey(msg)
+		}
+		// Let the filter cursor blink; everything else is handled below.
+		var pickerCmd tea.Cmd
+		m.picker.filter, pickerCmd = m.picker.filter.Update(msg)
+		if pickerCmd != nil {
+			return m, pickerCmd
+		}
+	}
+	if m.preview != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.handlePreviewKey(msg)
+		}
+		var previewCmd tea.Cmd
+		m.preview.input, previewCmd = m.preview.input.Update(msg)
+		if previewCmd != nil {
+			return m, previewCmd
+		}
+	}
+	if m.artifactList != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleArtifactsKey(msg)
+		}
+	}
+	if m.changeset != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleChangesetKey(msg)
+		}
+	}
+	if m.stalled {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleStallKey(msg)
+		}
+	}
+	if m.guard != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleGuardKey(msg)
+		}
+	}
+	if m.reviewingBatch {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleBatchKey(msg)
+		}
+	}
 	if m.permissionRequest != nil {
 		if msg, ok := msg.(tea.KeyMsg); ok {
 			var focusCmd tea.Cmd
@@ -196,16 +383,33 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "a": // Allow once
 				action := m.permissionRequest
 				m.permissionRequest = nil // Return to normal state
+				m.agent.AcceptChanges(action.Tool, action.Input)
 				model, execCmd := m.executeAndRespond(action.Tool, action.Input)
 				return model, tea.Batch(focusCmd, execCmd, tea.ClearScreen)
 
 			case "y": // Yes to all
 				action := m.permissionRequest
-				if path, ok := action.Input["path"].(string); ok {
-					permissionKey := fmt.Sprintf("%s:%s", action.Tool, path)
+				if permissionKey := m.permissionKey(action); permissionKey != "" {
 					m.alwaysAllow[permissionKey] = true
 				}
 				m.permissionRequest = nil // Return to normal state
+				m.agent.AcceptChanges(action.Tool, action.Input)
+				model, execCmd := m.executeAndRespond(action.Tool, action.Input)
+				return model, tea.Batch(focusCmd, execCmd, tea.ClearScreen)
+
+			case "d", "e": // Always allow for the directory or the file type
+				action := m.permissionRequest
+				dir, ext := m.ruleChoices(action)
+				rule := dir
+				if strings.ToLower(msg.String()) == "e" {
+					rule = ext
+				}
+				if rule.Path == "" {
+					return m, nil
+				}
+				m.permissionRequest = nil // Return to normal state
+				m.addPermissionRule(rule)
+				m.agent.AcceptChanges(action.Tool, action.Input)
 				model, execCmd := m.executeAndRespond(action.Tool, action.Input)
 				return model, tea.Batch(focusCmd, execCmd, tea.ClearScreen)
 
@@ -213,9 +417,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				details := m.renderCommandDetails(m.permissionRequest)
 				deniedMsg := fmt.Sprintf("Command denied by user:\n\n%s", details)
 				m.messages[len(m.messages)-1].Content = deniedMsg
+				m.messages[len(m.messages)-1].Raw = nil
 				m.viewport.SetContent(m.renderMessages())
 				m.viewport.GotoBottom()
 				m.permissionRequest = nil // Return to normal state
+				m.saveSession()
 				return m, tea.Batch(focusCmd, tea.ClearScreen)
 			}
 		}
@@ -229,40 +435,57 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 	case tea.MouseMsg:
-		m.viewport, vpCmd = m.viewport.Update(msg)
-		return m, vpCmd
+		return m, m.handleMouse(msg)
 	case tea.KeyMsg:
 		if m.ctrlCpressed {
 			switch msg.Type {
 			case tea.KeyCtrlC:
-				return m, tea.Quit
+				return m.quit()
 			case tea.KeyEsc:
 				m.ctrlCpressed = false
 				return m, nil
 			}
 		}
 
+		if msg.Paste && m.focused == focusTextarea {
+			m.ctrlCpressed = false
+			return m.handlePaste(msg)
+		}
+
 		switch msg.Type {
 		case tea.KeyCtrlY:
-			m.yoloMode = !m.yoloMode
-			var statusMsg string
-			if m.yoloMode {
-				statusMsg = "YOLO mode enabled. All commands will be executed without permission."
-			} else {
-				statusMsg = "YOLO mode disabled. Destructive commands will require permission."
-			}
-			m.messages = append(m.messages, types.Message{Role: "assistant", Content: statusMsg})
-			m.viewport.SetContent(m.renderMessages())
-			m.viewport.GotoBottom()
+			m.toggleYolo()
 			return m, nil
+		case tea.KeyCtrlT:
+			m.ctrlCpressed = false
+			return m, m.toggleDictation()
+		case tea.KeyCtrlL:
+			m.ctrlCpressed = false
+			if m.focused == focusTextarea {
+				m.insertLastFile()
+				return m, nil
+			}
+		case tea.KeyCtrlE:
+			m.ctrlCpressed = false
+			if m.focused == focusTextarea {
+				return m, m.openEditor()
+			}
+		case tea.KeyCtrlO:
+			m.ctrlCpressed = false
+			if m.focused == focusTextarea {
+				return m, m.openMentionPreview()
+			}
+		case tea.KeyCtrlR:
+			m.ctrlCpressed = false
+			if m.streaming {
+				m.interrupt()
+				return m, nil
+			}
 		case tea.KeyCtrlC:
 			m.ctrlCpressed = true
 			if m.sending {
-				if m.cancel != nil {
-					m.cancel()
-				}
-				m.streaming = false
-				m.sending = false
+				m.queued = nil
+				m.endStream()
 				if len(m.messages) > 0 && m.messages[len(m.messages)-1].Role == "assistant" {
 					m.messages[len(m.messages)-1].Content += "\n\n--- Canceled ---"
 				}
@@ -273,45 +496,94 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyEnter:
 			m.ctrlCpressed = false
 			if m.focused == focusTextarea {
+				if m.multiline && !msg.Alt {
+					return m.handleTextInput(msg)
+				}
 				return m.handleEnter()
 			}
+			return m.toggleFold()
 		case tea.KeyUp, tea.KeyDown:
 			m.ctrlCpressed = false
+			if m.multiline && m.focused == focusTextarea {
+				return m.handleTextInput(msg)
+			}
 			return m.handleArrowKeys(msg)
 		case tea.KeyTab:
 			m.ctrlCpressed = false
 			return m.handleTabKey()
 		case tea.KeyEsc:
 			m.ctrlCpressed = false
+			if m.redirecting {
+				return m, m.redirect("")
+			}
 			return m.handleEscKey()
 		}
 
 		if m.focused == focusTextarea {
 			m.ctrlCpressed = false
 			return m.handleTextInput(msg)
+		} else if m.jumpViewport(msg.String()) {
+			return m, nil
 		} else {
 			m.viewport, vpCmd = m.viewport.Update(msg)
 		}
 
+	case editorFinishedMsg:
+		return m.handleEditorFinished(msg)
+
+	case openFinishedMsg:
+		return m.handleOpenFinished(msg)
+
+	case runFinishedMsg:
+		return m.handleRunFinished(msg)
+
+	case bundleLoadedMsg:
+		return m.handleBundleLoaded(msg)
+
+	case planReadyMsg:
+		return m, m.handlePlanReady(msg)
+	case translatedMsg:
+		m.handleTranslated(msg)
+		return m, nil
+
+	case modelsLoadedMsg:
+		return m, m.handleModelsLoaded(msg)
+
+	case modelDetailsMsg:
+		m.handleModelDetails(msg)
+		return m, nil
+
+	case resizeSettledMsg:
+		m.handleResizeSettled(msg)
+		return m, nil
+
+	case dictationFinishedMsg:
+		return m.handleDictationFinished(msg)
+
+	case speakFinishedMsg:
+		if msg.err != nil {
+			m.showError(i18n.T("speak.failed", msg.err))
+		}
+		return m, nil
+
 	case types.StreamChunkMsg:
 		if m.streaming {
 			if m.currentJoke != "" {
 				m.currentJoke = ""
 			}
 
-			// On the first chunk, determine if this is a JSON response
-			if m.messages[len(m.messages)-1].Content == "" {
-				if strings.Contains(string(msg), `{"version"`) {
-					m.isJsonResponse = true
-				}
-			}
+			m.lastChunk = time.Now()
+			m.stalled = false
 
-			// If it's not a JSON response, stream the text to the UI
-			if !m.isJsonResponse {
-				m.messages[len(m.messages)-1].Content += string(msg)
-				m.viewport.SetContent(m.renderMessages())
-				m.viewport.GotoBottom()
+			// Stream the cleaned text to the UI unless the reply is, or may
+			// still turn out to be, an agent JSON reply.
+			m.streamText += string(msg)
+			if !m.chatMode && m.postProcess.Structured(m.streamText) {
+				m.messages[len(m.messages)-1].Content = ""
+			} else {
+				m.messages[len(m.messages)-1].Content = m.postProcess.Clean(m.streamText)
 			}
+			m.followStream()
 
 			// We still need to process the waitgroup and listen for the next chunk
 			m.wg.Done()
@@ -321,21 +593,43 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case types.StreamDoneMsg:
 		m.wg.Wait() // Wait for all chunks to be processed
 		if m.streaming {
-			m.streaming = false
-			m.sending = false
-			m.isJsonResponse = false // Reset the flag
+			defer m.saveSession()
+			m.endStream()
+			m.streamText = ""
 			m.stats = msg.Stats
+			m.recordReply(msg)
+			m.messages[len(m.messages)-1].Time = time.Now()
 
 			finalMessage := msg.FinalMessage
+			// Keep the reply as generated so the next request repeats it
+			// byte for byte and Ollama can reuse its prompt cache.
+			raw := finalMessage
+			raw.Role = "assistant"
+			m.messages[len(m.messages)-1].Raw = &raw
+			finalMessage.Content = m.postProcess.Clean(finalMessage.Content)
 			var llmAction *types.Action
 
-			// Check for native tool calls first
-			if len(finalMessage.ToolCalls) > 0 {
+			// In chat mode the reply is plain text, even if it looks like JSON.
+			if m.structured != nil && m.structured.sent {
+				m.finishStructured(finalMessage.Content)
+			} else if m.chatMode {
+				m.messages[len(m.messages)-1].Content = finalMessage.Content
+			} else if len(finalMessage.ToolCalls) > 0 {
+				// Check for native tool calls first
 				m.logger.Log("Found native tool_calls.")
-				call := finalMessage.ToolCalls[0]
-				llmAction = &types.Action{
-					Tool:  call.Function.Name,
-					Input: call.Function.Arguments,
+				var actions []types.Action
+				for _, call := range finalMessage.ToolCalls {
+					actions = append(actions, types.Action{Tool: call.Function.Name, Input: call.Function.Arguments})
+				}
+				// Like the "actions" array, a batch drops "respond" calls
+				// and a single call takes the same path as any other.
+				switch tools := toolActions(actions); len(tools) {
+				case 0:
+					llmAction = &actions[0]
+				case 1:
+					llmAction = &tools[0]
+				default:
+					return m.startBatch(tools)
 				}
 			} else if finalMessage.Content != "" {
 				// If no native tool calls, try to parse the content for either a
@@ -344,7 +638,16 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if err == nil {
 					// Attempt to parse as a tool call first
 					var llmResponse types.LLMResponse
-					if err := json.Unmarshal([]byte(jsonStr), &llmResponse); err == nil && llmResponse.Action.Tool != "" {
+					if err := json.Unmarshal([]byte(jsonStr), &llmResponse); err == nil && len(llmResponse.Actions) > 0 && llmResponse.Action.Tool == "" {
+						switch actions := toolActions(llmResponse.Actions); len(actions) {
+						case 0:
+							m.messages[len(m.messages)-1].Content = finalMessage.Content
+						case 1:
+							llmAction = &actions[0]
+						default:
+							return m.startBatch(actions)
+						}
+					} else if err == nil && llmResponse.Action.Tool != "" {
 						if llmResponse.Action.Tool == "respond" {
 							// This is a final answer, not a tool call to execute
 							if msgStr, ok := llmResponse.Action.Input["message"].(string); ok {
@@ -375,53 +678,63 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.messages[len(m.messages)-1].Content = "" // Clear content as ToolCalls is primary
 
 				toolName := llmAction.Tool
-				isDestructive := toolName == "write_file" || toolName == "append_file" || toolName == "delete_file"
-
-				var permissionKey string
-				if path, ok := llmAction.Input["path"].(string); ok {
-					permissionKey = fmt.Sprintf("%s:%s", toolName, path)
+				if p, ok := m.personas[m.personaName]; ok && !p.Allows(toolName) {
+					return m.sendToolResult(agent.ErrorResult(toolName, types.ErrorPermission, fmt.Sprintf("Error: tool '%s' is not allowed for the '%s' persona. Allowed tools: %s, respond.", toolName, m.personaName, strings.Join(p.AllowedTools, ", "))))
 				}
-
-				if isDestructive && !m.alwaysAllow[permissionKey] && !m.yoloMode {
+				conflict := m.agent.WriteConflict(toolName, llmAction.Input)
+				if toolName == "apply_changeset" && m.needsApproval(llmAction, conflict) {
+					return m.reviewChangeset(llmAction)
+				}
+				if m.needsApproval(llmAction, conflict) {
 					m.permissionRequest = llmAction
+					m.permissionConflict = conflict
+					m.permissionReview = ""
 					m.viewport.SetContent(m.renderMessages())
 					m.viewport.GotoBottom()
-					return m, nil
+					return m, m.reviewChange(llmAction)
 				}
 
 				return m.executeAndRespond(llmAction.Tool, llmAction.Input)
 			}
 
 			// If it wasn't a tool call, just update the viewport with the (potentially modified) content
-			m.viewport.SetContent(m.renderMessages())
-			m.viewport.GotoBottom()
+			m.followStream()
+			return m, m.answerFinished()
 		}
 
 	case types.ErrorMsg:
 		if strings.Contains(msg.Err.Error(), "context canceled") {
 			return m, nil
 		}
-		m.sending = false
+		m.endStream()
+		m.plan = nil
+		var apiErr *ollama.APIError
+		if errors.As(msg.Err, &apiErr) {
+			return m, m.handleAPIError(apiErr)
+		}
 		m.err = msg.Err
 
 	case tea.WindowSizeMsg:
 		newWidth := msg.Width
+		atBottom := m.viewport.AtBottom()
 
 		// Set widths of components. The viewport is full width, but the textarea needs to be slightly narrower.
 		m.viewport.Width = newWidth
 		m.textarea.SetWidth(newWidth - 2)
 
-		// Get the rendered height of the textarea and footer.
-		occupiedHeight := lipgloss.Height(m.textarea.View()) + 1 // +1 for the footer
-
-		// Set the viewport height.
-		m.viewport.Height = msg.Height - occupiedHeight
+		// Give the viewport what the textarea, footer and task panel leave.
+		m.height = msg.Height
+		m.layout()
+		if atBottom {
+			m.viewport.GotoBottom()
+		}
 
-		// Update content and pass messages.
-		m.viewport.SetContent(m.renderMessages())
-		m.viewport.GotoBottom()
+		// Re-wrap the transcript only when the width really changed, once the
+		// resize settles, then pass messages.
+		resizeCmd := m.scheduleRerender()
 		m.textarea, taCmd = m.textarea.Update(msg)
 		m.viewport, vpCmd = m.viewport.Update(msg)
+		return m, tea.Batch(taCmd, vpCmd, resizeCmd)
 
 	default:
 		m.textarea, taCmd = m.textarea.Update(msg)
@@ -434,32 +747,40 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m *Model) executeAndRespond(toolName string, input map[string]interface{}) (tea.Model, tea.Cmd) {
 	if toolName == "respond" {
 		m.logger.Log("Handling 'respond' tool.")
-		var message string
-		if msgStr, ok := input["message"].(string); ok {
-			message = msgStr
-		} else if msgArr, ok := input["message"].([]interface{}); ok {
-			var parts []string
-			for _, item := range msgArr {
-				if part, ok := item.(string); ok {
-					parts = append(parts, part)
-				}
-			}
-			message = strings.Join(parts, "\n")
-		}
+		message := runner.RespondMessage(input)
 		if message != "" {
 			m.logger.Log(fmt.Sprintf("Extracted message for UI: '%.60s...'.", message))
 			m.messages[len(m.messages)-1].Content = message
 		}
 		m.viewport.SetContent(m.renderMessages())
 		m.viewport.GotoBottom()
-		return m, nil
+		return m, m.answerFinished()
 	}
 
 	// Execute the command
-	responseToLLM := m.agent.ExecuteCommand(toolName, input)
+	m.snapshotArtifact(toolName, input)
+	result := m.agent.ExecuteTool(toolName, input)
+	numberSources(sessionSources(m.messages), &result)
+	m.trackTouchedFile(input)
+	m.recordToolCall(toolName, input, result)
+	if agent.Suspicious(result.Output) {
+		m.showError(i18n.T("guard.suspicious", toolName))
+	}
+
+	return m.sendToolResult(result)
+}
+
+// sendToolResult appends the result of a single tool call to the
+// conversation and asks the model to continue.
+func (m *Model) sendToolResult(result types.ToolResult) (tea.Model, tea.Cmd) {
+	return m.sendToolMessage(types.Message{Role: "tool", Content: result.String(), Result: &result})
+}
 
-	// Append the tool result as a "tool" message
-	m.messages = append(m.messages, types.Message{Role: "tool", Content: responseToLLM})
+// sendToolMessage appends a tool message to the conversation and, if it is
+// not empty, asks the model to continue.
+func (m *Model) sendToolMessage(msg types.Message) (tea.Model, tea.Cmd) {
+	responseToLLM := msg.Content
+	m.addMessage(msg)
 
 	// Update the UI to show the command executed and its result
 	m.viewport.SetContent(m.renderMessages())
@@ -467,17 +788,15 @@ func (m *Model) executeAndRespond(toolName string, input map[string]interface{})
 
 	// If there was a response to send to LLM, start a new stream
 	if responseToLLM != "" {
-		ctx, cancel := context.WithCancel(context.Background())
-		m.cancel = cancel
-		m.sending = true
-		m.streaming = true
-		m.stream = make(chan interface{})
-		m.messages = append(m.messages, types.Message{Role: "assistant", Content: ""}) // Prepare for assistant's next response
+		ctx, ok := m.beginStream()
+		if !ok {
+			m.refuseBusy("tool result")
+			return m, nil
+		}
+		m.addMessage(types.Message{Role: "assistant", Content: ""}) // Prepare for assistant's next response
 		m.viewport.SetContent(m.renderMessages())
 		m.viewport.GotoBottom()
-
-		m.ollamaClient.StartStream(ctx, m.modelName, m.messages, m.modelContextSize, m.stream, m.wg)
-		return m, m.waitForStream()
+		return m, m.startStream(ctx)
 	}
 
 	// If command had no response to send to LLM, just return
@@ -513,8 +832,8 @@ func (m *Model) handleArrowKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *Model) handleTabKey() (tea.Model, tea.Cmd) {
 	if m.fileSearchActive && m.fileSearchResult != "" {
 		val := m.textarea.Value()
-		re := regexp.MustCompile(`@\w*$`)
-		newVal := re.ReplaceAllString(val, "@"+m.fileSearchResult)
+		re := regexp.MustCompile(`@\S*$`)
+		newVal := re.ReplaceAllLiteralString(val, "@"+m.fileSearchResult+rangeSuffixRegex.FindString(val))
 		m.textarea.SetValue(newVal)
 		m.fileSearchActive = false
 		m.textarea.CursorEnd()
@@ -544,7 +863,7 @@ func (m *Model) handleTextInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	if len(matches) > 1 {
 		m.fileSearchActive = true
-		m.fileSearchTerm = matches[1]
+		m.fileSearchTerm = rangeSuffixRegex.ReplaceAllString(matches[1], "")
 		m.fileSearchResult = ""
 		for _, f := range m.files {
 			if strings.Contains(strings.ToLower(f), strings.ToLower(m.fileSearchTerm)) {
@@ -560,6 +879,15 @@ func (m *Model) handleTextInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m *Model) handleEnter() (tea.Model, tea.Cmd) {
 	userInput := strings.TrimSpace(m.textarea.Value())
+	m.setMultiline(false)
+	if expanded, ok, err := m.expandHistory(userInput); ok {
+		if err != nil {
+			m.showError(err.Error())
+			return m, nil
+		}
+		userInput = expanded
+	}
+	m.recordPrompt(userInput)
 	if userInput != "" {
 		m.history = append([]string{userInput}, m.history...)
 		if len(m.history) > 5 {
@@ -567,12 +895,19 @@ func (m *Model) handleEnter() (tea.Model, tea.Cmd) {
 		}
 	}
 	m.historyCursor = -1
+	if m.redirecting {
+		return m, m.redirect(userInput)
+	}
 	if userInput == "/stop" {
-		if m.cancel != nil {
-			m.cancel()
+		m.endStream()
+		m.queued = nil
+		if m.reviewing {
+			m.reviewing = false
+			m.plan = nil
+			m.textarea.Reset()
+			return m, nil
 		}
-		m.streaming = false
-		m.sending = false
+		m.plan = nil
 		if len(m.messages) > 0 && m.messages[len(m.messages)-1].Role == "assistant" {
 			m.messages[len(m.messages)-1].Content += "\n\n--- Canceled ---"
 		}
@@ -583,29 +918,172 @@ func (m *Model) handleEnter() (tea.Model, tea.Cmd) {
 	}
 
 	if !m.sending {
+		if args, ok := commandArgs(userInput, "/persona"); ok {
+			m.textarea.Reset()
+			m.handlePersona(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/agent"); ok {
+			m.textarea.Reset()
+			m.handleAgentMode(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/yolo"); ok {
+			m.textarea.Reset()
+			m.handleYolo(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/permissions"); ok {
+			m.textarea.Reset()
+			m.handlePermissions(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/bundle"); ok {
+			m.textarea.Reset()
+			return m, m.handleBundle(args)
+		}
+		if args, ok := commandArgs(userInput, "/run"); ok {
+			m.textarea.Reset()
+			return m, m.runCommand(args)
+		}
+		if args, ok := commandArgs(userInput, "/history"); ok {
+			m.textarea.Reset()
+			m.handleHistory(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/model"); ok {
+			m.textarea.Reset()
+			return m, m.handleModel(args)
+		}
+		if args, ok := commandArgs(userInput, "/ctx"); ok {
+			m.textarea.Reset()
+			m.handleContextSize(args)
+			return m, nil
+		}
+		if userInput == "/undo" {
+			m.textarea.Reset()
+			m.handleUndo()
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/share"); ok {
+			m.textarea.Reset()
+			m.handleShare(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/translate"); ok {
+			m.textarea.Reset()
+			return m, m.handleTranslate(args)
+		}
+		if args, ok := commandArgs(userInput, "/preset"); ok {
+			m.textarea.Reset()
+			m.handlePreset(args)
+			return m, nil
+		}
+		if userInput == "/toolstats" {
+			m.textarea.Reset()
+			m.handleToolStats()
+			return m, nil
+		}
+		if userInput == "/artifacts" {
+			m.textarea.Reset()
+			m.openArtifacts()
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/json"); ok {
+			m.textarea.Reset()
+			return m, m.handleJSON(args)
+		}
+		if args, ok := commandArgs(userInput, "/pull"); ok {
+			m.textarea.Reset()
+			return m, m.handlePull(args)
+		}
+		if args, ok := commandArgs(userInput, "/forget"); ok {
+			m.textarea.Reset()
+			m.handleForget(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/older"); ok {
+			m.textarea.Reset()
+			m.handleOlder(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/timestamps"); ok {
+			m.textarea.Reset()
+			m.handleTimestamps(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/open-full"); ok {
+			m.textarea.Reset()
+			return m, m.handleOpenFull(args)
+		}
+		if args, ok := commandArgs(userInput, "/open"); ok {
+			m.textarea.Reset()
+			return m, m.openReference(args)
+		}
+		if args, ok := commandArgs(userInput, "/critic"); ok {
+			m.textarea.Reset()
+			m.handleCritic(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/plan"); ok {
+			m.textarea.Reset()
+			return m, m.handlePlan(args)
+		}
+		if args, ok := commandArgs(userInput, "/sources"); ok {
+			m.textarea.Reset()
+			m.handleSources(args)
+			return m, nil
+		}
+		if args, ok := commandArgs(userInput, "/explain"); ok {
+			m.textarea.Reset()
+			return m, m.handleExplain(args)
+		}
+		if args, ok := commandArgs(userInput, "/send-to"); ok {
+			m.textarea.Reset()
+			m.handleSendTo(args)
+			return m, nil
+		}
+
 		switch userInput {
+		case "/speak":
+			m.textarea.Reset()
+			m.handleSpeak()
+			return m, nil
+		case "/paste-image":
+			m.textarea.Reset()
+			m.handlePasteImage()
+			return m, nil
 		case "/new":
+			m.saveSession()
 			if len(m.messages) > 0 {
 				m.messages = []types.Message{m.messages[0]}
 			} else {
 				m.messages = []types.Message{}
 			}
-			if m.cancel != nil {
-				m.cancel()
-			}
-			m.streaming = false
-			m.sending = false
+			m.endStream()
 			m.stats = ""
 			m.currentJoke = ""
+			m.agent.ClearCache()
+			m.session = session.New(m.modelName)
+			m.useSessionDirs()
+			m.restoredMessages = 0
+			m.numCtxOverride = 0
+			m.expanded = make(map[int]bool)
+			m.artifacts = nil
+			m.pendingImages = nil
+			m.pendingContext = nil
+			m.plan = nil
+			m.structured = nil
+			m.queued = nil
 
 			m.viewport.SetContent(m.renderMessages())
 			m.textarea.Reset()
 			m.viewport.GotoBottom()
 			return m, nil
 		case "/bye":
-			return m, tea.Quit
+			return m.quit()
 		case "/help":
-			m.messages = append(m.messages, types.Message{Role: "assistant", Content: "Commands:\n/new - Start a new chat session\n/bye - Exit the application\n/help - Show this help message\n/stop - Stop the current response\n/log - Toggle logging to a file\n/copy - Copy the last response to the clipboard"})
+			m.addMessage(types.Message{Role: "assistant", Content: helpText()})
 			m.viewport.SetContent(m.renderMessages())
 			m.textarea.Reset()
 			m.viewport.GotoBottom()
@@ -620,9 +1098,9 @@ func (m *Model) handleEnter() (tea.Model, tea.Cmd) {
 			}
 			if lastResponse != "" {
 				clipboard.WriteAll(lastResponse)
-				m.messages = append(m.messages, types.Message{Role: "assistant", Content: "Copied last response to clipboard."})
+				m.addMessage(types.Message{Role: "assistant", Content: i18n.T("copy.done")})
 			} else {
-				m.messages = append(m.messages, types.Message{Role: "assistant", Content: "No response to copy."})
+				m.addMessage(types.Message{Role: "assistant", Content: i18n.T("copy.empty")})
 			}
 			m.viewport.SetContent(m.renderMessages())
 			m.textarea.Reset()
@@ -630,140 +1108,271 @@ func (m *Model) handleEnter() (tea.Model, tea.Cmd) {
 			return m, nil
 		case "/log":
 			logMsg := m.logger.Toggle()
-			m.messages = append(m.messages, types.Message{Role: "assistant", Content: logMsg})
+			m.addMessage(types.Message{Role: "assistant", Content: logMsg})
 			m.viewport.SetContent(m.renderMessages())
 			m.textarea.Reset()
 			m.viewport.GotoBottom()
 			return m, nil
 		}
 
-		re := regexp.MustCompile(`@(\S+)`)
-		matches := re.FindAllStringSubmatch(userInput, -1)
-
-		if len(matches) > 0 {
-			processedInput := userInput
-			for _, match := range matches {
-				fileName := match[1]
-				fileContent, err := os.ReadFile(fileName)
-				if err != nil {
-					continue
-				}
-				replacement := fmt.Sprintf("\n\n---\nFile: %s\n```\n%s\n```\n", fileName, string(fileContent))
-				processedInput = strings.Replace(processedInput, "@"+fileName, replacement, 1)
-			}
-			userInput = processedInput
-		}
-
-		ctx, cancel := context.WithCancel(context.Background())
-		m.cancel = cancel
-		m.sending = true
-		m.streaming = true
-		m.isJsonResponse = false // Reset the flag for the new message
-		m.stream = make(chan interface{})
-		m.currentJoke = devJokes[rand.Intn(len(devJokes))]
-		m.logger.Log(fmt.Sprintf("User input before sending to Ollama: %s", userInput))
-		m.messages = append(m.messages, types.Message{Role: "user", Content: userInput})
-		m.messages = append(m.messages, types.Message{Role: "assistant", Content: ""})
-		m.viewport.SetContent(m.renderMessages())
-
-		m.textarea.Reset()
-		m.viewport.GotoBottom()
+		prompt := userInput
+		userInput = m.expandMentions(m.expandLastFile(userInput))
 
-		m.ollamaClient.StartStream(ctx, m.modelName, m.messages, m.modelContextSize, m.stream, m.wg)
-		return m, m.waitForStream()
+		cmd := m.guardedSend(prompt, userInput)
+		if m.sending || m.guard != nil {
+			m.textarea.Reset()
+		}
+		return m, cmd
+	}
+	if userInput != "" && !strings.HasPrefix(userInput, "/") {
+		m.queuePrompt(userInput)
 	}
 	return m, nil
 }
 
-func (m *Model) waitForStream() tea.Cmd {
-	return func() tea.Msg {
-		msg, ok := <-m.stream
-		if !ok {
+// send sends userInput to the model as the next user message, with the
+// pending context and images attached, and starts streaming the reply.
+func (m *Model) send(userInput string) tea.Cmd {
+	ctx, ok := m.beginStream()
+	if !ok {
+		m.refuseBusy("prompt")
+		return nil
+	}
+	m.currentJoke = devJokes[rand.Intn(len(devJokes))]
+	m.logger.Log(fmt.Sprintf("User input before sending to Ollama: %s", userInput))
+	m.usage.turns++
+	userMessage := types.Message{Role: "user", Content: userInput}
+	var attachments []string
+	if len(m.pendingContext) > 0 {
+		userMessage.Content += "\n\n" + strings.Join(m.pendingContext, "\n\n")
+		attachments = append(attachments, fmt.Sprintf("%d context item(s)", len(m.pendingContext)))
+		m.pendingContext = nil
+	}
+	if len(m.pendingImages) > 0 {
+		images, err := encodeImages(m.pendingImages)
+		if err != nil {
+			m.endStream()
+			m.showError(i18n.T("image.attach_error", err))
 			return nil
 		}
-		return msg
+		userMessage.Images = images
+		attachments = append(attachments, "images: "+strings.Join(m.pendingImages, ", "))
+		m.pendingImages = nil
 	}
+	if len(attachments) > 0 {
+		userMessage.DisplayContent = fmt.Sprintf("%s\n\n_Attached %s_", userInput, strings.Join(attachments, "; "))
+	}
+	m.addMessage(userMessage)
+	m.addMessage(types.Message{Role: "assistant", Content: ""})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+
+	m.ollamaClient.SetFormat(m.requestFormat())
+	return m.startStream(ctx)
 }
+
 func (m *Model) renderMessages() string {
-	// Re-create renderer with the correct width, accounting for viewport padding
-	r, _ := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(m.viewport.Width-2),
-	)
+	content, offsets := m.renderTranscript(m.messages)
+	m.messageOffsets = offsets
+	m.renderCache.trim(len(m.messages))
+	return content
+}
+
+// renderMessageList renders the given messages as the transcript shown in
+// the viewport.
+func (m *Model) renderMessageList(messages []types.Message) string {
+	content, _ := m.renderTranscript(messages)
+	return content
+}
+
+// renderTranscript renders messages and returns, along with the text, the
+// line each message starts on. Blocks of unchanged messages come from the
+// render cache, so only new or edited messages go through glamour.
+func (m *Model) renderTranscript(messages []types.Message) (string, []int) {
+	blocks := make([]string, len(messages))
+	offsets := make([]int, len(messages))
+	lines, size := 0, 0
+	for i := range messages {
+		offsets[i] = lines
+		block := m.renderCache.block(m, messages, i)
+		blocks[i] = block.text
+		lines += block.lines
+		size += len(block.text)
+	}
 
 	var content strings.Builder
-	for i, msg := range m.messages {
-		if msg.Role == "system" {
-			continue
-		}
+	content.Grow(size)
+	for _, block := range blocks {
+		content.WriteString(block)
+	}
+	return content.String(), offsets
+}
 
-		var roleHeader string
-		var renderedMsg string
+// renderMessage renders the transcript block of messages[i]. System
+// messages are not shown.
+func (m *Model) renderMessage(messages []types.Message, i int) string {
+	msg := messages[i]
+	if msg.Role == "system" {
+		return ""
+	}
+	r := m.renderers.markdown(m.viewport.Width - 2)
+
+	var roleHeader string
+	var renderedMsg string
 
-		if msg.Role == "tool" {
-			roleHeader = "## Tool Output"
-			renderedMsg = fmt.Sprintf("```\n%s\n```", msg.Content) // Render tool output as a code block
+	if msg.Role == "tool" {
+		roleHeader = "## Tool Output" + m.timeLabel(msg.Time)
+		if m.isExpanded(i) && msg.Result != nil {
+			renderedMsg = expandedResult(msg.Result, resultLanguage(messages, i))
+		} else if m.isExpanded(i) {
+			renderedMsg = fence(msg.Content, resultLanguage(messages, i)) // Render tool output as a code block
+		} else {
+			renderedMsg = m.toolOutputSummary(messages, i)
+		}
+	} else {
+		roleHeader = "## " + strings.Title(msg.Role) + m.timeLabel(msg.Time)
+		if msg.IsError {
+			md, _ := r.Render(fmt.Sprintf("%s\n\n%s\n\n---", roleHeader, msg.Content))
+			return errorStyle.Render(md)
 		} else {
-			roleHeader = "## " + strings.Title(msg.Role)
-			if msg.IsError {
-				md, _ := r.Render(fmt.Sprintf("%s\n\n%s\n\n---", roleHeader, msg.Content))
-				content.WriteString(errorStyle.Render(md))
-				continue
+			if msg.DisplayContent != "" {
+				renderedMsg = msg.DisplayContent
 			} else {
-				if msg.DisplayContent != "" {
-					renderedMsg = msg.DisplayContent
-				} else {
-					renderedMsg = msg.Content
+				renderedMsg = msg.Content
+			}
+			if preview, hidden, long := spoolPreview(renderedMsg); long && msg.Role == "assistant" {
+				renderedMsg = preview + "\n\n*" + spoolNote(hidden, len(renderedMsg), msg.Spool) + "*"
+			}
+			if msg.Thinking != "" {
+				renderedMsg = thinkingBlock(msg.Thinking) + "\n\n" + renderedMsg
+			}
+			if msg.Role == "assistant" && !msg.Local {
+				if cited := citedSources(messages, i); len(cited) > 0 {
+					renderedMsg += "\n\n**" + i18n.T("sources.heading") + "**\n\n" + sourcesList(cited)
 				}
 			}
 		}
+	}
 
-		// If this is the last message, it's an assistant message, it's empty,
-		// and we are waiting for a response, render the joke.
-		if i == len(m.messages)-1 && msg.Role == "assistant" && msg.Content == "" && m.sending && m.currentJoke != "" {
-			// Create a plain glamour renderer that only does word wrapping, no colors.
-			// We subtract 2 for the padding we're adding manually.
-			plainRenderer, _ := glamour.NewTermRenderer(
-				glamour.WithWordWrap(m.viewport.Width - 2),
-			)
+	// If this is the last message, it's an assistant message, it's empty,
+	// and we are waiting for a response, render the joke.
+	if i == len(messages)-1 && msg.Role == "assistant" && msg.Content == "" && m.sending && m.currentJoke != "" {
+		// Use a plain glamour renderer that only does word wrapping, no colors.
+		// We subtract 2 for the padding we're adding manually.
+		plainRenderer := m.renderers.plain(m.viewport.Width - 2)
 
-			renderedJoke, _ := plainRenderer.Render(m.currentJoke)
+		renderedJoke, _ := plainRenderer.Render(m.currentJoke)
 
-			// 1. Style the joke content part with yellow
-			yellowJoke := jokeStyle.Render(renderedJoke)
+		// 1. Style the joke content part with yellow
+		yellowJoke := jokeStyle.Render(renderedJoke)
 
-			// 2. Render the separator
-			separator, _ := plainRenderer.Render("---")
+		// 2. Render the separator
+		separator, _ := plainRenderer.Render("---")
 
-			// 3. Join the parts vertically
-			fullBlock := lipgloss.JoinVertical(lipgloss.Left,
-				yellowJoke,
-				separator,
-			)
+		// 3. Join the parts vertically
+		fullBlock := lipgloss.JoinVertical(lipgloss.Left,
+			yellowJoke,
+			separator,
+		)
 
-			// 4. Add left padding to the whole block for indentation
-			indentedBlock := lipgloss.NewStyle().PaddingLeft(2).Render(fullBlock)
+		// 4. Add left padding to the whole block for indentation
+		indentedBlock := lipgloss.NewStyle().PaddingLeft(2).Render(fullBlock)
 
-			content.WriteString(indentedBlock)
-			continue
-		}
+		return indentedBlock
+	}
+
+	if msg.Excluded {
+		// Greyed out without syntax colors, see /forget.
+		plain, _ := m.renderers.plain(m.viewport.Width - 2).Render(fmt.Sprintf("%s `#%d` %s\n\n%s\n\n---", roleHeader, i, i18n.T("forget.marker"), renderedMsg))
+		return forgottenStyle.Render(plain)
+	}
+	md, _ := r.Render(fmt.Sprintf("%s\n\n%s\n\n---", roleHeader, renderedMsg))
+	return md
+}
+
+// helpKeys lists the catalog entries shown by /help, in order.
+var helpKeys = []string{
+	"help.new", "help.bye", "help.help", "help.stop", "help.log", "help.copy", "help.explain",
+	"help.open", "help.open_full", "help.paste_image", "help.history", "help.timestamps", "help.share", "help.undo", "help.sources", "help.translate", "help.older", "help.forget", "help.model", "help.pull", "help.ctx", "help.run", "help.bundle",
+	"help.persona", "help.preset", "help.json", "help.artifacts", "help.toolstats", "help.agent", "help.yolo", "help.permissions", "help.speak", "help.critic", "help.plan", "help.send_to",
+	"help.ctrl_e", "help.ctrl_t", "help.ctrl_l", "help.ctrl_o", "help.ctrl_r", "help.fold", "help.jump",
+}
+
+// helpText builds the /help message in the current locale.
+func helpText() string {
+	lines := []string{i18n.T("help.title")}
+	for _, key := range helpKeys {
+		lines = append(lines, i18n.T(key))
+	}
+	return strings.Join(lines, "\n")
+}
 
-		md, _ := r.Render(fmt.Sprintf("%s\n\n%s\n\n---", roleHeader, renderedMsg))
-		content.WriteString(md)
+// commandArgs reports whether input invokes the named slash command and
+// returns the trimmed argument string that follows it.
+func commandArgs(input, name string) (string, bool) {
+	if input == name {
+		return "", true
 	}
-	return content.String()
+	if strings.HasPrefix(input, name+" ") {
+		return strings.TrimSpace(input[len(name):]), true
+	}
+	return "", false
+}
+
+// showStatus appends an informational message to the transcript.
+func (m *Model) showStatus(content string) {
+	m.addMessage(types.Message{Role: "assistant", Content: content})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// showNotice appends an informational message that is only shown, never
+// sent to the model as something it said.
+func (m *Model) showNotice(content string) {
+	m.addMessage(types.Message{Role: "assistant", Content: content, Local: true})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// showError appends an error message to the transcript.
+func (m *Model) showError(content string) {
+	m.addMessage(types.Message{Role: "assistant", Content: content, IsError: true})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// trackTouchedFile remembers the path a tool call worked on so it can be
+// reopened later.
+func (m *Model) trackTouchedFile(input map[string]interface{}) {
+	path, ok := input["path"].(string)
+	if !ok || path == "" {
+		return
+	}
+	for i, f := range m.touchedFiles {
+		if f == path {
+			m.touchedFiles = append(m.touchedFiles[:i], m.touchedFiles[i+1:]...)
+			break
+		}
+	}
+	m.touchedFiles = append(m.touchedFiles, path)
 }
 
 func (m *Model) updateFileList() {
-	files, err := os.ReadDir(".")
-	if err != nil {
-		log.Println("could not list files:", err)
+	m.files = m.agent.WorkspaceFiles()
+}
+
+// permissionKey builds the "Always Allow" key for an action. The path is
+// resolved first so that "root:file" and the equivalent on-disk path share
+// the same permission.
+func (m *Model) permissionKey(action *types.Action) string {
+	path, ok := action.Input["path"].(string)
+	if !ok {
+		return ""
 	}
-	var fileNames []string
-	for _, file := range files {
-		fileNames = append(fileNames, file.Name())
+	if resolved, err := m.agent.ResolvePath(path); err == nil {
+		path = m.agent.DisplayPath(resolved)
 	}
-	m.files = fileNames
+	return fmt.Sprintf("%s:%s", action.Tool, path)
 }
 
 // calculateUsedTokens approximates the number of tokens used in the current chat history.
@@ -782,12 +1391,25 @@ func (m *Model) calculateUsedTokens() int {
 func (m *Model) renderCommandDetails(action *types.Action) string {
 	var details strings.Builder
 	details.WriteString(fmt.Sprintf("Tool: %s\n", action.Tool))
+	if action.Tool == "shell" {
+		details.WriteString(fmt.Sprintf("Shell: %s\n", m.agent.Shell().Name))
+	}
 
 	for key, value := range action.Input {
 		// Don't show content for write/append, it can be long
 		if (action.Tool == "write_file" || action.Tool == "append_file") && key == "content" {
 			continue // Skip rendering the content field
 		}
+		if action.Tool == "apply_changeset" && key == "changes" {
+			if files, err := agent.ParseChangeset(action.Input); err == nil {
+				var paths []string
+				for _, file := range files {
+					paths = append(paths, file.Path)
+				}
+				details.WriteString(fmt.Sprintf("Files: %s\n", strings.Join(paths, ", ")))
+			}
+			continue
+		}
 
 		details.WriteString(fmt.Sprintf("%s: ", strings.Title(key)))
 		switch v := value.(type) {
@@ -801,12 +1423,164 @@ func (m *Model) renderCommandDetails(action *types.Action) string {
 			details.WriteString(fmt.Sprintf("%v\n", v))
 		}
 	}
+
+	if action == m.permissionRequest && m.permissionConflict != "" {
+		path, _ := action.Input["path"].(string)
+		details.WriteString(i18n.T("conflict.warning", path) + "\n")
+	}
+	if action == m.permissionRequest && m.permissionReview != "" {
+		details.WriteString(i18n.T("critic.change", m.permissionReview) + "\n")
+	}
+
+	// Show where a root-relative path actually lands on disk.
+	if path, ok := action.Input["path"].(string); ok && len(m.agent.RootNames()) > 0 {
+		if resolved, err := m.agent.ResolvePath(path); err == nil {
+			details.WriteString(fmt.Sprintf("Resolved: %q\n", resolved))
+		} else {
+			details.WriteString(fmt.Sprintf("Resolved: %v\n", err))
+		}
+	}
 	return details.String()
 }
 
+// footerText returns the status line shown below the input.
+func (m *Model) footerText() string {
+	if m.fileSearchActive {
+		result := m.fileSearchResult
+		if result == "" {
+			result = i18n.T("footer.no_matches")
+		}
+		return i18n.T("footer.file_search", result)
+	}
+
+	stats := i18n.T("footer.no_stats") + " "
+	if m.stats != "" {
+		stats = m.stats
+	}
+
+	var contextInfo string
+	if contextSize := m.contextSize(); contextSize > 0 {
+		usedTokens := m.calculateUsedTokens()
+		remainingTokens := contextSize - int64(usedTokens)
+		if remainingTokens < 0 {
+			remainingTokens = 0
+		}
+		contextInfo = fmt.Sprintf("Context: %d | Used: %d", contextSize, usedTokens)
+	} else {
+		contextInfo = i18n.T("footer.no_context")
+	}
+
+	var yoloIndicator string
+	if len(m.yoloScopes) > 0 {
+		yoloIndicator = " | YOLO"
+		if !m.yoloScopes["all"] {
+			yoloIndicator += ": " + m.yoloSummary()
+		}
+	}
+
+	var attachmentIndicator string
+	if len(m.pendingImages) > 0 {
+		attachmentIndicator = fmt.Sprintf(" | Images: %d", len(m.pendingImages))
+	}
+	if len(m.pendingContext) > 0 {
+		attachmentIndicator += fmt.Sprintf(" | Attached: %d", len(m.pendingContext))
+	}
+
+	var personaIndicator string
+	if m.chatMode {
+		personaIndicator = " | " + i18n.T("footer.chat")
+	}
+	if m.personaName != "" {
+		personaIndicator += " | Persona: " + m.personaName
+	}
+	if m.preset != "" {
+		personaIndicator += " | Preset: " + m.preset
+	}
+	if m.structured != nil {
+		personaIndicator += " | JSON: " + m.structured.source
+	}
+	if m.speakEnabled {
+		personaIndicator += " | Speak"
+	}
+	if m.multiline {
+		personaIndicator += " | " + i18n.T("footer.multiline")
+	}
+	if m.plan != nil {
+		personaIndicator += " | " + i18n.T("footer.plan", min(m.plan.current+1, len(m.plan.steps)), len(m.plan.steps), m.plan.executor)
+	}
+
+	modelInfo := m.modelName
+	if facts := modelFacts(m.modelEntry); len(facts) > 0 {
+		modelInfo += " (" + strings.Join(facts, ", ") + ")"
+	}
+
+	return fmt.Sprintf("Model: %s | %s | %s%s%s%s", modelInfo, contextInfo, stats, yoloIndicator, personaIndicator, attachmentIndicator)
+}
+
 func (m *Model) View() string {
 	if m.err != nil {
-		return fmt.Sprintf("An error occurred: %v\n\nPress Ctrl+C to quit.", m.err)
+		return i18n.T("error.screen", m.err)
+	}
+	if m.accessible {
+		return m.accessibleView()
+	}
+
+	if m.picker != nil {
+		m.focused = focusViewport
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.transcriptView(),
+			lipgloss.NewStyle().Border(lipgloss.DoubleBorder(), true).BorderForeground(lipgloss.Color("12")).Padding(0, 1).Render(m.picker.view()),
+		)
+	}
+
+	// If the model asked for several tool calls, show the approval list.
+	if m.preview != nil {
+		m.focused = focusViewport
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.transcriptView(),
+			lipgloss.NewStyle().Border(lipgloss.DoubleBorder(), true).BorderForeground(lipgloss.Color("12")).Padding(0, 1).Render(m.preview.view(max(m.viewport.Width-4, 1))),
+		)
+	}
+
+	if m.artifactList != nil {
+		m.focused = focusViewport
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.transcriptView(),
+			lipgloss.NewStyle().Border(lipgloss.DoubleBorder(), true).BorderForeground(lipgloss.Color("12")).Padding(0, 1).Render(m.artifactsView(max(m.viewport.Width-4, 1))),
+		)
+	}
+
+	if m.changeset != nil {
+		m.focused = focusViewport
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.transcriptView(),
+			lipgloss.NewStyle().Border(lipgloss.DoubleBorder(), true).BorderForeground(lipgloss.Color("1")).Padding(0, 1).Render(m.changesetView(max(m.viewport.Width-4, 1))),
+		)
+	}
+
+	if m.stalled {
+		m.focused = focusViewport
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.transcriptView(),
+			lipgloss.NewStyle().Border(lipgloss.DoubleBorder(), true).BorderForeground(lipgloss.Color("11")).Padding(1).Render(m.stallView()),
+		)
+	}
+
+	if m.reviewingBatch {
+		m.textarea.Blur()
+		m.focused = focusViewport
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.transcriptView(),
+			lipgloss.NewStyle().Border(lipgloss.DoubleBorder(), true).BorderForeground(lipgloss.Color("1")).Padding(1).Render(m.renderBatch()),
+		)
+	}
+
+	if m.guard != nil {
+		m.focused = focusViewport
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.transcriptView(),
+			lipgloss.NewStyle().Border(lipgloss.DoubleBorder(), true).BorderForeground(lipgloss.Color("11")).Padding(1).Render(m.guardView()),
+		)
 	}
 
 	// If we are waiting for permission, show the permission prompt.
@@ -814,60 +1588,50 @@ func (m *Model) View() string {
 		m.textarea.Blur()
 		m.focused = focusViewport
 		details := m.renderCommandDetails(m.permissionRequest)
-		prompt := fmt.Sprintf("The model wants to execute the following command:\n\n%s\nDo you want to proceed?\n\n(A)llow Once   (Y)es to All   (N)o / Display Command", details)
+		prompt := i18n.T("permission.prompt", details) + "\n\n" + i18n.T("permission.options")
+		if rules := m.ruleOptions(m.permissionRequest); rules != "" {
+			prompt += "\n" + rules
+		}
 		return lipgloss.JoinVertical(lipgloss.Left,
-			m.viewport.View(),
+			m.transcriptView(),
 			lipgloss.NewStyle().Border(lipgloss.DoubleBorder(), true).BorderForeground(lipgloss.Color("1")).Padding(1).Render(prompt),
 		)
 	}
 
 	if m.ctrlCpressed {
 		return lipgloss.JoinVertical(lipgloss.Left,
-			m.viewport.View(),
+			m.transcriptView(),
 			m.textarea.View(),
-			footerStyle.Render("Press Ctrl-C again to exit the application. Press Esc to cancel."),
+			footerStyle.Render(i18n.T("footer.quit_confirm")),
 		)
 	}
 
-	var leftFooter string
-	if m.fileSearchActive {
-		footerText := "File search: "
-		if m.fileSearchResult != "" {
-			footerText += m.fileSearchResult
-		} else {
-			footerText += "No matches found"
-		}
-		leftFooter = footerStyle.Render(footerText)
-	} else {
-		stats := "Tokens/sec: N/A "
-		if m.stats != "" {
-			stats = m.stats
-		}
-
-		var contextInfo string
-		if m.modelContextSize > 0 {
-			usedTokens := m.calculateUsedTokens()
-			remainingTokens := m.modelContextSize - int64(usedTokens)
-			if remainingTokens < 0 {
-				remainingTokens = 0
-			}
-			contextInfo = fmt.Sprintf("Context: %d | Used: %d", m.modelContextSize, usedTokens)
-		} else {
-			contextInfo = "Context: N/A"
-		}
-
-		var yoloIndicator string
-		if m.yoloMode {
-			yoloIndicator = " | YOLO"
-		}
-
-		footerText := fmt.Sprintf("Model: %s | %s | %s%s", m.modelName, contextInfo, stats, yoloIndicator)
-		leftFooter = footerStyle.Render(footerText)
-	}
+	leftFooter := footerStyle.Render(m.footerText())
 
 	var rightFooter string
 	if m.sending {
-		rightFooter = m.spinner.View() + " Waiting for response..."
+		rightFooter = m.spinner.View() + " " + i18n.T("footer.waiting")
+	}
+	if m.translating {
+		rightFooter = m.spinner.View() + " " + i18n.T("footer.translating")
+	}
+	if m.planning {
+		rightFooter = m.spinner.View() + " " + i18n.T("footer.planning")
+	}
+	if m.reviewing {
+		rightFooter = m.spinner.View() + " " + i18n.T("footer.reviewing")
+	}
+	if m.redirecting {
+		rightFooter = i18n.T("footer.redirect")
+	}
+	if m.pulling != "" && rightFooter == "" {
+		rightFooter = m.spinner.View() + " " + i18n.T("footer.pulling", m.pulling, m.pullStatus)
+	}
+	if m.dictation != nil {
+		rightFooter = i18n.T("footer.recording")
+	}
+	if m.newBelow && !m.viewport.AtBottom() {
+		rightFooter = i18n.T("footer.new_below") + "  " + rightFooter
 	}
 
 	spacerWidth := m.viewport.Width - lipgloss.Width(leftFooter) - lipgloss.Width(rightFooter)
@@ -888,9 +1652,7 @@ func (m *Model) View() string {
 		m.viewport.Style.BorderForeground(lipgloss.Color("62")) // Purple
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left,
-		m.viewport.View(),
-		m.textarea.View(),
-		footer,
-	)
+	views := append([]string{m.transcriptView()}, m.panels()...)
+	views = append(views, m.textarea.View(), footer)
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
 }
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"prompt-cli/internal/agent"
+	"prompt-cli/internal/i18n"
+	"slices"
+	"strings"
+)
+
+// autoApproved reports whether YOLO mode covers the tool, so it runs
+// without asking for permission.
+func (m *Model) autoApproved(toolName string) bool {
+	return m.yoloScopes["all"] || m.yoloScopes[agent.PermissionScope(toolName)]
+}
+
+// toggleYolo implements Ctrl+Y: it turns off every scope if any is on and
+// otherwise approves everything.
+func (m *Model) toggleYolo() {
+	if len(m.yoloScopes) > 0 {
+		m.yoloScopes = make(map[string]bool)
+		m.showStatus(i18n.T("yolo.disabled"))
+		return
+	}
+	m.yoloScopes = map[string]bool{"all": true}
+	m.showStatus(i18n.T("yolo.enabled"))
+}
+
+// handleYolo implements "/yolo [files|git|web|shell|all|off]...". Each named
+// scope is toggled; with no arguments the active scopes are shown.
+func (m *Model) handleYolo(args string) {
+	if args == "" {
+		if len(m.yoloScopes) == 0 {
+			m.showStatus(i18n.T("yolo.status_off"))
+		} else {
+			m.showStatus(i18n.T("yolo.status", m.yoloSummary()))
+		}
+		return
+	}
+
+	for _, scope := range strings.Fields(args) {
+		switch {
+		case scope == "off":
+			m.yoloScopes = make(map[string]bool)
+		case scope == "all" || slices.Contains(agent.PermissionScopes, scope):
+			if m.yoloScopes[scope] {
+				delete(m.yoloScopes, scope)
+			} else {
+				m.yoloScopes[scope] = true
+			}
+		default:
+			m.showError(i18n.T("yolo.usage", strings.Join(agent.PermissionScopes, "|")))
+			return
+		}
+	}
+
+	if len(m.yoloScopes) == 0 {
+		m.showStatus(i18n.T("yolo.disabled"))
+	} else {
+		m.showStatus(i18n.T("yolo.scoped", m.yoloSummary()))
+	}
+}
+
+// yoloSummary lists the active scopes for the footer and status messages.
+func (m *Model) yoloSummary() string {
+	if m.yoloScopes["all"] {
+		return "all"
+	}
+	var scopes []string
+	for _, scope := range agent.PermissionScopes {
+		if m.yoloScopes[scope] {
+			scopes = append(scopes, scope)
+		}
+	}
+	return strings.Join(scopes, ",")
+}
@@ -0,0 +1,16 @@
+package types
+
+// Task statuses of the task_list tool.
+const (
+	TaskPending    = "pending"
+	TaskInProgress = "in_progress"
+	TaskDone       = "done"
+)
+
+// Task is an item of the checklist the agent keeps with task_list to track
+// multi-step work. It is saved with the session.
+type Task struct {
+	ID     int    `json:"id"`
+	Text   string `json:"text"`
+	Status string `json:"status"`
+}
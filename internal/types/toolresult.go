@@ -0,0 +1,122 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Tool result statuses.
+const (
+	ToolOK    = "ok"
+	ToolError = "error"
+)
+
+// Error classes of failed tool calls, so the model can tell a typo in its
+// input from a missing file or a timeout without parsing the message.
+const (
+	ErrorInvalidInput = "invalid_input" // Missing or malformed arguments.
+	ErrorNotFound     = "not_found"     // The file, URL or object does not exist.
+	ErrorPermission   = "permission"    // Outside the workspace, not allowed or denied by the user.
+	ErrorConflict     = "conflict"      // The target changed since it was read, or is in the way.
+	ErrorTimeout      = "timeout"
+	ErrorFailed       = "failed" // The tool ran and failed, e.g. a non-zero exit code.
+)
+
+// ToolResult is the outcome of a tool call. It is sent to the model in one
+// consistent format (see String) and kept on the tool message so the UI can
+// show status, timing and stderr separately.
+type ToolResult struct {
+	Tool       string `json:"tool"`
+	Status     string `json:"status"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Output     string `json:"output,omitempty"`
+	Stderr     string `json:"stderr,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	Cached     bool   `json:"cached,omitempty"`
+	// Sources are the web pages the output came from, numbered by the UI
+	// so answers can cite them.
+	Sources []Source `json:"sources,omitempty"`
+}
+
+// Source is a web page a tool result is based on. N is its citation
+// number in the session, or 0 if it has none.
+type Source struct {
+	N     int    `json:"n,omitempty"`
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+// SourcesMarker starts the list of sources of a serialized result.
+const SourcesMarker = "[sources]"
+
+// resultHeader is the first line of a serialized result.
+type resultHeader struct {
+	Tool       string `json:"tool"`
+	Status     string `json:"status"`
+	ErrorClass string `json:"error_class,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	Cached     bool   `json:"cached,omitempty"`
+}
+
+// ResultMarker starts the status line of a serialized tool result.
+const ResultMarker = "[tool_result] "
+
+// String serializes the result for the model: a status line with a JSON
+// object, then the error message, the output and stderr, each as plain
+// text so file contents are not escaped.
+func (r ToolResult) String() string {
+	header, _ := json.Marshal(resultHeader{
+		Tool:       r.Tool,
+		Status:     r.Status,
+		ErrorClass: r.ErrorClass,
+		DurationMs: r.DurationMs,
+		Truncated:  r.Truncated,
+		Cached:     r.Cached,
+	})
+	var builder strings.Builder
+	builder.WriteString(ResultMarker + string(header) + "\n")
+	if r.Error != "" {
+		builder.WriteString(r.Error + "\n")
+	}
+	if r.Output != "" {
+		if r.Error != "" {
+			builder.WriteString("[output]\n")
+		}
+		builder.WriteString(r.Output)
+		if !strings.HasSuffix(r.Output, "\n") {
+			builder.WriteString("\n")
+		}
+	}
+	if r.Stderr != "" {
+		builder.WriteString("[stderr]\n" + strings.TrimRight(r.Stderr, "\n") + "\n")
+	}
+	if len(r.Sources) > 0 {
+		builder.WriteString(SourcesMarker + "\n")
+		for _, source := range r.Sources {
+			builder.WriteString(source.String() + "\n")
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// Failed reports whether the tool call did not succeed.
+func (r ToolResult) Failed() bool {
+	return r.Status == ToolError
+}
+
+// String formats the source as a line of the sources list, e.g.
+// "[2] Go 1.25 Release Notes <https://go.dev/doc/go1.25>".
+func (s Source) String() string {
+	title := s.Title
+	if title == "" {
+		title = s.URL
+	}
+	if s.N == 0 {
+		return fmt.Sprintf("- %s <%s>", title, s.URL)
+	}
+	return fmt.Sprintf("[%d] %s <%s>", s.N, title, s.URL)
+}
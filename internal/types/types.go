@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // --- API Data Structures ---
@@ -27,15 +28,19 @@ type TagsResponse struct {
 
 // Model represents a single model entry in the tags list.
 type Model struct {
-	Name string `json:"name"`
+	Name       string    `json:"name"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Size       int64     `json:"size"` // Size on disk in bytes
+	Details    Details   `json:"details"`
 }
 
 // ShowModelResponse contains detailed information about a model.
 // The Details field holds generic metadata and ModelInfo holds
 // architecture‑specific fields.
 type ShowModelResponse struct {
-	Details   Details   `json:"details"`
-	ModelInfo ModelInfo `json:"model_info"`
+	Details      Details   `json:"details"`
+	ModelInfo    ModelInfo `json:"model_info"`
+	Capabilities []string  `json:"capabilities"` // e.g. "completion", "tools", "vision", "thinking"
 }
 
 // Details describes the general model metadata.
@@ -46,57 +51,86 @@ type Details struct {
 	QuantizationLevel string `json:"quantization_level"`
 }
 
-// ModelInfo contains architecture‑specific fields.  The
-// GenericContextLength field is not marshalled but is set
-// dynamically at runtime based on the architecture.
-type ModelInfo struct {
-	// Generic field that can capture context length regardless of model architecture
-	GenericContextLength interface{} `json:"-"` // This will be set dynamically based on architecture
-	BlockCount           interface{} `json:"llama.block_count,omitempty"`
-	EmbeddingLength      interface{} `json:"llama.embedding_length,omitempty"`
-	VocabSize            interface{} `json:"llama.vocab_size,omitempty"`
-	ParameterCount       int64       `json:"general.parameter_count,omitempty"`
-	Architecture         string      `json:"general.architecture,omitempty"`
-	// Architecture-specific fields
-	LlamaContextLength interface{} `json:"llama.context_length,omitempty"`
-	GemmaContextLength interface{} `json:"gemma.context_length,omitempty"`
-
-	MistralContextLength interface{} `json:"mistral.context_length,omitempty"`
-	GptossContextLength  interface{} `json:"gptoss.context_length,omitempty"`
-}
+// ModelInfo holds the model_info metadata of /api/show. Keys are
+// namespaced by architecture ("llama.context_length",
+// "qwen2.context_length", ...) plus "general.*" entries, so it is decoded
+// generically rather than into per-architecture fields.
+type ModelInfo map[string]interface{}
 
-// Options represents the options for a chat request.
+// Options represents the options for a chat request. Sampling fields are
+// pointers so that an unset value is left to the model's default.
 type Options struct {
-	NumCtx int64 `json:"num_ctx,omitempty"`
+	NumCtx        int64    `json:"num_ctx,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"top_p,omitempty"`
+	TopK          *int     `json:"top_k,omitempty"`
+	RepeatPenalty *float64 `json:"repeat_penalty,omitempty"`
+	Seed          *int     `json:"seed,omitempty"`
+}
+
+// Merge returns o with every field that is set in other copied over it.
+func (o Options) Merge(other Options) Options {
+	if other.NumCtx != 0 {
+		o.NumCtx = other.NumCtx
+	}
+	if other.Temperature != nil {
+		o.Temperature = other.Temperature
+	}
+	if other.TopP != nil {
+		o.TopP = other.TopP
+	}
+	if other.TopK != nil {
+		o.TopK = other.TopK
+	}
+	if other.RepeatPenalty != nil {
+		o.RepeatPenalty = other.RepeatPenalty
+	}
+	if other.Seed != nil {
+		o.Seed = other.Seed
+	}
+	return o
 }
 
 // ChatRequest represents a request to the chat endpoint.
 // It contains the model, a sequence of messages, and a flag
 // indicating whether the response should be streamed.
 type ChatRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Stream   bool      `json:"stream"`
-	Options  Options   `json:"options,omitempty"`
+	Model    string          `json:"model"`
+	Messages []Message       `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  Options         `json:"options,omitempty"`
+	Think    bool            `json:"think,omitempty"`  // Ask thinking models to return their reasoning separately
+	Format   json.RawMessage `json:"format,omitempty"` // JSON schema the answer must follow (structured outputs)
 }
 
 // Message is an individual chat message.  It may contain tool
 // calls and an error flag used internally.
 type Message struct {
-	Role           string     `json:"role"`
-	Content        string     `json:"content"`
-	DisplayContent string     `json:"-"`
-	ToolCalls      []ToolCall `json:"tool_calls,omitempty"`
-	IsError        bool       `json:"-"`
+	Role           string      `json:"role"`
+	Content        string      `json:"content"`
+	Thinking       string      `json:"thinking,omitempty"` // Reasoning of thinking models, see ChatRequest.Think
+	DisplayContent string      `json:"-"`
+	ToolCalls      []ToolCall  `json:"tool_calls,omitempty"`
+	Images         []string    `json:"images,omitempty"` // Base64-encoded images for multimodal models
+	IsError        bool        `json:"-"`
+	Local          bool        `json:"local,omitempty"`    // Shown in the UI but never sent to the model
+	Excluded       bool        `json:"excluded,omitempty"` // Left out of requests with /forget, but still shown
+	Raw            *Message    `json:"raw,omitempty"`      // The reply exactly as the model produced it, sent back instead of the edited message
+	Result         *ToolResult `json:"result,omitempty"`   // Structured outcome of a tool message, for the UI; Content holds its serialized form
+	Spool          string      `json:"spool,omitempty"`    // File holding a reply too long to show in full, see /open-full
+	Time           time.Time   `json:"time,omitzero"`      // When the message was added; zero in sessions saved before timestamps
 }
 
 // ChatResponse is the response from the chat endpoint.
 // It contains the resulting message, a done flag, and
-// the number of evaluation tokens.
+// the number of prompt and evaluation tokens.  Tokens
+// served from Ollama's prompt cache are not counted in
+// PromptEvalCount.
 type ChatResponse struct {
-	Message   Message `json:"message"`
-	Done      bool    `json:"done"`
-	EvalCount int     `json:"eval_count"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	EvalCount       int     `json:"eval_count"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
 }
 
 // FlexibleStringSlice can unmarshal a JSON string or array of strings
@@ -148,6 +182,7 @@ type LLMResponse struct {
 	Version   string              `json:"version"`
 	Thoughts  FlexibleStringSlice `json:"thoughts"`
 	Action    Action              `json:"action"`
+	Actions   []Action            `json:"actions"` // Several independent tool calls in one turn.
 	ToolCalls []ToolCall          `json:"tool_calls"`
 }
 
@@ -158,6 +193,9 @@ type StreamChunkMsg string
 type StreamDoneMsg struct {
 	Stats        string
 	FinalMessage Message
+	PromptTokens int           // Prompt tokens Ollama evaluated for this reply.
+	OutputTokens int           // Tokens generated.
+	Duration     time.Duration // Time from request to the last chunk.
 }
 
 // ErrorMsg is a wrapper for errors that occur during the
@@ -308,4 +346,4 @@ func ExtractJSON(s string) (string, error) {
 
 	// If all attempts to parse fail, return an empty JSON object.
 	return "{}", nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,50 @@
+// Package workspace stores per-project state in a ".promptcli" folder in the
+// directory the application was started from.
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// dirName is the folder holding the workspace state.
+const dirName = ".promptcli"
+
+// Dir returns the workspace state directory, creating it if needed.
+func Dir() (string, error) {
+	if err := os.MkdirAll(dirName, 0755); err != nil {
+		return "", fmt.Errorf("error creating workspace directory: %w", err)
+	}
+	return dirName, nil
+}
+
+// ReadJSON decodes the named state file into v. A missing file is not an
+// error; v is left untouched.
+func ReadJSON(name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(dirName, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error decoding %s: %w", name, err)
+	}
+	return nil
+}
+
+// WriteJSON encodes v into the named state file.
+func WriteJSON(name string, v interface{}) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", name, err)
+	}
+	return os.WriteFile(filepath.Join(dir, name), data, 0644)
+}
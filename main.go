@@ -5,16 +5,22 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"prompt-cli/internal/agent"
+	"prompt-cli/internal/appdir"
 	"prompt-cli/internal/config"
+	"prompt-cli/internal/i18n"
 	"prompt-cli/internal/logger"
 	"prompt-cli/internal/ollama"
+	"prompt-cli/internal/rpc"
+	"prompt-cli/internal/session"
 	"prompt-cli/internal/tui"
+	"prompt-cli/internal/types"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -29,31 +35,139 @@ func loadPrompt(path string) (string, error) {
 	return string(content), nil
 }
 
-func main() {
-	// Define a command-line flag for chat-only mode. This allows the user to
-	// start the application without the system prompt that defines the tool-using agent persona.
-	chatOnly := flag.Bool("chatonly", false, "Enable chat-only mode, without the tool-using agent persona.")
-
-	// Determine the directory of the running executable.
-	exePath, err := os.Executable()
+// loadConfig reads and validates config.json from the application
+// directory.
+func loadConfig() (*config.Config, error) {
+	// Determine the application directory, next to the executable or
+	// %APPDATA%\PromptCLI on Windows.
+	dir, err := appdir.Dir()
 	if err != nil {
-		log.Fatalf("Error finding executable path: %v", err)
+		return nil, err
 	}
-	exeDir := filepath.Dir(exePath)
 
-	// Build the path to the configuration file relative to the executable directory.
-	configPath := filepath.Join(exeDir, "config.json")
+	// Build the path to the configuration file relative to the application directory.
+	configPath := filepath.Join(dir, "config.json")
 
 	// Load configuration from the JSON file.
 	configs, err := config.LoadConfig(configPath)
 	if err != nil {
-		log.Fatalf("Error loading config from %s: %v", configPath, err)
+		return nil, fmt.Errorf("error loading config from %s: %v", configPath, err)
 	}
 
 	// Validate the loaded configuration to ensure required fields are set.
 	if err := config.ValidateConfig(configs); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
+		return nil, fmt.Errorf("invalid configuration: %v", err)
+	}
+	return configs, nil
+}
+
+// ollamaBaseURL constructs the base URL for the Ollama server, adding the
+// HTTP scheme if missing.
+func ollamaBaseURL(configs *config.Config) string {
+	if strings.HasPrefix(configs.OllamaServerURL, "http") {
+		return fmt.Sprintf("%s:%d", configs.OllamaServerURL, configs.OllamaServerPort)
+	}
+	return fmt.Sprintf("http://%s:%d", configs.OllamaServerURL, configs.OllamaServerPort)
+}
+
+// newAgent creates the tool agent with the workspace roots, remote
+// workspace, container, shell, timeouts and opt-in tools from the config.
+func newAgent(configs *config.Config, appLogger *logger.Logger) *agent.Agent {
+	appAgent := agent.NewAgent(appLogger)
+	appAgent.SetRoots(configs.WorkspaceRoots)
+	appAgent.SetTimeouts(configs.ToolTimeoutMs, configs.ToolTimeoutsMs)
+	appAgent.SetSSHWorkspace(configs.SSHWorkspace)
+	appAgent.SetDocker(configs.Docker)
+	if err := agent.SetShell(configs.Shell); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	appAgent.SetForge("github", configs.GitHub)
+	appAgent.SetForge("gitlab", configs.GitLab)
+	appAgent.SetTicketSources(configs.Jira, configs.Linear)
+	appAgent.SetSearch(configs.Search)
+	appAgent.SetFetch(configs.Fetch)
+	appAgent.SetGuard(configs.InjectionGuard)
+	appAgent.SetReadAllLimit(configs.ReadAllMaxBytes)
+	appAgent.SetDownloadLimit(configs.DownloadMaxBytes)
+	appAgent.SetValidators(configs.Validators)
+	appAgent.SetFormatters(configs.Formatters)
+	appAgent.SetHooks(configs.Hooks)
+	appAgent.DetectProjects()
+	appAgent.SetCacheEnabled(!configs.LowMemoryMode())
+	if err := appAgent.EnableTools(configs.OptionalTools); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return appAgent
+}
+
+// newClient creates an Ollama client adapted to the server version for the
+// commands without a TUI, which print the warning about disabled features.
+func newClient(configs *config.Config, baseURL string, appLogger *logger.Logger) *ollama.OllamaClient {
+	client := ollama.NewOllamaClient(baseURL, appLogger)
+	client.SetHideThoughts(configs.HideThoughts)
+	if features := client.Probe(); len(features.Disabled()) > 0 {
+		log.Printf("Warning: %s", i18n.T("server.old", features.Version, strings.Join(features.Disabled(), ", ")))
+	}
+	return client
+}
+
+// systemPromptFor loads the agent system prompt from Prompt.MD, falling
+// back to the chat prompt if it is missing. Chat-only mode skips the
+// tool-using agent persona.
+func systemPromptFor(configs *config.Config, chatOnly bool, appAgent *agent.Agent) string {
+	if chatOnly {
+		return configs.ChatPrompt
 	}
+	systemPrompt, err := loadPrompt("Prompt.MD")
+	if err != nil {
+		log.Printf("Warning: Could not load system prompt: %v", err)
+		return configs.ChatPrompt
+	}
+	for _, summary := range []string{appAgent.ToolsSummary(), appAgent.RootsSummary(), appAgent.EnvironmentSummary(), appAgent.ProjectSummary()} {
+		if summary != "" {
+			systemPrompt += "\n\n" + summary
+		}
+	}
+	return systemPrompt
+}
+
+func main() {
+	// Subcommands are dispatched before the TUI flags are parsed.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sessions":
+			os.Exit(runSessions(os.Args[2:]))
+		case "eval":
+			os.Exit(runEval(os.Args[2:]))
+		case "batch":
+			os.Exit(runBatch(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "daemon":
+			os.Exit(runDaemon(os.Args[2:]))
+		case "attach":
+			os.Exit(runAttach(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		}
+	}
+
+	// Define a command-line flag for chat-only mode. This allows the user to
+	// start the application without the system prompt that defines the tool-using agent persona.
+	// Agent mode can still be turned on later with /agent on.
+	chatOnly := flag.Bool("chatonly", false, "Enable chat-only mode, without the tool-using agent persona.")
+	accessible := flag.Bool("accessible", false, "Screen-reader friendly mode: no alternate screen, colors or spinners, plain-text updates.")
+	inline := flag.Bool("inline", false, "Render without the alternate screen or mouse capture, keeping the conversation in the terminal's scrollback (for tmux copy-mode).")
+	resumeID := flag.String("resume", "", "Resume a saved session by ID (see 'prompt-cli sessions list').")
+	listen := flag.Bool("listen", false, "Serve the editor bridge (JSON-RPC on stdin/stdout) instead of starting the TUI.")
+	socketPath := flag.String("socket", "", "Accept JSON-RPC requests on this Unix socket while the TUI runs (overrides automation_socket).")
+
+	configs, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	i18n.SetLocale(configs.Locale)
 
 	appLogger := logger.NewLogger()
 	if configs.LogEnabled {
@@ -61,13 +175,7 @@ func main() {
 	}
 	appLogger.Setup()
 
-	// Construct the base URL for the Ollama server, adding the HTTP scheme if missing.
-	var baseURL string
-	if strings.HasPrefix(configs.OllamaServerURL, "http") {
-		baseURL = fmt.Sprintf("%s:%d", configs.OllamaServerURL, configs.OllamaServerPort)
-	} else {
-		baseURL = fmt.Sprintf("http://%s:%d", configs.OllamaServerURL, configs.OllamaServerPort)
-	}
+	baseURL := ollamaBaseURL(configs)
 
 	appLogger.Log(fmt.Sprintf("Connecting to Ollama at: %s", baseURL))
 
@@ -82,54 +190,140 @@ func main() {
 
 	flag.Parse()
 
+	if *accessible {
+		configs.Accessible = true
+	}
+	if *inline {
+		configs.Inline = true
+	}
+	if *socketPath != "" {
+		configs.AutomationSocket = *socketPath
+	}
+
+	if *listen {
+		os.Exit(runListen(configs, appLogger, baseURL, models, *chatOnly))
+	}
+
 	// Determine which model to use: a default from config or user selection.
 	var selectedModel string
 	if configs.DefaultLLM != "" {
 		selectedModel = configs.DefaultLLM
+	} else if configs.Accessible {
+		selectedModel = promptForModel(models, baseURL)
 	} else {
-		fmt.Println("Please select a model:")
-		for i, m := range models {
-			fmt.Printf("%d: %s\n", i+1, m.Name)
+		selectedModel, err = tui.PickModel(models, ollama.ListCapabilities(baseURL, models))
+		if err != nil {
+			log.Fatalf("Error running the model picker: %v", err)
 		}
-
-		// Prompt the user until a valid model index is entered.
-		var choice int
-		reader := bufio.NewReader(os.Stdin)
-		for {
-			fmt.Print("> ")
-			input, _ := reader.ReadString('\n')
-			choice, err = strconv.Atoi(strings.TrimSpace(input))
-			if err == nil && choice > 0 && choice <= len(models) {
-				break
-			}
-			fmt.Println("Invalid choice, please try again.")
+		if selectedModel == "" {
+			return
 		}
-		selectedModel = models[choice-1].Name
 	}
 
-	// Load the system prompt from a Markdown file; fall back to a default prompt if missing.
-	var systemPrompt string
-	if *chatOnly {
-		systemPrompt = "You are a helpful assistant."
+	var caps ollama.Capabilities
+	var maxContext int64
+	details, err := ollama.GetModelDetails(baseURL, selectedModel)
+	if err != nil {
+		appLogger.Log(fmt.Sprintf("Could not read details of %s: %v", selectedModel, err))
 	} else {
-		var err error
-		systemPrompt, err = loadPrompt("Prompt.MD")
-		if err != nil {
-			log.Printf("Warning: Could not load system prompt: %v", err)
-			systemPrompt = "You are a helpful assistant."
-		}
+		caps = ollama.ParseCapabilities(details.Capabilities)
+		maxContext = ollama.ExtractContextLength(details.ModelInfo)
+	}
+	if !caps.CanChat() {
+		log.Fatal(i18n.T("model.embedding_only", selectedModel))
 	}
 
 	// Initialize the components.
 	ollamaClient := ollama.NewOllamaClient(baseURL, appLogger)
-	appAgent := agent.NewAgent(appLogger)
-	m := tui.NewModel(baseURL, selectedModel, configs.ContextLength, systemPrompt, configs.LogEnabled, appLogger, appAgent, ollamaClient)
+	ollamaClient.SetHideThoughts(configs.HideThoughts)
+	appAgent := newAgent(configs, appLogger)
+	systemPrompt := systemPromptFor(configs, false, appAgent)
+	m := tui.NewModel(baseURL, selectedModel, systemPrompt, configs, appLogger, appAgent, ollamaClient)
+	if *chatOnly {
+		m.SetAgentMode(false)
+	}
+	if *resumeID != "" {
+		saved, err := session.Load(*resumeID)
+		if err != nil {
+			log.Fatalf("Error resuming session: %v", err)
+		}
+		m.ResumeSession(saved)
+	}
+	m.ProbeServer()
+	m.SetCapabilities(caps)
+	m.SetModelMaxContext(maxContext)
+	for _, model := range models {
+		if model.Name == selectedModel {
+			m.SetModelEntry(model)
+		}
+	}
 
 	// Create a new Bubble Tea program with alternate screen and mouse support.
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseAllMotion())
+	// Accessible mode stays in the normal screen so output is read linearly,
+	// and inline mode so it stays in the terminal's scrollback.
+	var p *tea.Program
+	switch {
+	case configs.Accessible:
+		p = tea.NewProgram(m)
+	case configs.Inline:
+		m.SetInline(true)
+		p = tea.NewProgram(m)
+	default:
+		p = tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseAllMotion())
+	}
+
+	var listener net.Listener
+	if configs.AutomationSocket != "" {
+		listener, err = rpc.Listen(configs.AutomationSocket)
+		if err != nil {
+			log.Fatalf("Error opening automation socket: %v", err)
+		}
+		go rpc.Serve(listener, tui.AutomationHandler(p))
+		appLogger.Log(fmt.Sprintf("Listening for automation requests on %s", configs.AutomationSocket))
+	}
 
 	// Run the TUI; terminate on error.
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if listener != nil {
+		listener.Close()
+	}
+	appAgent.RemoveScratchDir()
+	if configs.PurgeTrashOnExit {
+		appAgent.PurgeTrash()
+	}
+	if err != nil {
 		log.Fatalf("Alas, there's been an error: %v", err)
 	}
+	if final, ok := final.(*tui.Model); ok {
+		fmt.Print(final.ExitSummary())
+	}
+}
+
+// promptForModel asks for a model with a numbered list on stdin. Accessible
+// mode uses it instead of the full-screen picker because screen readers
+// follow plain line output better.
+func promptForModel(models []types.Model, baseURL string) string {
+	fmt.Println("Please select a model:")
+	for i, m := range models {
+		if caps, err := ollama.GetCapabilities(baseURL, m.Name); err == nil && caps.String() != "" {
+			fmt.Printf("%d: %s (%s)\n", i+1, m.Name, caps)
+		} else {
+			fmt.Printf("%d: %s\n", i+1, m.Name)
+		}
+	}
+
+	// Prompt the user until a valid model index is entered.
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("> ")
+		input, readErr := reader.ReadString('\n')
+		choice, err := strconv.Atoi(strings.TrimSpace(input))
+		if err == nil && choice > 0 && choice <= len(models) {
+			return models[choice-1].Name
+		}
+		if readErr != nil {
+			log.Fatalf("Error reading the model choice: %v", readErr)
+		}
+		fmt.Println("Invalid choice, please try again.")
+	}
 }